@@ -1,14 +1,15 @@
 package main
 
 import (
-	"avito-intern/internal/api"
-	"avito-intern/internal/database"
-	"avito-intern/internal/repository"
-	"avito-intern/internal/services"
+	"avito-intern/pkg/pvz"
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -24,28 +25,37 @@ func main() {
 	dbAddress := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		dbHost, dbPort, dbUser, dbPassword, dbName)
 
-	dbConn, err := database.NewPostgres(dbAddress)
+	dbConn, err := pvz.OpenPostgres(dbAddress)
 
 	if err != nil {
 		log.Fatal("Could not connect to database: ", err)
 	}
 
-	userRepo := repository.NewUserRepository(dbConn)
-	pvzRepo := repository.NewPVZRepository(dbConn)
-	receptionRepo := repository.NewReceptionRepository(dbConn)
-	productRepo := repository.NewProductRepository(dbConn)
+	// Live event streams get the events of every replica through Postgres
+	// notifications.
+	options := optionsFromEnv()
+	options.Events = pvz.NewEventHub(getEnvInt("EVENT_STREAM_REPLAY", 100))
+	options.StreamTokenTTL = getEnvDuration("STREAM_TOKEN_TTL", options.StreamTokenTTL)
+	options.StreamOrigins = getEnvList("STREAM_ALLOWED_ORIGINS")
+	listener := pvz.NewPostgresEventListener(dbAddress, options.Events)
+	go func() {
+		if err := listener.Run(context.Background()); err != nil {
+			log.Printf("Event stream listener stopped: %v", err)
+		}
+	}()
+
+	app, err := pvz.New(pvz.NewPostgresRepositories(dbConn), options)
+	if err != nil {
+		log.Fatal("Could not build the service: ", err)
+	}
 
-	authService := services.NewAuthService(userRepo)
-	pvzService := services.NewPVZService(pvzRepo)
-	receptionService := services.NewReceptionService(receptionRepo)
-	productService := services.NewProductService(productRepo, receptionRepo)
+	if adminEmail := getEnv("ADMIN_EMAIL", ""); adminEmail != "" {
+		if err := app.Services.Auth.EnsureAdmin(adminEmail, getEnv("ADMIN_PASSWORD", "")); err != nil {
+			log.Printf("Could not create admin user: %v", err)
+		}
+	}
 
-	router := api.SetupRouter(
-		authService,
-		pvzService,
-		receptionService,
-		productService,
-	)
+	go app.RunPostgresWorkers(context.Background(), dbConn, workerOptionsFromEnv())
 
 	go func() {
 		metricsRouter := http.NewServeMux()
@@ -56,10 +66,24 @@ func main() {
 		}
 	}()
 
+	grpcPort := getEnv("GRPC_PORT", "9090")
+	grpcServer := app.NewGRPCServer()
+	go func() {
+		listener, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			log.Printf("Failed to start gRPC server: %v", err)
+			return
+		}
+		log.Printf("gRPC server is running on :%s", grpcPort)
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Printf("gRPC server stopped: %v", err)
+		}
+	}()
+
 	port := getEnv("APP_PORT", "8080")
 	log.Printf("Server is running on :%s", port)
 	srv := &http.Server{
-		Handler:      router,
+		Handler:      app.Handler,
 		Addr:         ":" + port,
 		WriteTimeout: 15 * time.Second,
 		ReadTimeout:  15 * time.Second,
@@ -69,9 +93,229 @@ func main() {
 	}
 }
 
+// legacyDeprecatedAt is when the root paths were deprecated in favour of
+// /v1, unless LEGACY_API_DEPRECATED_AT says otherwise.
+var legacyDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// optionsFromEnv overrides the defaults of the service with the environment.
+func optionsFromEnv() pvz.Options {
+	options := pvz.DefaultOptions()
+
+	options.Registration = pvz.RegistrationPolicy{
+		EmployeeSelfRegistration: getEnvBool("EMPLOYEE_SELF_REGISTRATION", options.Registration.EmployeeSelfRegistration),
+		EmployeeApproval:         getEnvBool("EMPLOYEE_REGISTRATION_APPROVAL", options.Registration.EmployeeApproval),
+		InviteTTL:                getEnvDuration("INVITE_TTL", options.Registration.InviteTTL),
+		PasswordResetTTL:         getEnvDuration("PASSWORD_RESET_TTL", options.Registration.PasswordResetTTL),
+		DummyLogin:               getEnvBool("DUMMY_LOGIN", options.Registration.DummyLogin),
+		PasswordResetURL:         getEnv("PASSWORD_RESET_URL", ""),
+	}
+	options.Lockout = pvz.LockoutPolicy{
+		AccountThreshold: getEnvInt("LOGIN_LOCKOUT_THRESHOLD", options.Lockout.AccountThreshold),
+		IPThreshold:      getEnvInt("LOGIN_IP_LOCKOUT_THRESHOLD", options.Lockout.IPThreshold),
+		LockoutDuration:  getEnvDuration("LOGIN_LOCKOUT_DURATION", options.Lockout.LockoutDuration),
+		BaseDelay:        getEnvDuration("LOGIN_DELAY_BASE", options.Lockout.BaseDelay),
+		MaxDelay:         getEnvDuration("LOGIN_DELAY_MAX", options.Lockout.MaxDelay),
+		FailureWindow:    getEnvDuration("LOGIN_FAILURE_WINDOW", options.Lockout.FailureWindow),
+	}
+	options.TwoFactor = pvz.TwoFactorPolicy{
+		Issuer:        getEnv("TWO_FACTOR_ISSUER", options.TwoFactor.Issuer),
+		RequiredRoles: getEnvList("TWO_FACTOR_REQUIRED_ROLES"),
+	}
+	options.APIKeys.SignatureSkew = getEnvDuration("API_KEY_SIGNATURE_SKEW", options.APIKeys.SignatureSkew)
+	options.ImpersonationTTL = getEnvDuration("IMPERSONATION_TTL", options.ImpersonationTTL)
+	options.IdempotencyTTL = getEnvDuration("IDEMPOTENCY_KEY_TTL", options.IdempotencyTTL)
+	options.GraphQL = pvz.GraphQLLimits{
+		MaxDepth:      getEnvInt("GRAPHQL_MAX_DEPTH", options.GraphQL.MaxDepth),
+		MaxComplexity: getEnvInt("GRAPHQL_MAX_COMPLEXITY", options.GraphQL.MaxComplexity),
+	}
+	options.Legacy = pvz.LegacyRoutes{
+		DeprecatedAt: getEnvDate("LEGACY_API_DEPRECATED_AT", legacyDeprecatedAt),
+		Sunset:       getEnvDate("LEGACY_API_SUNSET", options.Legacy.Sunset),
+	}
+	options.Mailer = newMailer()
+	// SCIM provisioning is enabled only when the identity provider has a token.
+	options.SCIMToken = getEnv("SCIM_TOKEN", "")
+	configureOIDC(&options)
+	return options
+}
+
+// workerOptionsFromEnv overrides the defaults of the background workers
+// with the environment. Webhooks get the domain events next to the
+// configured publisher; the warehouse management system may send its
+// commands over a broker.
+func workerOptionsFromEnv() pvz.WorkerOptions {
+	options := pvz.DefaultWorkerOptions()
+
+	options.Publisher = newPublisher()
+	options.Relay = pvz.RelayPolicy{
+		BatchSize:    getEnvInt("OUTBOX_BATCH_SIZE", options.Relay.BatchSize),
+		PollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", options.Relay.PollInterval),
+		BaseBackoff:  getEnvDuration("OUTBOX_RETRY_BASE", options.Relay.BaseBackoff),
+		MaxBackoff:   getEnvDuration("OUTBOX_RETRY_MAX", options.Relay.MaxBackoff),
+		Retention:    getEnvDuration("OUTBOX_RETENTION", options.Relay.Retention),
+	}
+	options.Webhooks = pvz.DispatcherPolicy{
+		Timeout:     getEnvDuration("WEBHOOK_TIMEOUT", options.Webhooks.Timeout),
+		BaseBackoff: getEnvDuration("WEBHOOK_RETRY_BASE", options.Webhooks.BaseBackoff),
+		MaxBackoff:  getEnvDuration("WEBHOOK_RETRY_MAX", options.Webhooks.MaxBackoff),
+		MaxAttempts: getEnvInt("WEBHOOK_MAX_ATTEMPTS", options.Webhooks.MaxAttempts),
+	}
+	options.Consumer = newConsumer()
+	options.Ingest = pvz.IngestorPolicy{
+		MaxAttempts: getEnvInt("INGEST_MAX_ATTEMPTS", options.Ingest.MaxAttempts),
+		Retention:   getEnvDuration("INGEST_RETENTION", options.Ingest.Retention),
+	}
+	return options
+}
+
+// configureOIDC enables external identity provider tokens when OIDC_ISSUER
+// is set, which then requires OIDC_AUDIENCE. OIDC_GROUP_ROLES is a
+// comma-separated list of group:role pairs.
+func configureOIDC(options *pvz.Options) {
+	issuer := getEnv("OIDC_ISSUER", "")
+	if issuer == "" {
+		return
+	}
+	groupRoles := make(map[string]string)
+	for _, pair := range getEnvList("OIDC_GROUP_ROLES") {
+		group, role, ok := strings.Cut(pair, ":")
+		if !ok {
+			log.Printf("Ignoring invalid OIDC_GROUP_ROLES entry %q", pair)
+			continue
+		}
+		groupRoles[strings.TrimSpace(group)] = strings.TrimSpace(role)
+	}
+	verifier, err := pvz.NewOIDCProvider(pvz.OIDCConfig{
+		Issuer:   issuer,
+		Audience: getEnv("OIDC_AUDIENCE", ""),
+		CacheTTL: getEnvDuration("OIDC_CACHE_TTL", time.Hour),
+	})
+	if err != nil {
+		log.Fatal("Could not configure OIDC: ", err)
+	}
+	options.OIDCVerifier = verifier
+	options.OIDC = pvz.OIDCPolicy{
+		GroupsClaim: getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		GroupRoles:  groupRoles,
+	}
+}
+
+// newPublisher selects where the outbox relay publishes domain events.
+func newPublisher() pvz.EventPublisher {
+	switch publisher := getEnv("OUTBOX_PUBLISHER", "log"); publisher {
+	case "file":
+		return pvz.NewFilePublisher(getEnv("OUTBOX_FILE", "events.ndjson"))
+	case "http":
+		headers := http.Header{}
+		if authorization := getEnv("OUTBOX_HTTP_AUTHORIZATION", ""); authorization != "" {
+			headers.Set("Authorization", authorization)
+		}
+		return pvz.NewHTTPPublisher(getEnv("OUTBOX_HTTP_URL", ""), headers, nil)
+	case "kafka":
+		return pvz.NewKafkaPublisher(getEnv("OUTBOX_KAFKA_PROXY_URL", ""), getEnv("OUTBOX_KAFKA_TOPIC", "pvz.events"), nil)
+	default:
+		if publisher != "log" {
+			log.Printf("Unknown OUTBOX_PUBLISHER %q, falling back to log", publisher)
+		}
+		return pvz.NewLogPublisher()
+	}
+}
+
+// newConsumer selects the broker commands are taken from. Nil leaves the
+// ingestion off.
+func newConsumer() pvz.CommandConsumer {
+	switch broker := getEnv("INGEST_BROKER", "none"); broker {
+	case "nats":
+		return pvz.NewNATSConsumer(
+			getEnv("INGEST_NATS_URL", "nats://localhost:4222"),
+			getEnv("INGEST_NATS_SUBJECT", "pvz.commands"),
+			getEnv("INGEST_NATS_QUEUE", "pvz-service"),
+		)
+	case "kafka":
+		return pvz.NewKafkaConsumer(
+			getEnv("INGEST_KAFKA_PROXY_URL", ""),
+			getEnv("INGEST_KAFKA_GROUP", "pvz-service"),
+			getEnv("INGEST_KAFKA_TOPIC", "pvz.commands"),
+			nil,
+		)
+	default:
+		if broker != "none" && broker != "" {
+			log.Printf("Unknown INGEST_BROKER %q, command ingestion is off", broker)
+		}
+		return nil
+	}
+}
+
+func newMailer() pvz.Mailer {
+	switch driver := getEnv("MAIL_DRIVER", "log"); driver {
+	case "smtp":
+		return pvz.NewSMTPMailer(
+			getEnv("SMTP_HOST", "localhost"),
+			getEnv("SMTP_PORT", "25"),
+			getEnv("SMTP_USERNAME", ""),
+			getEnv("SMTP_PASSWORD", ""),
+			getEnv("MAIL_FROM", "noreply@pvz.local"),
+		)
+	case "file":
+		return pvz.NewFileMailer(getEnv("MAIL_FILE", "mail.log"))
+	default:
+		if driver != "log" {
+			log.Printf("Unknown MAIL_DRIVER %q, falling back to log", driver)
+		}
+		return pvz.NewLogMailer()
+	}
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
 	}
 	return fallback
 }
+
+func getEnvBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// getEnvDate reads a date in the 2006-01-02 format.
+func getEnvDate(key string, fallback time.Time) time.Time {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := time.Parse(time.DateOnly, value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,16 @@
+package internalErrors
+
+// ConflictError wraps ErrVersionConflict with the current state of the
+// resource, so that the client can merge its change and retry.
+type ConflictError struct {
+	Err     error
+	Current interface{}
+}
+
+func (e *ConflictError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
@@ -11,4 +11,47 @@ var (
 	ErrInvalidCredentials    = errors.New("invalid credentials")
 	ErrInvalidProductType    = errors.New("invalid product type")
 	ErrUserNotFound          = errors.New("user not found")
+	ErrInviteRequired        = errors.New("invite required")
+	ErrInvalidInvite         = errors.New("invalid invite")
+	ErrInvalidRole           = errors.New("invalid role")
+	ErrAccessDenied          = errors.New("access denied")
+	ErrAccountPending        = errors.New("account pending approval")
+	ErrUserNotPending        = errors.New("user is not pending approval")
+	ErrAccountDeactivated    = errors.New("account deactivated")
+	ErrTokenRevoked          = errors.New("token revoked")
+	ErrCannotModifySelf      = errors.New("cannot modify own account")
+	ErrInvalidUserStatus     = errors.New("invalid user status transition")
+	ErrTooManyLoginAttempts  = errors.New("too many login attempts")
+	ErrTwoFactorNotEnrolled  = errors.New("two-factor authentication not enrolled")
+	ErrTwoFactorEnabled      = errors.New("two-factor authentication already enabled")
+	ErrInvalidTwoFactorCode  = errors.New("invalid two-factor code")
+	ErrInvalidTwoFactorToken = errors.New("invalid two-factor token")
+	ErrInvalidResetToken     = errors.New("invalid password reset token")
+	ErrInvalidPassword       = errors.New("invalid password")
+	ErrPVZNotFound           = errors.New("pvz not found")
+	ErrGroupNotFound         = errors.New("group not found")
+	ErrInvalidFilter         = errors.New("invalid filter")
+	ErrInvalidPatch          = errors.New("invalid patch operation")
+	ErrAPIKeyNotFound        = errors.New("api key not found")
+	ErrInvalidAPIKey         = errors.New("invalid api key")
+	ErrInvalidScope          = errors.New("invalid api key scope")
+	ErrInvalidSignature      = errors.New("invalid request signature")
+	ErrReplayedRequest       = errors.New("replayed request")
+	ErrInvalidExpiration     = errors.New("invalid expiration")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key reused with a different request")
+	ErrIdempotencyInProgress = errors.New("request with the idempotency key in progress")
+	ErrPreconditionFailed    = errors.New("precondition failed")
+	ErrVersionConflict       = errors.New("version conflict")
+	ErrNotAcceptable         = errors.New("no acceptable response format")
+	ErrUnsupportedMediaType  = errors.New("unsupported request format")
+	ErrWebhookNotFound       = errors.New("webhook not found")
+	ErrDeliveryNotFound      = errors.New("webhook delivery not found")
+	ErrInvalidWebhookURL     = errors.New("invalid webhook url")
+	ErrInvalidEventType      = errors.New("invalid event type")
+
+	// Translated database errors, see repository.translateError.
+	ErrAlreadyExists       = errors.New("already exists")
+	ErrReferenceNotFound   = errors.New("referenced entity not found")
+	ErrConstraintViolation = errors.New("constraint violation")
+	ErrInvalidID           = errors.New("invalid identifier")
 )
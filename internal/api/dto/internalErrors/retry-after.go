@@ -0,0 +1,18 @@
+package internalErrors
+
+import "time"
+
+// RetryAfterError wraps a sentinel error with the time the client should wait
+// before trying again.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,11 @@
+package apiKeyDto
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name             string     `json:"name"`
+	Role             string     `json:"role"`
+	Scopes           []string   `json:"scopes"`
+	RequireSignature bool       `json:"requireSignature"`
+	ExpiresAt        *time.Time `json:"expiresAt"`
+}
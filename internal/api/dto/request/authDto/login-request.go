@@ -3,4 +3,5 @@ package authDto
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	ClientIP string `json:"-"`
 }
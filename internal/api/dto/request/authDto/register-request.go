@@ -1,7 +1,8 @@
 package authDto
 
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Role     string `json:"role"`
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	Role        string `json:"role"`
+	InviteToken string `json:"inviteToken,omitempty"`
 }
@@ -0,0 +1,6 @@
+package inviteDto
+
+type CreateInviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
@@ -0,0 +1,5 @@
+package passwordDto
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
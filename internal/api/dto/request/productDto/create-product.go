@@ -3,4 +3,7 @@ package productDto
 type CreateProductRequest struct {
 	Type  string `json:"type"`
 	PvzID string `json:"pvzId"`
+	// ID is set by internal callers that need a stable product ID; the
+	// API always leaves it empty.
+	ID string `json:"-"`
 }
@@ -0,0 +1,8 @@
+package pvzDto
+
+// UpdatePVZRequest changes a pickup point. Version is the one the change is
+// based on, as returned by the last read.
+type UpdatePVZRequest struct {
+	City    string `json:"city"`
+	Version int    `json:"version"`
+}
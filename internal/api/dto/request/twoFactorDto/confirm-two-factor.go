@@ -0,0 +1,5 @@
+package twoFactorDto
+
+type ConfirmRequest struct {
+	Code string `json:"code"`
+}
@@ -0,0 +1,6 @@
+package twoFactorDto
+
+type LoginRequest struct {
+	TwoFactorToken string `json:"twoFactorToken"`
+	Code           string `json:"code"`
+}
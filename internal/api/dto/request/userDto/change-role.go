@@ -0,0 +1,5 @@
+package userDto
+
+type ChangeRoleRequest struct {
+	Role string `json:"role"`
+}
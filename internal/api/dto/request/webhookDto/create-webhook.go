@@ -0,0 +1,7 @@
+package webhookDto
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	PvzIDs     []string `json:"pvzIds"`
+}
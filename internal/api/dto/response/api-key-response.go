@@ -0,0 +1,36 @@
+package response
+
+import (
+	"avito-intern/internal/models"
+	"time"
+)
+
+type APIKeyResponse struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	KeyPrefix        string     `json:"keyPrefix"`
+	Role             string     `json:"role"`
+	Scopes           []string   `json:"scopes"`
+	RequireSignature bool       `json:"requireSignature"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt       *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	Key              string     `json:"key,omitempty"`
+	SigningSecret    string     `json:"signingSecret,omitempty"`
+}
+
+func NewAPIKeyResponse(key *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:               key.ID,
+		Name:             key.Name,
+		KeyPrefix:        key.KeyPrefix,
+		Role:             key.Role,
+		Scopes:           key.Scopes,
+		RequireSignature: key.RequireSignature,
+		CreatedAt:        key.CreatedAt,
+		ExpiresAt:        key.ExpiresAt,
+		LastUsedAt:       key.LastUsedAt,
+		RevokedAt:        key.RevokedAt,
+	}
+}
@@ -0,0 +1,12 @@
+package response
+
+import "time"
+
+type AuditEventResponse struct {
+	ID        string    `json:"id"`
+	ActorID   string    `json:"actorId"`
+	UserID    string    `json:"userId,omitempty"`
+	Action    string    `json:"action"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,10 @@
+package response
+
+import "time"
+
+type ImpersonationResponse struct {
+	Token     string    `json:"token"`
+	UserID    string    `json:"userId"`
+	ActorID   string    `json:"actorId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,11 @@
+package response
+
+import "time"
+
+type InviteResponse struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Token     string    `json:"token"`
+}
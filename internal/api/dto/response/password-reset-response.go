@@ -0,0 +1,5 @@
+package response
+
+type PasswordResetResponse struct {
+	TemporaryPassword string `json:"temporaryPassword"`
+}
@@ -1,7 +1,8 @@
 package response
 
 type UserResponse struct {
-	ID    string `json:"id,omitempty"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID     string `json:"id,omitempty"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	Status string `json:"status,omitempty"`
 }
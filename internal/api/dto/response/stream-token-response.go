@@ -0,0 +1,8 @@
+package response
+
+import "time"
+
+type StreamTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
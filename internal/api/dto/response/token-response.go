@@ -1,5 +1,6 @@
 package response
 
 type TokenResponse struct {
-	Token string `json:"token"`
+	Token                       string `json:"token"`
+	TwoFactorEnrollmentRequired bool   `json:"twoFactorEnrollmentRequired,omitempty"`
 }
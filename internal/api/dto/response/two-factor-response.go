@@ -0,0 +1,16 @@
+package response
+
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	TwoFactorToken    string `json:"twoFactorToken"`
+}
+
+type TwoFactorEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+type TwoFactorConfirmResponse struct {
+	Token         string   `json:"token"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
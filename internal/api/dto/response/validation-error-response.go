@@ -0,0 +1,7 @@
+package response
+
+type ValidationError struct {
+	Location string `json:"location"`
+	Name     string `json:"name,omitempty"`
+	Reason   string `json:"reason"`
+}
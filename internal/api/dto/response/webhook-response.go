@@ -0,0 +1,74 @@
+package response
+
+import (
+	"avito-intern/internal/models"
+	"encoding/json"
+	"time"
+)
+
+type WebhookResponse struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	PvzIDs     []string  `json:"pvzIds"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Secret     string    `json:"secret,omitempty"`
+}
+
+func NewWebhookResponse(subscription *models.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		ID:         subscription.ID,
+		URL:        subscription.URL,
+		EventTypes: nonNil(subscription.EventTypes),
+		PvzIDs:     nonNil(subscription.PvzIDs),
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhookId"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	PvzID          string          `json:"pvzId"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time      `json:"lastAttemptAt,omitempty"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// NewWebhookDeliveryResponse shows the next attempt only for deliveries
+// that are still pending.
+func NewWebhookDeliveryResponse(delivery *models.WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		PvzID:          delivery.PvzID,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+	if delivery.Status == models.WebhookDeliveryPending {
+		resp.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	return resp
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,105 @@
+package scimDto
+
+import (
+	"avito-intern/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const basePath = "/scim/v2"
+
+func NewUser(user *models.SCIMUser) User {
+	groups := []Reference{{
+		Value:   user.User.Role,
+		Display: user.User.Role,
+		Ref:     basePath + "/Groups/" + user.User.Role,
+	}}
+	for _, pvzID := range user.PVZIDs {
+		groups = append(groups, Reference{
+			Value:   pvzID,
+			Display: "pvz-" + pvzID,
+			Ref:     basePath + "/Groups/" + pvzID,
+		})
+	}
+	return User{
+		Schemas:  []string{UserSchema},
+		ID:       user.User.ID,
+		UserName: user.User.Email,
+		Active:   user.User.Status == "" || user.User.Status == "active",
+		Emails:   []Email{{Value: user.User.Email, Primary: true}},
+		Roles:    []Role{{Value: user.User.Role}},
+		Groups:   groups,
+		Meta: Meta{
+			ResourceType: "User",
+			Location:     basePath + "/Users/" + user.User.ID,
+		},
+	}
+}
+
+func NewGroup(group *models.SCIMGroup) Group {
+	members := make([]Reference, 0, len(group.Members))
+	for _, userID := range group.Members {
+		members = append(members, Reference{
+			Value: userID,
+			Ref:   basePath + "/Users/" + userID,
+		})
+	}
+	return Group{
+		Schemas:     []string{GroupSchema},
+		ID:          group.ID,
+		DisplayName: group.DisplayName,
+		Members:     members,
+		Meta: Meta{
+			ResourceType: "Group",
+			Location:     basePath + "/Groups/" + group.ID,
+		},
+	}
+}
+
+func NewListResponse(resources interface{}, total, startIndex, itemsPerPage int) ListResponse {
+	return ListResponse{
+		Schemas:      []string{ListResponseSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: itemsPerPage,
+		Resources:    resources,
+	}
+}
+
+func WriteJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func WriteError(w http.ResponseWriter, status int, scimType, detail string) {
+	WriteJSON(w, status, Error{
+		Schemas:  []string{ErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// ParsePage reads the 1-based startIndex and count query parameters. A
+// missing count is returned as -1 so the service applies its default.
+func ParsePage(r *http.Request) (int, int, error) {
+	startIndex, count := 1, -1
+	var err error
+	if value := r.URL.Query().Get("startIndex"); value != "" {
+		if startIndex, err = strconv.Atoi(value); err != nil {
+			return 0, 0, err
+		}
+		if startIndex < 1 {
+			startIndex = 1
+		}
+	}
+	if value := r.URL.Query().Get("count"); value != "" {
+		if count, err = strconv.Atoi(value); err != nil || count < 0 {
+			return 0, 0, errors.New("invalid count")
+		}
+	}
+	return startIndex, count, nil
+}
@@ -0,0 +1,91 @@
+package scimDto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	UserSchema         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	GroupSchema        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	ListResponseSchema = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	PatchOpSchema      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	ErrorSchema        = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	ContentType = "application/scim+json"
+)
+
+type Email struct {
+	Value   string `json:"value"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type Role struct {
+	Value string `json:"value"`
+}
+
+type Reference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Location     string     `json:"location"`
+	Created      *time.Time `json:"created,omitempty"`
+}
+
+type UserRequest struct {
+	Schemas  []string `json:"schemas"`
+	UserName string   `json:"userName"`
+	Active   *bool    `json:"active,omitempty"`
+	Password string   `json:"password,omitempty"`
+	Emails   []Email  `json:"emails,omitempty"`
+	Roles    []Role   `json:"roles,omitempty"`
+}
+
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type User struct {
+	Schemas  []string    `json:"schemas"`
+	ID       string      `json:"id"`
+	UserName string      `json:"userName"`
+	Active   bool        `json:"active"`
+	Emails   []Email     `json:"emails"`
+	Roles    []Role      `json:"roles"`
+	Groups   []Reference `json:"groups"`
+	Meta     Meta        `json:"meta"`
+}
+
+type Group struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id"`
+	DisplayName string      `json:"displayName"`
+	Members     []Reference `json:"members"`
+	Meta        Meta        `json:"meta"`
+}
+
+type ListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
@@ -0,0 +1,46 @@
+package createApiKey
+
+import (
+	"avito-intern/internal/api/dto/request/apiKeyDto"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/models"
+	"net/http"
+)
+
+type APIKeyService interface {
+	CreateKey(creator models.User, req apiKeyDto.CreateAPIKeyRequest) (*models.APIKey, string, error)
+}
+
+func New(service APIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		creator, _ := middleware.GetUserFromContext(r.Context())
+
+		var req apiKeyDto.CreateAPIKeyRequest
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		if req.Name == "" {
+			problem.Write(w, r, problem.New(problem.InvalidRequest))
+			return
+		}
+
+		key, rawKey, err := service.CreateKey(creator, req)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		resp := response.NewAPIKeyResponse(key)
+		resp.Key = rawKey
+		resp.SigningSecret = key.SigningSecret
+		render.Write(w, r, http.StatusCreated, resp)
+	}
+}
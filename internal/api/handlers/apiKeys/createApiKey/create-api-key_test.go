@@ -0,0 +1,139 @@
+package createApiKey
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/apiKeyDto"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAPIKeyService struct {
+	mock.Mock
+}
+
+var _ APIKeyService = (*mockAPIKeyService)(nil)
+
+func (m *mockAPIKeyService) CreateKey(creator models.User, req apiKeyDto.CreateAPIKeyRequest) (*models.APIKey, string, error) {
+	args := m.Called(creator, req)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*models.APIKey), args.String(1), args.Error(2)
+}
+
+func TestCreateApiKeyHandler(t *testing.T) {
+	admin := models.User{ID: "admin-id", Role: "admin"}
+	body := `{"name":"warehouse","scopes":["receptions:write"]}`
+	req := apiKeyDto.CreateAPIKeyRequest{Name: "warehouse", Scopes: []string{models.ScopeReceptionsWrite}}
+
+	tests := []struct {
+		name           string
+		user           models.User
+		body           string
+		setupMock      func(mock *mockAPIKeyService)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "Key created",
+			user: admin,
+			body: body,
+			setupMock: func(mock *mockAPIKeyService) {
+				mock.On("CreateKey", admin, req).Return(&models.APIKey{
+					ID:            "key-id",
+					Name:          "warehouse",
+					KeyPrefix:     "pvz_12345678",
+					SigningSecret: "pvzsig_secret",
+					Role:          "employee",
+					Scopes:        req.Scopes,
+				}, "pvz_1234567890", nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Moderator cannot create keys",
+			user:           models.User{ID: "moderator-id", Role: "moderator"},
+			body:           body,
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name:           "Missing name",
+			user:           admin,
+			body:           `{"scopes":["pvz:read"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid request"},
+		},
+		{
+			name: "Invalid scopes",
+			user: admin,
+			body: body,
+			setupMock: func(mock *mockAPIKeyService) {
+				mock.On("CreateKey", admin, req).Return(nil, "", internalErrors.ErrInvalidScope)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid scopes"},
+		},
+		{
+			name: "Invalid role",
+			user: admin,
+			body: body,
+			setupMock: func(mock *mockAPIKeyService) {
+				mock.On("CreateKey", admin, req).Return(nil, "", internalErrors.ErrInvalidRole)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid role"},
+		},
+		{
+			name: "Service error",
+			user: admin,
+			body: body,
+			setupMock: func(mock *mockAPIKeyService) {
+				mock.On("CreateKey", admin, req).Return(nil, "", errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockAPIKeyService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/admin/api_keys", strings.NewReader(tt.body))
+			r = r.WithContext(context.WithValue(r.Context(), middleware.UserCtxKey, tt.user))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResp != nil {
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			} else {
+				var resp response.APIKeyResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, "key-id", resp.ID)
+				require.Equal(t, "pvz_1234567890", resp.Key)
+				require.Equal(t, "pvzsig_secret", resp.SigningSecret)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
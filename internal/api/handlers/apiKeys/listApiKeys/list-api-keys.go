@@ -0,0 +1,35 @@
+package listApiKeys
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/models"
+	"net/http"
+)
+
+type APIKeyService interface {
+	ListKeys() ([]*models.APIKey, error)
+}
+
+func New(service APIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		keys, err := service.ListKeys()
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		resp := make([]response.APIKeyResponse, 0, len(keys))
+		for _, key := range keys {
+			resp = append(resp, response.NewAPIKeyResponse(key))
+		}
+		render.List(w, r, http.StatusOK, resp)
+	}
+}
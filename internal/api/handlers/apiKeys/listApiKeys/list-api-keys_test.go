@@ -0,0 +1,87 @@
+package listApiKeys
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAPIKeyService struct {
+	mock.Mock
+}
+
+var _ APIKeyService = (*mockAPIKeyService)(nil)
+
+func (m *mockAPIKeyService) ListKeys() ([]*models.APIKey, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.APIKey), args.Error(1)
+}
+
+func TestListApiKeysHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		setupMock      func(mock *mockAPIKeyService)
+		expectedStatus int
+	}{
+		{
+			name: "Keys listed",
+			role: "admin",
+			setupMock: func(mock *mockAPIKeyService) {
+				mock.On("ListKeys").Return([]*models.APIKey{{ID: "key-id", KeyHash: "secret-hash"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Employee cannot list keys",
+			role:           "employee",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Service error",
+			role: "admin",
+			setupMock: func(mock *mockAPIKeyService) {
+				mock.On("ListKeys").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockAPIKeyService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/admin/api_keys", nil)
+			r = r.WithContext(context.WithValue(r.Context(), middleware.UserCtxKey, models.User{ID: "user-id", Role: tt.role}))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				require.NotContains(t, w.Body.String(), "secret-hash")
+				var resp []response.APIKeyResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(t, resp, 1)
+				require.Empty(t, resp[0].Key)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
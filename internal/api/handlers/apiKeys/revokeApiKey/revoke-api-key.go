@@ -0,0 +1,28 @@
+package revokeApiKey
+
+import (
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type APIKeyService interface {
+	RevokeKey(id string) error
+}
+
+func New(service APIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		if err := service.RevokeKey(chi.URLParam(r, "keyId")); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,87 @@
+package revokeApiKey
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAPIKeyService struct {
+	mock.Mock
+}
+
+var _ APIKeyService = (*mockAPIKeyService)(nil)
+
+func (m *mockAPIKeyService) RevokeKey(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestRevokeApiKeyHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		setupMock      func(mock *mockAPIKeyService)
+		expectedStatus int
+	}{
+		{
+			name: "Key revoked",
+			role: "admin",
+			setupMock: func(mock *mockAPIKeyService) {
+				mock.On("RevokeKey", "key-id").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Moderator cannot revoke keys",
+			role:           "moderator",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Key not found",
+			role: "admin",
+			setupMock: func(mock *mockAPIKeyService) {
+				mock.On("RevokeKey", "key-id").Return(internalErrors.ErrAPIKeyNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Service error",
+			role: "admin",
+			setupMock: func(mock *mockAPIKeyService) {
+				mock.On("RevokeKey", "key-id").Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockAPIKeyService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("keyId", "key-id")
+			ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, middleware.UserCtxKey, models.User{ID: "user-id", Role: tt.role})
+			r := httptest.NewRequest(http.MethodDelete, "/admin/api_keys/key-id", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
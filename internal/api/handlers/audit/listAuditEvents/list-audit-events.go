@@ -0,0 +1,48 @@
+package listAuditEvents
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/models"
+	"net/http"
+)
+
+type AuditService interface {
+	ListAuditEvents(actorID, userID, limit, page string) ([]*models.AuditEvent, error)
+}
+
+func New(service AuditService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		query := r.URL.Query()
+		events, err := service.ListAuditEvents(
+			query.Get("actorId"),
+			query.Get("userId"),
+			query.Get("limit"),
+			query.Get("page"),
+		)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		resp := make([]response.AuditEventResponse, 0, len(events))
+		for _, event := range events {
+			resp = append(resp, response.AuditEventResponse{
+				ID:        event.ID,
+				ActorID:   event.ActorID,
+				UserID:    event.UserID,
+				Action:    event.Action,
+				Details:   event.Details,
+				CreatedAt: event.CreatedAt,
+			})
+		}
+		render.List(w, r, http.StatusOK, resp)
+	}
+}
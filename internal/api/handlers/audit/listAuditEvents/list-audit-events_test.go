@@ -0,0 +1,91 @@
+package listAuditEvents
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAuditService struct {
+	mock.Mock
+}
+
+var _ AuditService = (*mockAuditService)(nil)
+
+func (m *mockAuditService) ListAuditEvents(actorID, userID, limit, page string) ([]*models.AuditEvent, error) {
+	args := m.Called(actorID, userID, limit, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AuditEvent), args.Error(1)
+}
+
+func TestListAuditEventsHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		setupMock      func(mock *mockAuditService)
+		expectedStatus int
+	}{
+		{
+			name: "Events listed",
+			role: "admin",
+			setupMock: func(mock *mockAuditService) {
+				mock.On("ListAuditEvents", "admin-id", "", "10", "2").Return([]*models.AuditEvent{{
+					ID:      "event-id",
+					ActorID: "admin-id",
+					UserID:  "user-id",
+					Action:  models.AuditImpersonationStart,
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Moderator cannot read the audit log",
+			role:           "moderator",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Service error",
+			role: "admin",
+			setupMock: func(mock *mockAuditService) {
+				mock.On("ListAuditEvents", "admin-id", "", "10", "2").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockAuditService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/admin/audit?actorId=admin-id&limit=10&page=2", nil)
+			r = r.WithContext(context.WithValue(r.Context(), middleware.UserCtxKey, models.User{ID: "admin-id", Role: tt.role}))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp []response.AuditEventResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(t, resp, 1)
+				require.Equal(t, models.AuditImpersonationStart, resp[0].Action)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
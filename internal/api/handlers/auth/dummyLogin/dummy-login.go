@@ -3,9 +3,10 @@ package dummyLogin
 import (
 	"avito-intern/internal/api/dto/request/authDto"
 	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
 	"avito-intern/internal/models"
 	"avito-intern/internal/utils"
-	"encoding/json"
 	"github.com/google/uuid"
 	"net/http"
 )
@@ -13,14 +14,12 @@ import (
 func New() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req authDto.DummyLoginRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Invalid request"})
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
 			return
 		}
 		if req.Role != "employee" && req.Role != "moderator" {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Invalid role"})
+			problem.Write(w, r, problem.New(problem.InvalidRole))
 			return
 		}
 
@@ -29,12 +28,11 @@ func New() http.HandlerFunc {
 			Role:  req.Role,
 			Email: "dummy@example.com",
 		}
-		token, err := utils.GenerateJWT(user.ID, user.Role)
+		token, err := utils.GenerateJWT(user.ID, user.Role, user.TokenVersion, false)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Could not generate token"})
+			problem.Write(w, r, err)
 			return
 		}
-		json.NewEncoder(w).Encode(response.TokenResponse{Token: token})
+		render.Write(w, r, http.StatusOK, response.TokenResponse{Token: token})
 	}
 }
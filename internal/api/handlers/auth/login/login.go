@@ -1,40 +1,45 @@
 package login
 
 import (
-	"avito-intern/internal/api/dto/internalErrors"
 	"avito-intern/internal/api/dto/request/authDto"
 	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
 	"avito-intern/internal/models"
-	"encoding/json"
-	"errors"
+	"avito-intern/internal/services"
+	"avito-intern/internal/utils"
 	"net/http"
 )
 
 type AuthService interface {
 	RegisterUser(req authDto.RegisterRequest) (*models.User, error)
-	AuthenticateUser(req authDto.LoginRequest) (string, error)
+	AuthenticateUser(req authDto.LoginRequest) (*services.LoginResult, error)
 }
 
 func New(service AuthService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req authDto.LoginRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request", http.StatusBadRequest)
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
 			return
 		}
+		req.ClientIP = utils.ClientIP(r)
 
-		token, err := service.AuthenticateUser(req)
+		result, err := service.AuthenticateUser(req)
 		if err != nil {
-			if errors.Is(err, internalErrors.ErrInvalidCredentials) {
-				w.WriteHeader(http.StatusUnauthorized)
-				json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Invalid credentials"})
-			} else {
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Could not generate token"})
-			}
+			problem.Write(w, r, err)
 			return
 		}
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(response.TokenResponse{Token: token})
+		if result.TwoFactorRequired {
+			render.Write(w, r, http.StatusOK, response.TwoFactorChallengeResponse{
+				TwoFactorRequired: true,
+				TwoFactorToken:    result.Token,
+			})
+			return
+		}
+		render.Write(w, r, http.StatusOK, response.TokenResponse{
+			Token:                       result.Token,
+			TwoFactorEnrollmentRequired: result.TwoFactorEnrollmentRequired,
+		})
 	}
 }
@@ -4,11 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -17,6 +17,7 @@ import (
 	"avito-intern/internal/api/dto/request/authDto"
 	"avito-intern/internal/api/dto/response"
 	"avito-intern/internal/models"
+	"avito-intern/internal/services"
 )
 
 type mockAuthService struct {
@@ -33,9 +34,12 @@ func (m *mockAuthService) RegisterUser(req authDto.RegisterRequest) (*models.Use
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *mockAuthService) AuthenticateUser(req authDto.LoginRequest) (string, error) {
+func (m *mockAuthService) AuthenticateUser(req authDto.LoginRequest) (*services.LoginResult, error) {
 	args := m.Called(req)
-	return args.String(0), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.LoginResult), args.Error(1)
 }
 
 func TestLoginHandler(t *testing.T) {
@@ -46,6 +50,7 @@ func TestLoginHandler(t *testing.T) {
 		setupMock      func(mock *mockAuthService)
 		expectedStatus int
 		expectedResp   interface{}
+		retryAfter     string
 	}{
 		{
 			name: "Successful login",
@@ -57,11 +62,28 @@ func TestLoginHandler(t *testing.T) {
 				mock.On("AuthenticateUser", authDto.LoginRequest{
 					Email:    "test@example.com",
 					Password: "password123",
-				}).Return("token123", nil)
+					ClientIP: "192.0.2.1",
+				}).Return(&services.LoginResult{Token: "token123"}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedResp:   response.TokenResponse{Token: "token123"},
 		},
+		{
+			name: "Two-factor challenge",
+			credentials: authDto.LoginRequest{
+				Email:    "test@example.com",
+				Password: "password123",
+			},
+			setupMock: func(mock *mockAuthService) {
+				mock.On("AuthenticateUser", authDto.LoginRequest{
+					Email:    "test@example.com",
+					Password: "password123",
+					ClientIP: "192.0.2.1",
+				}).Return(&services.LoginResult{Token: "intermediate", TwoFactorRequired: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   response.TwoFactorChallengeResponse{TwoFactorRequired: true, TwoFactorToken: "intermediate"},
+		},
 		{
 			name: "Invalid credentials",
 			credentials: authDto.LoginRequest{
@@ -72,7 +94,8 @@ func TestLoginHandler(t *testing.T) {
 				mock.On("AuthenticateUser", authDto.LoginRequest{
 					Email:    "test@example.com",
 					Password: "wrongpass",
-				}).Return("", internalErrors.ErrInvalidCredentials)
+					ClientIP: "192.0.2.1",
+				}).Return(nil, internalErrors.ErrInvalidCredentials)
 			},
 			expectedStatus: http.StatusUnauthorized,
 			expectedResp:   response.ErrorResponse{Message: "Invalid credentials"},
@@ -87,10 +110,31 @@ func TestLoginHandler(t *testing.T) {
 				mock.On("AuthenticateUser", authDto.LoginRequest{
 					Email:    "test@example.com",
 					Password: "password123",
-				}).Return("", errors.New("internal error"))
+					ClientIP: "192.0.2.1",
+				}).Return(nil, errors.New("internal error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
-			expectedResp:   response.ErrorResponse{Message: "Could not generate token"},
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+		{
+			name: "Too many attempts",
+			credentials: authDto.LoginRequest{
+				Email:    "test@example.com",
+				Password: "password123",
+			},
+			setupMock: func(mock *mockAuthService) {
+				mock.On("AuthenticateUser", authDto.LoginRequest{
+					Email:    "test@example.com",
+					Password: "password123",
+					ClientIP: "192.0.2.1",
+				}).Return(nil, &internalErrors.RetryAfterError{
+					Err:        internalErrors.ErrTooManyLoginAttempts,
+					RetryAfter: 1500 * time.Millisecond,
+				})
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedResp:   response.ErrorResponse{Message: "Too many login attempts"},
+			retryAfter:     "2",
 		},
 		{
 			name:           "Invalid request body",
@@ -125,14 +169,20 @@ func TestLoginHandler(t *testing.T) {
 			handler.ServeHTTP(w, req)
 
 			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
 
 			if tt.invalidBody {
-				body, err := io.ReadAll(w.Body)
-				require.NoError(t, err)
-				require.Equal(t, tt.expectedResp, strings.TrimSpace(string(body)))
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+				require.Equal(t, tt.expectedResp, errorResp.Message)
 			} else {
 				var resp interface{}
-				if tt.expectedStatus == http.StatusOK {
+				if _, ok := tt.expectedResp.(response.TwoFactorChallengeResponse); ok {
+					var challengeResp response.TwoFactorChallengeResponse
+					err = json.NewDecoder(w.Body).Decode(&challengeResp)
+					resp = challengeResp
+				} else if tt.expectedStatus == http.StatusOK {
 					var successResp response.TokenResponse
 					err = json.NewDecoder(w.Body).Decode(&successResp)
 					resp = successResp
@@ -0,0 +1,31 @@
+package loginTwoFactor
+
+import (
+	"avito-intern/internal/api/dto/request/twoFactorDto"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/utils"
+	"net/http"
+)
+
+type TwoFactorService interface {
+	Verify(twoFactorToken, code, clientIP string) (string, error)
+}
+
+func New(service TwoFactorService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req twoFactorDto.LoginRequest
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		token, err := service.Verify(req.TwoFactorToken, req.Code, utils.ClientIP(r))
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		render.Write(w, r, http.StatusOK, response.TokenResponse{Token: token})
+	}
+}
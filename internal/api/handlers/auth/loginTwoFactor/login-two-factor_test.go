@@ -0,0 +1,127 @@
+package loginTwoFactor
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/twoFactorDto"
+	"avito-intern/internal/api/dto/response"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTwoFactorService struct {
+	mock.Mock
+}
+
+var _ TwoFactorService = (*mockTwoFactorService)(nil)
+
+func (m *mockTwoFactorService) Verify(twoFactorToken, code, clientIP string) (string, error) {
+	args := m.Called(twoFactorToken, code, clientIP)
+	return args.String(0), args.Error(1)
+}
+
+func TestLoginTwoFactorHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(mock *mockTwoFactorService)
+		expectedStatus int
+		expectedResp   interface{}
+		retryAfter     string
+	}{
+		{
+			name: "Successful verification",
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Verify", "intermediate", "123456", "192.0.2.1").Return("token123", nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   response.TokenResponse{Token: "token123"},
+		},
+		{
+			name: "Invalid code",
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Verify", "intermediate", "123456", "192.0.2.1").Return("", internalErrors.ErrInvalidTwoFactorCode)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid two-factor code"},
+		},
+		{
+			name: "Invalid intermediate token",
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Verify", "intermediate", "123456", "192.0.2.1").Return("", internalErrors.ErrInvalidTwoFactorToken)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp:   response.ErrorResponse{Message: "Invalid two-factor token"},
+		},
+		{
+			name: "Too many attempts",
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Verify", "intermediate", "123456", "192.0.2.1").Return("", &internalErrors.RetryAfterError{
+					Err:        internalErrors.ErrTooManyLoginAttempts,
+					RetryAfter: 10 * time.Second,
+				})
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedResp:   response.ErrorResponse{Message: "Too many login attempts"},
+			retryAfter:     "10",
+		},
+		{
+			name: "Internal server error",
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Verify", "intermediate", "123456", "192.0.2.1").Return("", errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+		{
+			name:           "Invalid request body",
+			body:           "invalid json",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockTwoFactorService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			body := tt.body
+			if body == "" {
+				raw, _ := json.Marshal(twoFactorDto.LoginRequest{TwoFactorToken: "intermediate", Code: "123456"})
+				body = string(raw)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/login/2fa", bytes.NewReader([]byte(body)))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+			switch expected := tt.expectedResp.(type) {
+			case response.TokenResponse:
+				var resp response.TokenResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, expected, resp)
+			case response.ErrorResponse:
+				var resp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, expected, resp)
+			default:
+				var resp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, "Invalid request", resp.Message)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -36,9 +37,69 @@ func (m *mockUserRepository) GetUserByEmail(email string) (*models.User, error)
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *mockUserRepository) GetUserByID(id string) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) UpdateUserStatus(id, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) ListUsers(email, role, status string, limit, offset int) ([]*models.User, error) {
+	args := m.Called(email, role, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) CountUsers(email, role, status string) (int, error) {
+	args := m.Called(email, role, status)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockUserRepository) UpdateUserRole(id, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) UpdateUserPassword(id, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+type mockInviteRepository struct {
+	mock.Mock
+}
+
+func (m *mockInviteRepository) CreateInvite(invite *models.Invite) error {
+	args := m.Called(invite)
+	return args.Error(0)
+}
+
+func (m *mockInviteRepository) GetInviteByTokenHash(tokenHash string) (*models.Invite, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invite), args.Error(1)
+}
+
+func (m *mockInviteRepository) AcceptInvite(id string, usedAt time.Time, user *models.User) error {
+	args := m.Called(id, usedAt, user)
+	return args.Error(0)
+}
+
 func createMockAuthService(t *testing.T) (*services.AuthService, *mockUserRepository) {
 	mockRepo := new(mockUserRepository)
-	authService := services.NewAuthService(mockRepo)
+	authService := services.NewAuthService(mockRepo, new(mockInviteRepository), nil, nil, nil, nil, services.RegistrationPolicy{
+		EmployeeSelfRegistration: true,
+	})
 	return authService, mockRepo
 }
 
@@ -96,18 +157,31 @@ func TestRegisterHandler(t *testing.T) {
 			expectedResp:   response.ErrorResponse{Message: "Invalid role"},
 		},
 		{
-			name: "Internal server error",
+			name: "Moderator without invite",
 			registerData: authDto.RegisterRequest{
 				Email:    "test@example.com",
 				Password: "password123",
 				Role:     "moderator",
 			},
+			setupMockRepo: func(mockRepo *mockUserRepository) {
+				mockRepo.On("GetUserByEmail", "test@example.com").Return(nil, internalErrors.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Invite required"},
+		},
+		{
+			name: "Internal server error",
+			registerData: authDto.RegisterRequest{
+				Email:    "test@example.com",
+				Password: "password123",
+				Role:     "employee",
+			},
 			setupMockRepo: func(mockRepo *mockUserRepository) {
 				mockRepo.On("GetUserByEmail", "test@example.com").Return(nil, internalErrors.ErrUserNotFound)
 				mockRepo.On("CreateUser", mock.AnythingOfType("*models.User")).Return(errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
-			expectedResp:   response.ErrorResponse{Message: "Error registering user"},
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
 		},
 		{
 			name:           "Invalid request body",
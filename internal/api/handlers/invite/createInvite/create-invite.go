@@ -0,0 +1,45 @@
+package createInvite
+
+import (
+	"avito-intern/internal/api/dto/request/inviteDto"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/services"
+	"net/http"
+)
+
+func New(service *services.AuthService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireAnyRole(r.Context(), "moderator", "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		creator, _ := middleware.GetUserFromContext(r.Context())
+
+		var req inviteDto.CreateInviteRequest
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		if req.Email == "" {
+			problem.Write(w, r, problem.New(problem.InvalidRequest))
+			return
+		}
+
+		invite, token, err := service.CreateInvite(creator, req)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		render.Write(w, r, http.StatusCreated, response.InviteResponse{
+			ID:        invite.ID,
+			Email:     invite.Email,
+			Role:      invite.Role,
+			ExpiresAt: invite.ExpiresAt,
+			Token:     token,
+		})
+	}
+}
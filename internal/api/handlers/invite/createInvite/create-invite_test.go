@@ -0,0 +1,218 @@
+package createInvite
+
+import (
+	"avito-intern/internal/api/dto/request/inviteDto"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"avito-intern/internal/services"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUserRepository struct {
+	mock.Mock
+}
+
+func (m *mockUserRepository) CreateUser(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) GetUserByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) GetUserByID(id string) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) UpdateUserStatus(id, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) ListUsers(email, role, status string, limit, offset int) ([]*models.User, error) {
+	args := m.Called(email, role, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) CountUsers(email, role, status string) (int, error) {
+	args := m.Called(email, role, status)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockUserRepository) UpdateUserRole(id, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) UpdateUserPassword(id, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+type mockInviteRepository struct {
+	mock.Mock
+}
+
+func (m *mockInviteRepository) CreateInvite(invite *models.Invite) error {
+	args := m.Called(invite)
+	return args.Error(0)
+}
+
+func (m *mockInviteRepository) GetInviteByTokenHash(tokenHash string) (*models.Invite, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invite), args.Error(1)
+}
+
+func (m *mockInviteRepository) AcceptInvite(id string, usedAt time.Time, user *models.User) error {
+	args := m.Called(id, usedAt, user)
+	return args.Error(0)
+}
+
+func createUserContext(role string) context.Context {
+	user := models.User{
+		ID:   "creator-id",
+		Role: role,
+	}
+	return context.WithValue(context.Background(), middleware.UserCtxKey, user)
+}
+
+func TestCreateInviteHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		inviteData     inviteDto.CreateInviteRequest
+		userRole       string
+		invalidBody    bool
+		setupMock      func(mockRepo *mockInviteRepository)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:       "Successful invite",
+			inviteData: inviteDto.CreateInviteRequest{Email: "moderator@example.com", Role: "moderator"},
+			userRole:   "admin",
+			setupMock: func(mockRepo *mockInviteRepository) {
+				mockRepo.On("CreateInvite", mock.MatchedBy(func(invite *models.Invite) bool {
+					return invite.Email == "moderator@example.com" && invite.CreatedBy == "creator-id"
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Employee cannot invite",
+			inviteData:     inviteDto.CreateInviteRequest{Email: "employee@example.com", Role: "employee"},
+			userRole:       "employee",
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name:           "Moderator cannot invite moderator",
+			inviteData:     inviteDto.CreateInviteRequest{Email: "moderator@example.com", Role: "moderator"},
+			userRole:       "moderator",
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name:           "Moderator cannot invite admin",
+			inviteData:     inviteDto.CreateInviteRequest{Email: "admin@example.com", Role: "admin"},
+			userRole:       "moderator",
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name:           "Invalid role",
+			inviteData:     inviteDto.CreateInviteRequest{Email: "someone@example.com", Role: "superuser"},
+			userRole:       "admin",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid role"},
+		},
+		{
+			name:           "Missing email",
+			inviteData:     inviteDto.CreateInviteRequest{Role: "employee"},
+			userRole:       "admin",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid request"},
+		},
+		{
+			name:           "Invalid request body",
+			invalidBody:    true,
+			userRole:       "admin",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid request"},
+		},
+		{
+			name:       "Repository error",
+			inviteData: inviteDto.CreateInviteRequest{Email: "employee@example.com", Role: "employee"},
+			userRole:   "admin",
+			setupMock: func(mockRepo *mockInviteRepository) {
+				mockRepo.On("CreateInvite", mock.AnythingOfType("*models.Invite")).Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockInviteRepo := new(mockInviteRepository)
+			if tt.setupMock != nil {
+				tt.setupMock(mockInviteRepo)
+			}
+			service := services.NewAuthService(new(mockUserRepository), mockInviteRepo, nil, nil, nil, nil, services.RegistrationPolicy{})
+			handler := New(service)
+
+			var req *http.Request
+			if tt.invalidBody {
+				req = httptest.NewRequest(http.MethodPost, "/invites", strings.NewReader("invalid json"))
+			} else {
+				body, _ := json.Marshal(tt.inviteData)
+				req = httptest.NewRequest(http.MethodPost, "/invites", bytes.NewReader(body))
+			}
+			req = req.WithContext(createUserContext(tt.userRole))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				var inviteResp response.InviteResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&inviteResp))
+				require.Equal(t, tt.inviteData.Email, inviteResp.Email)
+				require.Equal(t, tt.inviteData.Role, inviteResp.Role)
+				require.NotEmpty(t, inviteResp.Token)
+			} else {
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			}
+
+			mockInviteRepo.AssertExpectations(t)
+		})
+	}
+}
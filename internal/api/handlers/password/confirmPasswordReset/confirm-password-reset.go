@@ -0,0 +1,29 @@
+package confirmPasswordReset
+
+import (
+	"avito-intern/internal/api/dto/request/passwordDto"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"net/http"
+)
+
+type AuthService interface {
+	ResetPassword(token, password string) error
+}
+
+func New(service AuthService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req passwordDto.ResetPasswordRequest
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		if err := service.ResetPassword(req.Token, req.Password); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,101 @@
+package confirmPasswordReset
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAuthService struct {
+	mock.Mock
+}
+
+var _ AuthService = (*mockAuthService)(nil)
+
+func (m *mockAuthService) ResetPassword(token, password string) error {
+	args := m.Called(token, password)
+	return args.Error(0)
+}
+
+func TestConfirmPasswordResetHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(mock *mockAuthService)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "Password reset",
+			body: `{"token":"token123","password":"newpassword"}`,
+			setupMock: func(mock *mockAuthService) {
+				mock.On("ResetPassword", "token123", "newpassword").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Invalid token",
+			body: `{"token":"token123","password":"newpassword"}`,
+			setupMock: func(mock *mockAuthService) {
+				mock.On("ResetPassword", "token123", "newpassword").Return(internalErrors.ErrInvalidResetToken)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid or expired reset token"},
+		},
+		{
+			name: "Empty password",
+			body: `{"token":"token123","password":""}`,
+			setupMock: func(mock *mockAuthService) {
+				mock.On("ResetPassword", "token123", "").Return(internalErrors.ErrInvalidPassword)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid password"},
+		},
+		{
+			name: "Internal server error",
+			body: `{"token":"token123","password":"newpassword"}`,
+			setupMock: func(mock *mockAuthService) {
+				mock.On("ResetPassword", "token123", "newpassword").Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+		{
+			name:           "Invalid request body",
+			body:           "invalid json",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid request"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockAuthService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/password/reset", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResp != nil {
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
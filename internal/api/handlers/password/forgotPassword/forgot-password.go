@@ -0,0 +1,33 @@
+package forgotPassword
+
+import (
+	"avito-intern/internal/api/dto/request/passwordDto"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"net/http"
+)
+
+type AuthService interface {
+	ForgotPassword(email string) error
+}
+
+func New(service AuthService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req passwordDto.ForgotPasswordRequest
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		if req.Email == "" {
+			problem.Write(w, r, problem.New(problem.InvalidRequest))
+			return
+		}
+
+		if err := service.ForgotPassword(req.Email); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,88 @@
+package forgotPassword
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAuthService struct {
+	mock.Mock
+}
+
+var _ AuthService = (*mockAuthService)(nil)
+
+func (m *mockAuthService) ForgotPassword(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func TestForgotPasswordHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(mock *mockAuthService)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "Reset requested",
+			body: `{"email":"test@example.com"}`,
+			setupMock: func(mock *mockAuthService) {
+				mock.On("ForgotPassword", "test@example.com").Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "Missing email",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid request"},
+		},
+		{
+			name:           "Invalid request body",
+			body:           "invalid json",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid request"},
+		},
+		{
+			name: "Mailer error",
+			body: `{"email":"test@example.com"}`,
+			setupMock: func(mock *mockAuthService) {
+				mock.On("ForgotPassword", "test@example.com").Return(errors.New("smtp error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockAuthService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/password/forgot", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResp != nil {
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -1,57 +1,46 @@
 package createProduct
 
 import (
-	"avito-intern/internal/api/dto/internalErrors"
 	"avito-intern/internal/api/dto/request/productDto"
-	"avito-intern/internal/api/dto/response"
 	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
 	"avito-intern/internal/metrics"
 	"avito-intern/internal/services"
-	"encoding/json"
-	"errors"
 	"net/http"
 )
 
 func New(productService *services.ProductService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := middleware.RequireRole(r.Context(), "employee"); err != nil {
-			w.WriteHeader(http.StatusForbidden)
-			json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Access denied"})
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		receptionsVersion, err := middleware.IfMatchVersion(r)
+		if err != nil {
+			problem.Write(w, r, err)
 			return
 		}
 
 		var req productDto.CreateProductRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PvzID == "" {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Invalid request"})
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		if req.PvzID == "" {
+			problem.Write(w, r, problem.New(problem.InvalidRequest))
 			return
 		}
 
-		product, err := productService.AddProduct(&req)
+		product, err := productService.AddProduct(&req, receptionsVersion)
 		if err != nil {
-			switch {
-			case errors.Is(err, internalErrors.ErrNoActiveReception):
-				{
-					w.WriteHeader(http.StatusBadRequest)
-					json.NewEncoder(w).Encode(response.ErrorResponse{Message: "No active reception"})
-					return
-				}
-			case errors.Is(err, internalErrors.ErrInvalidProductType):
-				{
-					w.WriteHeader(http.StatusBadRequest)
-					json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Invalid product type"})
-					return
-				}
-			default:
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Invalid product type"})
-				return
-			}
+			problem.Write(w, r, middleware.PreconditionError(r, err))
+			return
 		}
 
 		metrics.ProductAddedCount.Inc()
 
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(product)
+		render.Write(w, r, http.StatusCreated, product)
 	}
 }
@@ -1,12 +1,10 @@
 package closeReception
 
 import (
-	"avito-intern/internal/api/dto/internalErrors"
-	"avito-intern/internal/api/dto/response"
 	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
 	"avito-intern/internal/services"
-	"encoding/json"
-	"errors"
 	"github.com/go-chi/chi/v5"
 	"net/http"
 )
@@ -14,23 +12,21 @@ import (
 func New(service *services.ReceptionService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := middleware.RequireRole(r.Context(), "employee"); err != nil {
-			w.WriteHeader(http.StatusForbidden)
-			json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Access denied"})
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		receptionsVersion, err := middleware.IfMatchVersion(r)
+		if err != nil {
+			problem.Write(w, r, err)
 			return
 		}
 		pvzId := chi.URLParam(r, "pvzId")
 
-		reception, err := service.CloseLastReception(pvzId)
+		reception, err := service.CloseLastReception(pvzId, receptionsVersion)
 		if err != nil {
-			if errors.Is(err, internalErrors.ErrNoActiveReception) {
-				w.WriteHeader(http.StatusBadRequest)
-				json.NewEncoder(w).Encode(response.ErrorResponse{Message: "No active reception"})
-			} else {
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Internal server error"})
-			}
+			problem.Write(w, r, middleware.PreconditionError(r, err))
 			return
 		}
-		json.NewEncoder(w).Encode(reception)
+		render.Write(w, r, http.StatusOK, reception)
 	}
 }
@@ -24,8 +24,8 @@ type mockReceptionRepository struct {
 	mock.Mock
 }
 
-func (m *mockReceptionRepository) CreateReception(reception *models.Reception) error {
-	args := m.Called(reception)
+func (m *mockReceptionRepository) CreateReception(reception *models.Reception, receptionsVersion int) error {
+	args := m.Called(reception, receptionsVersion)
 	return args.Error(0)
 }
 
@@ -37,11 +37,19 @@ func (m *mockReceptionRepository) GetActiveReception(pvzID string) (*models.Rece
 	return args.Get(0).(*models.Reception), args.Error(1)
 }
 
-func (m *mockReceptionRepository) CloseReception(receptionID string) error {
-	args := m.Called(receptionID)
+func (m *mockReceptionRepository) CloseReception(receptionID string, expectedVersion, receptionsVersion int) error {
+	args := m.Called(receptionID, expectedVersion, receptionsVersion)
 	return args.Error(0)
 }
 
+func (m *mockReceptionRepository) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	args := m.Called(pvzIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reception), args.Error(1)
+}
+
 func createUserContext(role string) context.Context {
 	user := models.User{
 		ID:    uuid.New().String(),
@@ -56,6 +64,7 @@ func TestCloseReceptionHandler(t *testing.T) {
 		name           string
 		pvzID          string
 		userRole       string
+		ifMatch        string
 		setupMock      func(mockReceptionRepo *mockReceptionRepository)
 		expectedStatus int
 		expectedResp   interface{}
@@ -73,7 +82,7 @@ func TestCloseReceptionHandler(t *testing.T) {
 				}
 
 				mockReceptionRepo.On("GetActiveReception", "test-pvz").Return(reception, nil)
-				mockReceptionRepo.On("CloseReception", "reception-id").Return(nil)
+				mockReceptionRepo.On("CloseReception", "reception-id", 0, 0).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedResp: &models.Reception{
@@ -82,6 +91,44 @@ func TestCloseReceptionHandler(t *testing.T) {
 				Status: "close",
 			},
 		},
+		{
+			name:     "Matching If-Match",
+			pvzID:    "test-pvz",
+			userRole: "employee",
+			ifMatch:  middleware.VersionETag(4),
+			setupMock: func(mockReceptionRepo *mockReceptionRepository) {
+				reception := &models.Reception{ID: "reception-id", PvzID: "test-pvz", Status: "in_progress", Version: 1}
+				mockReceptionRepo.On("GetActiveReception", "test-pvz").Return(reception, nil)
+				mockReceptionRepo.On("CloseReception", "reception-id", 1, 4).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: &models.Reception{
+				ID:     "reception-id",
+				PvzID:  "test-pvz",
+				Status: "close",
+			},
+		},
+		{
+			name:     "Stale If-Match",
+			pvzID:    "test-pvz",
+			userRole: "employee",
+			ifMatch:  middleware.VersionETag(3),
+			setupMock: func(mockReceptionRepo *mockReceptionRepository) {
+				reception := &models.Reception{ID: "reception-id", PvzID: "test-pvz", Status: "in_progress", Version: 1}
+				mockReceptionRepo.On("GetActiveReception", "test-pvz").Return(reception, nil)
+				mockReceptionRepo.On("CloseReception", "reception-id", 1, 3).Return(internalErrors.ErrVersionConflict)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedResp:   response.ErrorResponse{Message: "Resource was modified"},
+		},
+		{
+			name:           "Unknown If-Match tag",
+			pvzID:          "test-pvz",
+			userRole:       "employee",
+			ifMatch:        `"6c6a2a79929188f3860cd3068b2289ca"`,
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedResp:   response.ErrorResponse{Message: "Resource was modified"},
+		},
 		{
 			name:     "No active reception",
 			pvzID:    "test-pvz",
@@ -105,7 +152,7 @@ func TestCloseReceptionHandler(t *testing.T) {
 				}
 
 				mockReceptionRepo.On("GetActiveReception", "test-pvz").Return(reception, nil)
-				mockReceptionRepo.On("CloseReception", "reception-id").Return(errors.New("database error"))
+				mockReceptionRepo.On("CloseReception", "reception-id", 0, 0).Return(errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
@@ -133,6 +180,9 @@ func TestCloseReceptionHandler(t *testing.T) {
 			r.Post("/pvz/{pvzId}/close_reception", New(receptionService))
 
 			req := httptest.NewRequest(http.MethodPost, "/pvz/"+tt.pvzID+"/close_reception", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set(middleware.IfMatchHeader, tt.ifMatch)
+			}
 
 			ctx := createUserContext(tt.userRole)
 			req = req.WithContext(ctx)
@@ -38,6 +38,24 @@ func (m *mockPVZRepository) ListPVZ(limit, offset int, startDate, endDate *time.
 	return args.Get(0).([]*models.PVZ), args.Error(1)
 }
 
+func (m *mockPVZRepository) GetPVZByID(id string) (*models.PVZ, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZ), args.Error(1)
+}
+
+func (m *mockPVZRepository) CountPVZ() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockPVZRepository) UpdatePVZ(pvz *models.PVZ, expectedVersion int) error {
+	args := m.Called(pvz, expectedVersion)
+	return args.Error(0)
+}
+
 func createUserContext(role string) context.Context {
 	user := models.User{
 		ID:    uuid.New().String(),
@@ -1,12 +1,9 @@
 package deleteLastProduct
 
 import (
-	"avito-intern/internal/api/dto/internalErrors"
-	"avito-intern/internal/api/dto/response"
 	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
 	"avito-intern/internal/services"
-	"encoding/json"
-	"errors"
 	"github.com/go-chi/chi/v5"
 	"net/http"
 )
@@ -14,34 +11,19 @@ import (
 func New(service *services.ProductService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := middleware.RequireRole(r.Context(), "employee"); err != nil {
-			w.WriteHeader(http.StatusForbidden)
-			json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Access denied"})
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		receptionsVersion, err := middleware.IfMatchVersion(r)
+		if err != nil {
+			problem.Write(w, r, err)
 			return
 		}
 		pvzId := chi.URLParam(r, "pvzId")
-		err := service.DeleteLastProduct(pvzId)
 
-		if err != nil {
-			switch {
-			case errors.Is(err, internalErrors.ErrNoActiveReception):
-				{
-					w.WriteHeader(http.StatusBadRequest)
-					json.NewEncoder(w).Encode(response.ErrorResponse{Message: "No active reception"})
-					return
-				}
-			case errors.Is(err, internalErrors.ErrProductNotFound):
-				{
-					w.WriteHeader(http.StatusBadRequest)
-					json.NewEncoder(w).Encode(response.ErrorResponse{Message: "No products in reception"})
-					return
-				}
-			default:
-				{
-					w.WriteHeader(http.StatusInternalServerError)
-					json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Internal server error"})
-					return
-				}
-			}
+		if err := service.DeleteLastProduct(pvzId, receptionsVersion); err != nil {
+			problem.Write(w, r, middleware.PreconditionError(r, err))
+			return
 		}
 
 		w.WriteHeader(http.StatusOK)
@@ -24,8 +24,8 @@ type mockProductRepository struct {
 	mock.Mock
 }
 
-func (m *mockProductRepository) AddProduct(product *models.Product) error {
-	args := m.Called(product)
+func (m *mockProductRepository) AddProduct(product *models.Product, receptionsVersion int) error {
+	args := m.Called(product, receptionsVersion)
 	return args.Error(0)
 }
 
@@ -37,17 +37,25 @@ func (m *mockProductRepository) GetLastProduct(receptionID string) (*models.Prod
 	return args.Get(0).(*models.Product), args.Error(1)
 }
 
-func (m *mockProductRepository) DeleteProduct(id string) error {
-	args := m.Called(id)
+func (m *mockProductRepository) DeleteProduct(id string, receptionsVersion int) error {
+	args := m.Called(id, receptionsVersion)
 	return args.Error(0)
 }
 
+func (m *mockProductRepository) ListProducts(receptionIDs []string) ([]*models.Product, error) {
+	args := m.Called(receptionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
 type mockReceptionRepository struct {
 	mock.Mock
 }
 
-func (m *mockReceptionRepository) CreateReception(reception *models.Reception) error {
-	args := m.Called(reception)
+func (m *mockReceptionRepository) CreateReception(reception *models.Reception, receptionsVersion int) error {
+	args := m.Called(reception, receptionsVersion)
 	return args.Error(0)
 }
 
@@ -59,11 +67,19 @@ func (m *mockReceptionRepository) GetActiveReception(pvzID string) (*models.Rece
 	return args.Get(0).(*models.Reception), args.Error(1)
 }
 
-func (m *mockReceptionRepository) CloseReception(receptionID string) error {
-	args := m.Called(receptionID)
+func (m *mockReceptionRepository) CloseReception(receptionID string, expectedVersion, receptionsVersion int) error {
+	args := m.Called(receptionID, expectedVersion, receptionsVersion)
 	return args.Error(0)
 }
 
+func (m *mockReceptionRepository) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	args := m.Called(pvzIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reception), args.Error(1)
+}
+
 func createUserContext(role string) context.Context {
 	user := models.User{
 		ID:    uuid.New().String(),
@@ -100,7 +116,7 @@ func TestDeleteLastProductHandler(t *testing.T) {
 				}
 
 				mockProductRepo.On("GetLastProduct", "reception-id").Return(product, nil)
-				mockProductRepo.On("DeleteProduct", "product-id").Return(nil)
+				mockProductRepo.On("DeleteProduct", "product-id", 0).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedResp:   nil,
@@ -148,7 +164,7 @@ func TestDeleteLastProductHandler(t *testing.T) {
 				}
 
 				mockProductRepo.On("GetLastProduct", "reception-id").Return(product, nil)
-				mockProductRepo.On("DeleteProduct", "product-id").Return(errors.New("database error"))
+				mockProductRepo.On("DeleteProduct", "product-id", 0).Return(errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
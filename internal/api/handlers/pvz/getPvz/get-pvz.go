@@ -0,0 +1,30 @@
+package getPvz
+
+import (
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/services"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func New(service *services.PVZService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireAnyRole(r.Context(), "employee", "moderator"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		pvz, err := service.GetPVZ(chi.URLParam(r, "pvzId"))
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		if middleware.NotModified(w, r, middleware.VersionETag(pvz.Version)) {
+			return
+		}
+		render.Write(w, r, http.StatusOK, pvz)
+	}
+}
@@ -0,0 +1,158 @@
+package getPvz
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"avito-intern/internal/services"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPVZRepository struct {
+	mock.Mock
+}
+
+func (m *mockPVZRepository) CreatePVZ(pvz *models.PVZ) error {
+	args := m.Called(pvz)
+	return args.Error(0)
+}
+
+func (m *mockPVZRepository) ListPVZ(limit, offset int, startDate, endDate *time.Time) ([]*models.PVZ, error) {
+	args := m.Called(limit, offset, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PVZ), args.Error(1)
+}
+
+func (m *mockPVZRepository) GetPVZByID(id string) (*models.PVZ, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZ), args.Error(1)
+}
+
+func (m *mockPVZRepository) CountPVZ() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockPVZRepository) UpdatePVZ(pvz *models.PVZ, expectedVersion int) error {
+	args := m.Called(pvz, expectedVersion)
+	return args.Error(0)
+}
+
+func createUserContext(role string) context.Context {
+	user := models.User{
+		ID:    uuid.New().String(),
+		Email: "test@example.com",
+		Role:  role,
+	}
+	return context.WithValue(context.Background(), middleware.UserCtxKey, user)
+}
+
+func TestGetPVZHandler(t *testing.T) {
+	pvz := &models.PVZ{
+		ID:               "pvz-1",
+		City:             "Москва",
+		RegistrationDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Version:          2,
+	}
+	etag := middleware.VersionETag(2)
+
+	tests := []struct {
+		name           string
+		userRole       string
+		ifNoneMatch    string
+		setupMock      func(mockRepo *mockPVZRepository)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:     "Successful get",
+			userRole: "moderator",
+			setupMock: func(mockRepo *mockPVZRepository) {
+				mockRepo.On("GetPVZByID", "pvz-1").Return(pvz, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   pvz,
+		},
+		{
+			name:        "Not modified",
+			userRole:    "employee",
+			ifNoneMatch: etag,
+			setupMock: func(mockRepo *mockPVZRepository) {
+				mockRepo.On("GetPVZByID", "pvz-1").Return(pvz, nil)
+			},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:     "PVZ not found",
+			userRole: "employee",
+			setupMock: func(mockRepo *mockPVZRepository) {
+				mockRepo.On("GetPVZByID", "pvz-1").Return(nil, internalErrors.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   response.ErrorResponse{Message: "PVZ not found"},
+		},
+		{
+			name:           "Access denied",
+			userRole:       "user",
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockPVZRepository)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+
+			r := chi.NewRouter()
+			r.Get("/pvz/{pvzId}", New(services.NewPVZService(mockRepo)))
+
+			req := httptest.NewRequest(http.MethodGet, "/pvz/pvz-1", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set(middleware.IfNoneMatchHeader, tt.ifNoneMatch)
+			}
+			req = req.WithContext(createUserContext(tt.userRole))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			switch tt.expectedStatus {
+			case http.StatusOK:
+				require.Equal(t, etag, w.Header().Get(middleware.ETagHeader))
+				var resp models.PVZ
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, pvz.ID, resp.ID)
+				require.Equal(t, pvz.City, resp.City)
+			case http.StatusNotModified:
+				require.Equal(t, etag, w.Header().Get(middleware.ETagHeader))
+				require.Empty(t, w.Body.String())
+			default:
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
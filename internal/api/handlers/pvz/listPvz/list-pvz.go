@@ -1,11 +1,10 @@
 package listPvz
 
 import (
-	"avito-intern/internal/api/dto/response"
 	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
 	"avito-intern/internal/services"
-	"encoding/json"
-	"log"
 	"net/http"
 )
 
@@ -14,8 +13,7 @@ func New(service *services.PVZService) http.HandlerFunc {
 
 		user, err := middleware.GetUserFromContext(r.Context())
 		if err != nil || (user.Role != "employee" && user.Role != "moderator") {
-			w.WriteHeader(http.StatusForbidden)
-			json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Access denied"})
+			problem.Write(w, r, problem.New(problem.AccessDenied))
 			return
 		}
 
@@ -25,12 +23,18 @@ func New(service *services.PVZService) http.HandlerFunc {
 		limitStr := r.URL.Query().Get("limit")
 
 		pvzs, err := service.ListPVZ(limitStr, pageStr, startDateStr, endDateStr)
-		log.Println(err)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Internal server error"})
+			problem.Write(w, r, err)
 			return
 		}
-		json.NewEncoder(w).Encode(pvzs)
+		etag, err := middleware.ETag(pvzs)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		if middleware.NotModified(w, r, etag) {
+			return
+		}
+		render.List(w, r, http.StatusOK, pvzs)
 	}
 }
@@ -36,6 +36,24 @@ func (m *mockPVZRepository) ListPVZ(limit, offset int, startDate, endDate *time.
 	return args.Get(0).([]*models.PVZ), args.Error(1)
 }
 
+func (m *mockPVZRepository) GetPVZByID(id string) (*models.PVZ, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZ), args.Error(1)
+}
+
+func (m *mockPVZRepository) CountPVZ() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockPVZRepository) UpdatePVZ(pvz *models.PVZ, expectedVersion int) error {
+	args := m.Called(pvz, expectedVersion)
+	return args.Error(0)
+}
+
 func createUserContext(role string) context.Context {
 	user := models.User{
 		ID:    uuid.New().String(),
@@ -221,3 +239,26 @@ func TestListPVZHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestListPVZHandler_NotModified(t *testing.T) {
+	mockRepo := new(mockPVZRepository)
+	mockRepo.On("ListPVZ", 10, 0, (*time.Time)(nil), (*time.Time)(nil)).Return([]*models.PVZ{
+		{ID: "pvz-1", City: "Москва", RegistrationDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+	handler := New(services.NewPVZService(mockRepo))
+
+	req := httptest.NewRequest(http.MethodGet, "/pvz", nil).WithContext(createUserContext("employee"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get(middleware.ETagHeader)
+	require.NotEmpty(t, etag)
+
+	req = httptest.NewRequest(http.MethodGet, "/pvz", nil).WithContext(createUserContext("employee"))
+	req.Header.Set(middleware.IfNoneMatchHeader, etag)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Empty(t, w.Body.String())
+	require.Equal(t, etag, w.Header().Get(middleware.ETagHeader))
+}
@@ -0,0 +1,39 @@
+package listPvzReceptions
+
+import (
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/services"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func New(pvzService *services.PVZService, productService *services.ProductService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireAnyRole(r.Context(), "employee", "moderator"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		pvzId := chi.URLParam(r, "pvzId")
+
+		// The version is read first: a change in between makes the ETag
+		// older than the receptions, so If-Match fails instead of passing for
+		// receptions the client has not seen.
+		pvz, err := pvzService.GetPVZ(pvzId)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		if middleware.NotModified(w, r, middleware.VersionETag(pvz.ReceptionsVersion)) {
+			return
+		}
+		receptions, err := productService.ListPVZReceptions(pvzId)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		render.List(w, r, http.StatusOK, receptions)
+	}
+}
@@ -0,0 +1,229 @@
+package listPvzReceptions
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"avito-intern/internal/services"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPVZRepository struct {
+	mock.Mock
+}
+
+func (m *mockPVZRepository) CreatePVZ(pvz *models.PVZ) error {
+	args := m.Called(pvz)
+	return args.Error(0)
+}
+
+func (m *mockPVZRepository) ListPVZ(limit, offset int, startDate, endDate *time.Time) ([]*models.PVZ, error) {
+	args := m.Called(limit, offset, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PVZ), args.Error(1)
+}
+
+func (m *mockPVZRepository) GetPVZByID(id string) (*models.PVZ, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZ), args.Error(1)
+}
+
+func (m *mockPVZRepository) CountPVZ() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockPVZRepository) UpdatePVZ(pvz *models.PVZ, expectedVersion int) error {
+	args := m.Called(pvz, expectedVersion)
+	return args.Error(0)
+}
+
+type mockProductRepository struct {
+	mock.Mock
+}
+
+func (m *mockProductRepository) AddProduct(product *models.Product, receptionsVersion int) error {
+	args := m.Called(product, receptionsVersion)
+	return args.Error(0)
+}
+
+func (m *mockProductRepository) GetLastProduct(receptionID string) (*models.Product, error) {
+	args := m.Called(receptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *mockProductRepository) DeleteProduct(id string, receptionsVersion int) error {
+	args := m.Called(id, receptionsVersion)
+	return args.Error(0)
+}
+
+func (m *mockProductRepository) ListProducts(receptionIDs []string) ([]*models.Product, error) {
+	args := m.Called(receptionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
+type mockReceptionRepository struct {
+	mock.Mock
+}
+
+func (m *mockReceptionRepository) CreateReception(reception *models.Reception, receptionsVersion int) error {
+	args := m.Called(reception, receptionsVersion)
+	return args.Error(0)
+}
+
+func (m *mockReceptionRepository) GetActiveReception(pvzID string) (*models.Reception, error) {
+	args := m.Called(pvzID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reception), args.Error(1)
+}
+
+func (m *mockReceptionRepository) CloseReception(receptionID string, expectedVersion, receptionsVersion int) error {
+	args := m.Called(receptionID, expectedVersion, receptionsVersion)
+	return args.Error(0)
+}
+
+func (m *mockReceptionRepository) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	args := m.Called(pvzIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reception), args.Error(1)
+}
+
+func createUserContext(role string) context.Context {
+	user := models.User{
+		ID:    uuid.New().String(),
+		Email: "test@example.com",
+		Role:  role,
+	}
+	return context.WithValue(context.Background(), middleware.UserCtxKey, user)
+}
+
+func TestListPVZReceptionsHandler(t *testing.T) {
+	receptions := []*models.Reception{
+		{ID: "reception-1", PvzID: "pvz-1", Status: "close", DateTime: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "reception-2", PvzID: "pvz-1", Status: "in_progress", DateTime: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	products := []*models.Product{
+		{ID: "product-1", Type: "обувь", ReceptionID: "reception-1", DateTime: time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC)},
+	}
+	pvz := &models.PVZ{ID: "pvz-1", City: "Москва", Version: 1, ReceptionsVersion: 5}
+	etag := middleware.VersionETag(5)
+
+	tests := []struct {
+		name           string
+		userRole       string
+		ifNoneMatch    string
+		setupMock      func(pvzRepo *mockPVZRepository, receptionRepo *mockReceptionRepository, productRepo *mockProductRepository)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:     "Successful list",
+			userRole: "employee",
+			setupMock: func(pvzRepo *mockPVZRepository, receptionRepo *mockReceptionRepository, productRepo *mockProductRepository) {
+				pvzRepo.On("GetPVZByID", "pvz-1").Return(pvz, nil)
+				receptionRepo.On("ListReceptions", []string{"pvz-1"}).Return(receptions, nil)
+				productRepo.On("ListProducts", []string{"reception-1", "reception-2"}).Return(products, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "Not modified",
+			userRole:    "moderator",
+			ifNoneMatch: etag,
+			setupMock: func(pvzRepo *mockPVZRepository, receptionRepo *mockReceptionRepository, productRepo *mockProductRepository) {
+				pvzRepo.On("GetPVZByID", "pvz-1").Return(pvz, nil)
+			},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:     "PVZ not found",
+			userRole: "employee",
+			setupMock: func(pvzRepo *mockPVZRepository, receptionRepo *mockReceptionRepository, productRepo *mockProductRepository) {
+				pvzRepo.On("GetPVZByID", "pvz-1").Return(nil, internalErrors.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   response.ErrorResponse{Message: "PVZ not found"},
+		},
+		{
+			name:           "Access denied",
+			userRole:       "user",
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvzRepo := new(mockPVZRepository)
+			receptionRepo := new(mockReceptionRepository)
+			productRepo := new(mockProductRepository)
+			if tt.setupMock != nil {
+				tt.setupMock(pvzRepo, receptionRepo, productRepo)
+			}
+
+			r := chi.NewRouter()
+			r.Get("/pvz/{pvzId}/receptions", New(
+				services.NewPVZService(pvzRepo),
+				services.NewProductService(productRepo, receptionRepo),
+			))
+
+			req := httptest.NewRequest(http.MethodGet, "/pvz/pvz-1/receptions", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set(middleware.IfNoneMatchHeader, tt.ifNoneMatch)
+			}
+			req = req.WithContext(createUserContext(tt.userRole))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			switch tt.expectedStatus {
+			case http.StatusOK:
+				require.Equal(t, etag, w.Header().Get(middleware.ETagHeader))
+				var resp []*models.ReceptionWithProducts
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(t, resp, 2)
+				require.Equal(t, "reception-1", resp[0].Reception.ID)
+				require.Len(t, resp[0].Products, 1)
+				require.Empty(t, resp[1].Products)
+			case http.StatusNotModified:
+				require.Empty(t, w.Body.String())
+			default:
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			}
+
+			pvzRepo.AssertExpectations(t)
+			receptionRepo.AssertExpectations(t)
+			productRepo.AssertExpectations(t)
+		})
+	}
+}
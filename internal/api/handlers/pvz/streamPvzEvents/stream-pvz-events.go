@@ -0,0 +1,146 @@
+package streamPvzEvents
+
+import (
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/models"
+	"avito-intern/internal/services"
+	"avito-intern/internal/stream"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ResetEvent tells a resuming subscriber that events may be missing since
+// its last one, so it should load the state of the pickup point again.
+const ResetEvent = "stream.reset"
+
+// keepAlive is how often an idle stream writes something, so that proxies
+// do not close it.
+var keepAlive = 15 * time.Second
+
+// New streams the events of a pickup point as Server-Sent Events, with the
+// outbox sequence as the event ID.
+func New(service *services.PVZService, hub *stream.Hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subscription, missed, ok := subscribe(w, r, service, hub)
+		if !ok {
+			return
+		}
+		defer subscription.Close()
+
+		rc := http.NewResponseController(w)
+		// The server's write timeout is meant for ordinary responses.
+		_ = rc.SetWriteDeadline(time.Time{})
+		w.Header().Set("Content-Type", render.EventStream)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		for _, event := range missed {
+			if writeEvent(w, event) != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+		for {
+			var err error
+			select {
+			case <-r.Context().Done():
+				return
+			case event, open := <-subscription.Events():
+				if !open {
+					return
+				}
+				err = writeEvent(w, event)
+			case <-ticker.C:
+				_, err = fmt.Fprint(w, ": keep-alive\n\n")
+			}
+			if err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// subscribe checks the request and subscribes to the pickup point, after
+// Last-Event-ID if given. The events missed since are followed by a reset
+// event when the hub cannot tell that they are all of them. Failures are
+// written as problems.
+func subscribe(w http.ResponseWriter, r *http.Request, service *services.PVZService, hub *stream.Hub) (*stream.Subscription, []*models.OutboxEvent, bool) {
+	if err := middleware.RequireAnyRole(r.Context(), "employee", "moderator"); err != nil {
+		problem.Write(w, r, problem.New(problem.AccessDenied))
+		return nil, nil, false
+	}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	var after int64
+	if lastEventID != "" {
+		var err error
+		if after, err = strconv.ParseInt(lastEventID, 10, 64); err != nil {
+			problem.Write(w, r, problem.Wrap(problem.InvalidRequest, err))
+			return nil, nil, false
+		}
+	}
+
+	pvz, err := assignedPVZ(r, service)
+	if err != nil {
+		problem.Write(w, r, err)
+		return nil, nil, false
+	}
+	if lastEventID == "" {
+		return hub.Subscribe(pvz.ID), nil, true
+	}
+	subscription, missed, complete := hub.Resume(pvz.ID, after)
+	if !complete {
+		missed = append(missed, &models.OutboxEvent{Type: ResetEvent, PvzID: pvz.ID})
+	}
+	return subscription, missed, true
+}
+
+// assignedPVZ returns the pickup point of the route if the caller may follow
+// it: employees only their assigned ones. API keys are limited by scopes.
+func assignedPVZ(r *http.Request, service *services.PVZService) (*models.PVZ, error) {
+	pvz, err := service.GetPVZ(chi.URLParam(r, "pvzId"))
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := middleware.GetServiceFromContext(r.Context()); ok {
+		return pvz, nil
+	}
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	if err = service.CheckAssigned(user, pvz.ID); err != nil {
+		return nil, err
+	}
+	return pvz, nil
+}
+
+// writeEvent writes event as a Server-Sent Event. A reset has no ID, so that
+// the subscriber keeps its Last-Event-ID.
+func writeEvent(w http.ResponseWriter, event *models.OutboxEvent) error {
+	if event.Type == ResetEvent {
+		_, err := fmt.Fprintf(w, "event: %s\ndata: {}\n\n", ResetEvent)
+		return err
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data)
+	return err
+}
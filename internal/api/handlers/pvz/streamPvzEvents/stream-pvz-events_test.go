@@ -0,0 +1,409 @@
+package streamPvzEvents
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"avito-intern/internal/services"
+	"avito-intern/internal/stream"
+	"avito-intern/internal/utils"
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+type mockPVZRepository struct {
+	mock.Mock
+}
+
+func (m *mockPVZRepository) CreatePVZ(pvz *models.PVZ) error {
+	args := m.Called(pvz)
+	return args.Error(0)
+}
+
+func (m *mockPVZRepository) ListPVZ(limit, offset int, startDate, endDate *time.Time) ([]*models.PVZ, error) {
+	args := m.Called(limit, offset, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PVZ), args.Error(1)
+}
+
+func (m *mockPVZRepository) GetPVZByID(id string) (*models.PVZ, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZ), args.Error(1)
+}
+
+func (m *mockPVZRepository) CountPVZ() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockPVZRepository) UpdatePVZ(pvz *models.PVZ, expectedVersion int) error {
+	args := m.Called(pvz, expectedVersion)
+	return args.Error(0)
+}
+
+type mockAssignmentRepository struct {
+	mock.Mock
+}
+
+func (m *mockAssignmentRepository) AssignPVZ(userID, pvzID string) error {
+	args := m.Called(userID, pvzID)
+	return args.Error(0)
+}
+
+func (m *mockAssignmentRepository) UnassignPVZ(userID, pvzID string) error {
+	args := m.Called(userID, pvzID)
+	return args.Error(0)
+}
+
+func (m *mockAssignmentRepository) ListUserPVZs(userID string) ([]string, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockAssignmentRepository) ListPVZUsers(pvzID string) ([]string, error) {
+	args := m.Called(pvzID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func withRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := models.User{ID: "user-1", Email: "test@example.com", Role: role}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), middleware.UserCtxKey, user)))
+		})
+	}
+}
+
+func newTestServer(t *testing.T, hub *stream.Hub) *httptest.Server {
+	mockRepo := new(mockPVZRepository)
+	mockRepo.On("GetPVZByID", "pvz-1").Return(&models.PVZ{ID: "pvz-1", City: "Москва"}, nil)
+	service := services.NewPVZService(mockRepo)
+
+	r := chi.NewRouter()
+	r.Use(withRole("employee"))
+	r.Get("/pvz/{pvzId}/events", New(service, hub))
+	r.Get("/pvz/{pvzId}/events/ws", NewWebSocket(service, hub, []string{"https://pvz.example.com"}))
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testEvent(sequence int64) *models.OutboxEvent {
+	return &models.OutboxEvent{
+		Sequence:   sequence,
+		ID:         "event",
+		Type:       models.EventReceptionOpened,
+		PvzID:      "pvz-1",
+		Payload:    json.RawMessage(`{"id":"reception-1"}`),
+		OccurredAt: time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// readEvent reads the fields of the next Server-Sent Event.
+func readEvent(t *testing.T, reader *bufio.Reader) map[string]string {
+	fields := make(map[string]string)
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return fields
+		}
+		name, value, _ := strings.Cut(line, ": ")
+		fields[name] = value
+	}
+}
+
+func TestStreamPVZEventsHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		userRole       string
+		lastEventID    string
+		setupMock      func(mockRepo *mockPVZRepository)
+		expectedStatus int
+		expectedResp   response.ErrorResponse
+	}{
+		{
+			name:           "Access denied",
+			userRole:       "user",
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name:           "Invalid Last-Event-ID",
+			userRole:       "moderator",
+			lastEventID:    "event-1",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid request"},
+		},
+		{
+			name:     "PVZ not found",
+			userRole: "employee",
+			setupMock: func(mockRepo *mockPVZRepository) {
+				mockRepo.On("GetPVZByID", "pvz-1").Return(nil, internalErrors.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   response.ErrorResponse{Message: "PVZ not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockPVZRepository)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+
+			r := chi.NewRouter()
+			r.Use(withRole(tt.userRole))
+			r.Get("/pvz/{pvzId}/events", New(services.NewPVZService(mockRepo), stream.NewHub(0)))
+
+			req := httptest.NewRequest(http.MethodGet, "/pvz/pvz-1/events", nil)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			var errorResp response.ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+			require.Equal(t, tt.expectedResp, errorResp)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStreamPVZEventsHandler_ServerSentEvents(t *testing.T) {
+	hub := stream.NewHub(10)
+	server := newTestServer(t, hub)
+	require.NoError(t, hub.Publish(context.Background(), testEvent(1)))
+	require.NoError(t, hub.Publish(context.Background(), testEvent(2)))
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/pvz/pvz-1/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	replayed := readEvent(t, reader)
+	require.Equal(t, "2", replayed["id"])
+	require.Equal(t, models.EventReceptionOpened, replayed["event"])
+	var event models.OutboxEvent
+	require.NoError(t, json.Unmarshal([]byte(replayed["data"]), &event))
+	require.Equal(t, *testEvent(2), event)
+
+	closed := testEvent(3)
+	closed.Type = models.EventReceptionClosed
+	require.NoError(t, hub.Publish(context.Background(), closed))
+	live := readEvent(t, reader)
+	require.Equal(t, "3", live["id"])
+	require.Equal(t, models.EventReceptionClosed, live["event"])
+}
+
+func TestStreamPVZEventsHandler_Reset(t *testing.T) {
+	hub := stream.NewHub(1)
+	server := newTestServer(t, hub)
+	require.NoError(t, hub.Publish(context.Background(), testEvent(5)))
+	require.NoError(t, hub.Publish(context.Background(), testEvent(8)))
+
+	resp, err := http.Get(server.URL + "/pvz/pvz-1/events?lastEventId=5")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	require.Equal(t, "8", readEvent(t, reader)["id"])
+	reset := readEvent(t, reader)
+	require.Equal(t, map[string]string{"event": ResetEvent, "data": "{}"}, reset)
+}
+
+func TestStreamPVZEventsHandler_WebSocket(t *testing.T) {
+	hub := stream.NewHub(10)
+	server := newTestServer(t, hub)
+	require.NoError(t, hub.Publish(context.Background(), testEvent(1)))
+
+	config, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+"/pvz/pvz-1/events/ws?lastEventId=0", server.URL)
+	require.NoError(t, err)
+	ws, err := websocket.DialConfig(config)
+	require.NoError(t, err)
+	defer ws.Close()
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	var replayed models.OutboxEvent
+	require.NoError(t, websocket.JSON.Receive(ws, &replayed))
+	require.Equal(t, *testEvent(1), replayed)
+	var reset map[string]string
+	require.NoError(t, websocket.JSON.Receive(ws, &reset))
+	require.Equal(t, map[string]string{"type": ResetEvent}, reset, "the hub has not seen the event before 1")
+
+	require.NoError(t, hub.Publish(context.Background(), testEvent(2)))
+	var live models.OutboxEvent
+	require.NoError(t, websocket.JSON.Receive(ws, &live))
+	require.Equal(t, int64(2), live.Sequence)
+}
+
+func TestStreamPVZEventsHandler_WebSocketOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{name: "Same origin", origin: "", allowed: true},
+		{name: "Allowed origin", origin: "https://pvz.example.com", allowed: true},
+		{name: "Other origin", origin: "https://attacker.example.com", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, stream.NewHub(0))
+			origin := tt.origin
+			if origin == "" {
+				origin = server.URL
+			}
+
+			config, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+"/pvz/pvz-1/events/ws", origin)
+			require.NoError(t, err)
+			ws, err := websocket.DialConfig(config)
+			if !tt.allowed {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			ws.Close()
+		})
+	}
+}
+
+func TestStreamTokenHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		userRole       string
+		setupMock      func(mockRepo *mockPVZRepository)
+		expectedStatus int
+	}{
+		{
+			name:     "Token issued",
+			userRole: "employee",
+			setupMock: func(mockRepo *mockPVZRepository) {
+				mockRepo.On("GetPVZByID", "pvz-1").Return(&models.PVZ{ID: "pvz-1", City: "Москва"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Access denied",
+			userRole:       "user",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "PVZ not found",
+			userRole: "moderator",
+			setupMock: func(mockRepo *mockPVZRepository) {
+				mockRepo.On("GetPVZByID", "pvz-1").Return(nil, internalErrors.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockPVZRepository)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+
+			r := chi.NewRouter()
+			r.Use(withRole(tt.userRole))
+			r.Post("/pvz/{pvzId}/events/token", NewToken(services.NewPVZService(mockRepo), time.Minute))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/pvz/pvz-1/events/token", nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp response.StreamTokenResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.WithinDuration(t, time.Now().Add(time.Minute), resp.ExpiresAt, 5*time.Second)
+
+				claims, err := utils.ParseStreamJWT(resp.Token, "pvz-1")
+				require.NoError(t, err)
+				require.Equal(t, "user-1", claims["user_id"])
+				_, err = utils.ParseStreamJWT(resp.Token, "pvz-2")
+				require.Error(t, err, "the token is bound to its pickup point")
+				_, err = utils.ParseJWT(resp.Token)
+				require.Error(t, err, "the token is not an access token")
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStreamPVZEventsHandler_Assignments(t *testing.T) {
+	tests := []struct {
+		name           string
+		userRole       string
+		assigned       []string
+		expectedStatus int
+	}{
+		{name: "Assigned employee", userRole: "employee", assigned: []string{"pvz-0", "pvz-1"}, expectedStatus: http.StatusOK},
+		{name: "Unassigned employee", userRole: "employee", assigned: []string{"pvz-2"}, expectedStatus: http.StatusForbidden},
+		{name: "Moderator", userRole: "moderator", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockPVZRepository)
+			mockRepo.On("GetPVZByID", "pvz-1").Return(&models.PVZ{ID: "pvz-1", City: "Москва"}, nil)
+			assignments := new(mockAssignmentRepository)
+			if tt.assigned != nil {
+				assignments.On("ListUserPVZs", "user-1").Return(tt.assigned, nil)
+			}
+			service := services.NewPVZService(mockRepo)
+			service.SetAssignments(assignments)
+
+			r := chi.NewRouter()
+			r.Use(withRole(tt.userRole))
+			r.Get("/pvz/{pvzId}/events", New(service, stream.NewHub(0)))
+			r.Post("/pvz/{pvzId}/events/token", NewToken(service, time.Minute))
+			server := httptest.NewServer(r)
+			t.Cleanup(server.Close)
+
+			resp, err := http.Post(server.URL+"/pvz/pvz-1/events/token", "application/json", nil)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, tt.expectedStatus, resp.StatusCode, "stream token")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/pvz/pvz-1/events", nil)
+			require.NoError(t, err)
+			resp, err = http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.expectedStatus, resp.StatusCode, "event stream")
+			assignments.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package streamPvzEvents
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/services"
+	"avito-intern/internal/utils"
+	"net/http"
+	"time"
+)
+
+// NewToken issues a stream token of the caller for the event streams of a
+// pickup point they may follow, valid for ttl. Browsers open the streams with it in the
+// access_token query parameter.
+func NewToken(service *services.PVZService, ttl time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireAnyRole(r.Context(), "employee", "moderator"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		user, _ := middleware.GetUserFromContext(r.Context())
+
+		pvz, err := assignedPVZ(r, service)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		expiresAt := time.Now().Add(ttl)
+		token, err := utils.GenerateStreamJWT(user.ID, user.Role, user.TokenVersion, user.TwoFactor, user.ImpersonatedBy, user.ImpersonatorTokenVersion, pvz.ID, expiresAt)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		render.Write(w, r, http.StatusOK, response.StreamTokenResponse{
+			Token:     token,
+			ExpiresAt: expiresAt,
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package streamPvzEvents
+
+import (
+	"avito-intern/internal/models"
+	"avito-intern/internal/services"
+	"avito-intern/internal/stream"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// writeTimeout is how long a WebSocket subscriber may take to accept a
+// message.
+const writeTimeout = 10 * time.Second
+
+// NewWebSocket streams the events of a pickup point over a WebSocket as text
+// messages with the JSON of the event. A reset is the message
+// {"type":"stream.reset"}. Messages from the client are ignored.
+//
+// Browsers send the Origin of the page, which must be the API's own or one
+// of origins, such as "https://pvz.example.com". Clients that send no Origin
+// are not browsers and are accepted.
+func NewWebSocket(service *services.PVZService, hub *stream.Hub, origins []string) http.HandlerFunc {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[strings.TrimSuffix(origin, "/")] = true
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		subscription, missed, ok := subscribe(w, r, service, hub)
+		if !ok {
+			return
+		}
+		defer subscription.Close()
+
+		server := websocket.Server{
+			Handshake: func(_ *websocket.Config, r *http.Request) error {
+				return checkOrigin(r, allowed)
+			},
+			Handler: func(ws *websocket.Conn) {
+				serveWebSocket(r.Context(), ws, subscription, missed)
+			},
+		}
+		server.ServeHTTP(w, r)
+	}
+}
+
+// checkOrigin accepts requests without Origin, from the host of the request
+// and from the allowed origins.
+func checkOrigin(r *http.Request, allowed map[string]bool) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" || allowed[origin] {
+		return nil
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if parsed.Host != r.Host {
+		return fmt.Errorf("origin %q is not allowed", origin)
+	}
+	return nil
+}
+
+func serveWebSocket(ctx context.Context, ws *websocket.Conn, subscription *stream.Subscription, missed []*models.OutboxEvent) {
+	// The connection keeps the deadlines of the server's timeouts.
+	_ = ws.SetDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		defer cancel()
+		var message []byte
+		for websocket.Message.Receive(ws, &message) == nil {
+		}
+	}()
+
+	for _, event := range missed {
+		if send(ws, event) != nil {
+			return
+		}
+	}
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case event, open := <-subscription.Events():
+			if !open {
+				return
+			}
+			err = send(ws, event)
+		case <-ticker.C:
+			err = ping(ws)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func send(ws *websocket.Conn, event *models.OutboxEvent) error {
+	_ = ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if event.Type == ResetEvent {
+		return websocket.JSON.Send(ws, map[string]string{"type": ResetEvent})
+	}
+	return websocket.JSON.Send(ws, event)
+}
+
+func ping(ws *websocket.Conn) error {
+	_ = ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	ws.PayloadType = websocket.PingFrame
+	defer func() { ws.PayloadType = websocket.TextFrame }()
+	_, err := ws.Write(nil)
+	return err
+}
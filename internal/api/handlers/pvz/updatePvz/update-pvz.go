@@ -0,0 +1,48 @@
+package updatePvz
+
+import (
+	"avito-intern/internal/api/dto/request/pvzDto"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/services"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func New(service *services.PVZService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "moderator"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		var req pvzDto.UpdatePVZRequest
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		if req.Version < 1 {
+			problem.Write(w, r, problem.New(problem.InvalidRequest))
+			return
+		}
+		// If-Match holds the version too, as the ETag of GET /pvz/{pvzId}.
+		version, err := middleware.IfMatchVersion(r)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		if version != 0 && version != req.Version {
+			problem.Write(w, r, problem.New(problem.PreconditionFailed))
+			return
+		}
+
+		pvz, err := service.UpdatePVZ(chi.URLParam(r, "pvzId"), req.City, req.Version)
+		if err != nil {
+			problem.Write(w, r, middleware.PreconditionError(r, err))
+			return
+		}
+		render.Write(w, r, http.StatusOK, pvz)
+	}
+}
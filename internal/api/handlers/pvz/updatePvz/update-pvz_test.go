@@ -0,0 +1,225 @@
+package updatePvz
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"avito-intern/internal/services"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPVZRepository struct {
+	mock.Mock
+}
+
+func (m *mockPVZRepository) CreatePVZ(pvz *models.PVZ) error {
+	args := m.Called(pvz)
+	return args.Error(0)
+}
+
+func (m *mockPVZRepository) ListPVZ(limit, offset int, startDate, endDate *time.Time) ([]*models.PVZ, error) {
+	args := m.Called(limit, offset, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PVZ), args.Error(1)
+}
+
+func (m *mockPVZRepository) GetPVZByID(id string) (*models.PVZ, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZ), args.Error(1)
+}
+
+func (m *mockPVZRepository) CountPVZ() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockPVZRepository) UpdatePVZ(pvz *models.PVZ, expectedVersion int) error {
+	args := m.Called(pvz, expectedVersion)
+	return args.Error(0)
+}
+
+func createUserContext(role string) context.Context {
+	user := models.User{
+		ID:    uuid.New().String(),
+		Email: "test@example.com",
+		Role:  role,
+	}
+	return context.WithValue(context.Background(), middleware.UserCtxKey, user)
+}
+
+func TestUpdatePVZHandler(t *testing.T) {
+	registered := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := &models.PVZ{ID: "pvz-1", City: "Казань", RegistrationDate: registered, Version: 3}
+
+	tests := []struct {
+		name            string
+		userRole        string
+		body            string
+		ifMatch         string
+		setupMock       func(mockRepo *mockPVZRepository)
+		expectedStatus  int
+		expectedCode    string
+		expectedVersion int
+	}{
+		{
+			name:     "Successful update",
+			userRole: "moderator",
+			body:     `{"city":"Казань","version":2}`,
+			setupMock: func(mockRepo *mockPVZRepository) {
+				mockRepo.On("GetPVZByID", "pvz-1").Return(&models.PVZ{ID: "pvz-1", City: "Москва", RegistrationDate: registered, Version: 2}, nil)
+				mockRepo.On("UpdatePVZ", mock.MatchedBy(func(pvz *models.PVZ) bool {
+					return pvz.City == "Казань"
+				}), 2).Run(func(args mock.Arguments) {
+					args.Get(0).(*models.PVZ).Version = 3
+				}).Return(nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedVersion: 3,
+		},
+		{
+			name:     "Version conflict",
+			userRole: "moderator",
+			body:     `{"city":"Москва","version":2}`,
+			setupMock: func(mockRepo *mockPVZRepository) {
+				mockRepo.On("GetPVZByID", "pvz-1").Return(&models.PVZ{ID: "pvz-1", City: "Казань", RegistrationDate: registered, Version: 3}, nil)
+				mockRepo.On("UpdatePVZ", mock.Anything, 2).Return(&internalErrors.ConflictError{
+					Err:     internalErrors.ErrVersionConflict,
+					Current: current,
+				})
+			},
+			expectedStatus:  http.StatusConflict,
+			expectedCode:    "version_conflict",
+			expectedVersion: 3,
+		},
+		{
+			name:     "Matching If-Match",
+			userRole: "moderator",
+			body:     `{"city":"Казань","version":2}`,
+			ifMatch:  middleware.VersionETag(2),
+			setupMock: func(mockRepo *mockPVZRepository) {
+				mockRepo.On("GetPVZByID", "pvz-1").Return(&models.PVZ{ID: "pvz-1", City: "Москва", RegistrationDate: registered, Version: 2}, nil)
+				mockRepo.On("UpdatePVZ", mock.Anything, 2).Run(func(args mock.Arguments) {
+					args.Get(0).(*models.PVZ).Version = 3
+				}).Return(nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedVersion: 3,
+		},
+		{
+			name:     "Stale If-Match",
+			userRole: "moderator",
+			body:     `{"city":"Москва","version":2}`,
+			ifMatch:  middleware.VersionETag(2),
+			setupMock: func(mockRepo *mockPVZRepository) {
+				mockRepo.On("GetPVZByID", "pvz-1").Return(&models.PVZ{ID: "pvz-1", City: "Казань", RegistrationDate: registered, Version: 3}, nil)
+				mockRepo.On("UpdatePVZ", mock.Anything, 2).Return(&internalErrors.ConflictError{
+					Err:     internalErrors.ErrVersionConflict,
+					Current: current,
+				})
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedCode:   "precondition_failed",
+		},
+		{
+			name:           "If-Match of another version",
+			userRole:       "moderator",
+			body:           `{"city":"Москва","version":2}`,
+			ifMatch:        middleware.VersionETag(3),
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedCode:   "precondition_failed",
+		},
+		{
+			name:     "PVZ not found",
+			userRole: "moderator",
+			body:     `{"city":"Москва","version":1}`,
+			setupMock: func(mockRepo *mockPVZRepository) {
+				mockRepo.On("GetPVZByID", "pvz-1").Return(nil, internalErrors.ErrPVZNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "pvz_not_found",
+		},
+		{
+			name:           "Invalid city",
+			userRole:       "moderator",
+			body:           `{"city":"Новосибирск","version":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_city",
+		},
+		{
+			name:           "Missing version",
+			userRole:       "moderator",
+			body:           `{"city":"Москва"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_request",
+		},
+		{
+			name:           "Access denied",
+			userRole:       "employee",
+			body:           `{"city":"Москва","version":1}`,
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "access_denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockPVZRepository)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+
+			r := chi.NewRouter()
+			r.Patch("/pvz/{pvzId}", New(services.NewPVZService(mockRepo)))
+
+			req := httptest.NewRequest(http.MethodPatch, "/pvz/pvz-1", strings.NewReader(tt.body))
+			if tt.ifMatch != "" {
+				req.Header.Set(middleware.IfMatchHeader, tt.ifMatch)
+			}
+			req = req.WithContext(createUserContext(tt.userRole))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var resp models.PVZ
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, "Казань", resp.City)
+				require.Equal(t, tt.expectedVersion, resp.Version)
+				require.True(t, registered.Equal(resp.RegistrationDate))
+			} else {
+				var errorResp struct {
+					response.ErrorResponse
+					Code    string      `json:"code"`
+					Current *models.PVZ `json:"current"`
+				}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedCode, errorResp.Code)
+				if tt.expectedStatus == http.StatusConflict {
+					require.NotNil(t, errorResp.Current)
+					require.Equal(t, tt.expectedVersion, errorResp.Current.Version)
+				}
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
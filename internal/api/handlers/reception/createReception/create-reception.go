@@ -1,30 +1,36 @@
 package createReception
 
 import (
-	"avito-intern/internal/api/dto/internalErrors"
 	"avito-intern/internal/api/dto/request/receptionDto"
-	"avito-intern/internal/api/dto/response"
 	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
 	"avito-intern/internal/metrics"
 	"avito-intern/internal/models"
 	"avito-intern/internal/services"
-	"encoding/json"
-	"errors"
 	"net/http"
 )
 
 func New(service *services.ReceptionService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := middleware.RequireRole(r.Context(), "employee"); err != nil {
-			w.WriteHeader(http.StatusForbidden)
-			json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Access denied"})
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		receptionsVersion, err := middleware.IfMatchVersion(r)
+		if err != nil {
+			problem.Write(w, r, err)
 			return
 		}
 
 		var req receptionDto.CreateReceptionRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PVzID == "" {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Invalid request"})
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		if req.PVzID == "" {
+			problem.Write(w, r, problem.New(problem.InvalidRequest))
 			return
 		}
 
@@ -33,20 +39,12 @@ func New(service *services.ReceptionService) http.HandlerFunc {
 			Status: "in_progress",
 		}
 
-		err := service.CreateReception(&reception)
-		if err != nil {
-			if errors.Is(err, internalErrors.ErrActiveReceptionExists) {
-				w.WriteHeader(http.StatusBadRequest)
-				json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Active reception exists"})
-			} else {
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(response.ErrorResponse{Message: "Internal server error"})
-			}
+		if err := service.CreateReception(&reception, receptionsVersion); err != nil {
+			problem.Write(w, r, middleware.PreconditionError(r, err))
 			return
 		}
 		metrics.ReceptionCreatedCount.Inc()
 
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(reception)
+		render.Write(w, r, http.StatusCreated, reception)
 	}
 }
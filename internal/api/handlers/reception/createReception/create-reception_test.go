@@ -11,6 +11,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -26,8 +27,8 @@ type mockReceptionRepository struct {
 	mock.Mock
 }
 
-func (m *mockReceptionRepository) CreateReception(reception *models.Reception) error {
-	args := m.Called(reception)
+func (m *mockReceptionRepository) CreateReception(reception *models.Reception, receptionsVersion int) error {
+	args := m.Called(reception, receptionsVersion)
 	return args.Error(0)
 }
 
@@ -39,11 +40,19 @@ func (m *mockReceptionRepository) GetActiveReception(pvzID string) (*models.Rece
 	return args.Get(0).(*models.Reception), args.Error(1)
 }
 
-func (m *mockReceptionRepository) CloseReception(receptionID string) error {
-	args := m.Called(receptionID)
+func (m *mockReceptionRepository) CloseReception(receptionID string, expectedVersion, receptionsVersion int) error {
+	args := m.Called(receptionID, expectedVersion, receptionsVersion)
 	return args.Error(0)
 }
 
+func (m *mockReceptionRepository) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	args := m.Called(pvzIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reception), args.Error(1)
+}
+
 func createUserContext(role string) context.Context {
 	user := models.User{
 		ID:    uuid.New().String(),
@@ -73,7 +82,7 @@ func TestCreateReceptionHandler(t *testing.T) {
 				mockRepo.On("GetActiveReception", "test-pvz-id").Return(nil, internalErrors.ErrNoActiveReception)
 				mockRepo.On("CreateReception", mock.MatchedBy(func(reception *models.Reception) bool {
 					return reception.PvzID == "test-pvz-id" && reception.Status == "in_progress"
-				})).Return(nil)
+				}), 0).Return(nil)
 			},
 			expectedStatus: http.StatusCreated,
 		},
@@ -104,11 +113,25 @@ func TestCreateReceptionHandler(t *testing.T) {
 				mockRepo.On("GetActiveReception", "test-pvz-id").Return(nil, internalErrors.ErrNoActiveReception)
 				mockRepo.On("CreateReception", mock.MatchedBy(func(reception *models.Reception) bool {
 					return reception.PvzID == "test-pvz-id"
-				})).Return(errors.New("database error"))
+				}), 0).Return(errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
 		},
+		{
+			name: "PVZ not found",
+			requestData: receptionDto.CreateReceptionRequest{
+				PVzID: "test-pvz-id",
+			},
+			userRole: "employee",
+			setupMock: func(mockRepo *mockReceptionRepository) {
+				mockRepo.On("GetActiveReception", "test-pvz-id").Return(nil, internalErrors.ErrNoActiveReception)
+				mockRepo.On("CreateReception", mock.Anything, 0).
+					Return(fmt.Errorf("%w: %w", internalErrors.ErrPVZNotFound, internalErrors.ErrReferenceNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   response.ErrorResponse{Message: "PVZ not found"},
+		},
 		{
 			name: "Access denied - moderator role",
 			requestData: receptionDto.CreateReceptionRequest{
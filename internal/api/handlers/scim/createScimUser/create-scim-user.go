@@ -0,0 +1,40 @@
+package createScimUser
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type SCIMService interface {
+	CreateUser(req scimDto.UserRequest) (*models.SCIMUser, error)
+}
+
+func New(service SCIMService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req scimDto.UserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			scimDto.WriteError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+			return
+		}
+
+		user, err := service.CreateUser(req)
+		if err != nil {
+			switch {
+			case errors.Is(err, internalErrors.ErrEmailExists):
+				scimDto.WriteError(w, http.StatusConflict, "uniqueness", "User already exists")
+			case errors.Is(err, internalErrors.ErrInvalidRole):
+				scimDto.WriteError(w, http.StatusBadRequest, "invalidValue", "Invalid role")
+			case errors.Is(err, internalErrors.ErrInvalidPatch):
+				scimDto.WriteError(w, http.StatusBadRequest, "invalidValue", "userName is required")
+			default:
+				scimDto.WriteError(w, http.StatusInternalServerError, "", "Internal server error")
+			}
+			return
+		}
+		scimDto.WriteJSON(w, http.StatusCreated, scimDto.NewUser(user))
+	}
+}
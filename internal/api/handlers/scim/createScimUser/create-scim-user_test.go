@@ -0,0 +1,119 @@
+package createScimUser
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSCIMService struct {
+	mock.Mock
+}
+
+var _ SCIMService = (*mockSCIMService)(nil)
+
+func (m *mockSCIMService) CreateUser(req scimDto.UserRequest) (*models.SCIMUser, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMUser), args.Error(1)
+}
+
+func TestCreateScimUserHandler(t *testing.T) {
+	body := `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"test@example.com","roles":[{"value":"moderator"}]}`
+	request := scimDto.UserRequest{
+		Schemas:  []string{scimDto.UserSchema},
+		UserName: "test@example.com",
+		Roles:    []scimDto.Role{{Value: "moderator"}},
+	}
+
+	tests := []struct {
+		name            string
+		body            string
+		setupMock       func(mock *mockSCIMService)
+		expectedStatus  int
+		expectedScimErr string
+	}{
+		{
+			name: "User created",
+			body: body,
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("CreateUser", request).Return(&models.SCIMUser{
+					User: &models.User{ID: "user-id", Email: "test@example.com", Role: "moderator", Status: "active"},
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:            "Invalid request body",
+			body:            "invalid json",
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidSyntax",
+		},
+		{
+			name: "User already exists",
+			body: body,
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("CreateUser", request).Return(nil, internalErrors.ErrEmailExists)
+			},
+			expectedStatus:  http.StatusConflict,
+			expectedScimErr: "uniqueness",
+		},
+		{
+			name: "Invalid role",
+			body: body,
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("CreateUser", request).Return(nil, internalErrors.ErrInvalidRole)
+			},
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidValue",
+		},
+		{
+			name: "Service error",
+			body: body,
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("CreateUser", request).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockSCIMService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/scim/v2/Users", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				var resp scimDto.User
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, "user-id", resp.ID)
+				require.True(t, resp.Active)
+				require.Equal(t, "/scim/v2/Users/user-id", resp.Meta.Location)
+			} else {
+				var errorResp scimDto.Error
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedScimErr, errorResp.ScimType)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
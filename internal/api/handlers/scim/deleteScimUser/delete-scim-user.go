@@ -0,0 +1,30 @@
+package deleteScimUser
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SCIMService interface {
+	DeactivateUser(id string) error
+}
+
+// New deactivates the user instead of deleting it so that receptions and
+// audit records keep pointing at an existing account.
+func New(service SCIMService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := service.DeactivateUser(chi.URLParam(r, "id")); err != nil {
+			if errors.Is(err, internalErrors.ErrUserNotFound) {
+				scimDto.WriteError(w, http.StatusNotFound, "", "User not found")
+			} else {
+				scimDto.WriteError(w, http.StatusInternalServerError, "", "Internal server error")
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,55 @@
+package deleteScimUser
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSCIMService struct {
+	mock.Mock
+}
+
+var _ SCIMService = (*mockSCIMService)(nil)
+
+func (m *mockSCIMService) DeactivateUser(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestDeleteScimUserHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{name: "User deactivated", expectedStatus: http.StatusNoContent},
+		{name: "User not found", serviceErr: internalErrors.ErrUserNotFound, expectedStatus: http.StatusNotFound},
+		{name: "Service error", serviceErr: errors.New("database error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockSCIMService)
+			mockService.On("DeactivateUser", "user-id").Return(tt.serviceErr)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "user-id")
+			req := httptest.NewRequest(http.MethodDelete, "/scim/v2/Users/user-id", nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
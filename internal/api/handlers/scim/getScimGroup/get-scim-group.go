@@ -0,0 +1,30 @@
+package getScimGroup
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SCIMService interface {
+	GetGroup(id string) (*models.SCIMGroup, error)
+}
+
+func New(service SCIMService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		group, err := service.GetGroup(chi.URLParam(r, "id"))
+		if err != nil {
+			if errors.Is(err, internalErrors.ErrGroupNotFound) {
+				scimDto.WriteError(w, http.StatusNotFound, "", "Group not found")
+			} else {
+				scimDto.WriteError(w, http.StatusInternalServerError, "", "Internal server error")
+			}
+			return
+		}
+		scimDto.WriteJSON(w, http.StatusOK, scimDto.NewGroup(group))
+	}
+}
@@ -0,0 +1,91 @@
+package getScimGroup
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSCIMService struct {
+	mock.Mock
+}
+
+var _ SCIMService = (*mockSCIMService)(nil)
+
+func (m *mockSCIMService) GetGroup(id string) (*models.SCIMGroup, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMGroup), args.Error(1)
+}
+
+func TestGetScimGroupHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMock      func(mock *mockSCIMService)
+		expectedStatus int
+	}{
+		{
+			name: "Group found",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("GetGroup", "some-id").Return(&models.SCIMGroup{ID: "some-id", DisplayName: "pvz-some-id"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Group not found",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("GetGroup", "some-id").Return(nil, internalErrors.ErrGroupNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Service error",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("GetGroup", "some-id").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockSCIMService)
+			tt.setupMock(mockService)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "some-id")
+			req := httptest.NewRequest(http.MethodGet, "/scim/v2/Groups/some-id", nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, scimDto.ContentType, w.Header().Get("Content-Type"))
+			if tt.expectedStatus == http.StatusOK {
+				var resp scimDto.Group
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, "some-id", resp.ID)
+				require.Equal(t, "pvz-some-id", resp.DisplayName)
+			} else {
+				var errorResp scimDto.Error
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, []string{scimDto.ErrorSchema}, errorResp.Schemas)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package getScimUser
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SCIMService interface {
+	GetUser(id string) (*models.SCIMUser, error)
+}
+
+func New(service SCIMService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := service.GetUser(chi.URLParam(r, "id"))
+		if err != nil {
+			if errors.Is(err, internalErrors.ErrUserNotFound) {
+				scimDto.WriteError(w, http.StatusNotFound, "", "User not found")
+			} else {
+				scimDto.WriteError(w, http.StatusInternalServerError, "", "Internal server error")
+			}
+			return
+		}
+		scimDto.WriteJSON(w, http.StatusOK, scimDto.NewUser(user))
+	}
+}
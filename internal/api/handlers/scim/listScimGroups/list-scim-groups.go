@@ -0,0 +1,39 @@
+package listScimGroups
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"errors"
+	"net/http"
+)
+
+type SCIMService interface {
+	ListGroups(filter string, startIndex, count int) ([]*models.SCIMGroup, int, error)
+}
+
+func New(service SCIMService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		startIndex, count, err := scimDto.ParsePage(r)
+		if err != nil {
+			scimDto.WriteError(w, http.StatusBadRequest, "invalidValue", "Invalid startIndex or count")
+			return
+		}
+
+		groups, total, err := service.ListGroups(r.URL.Query().Get("filter"), startIndex, count)
+		if err != nil {
+			if errors.Is(err, internalErrors.ErrInvalidFilter) {
+				scimDto.WriteError(w, http.StatusBadRequest, "invalidFilter", "Unsupported filter")
+			} else {
+				scimDto.WriteError(w, http.StatusInternalServerError, "", "Internal server error")
+			}
+			return
+		}
+
+		resources := make([]scimDto.Group, 0, len(groups))
+		for _, group := range groups {
+			resources = append(resources, scimDto.NewGroup(group))
+		}
+		scimDto.WriteJSON(w, http.StatusOK, scimDto.NewListResponse(resources, total, startIndex, len(resources)))
+	}
+}
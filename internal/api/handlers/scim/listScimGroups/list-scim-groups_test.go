@@ -0,0 +1,106 @@
+package listScimGroups
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSCIMService struct {
+	mock.Mock
+}
+
+var _ SCIMService = (*mockSCIMService)(nil)
+
+func (m *mockSCIMService) ListGroups(filter string, startIndex, count int) ([]*models.SCIMGroup, int, error) {
+	args := m.Called(filter, startIndex, count)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.SCIMGroup), args.Int(1), args.Error(2)
+}
+
+func TestListScimGroupsHandler(t *testing.T) {
+	groups := []*models.SCIMGroup{{ID: "admin", DisplayName: "admin", Members: []string{"user-id"}}}
+
+	tests := []struct {
+		name            string
+		query           string
+		setupMock       func(mock *mockSCIMService)
+		expectedStatus  int
+		expectedScimErr string
+	}{
+		{
+			name:  "List groups",
+			query: "?startIndex=3&count=1",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("ListGroups", "", 3, 1).Return(groups, 4, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "Negative count",
+			query:           "?count=-1",
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidValue",
+		},
+		{
+			name:  "Unsupported filter",
+			query: "?filter=id%20eq%20%22admin%22",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("ListGroups", `id eq "admin"`, 1, -1).Return(nil, 0, internalErrors.ErrInvalidFilter)
+			},
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidFilter",
+		},
+		{
+			name: "Service error",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("ListGroups", "", 1, -1).Return(nil, 0, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockSCIMService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/scim/v2/Groups"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp struct {
+					TotalResults int             `json:"totalResults"`
+					StartIndex   int             `json:"startIndex"`
+					Resources    []scimDto.Group `json:"Resources"`
+				}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, 4, resp.TotalResults)
+				require.Equal(t, 3, resp.StartIndex)
+				require.Len(t, resp.Resources, 1)
+				require.Equal(t, "user-id", resp.Resources[0].Members[0].Value)
+			} else {
+				var errorResp scimDto.Error
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedScimErr, errorResp.ScimType)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
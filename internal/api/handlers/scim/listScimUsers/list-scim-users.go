@@ -0,0 +1,39 @@
+package listScimUsers
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"errors"
+	"net/http"
+)
+
+type SCIMService interface {
+	ListUsers(filter string, startIndex, count int) ([]*models.SCIMUser, int, error)
+}
+
+func New(service SCIMService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		startIndex, count, err := scimDto.ParsePage(r)
+		if err != nil {
+			scimDto.WriteError(w, http.StatusBadRequest, "invalidValue", "Invalid startIndex or count")
+			return
+		}
+
+		users, total, err := service.ListUsers(r.URL.Query().Get("filter"), startIndex, count)
+		if err != nil {
+			if errors.Is(err, internalErrors.ErrInvalidFilter) {
+				scimDto.WriteError(w, http.StatusBadRequest, "invalidFilter", "Unsupported filter")
+			} else {
+				scimDto.WriteError(w, http.StatusInternalServerError, "", "Internal server error")
+			}
+			return
+		}
+
+		resources := make([]scimDto.User, 0, len(users))
+		for _, user := range users {
+			resources = append(resources, scimDto.NewUser(user))
+		}
+		scimDto.WriteJSON(w, http.StatusOK, scimDto.NewListResponse(resources, total, startIndex, len(resources)))
+	}
+}
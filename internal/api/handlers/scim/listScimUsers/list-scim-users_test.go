@@ -0,0 +1,119 @@
+package listScimUsers
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSCIMService struct {
+	mock.Mock
+}
+
+var _ SCIMService = (*mockSCIMService)(nil)
+
+func (m *mockSCIMService) ListUsers(filter string, startIndex, count int) ([]*models.SCIMUser, int, error) {
+	args := m.Called(filter, startIndex, count)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.SCIMUser), args.Int(1), args.Error(2)
+}
+
+func TestListScimUsersHandler(t *testing.T) {
+	users := []*models.SCIMUser{{
+		User:   &models.User{ID: "user-id", Email: "test@example.com", Role: "employee", Status: "active"},
+		PVZIDs: []string{},
+	}}
+
+	tests := []struct {
+		name            string
+		query           string
+		setupMock       func(mock *mockSCIMService)
+		expectedStatus  int
+		expectedTotal   int
+		expectedScimErr string
+	}{
+		{
+			name:  "List with defaults",
+			query: "",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("ListUsers", "", 1, -1).Return(users, 5, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedTotal:  5,
+		},
+		{
+			name:  "List with filter and paging",
+			query: "?filter=userName%20eq%20%22test@example.com%22&startIndex=2&count=10",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("ListUsers", `userName eq "test@example.com"`, 2, 10).Return(users, 1, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedTotal:  1,
+		},
+		{
+			name:            "Invalid count",
+			query:           "?count=abc",
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidValue",
+		},
+		{
+			name:  "Unsupported filter",
+			query: "?filter=title%20pr",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("ListUsers", "title pr", 1, -1).Return(nil, 0, internalErrors.ErrInvalidFilter)
+			},
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidFilter",
+		},
+		{
+			name: "Service error",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("ListUsers", "", 1, -1).Return(nil, 0, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockSCIMService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, scimDto.ContentType, w.Header().Get("Content-Type"))
+			if tt.expectedStatus == http.StatusOK {
+				var resp struct {
+					TotalResults int            `json:"totalResults"`
+					Resources    []scimDto.User `json:"Resources"`
+				}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, tt.expectedTotal, resp.TotalResults)
+				require.Len(t, resp.Resources, 1)
+				require.Equal(t, "test@example.com", resp.Resources[0].UserName)
+			} else {
+				var errorResp scimDto.Error
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedScimErr, errorResp.ScimType)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package patchScimGroup
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SCIMService interface {
+	PatchGroup(id string, req scimDto.PatchRequest) (*models.SCIMGroup, error)
+}
+
+func New(service SCIMService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req scimDto.PatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			scimDto.WriteError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+			return
+		}
+
+		group, err := service.PatchGroup(chi.URLParam(r, "id"), req)
+		if err != nil {
+			switch {
+			case errors.Is(err, internalErrors.ErrGroupNotFound):
+				scimDto.WriteError(w, http.StatusNotFound, "", "Group not found")
+			case errors.Is(err, internalErrors.ErrUserNotFound):
+				scimDto.WriteError(w, http.StatusBadRequest, "invalidValue", "Member not found")
+			case errors.Is(err, internalErrors.ErrInvalidPatch):
+				scimDto.WriteError(w, http.StatusBadRequest, "invalidPath", "Unsupported patch operation")
+			default:
+				scimDto.WriteError(w, http.StatusInternalServerError, "", "Internal server error")
+			}
+			return
+		}
+		scimDto.WriteJSON(w, http.StatusOK, scimDto.NewGroup(group))
+	}
+}
@@ -0,0 +1,125 @@
+package patchScimGroup
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSCIMService struct {
+	mock.Mock
+}
+
+var _ SCIMService = (*mockSCIMService)(nil)
+
+func (m *mockSCIMService) PatchGroup(id string, req scimDto.PatchRequest) (*models.SCIMGroup, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMGroup), args.Error(1)
+}
+
+func TestPatchScimGroupHandler(t *testing.T) {
+	body := `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"add","path":"members","value":[{"value":"user-id"}]}]}`
+	patch := scimDto.PatchRequest{
+		Schemas:    []string{scimDto.PatchOpSchema},
+		Operations: []scimDto.PatchOperation{{Op: "add", Path: "members", Value: json.RawMessage(`[{"value":"user-id"}]`)}},
+	}
+
+	tests := []struct {
+		name            string
+		body            string
+		setupMock       func(mock *mockSCIMService)
+		expectedStatus  int
+		expectedScimErr string
+	}{
+		{
+			name: "Group patched",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("PatchGroup", "some-id", patch).Return(&models.SCIMGroup{ID: "some-id", DisplayName: "pvz-some-id", Members: []string{"user-id"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "Invalid request body",
+			body:            "invalid json",
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidSyntax",
+		},
+		{
+			name: "Unsupported operation",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("PatchGroup", "some-id", patch).Return(nil, internalErrors.ErrInvalidPatch)
+			},
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidPath",
+		},
+		{
+			name: "Member not found",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("PatchGroup", "some-id", patch).Return(nil, internalErrors.ErrUserNotFound)
+			},
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidValue",
+		},
+		{
+			name: "Group not found",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("PatchGroup", "some-id", patch).Return(nil, internalErrors.ErrGroupNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Service error",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("PatchGroup", "some-id", patch).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockSCIMService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+			if tt.body == "" {
+				tt.body = body
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "some-id")
+			req := httptest.NewRequest(http.MethodPatch, "/scim/v2/Groups/some-id", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp scimDto.Group
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, "user-id", resp.Members[0].Value)
+			} else {
+				var errorResp scimDto.Error
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedScimErr, errorResp.ScimType)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package patchScimUser
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SCIMService interface {
+	PatchUser(id string, req scimDto.PatchRequest) (*models.SCIMUser, error)
+}
+
+func New(service SCIMService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req scimDto.PatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			scimDto.WriteError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+			return
+		}
+
+		user, err := service.PatchUser(chi.URLParam(r, "id"), req)
+		if err != nil {
+			switch {
+			case errors.Is(err, internalErrors.ErrUserNotFound):
+				scimDto.WriteError(w, http.StatusNotFound, "", "User not found")
+			case errors.Is(err, internalErrors.ErrInvalidRole):
+				scimDto.WriteError(w, http.StatusBadRequest, "invalidValue", "Invalid role")
+			case errors.Is(err, internalErrors.ErrInvalidPatch):
+				scimDto.WriteError(w, http.StatusBadRequest, "invalidPath", "Unsupported patch operation")
+			case errors.Is(err, internalErrors.ErrInvalidUserStatus):
+				scimDto.WriteError(w, http.StatusBadRequest, "mutability", "The user is erased")
+			default:
+				scimDto.WriteError(w, http.StatusInternalServerError, "", "Internal server error")
+			}
+			return
+		}
+		scimDto.WriteJSON(w, http.StatusOK, scimDto.NewUser(user))
+	}
+}
@@ -0,0 +1,133 @@
+package patchScimUser
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSCIMService struct {
+	mock.Mock
+}
+
+var _ SCIMService = (*mockSCIMService)(nil)
+
+func (m *mockSCIMService) PatchUser(id string, req scimDto.PatchRequest) (*models.SCIMUser, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMUser), args.Error(1)
+}
+
+func TestPatchScimUserHandler(t *testing.T) {
+	body := `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","path":"active","value":false}]}`
+	patch := scimDto.PatchRequest{
+		Schemas:    []string{scimDto.PatchOpSchema},
+		Operations: []scimDto.PatchOperation{{Op: "replace", Path: "active", Value: json.RawMessage(`false`)}},
+	}
+
+	tests := []struct {
+		name            string
+		body            string
+		setupMock       func(mock *mockSCIMService)
+		expectedStatus  int
+		expectedScimErr string
+	}{
+		{
+			name: "User patched",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("PatchUser", "some-id", patch).Return(&models.SCIMUser{User: &models.User{ID: "some-id", Email: "test@example.com", Role: "employee", Status: "deactivated"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "Invalid request body",
+			body:            "invalid json",
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidSyntax",
+		},
+		{
+			name: "Unsupported operation",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("PatchUser", "some-id", patch).Return(nil, internalErrors.ErrInvalidPatch)
+			},
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidPath",
+		},
+		{
+			name: "Invalid role",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("PatchUser", "some-id", patch).Return(nil, internalErrors.ErrInvalidRole)
+			},
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "invalidValue",
+		},
+		{
+			name: "Erased user",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("PatchUser", "some-id", patch).Return(nil, internalErrors.ErrInvalidUserStatus)
+			},
+			expectedStatus:  http.StatusBadRequest,
+			expectedScimErr: "mutability",
+		},
+		{
+			name: "User not found",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("PatchUser", "some-id", patch).Return(nil, internalErrors.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Service error",
+			setupMock: func(mock *mockSCIMService) {
+				mock.On("PatchUser", "some-id", patch).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockSCIMService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+			if tt.body == "" {
+				tt.body = body
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "some-id")
+			req := httptest.NewRequest(http.MethodPatch, "/scim/v2/Users/some-id", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp scimDto.User
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.False(t, resp.Active)
+			} else {
+				var errorResp scimDto.Error
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedScimErr, errorResp.ScimType)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
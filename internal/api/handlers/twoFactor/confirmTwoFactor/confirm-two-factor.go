@@ -0,0 +1,41 @@
+package confirmTwoFactor
+
+import (
+	"avito-intern/internal/api/dto/request/twoFactorDto"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"net/http"
+)
+
+type TwoFactorService interface {
+	Confirm(userID, code string) ([]string, string, error)
+}
+
+func New(service TwoFactorService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := middleware.GetUserFromContext(r.Context())
+		if err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		var req twoFactorDto.ConfirmRequest
+		if err = render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		codes, token, err := service.Confirm(user.ID, req.Code)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		render.Write(w, r, http.StatusOK, response.TwoFactorConfirmResponse{
+			Token:         token,
+			RecoveryCodes: codes,
+		})
+	}
+}
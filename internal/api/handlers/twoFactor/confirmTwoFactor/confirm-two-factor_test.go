@@ -0,0 +1,113 @@
+package confirmTwoFactor
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTwoFactorService struct {
+	mock.Mock
+}
+
+var _ TwoFactorService = (*mockTwoFactorService)(nil)
+
+func (m *mockTwoFactorService) Confirm(userID, code string) ([]string, string, error) {
+	args := m.Called(userID, code)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]string), args.String(1), args.Error(2)
+}
+
+func TestConfirmTwoFactorHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(mock *mockTwoFactorService)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "Successful confirmation",
+			body: `{"code":"123456"}`,
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Confirm", "user-id", "123456").Return([]string{"code1", "code2"}, "token123", nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   response.TwoFactorConfirmResponse{Token: "token123", RecoveryCodes: []string{"code1", "code2"}},
+		},
+		{
+			name: "Invalid code",
+			body: `{"code":"000000"}`,
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Confirm", "user-id", "000000").Return(nil, "", internalErrors.ErrInvalidTwoFactorCode)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid two-factor code"},
+		},
+		{
+			name: "Not enrolled",
+			body: `{"code":"123456"}`,
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Confirm", "user-id", "123456").Return(nil, "", internalErrors.ErrTwoFactorNotEnrolled)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Two-factor enrollment not started"},
+		},
+		{
+			name: "Internal server error",
+			body: `{"code":"123456"}`,
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Confirm", "user-id", "123456").Return(nil, "", errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+		{
+			name:           "Invalid request body",
+			body:           "invalid json",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid request"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockTwoFactorService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/2fa/confirm", strings.NewReader(tt.body))
+			ctx := context.WithValue(req.Context(), middleware.UserCtxKey, models.User{ID: "user-id", Role: "moderator"})
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req.WithContext(ctx))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp response.TwoFactorConfirmResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, tt.expectedResp, resp)
+			} else {
+				var resp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, tt.expectedResp, resp)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package enrollTwoFactor
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"net/http"
+)
+
+type TwoFactorService interface {
+	Enroll(userID string) (string, string, error)
+}
+
+func New(service TwoFactorService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := middleware.GetUserFromContext(r.Context())
+		if err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		secret, uri, err := service.Enroll(user.ID)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		render.Write(w, r, http.StatusCreated, response.TwoFactorEnrollmentResponse{
+			Secret:     secret,
+			OtpauthURI: uri,
+		})
+	}
+}
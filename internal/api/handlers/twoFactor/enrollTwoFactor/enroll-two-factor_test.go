@@ -0,0 +1,99 @@
+package enrollTwoFactor
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTwoFactorService struct {
+	mock.Mock
+}
+
+var _ TwoFactorService = (*mockTwoFactorService)(nil)
+
+func (m *mockTwoFactorService) Enroll(userID string) (string, string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func TestEnrollTwoFactorHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMock      func(mock *mockTwoFactorService)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "Successful enrollment",
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Enroll", "user-id").Return("SECRET", "otpauth://totp/PVZ:user?secret=SECRET", nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedResp: response.TwoFactorEnrollmentResponse{
+				Secret:     "SECRET",
+				OtpauthURI: "otpauth://totp/PVZ:user?secret=SECRET",
+			},
+		},
+		{
+			name: "Already enabled",
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Enroll", "user-id").Return("", "", internalErrors.ErrTwoFactorEnabled)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Two-factor authentication already enabled"},
+		},
+		{
+			name: "User not found",
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Enroll", "user-id").Return("", "", internalErrors.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   response.ErrorResponse{Message: "User not found"},
+		},
+		{
+			name: "Internal server error",
+			setupMock: func(mock *mockTwoFactorService) {
+				mock.On("Enroll", "user-id").Return("", "", errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockTwoFactorService)
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/2fa/enroll", nil)
+			ctx := context.WithValue(req.Context(), middleware.UserCtxKey, models.User{ID: "user-id", Role: "moderator"})
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, req.WithContext(ctx))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				var resp response.TwoFactorEnrollmentResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, tt.expectedResp, resp)
+			} else {
+				var resp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, tt.expectedResp, resp)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
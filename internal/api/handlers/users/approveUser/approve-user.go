@@ -0,0 +1,35 @@
+package approveUser
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/services"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func New(service *services.AuthService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireAnyRole(r.Context(), "moderator", "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		userID := chi.URLParam(r, "userId")
+
+		user, err := service.ApproveUser(userID)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		render.Write(w, r, http.StatusOK, response.UserResponse{
+			ID:     user.ID,
+			Email:  user.Email,
+			Role:   user.Role,
+			Status: user.Status,
+		})
+	}
+}
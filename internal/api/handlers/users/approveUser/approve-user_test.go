@@ -0,0 +1,156 @@
+package approveUser
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"avito-intern/internal/services"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUserRepository struct {
+	mock.Mock
+}
+
+func (m *mockUserRepository) CreateUser(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) GetUserByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) GetUserByID(id string) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) UpdateUserStatus(id, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) ListUsers(email, role, status string, limit, offset int) ([]*models.User, error) {
+	args := m.Called(email, role, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) CountUsers(email, role, status string) (int, error) {
+	args := m.Called(email, role, status)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockUserRepository) UpdateUserRole(id, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) UpdateUserPassword(id, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+func createRequest(role, userID string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/users/"+userID+"/approve", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userId", userID)
+	ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserCtxKey, models.User{ID: "approver-id", Role: role})
+	return req.WithContext(ctx)
+}
+
+func TestApproveUserHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		userRole       string
+		setupMock      func(mockRepo *mockUserRepository)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:     "Successful approval",
+			userRole: "moderator",
+			setupMock: func(mockRepo *mockUserRepository) {
+				mockRepo.On("GetUserByID", "user-id").Return(&models.User{
+					ID: "user-id", Email: "employee@example.com", Role: "employee", Status: "pending",
+				}, nil)
+				mockRepo.On("UpdateUserStatus", "user-id", "active").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: response.UserResponse{
+				ID: "user-id", Email: "employee@example.com", Role: "employee", Status: "active",
+			},
+		},
+		{
+			name:           "Employee cannot approve",
+			userRole:       "employee",
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name:     "User not found",
+			userRole: "admin",
+			setupMock: func(mockRepo *mockUserRepository) {
+				mockRepo.On("GetUserByID", "user-id").Return(nil, internalErrors.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   response.ErrorResponse{Message: "User not found"},
+		},
+		{
+			name:     "User already active",
+			userRole: "admin",
+			setupMock: func(mockRepo *mockUserRepository) {
+				mockRepo.On("GetUserByID", "user-id").Return(&models.User{ID: "user-id", Status: "active"}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "User is not pending approval"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockUserRepository)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+			service := services.NewAuthService(mockRepo, nil, nil, nil, nil, nil, services.RegistrationPolicy{})
+			handler := New(service)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, createRequest(tt.userRole, "user-id"))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var userResp response.UserResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&userResp))
+				require.Equal(t, tt.expectedResp, userResp)
+			} else {
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
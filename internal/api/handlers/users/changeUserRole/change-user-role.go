@@ -0,0 +1,42 @@
+package changeUserRole
+
+import (
+	"avito-intern/internal/api/dto/request/userDto"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/services"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func New(service *services.UserService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		actor, _ := middleware.GetUserFromContext(r.Context())
+
+		var req userDto.ChangeRoleRequest
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		user, err := service.ChangeRole(actor, chi.URLParam(r, "userId"), req.Role)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		render.Write(w, r, http.StatusOK, response.UserResponse{
+			ID:     user.ID,
+			Email:  user.Email,
+			Role:   user.Role,
+			Status: user.Status,
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package changeUserRole
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"avito-intern/internal/services"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUserRepository struct {
+	mock.Mock
+}
+
+func (m *mockUserRepository) CreateUser(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) GetUserByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) GetUserByID(id string) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) UpdateUserStatus(id, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) ListUsers(email, role, status string, limit, offset int) ([]*models.User, error) {
+	args := m.Called(email, role, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) CountUsers(email, role, status string) (int, error) {
+	args := m.Called(email, role, status)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockUserRepository) UpdateUserRole(id, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) UpdateUserPassword(id, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+func createRequest(method, target, role, userID string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userId", userID)
+	ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserCtxKey, models.User{ID: "admin-id", Role: role})
+	return req.WithContext(ctx)
+}
+
+func TestChangeUserRoleHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		userRole       string
+		userID         string
+		body           string
+		setupMock      func(mockRepo *mockUserRepository)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:     "Successful role change",
+			userRole: "admin",
+			userID:   "user-id",
+			body:     `{"role":"moderator"}`,
+			setupMock: func(mockRepo *mockUserRepository) {
+				mockRepo.On("GetUserByID", "user-id").Return(&models.User{ID: "user-id", Role: "employee", Status: "active"}, nil)
+				mockRepo.On("UpdateUserRole", "user-id", "moderator").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp:   response.UserResponse{ID: "user-id", Role: "moderator", Status: "active"},
+		},
+		{
+			name:           "Moderator cannot change roles",
+			userRole:       "moderator",
+			userID:         "user-id",
+			body:           `{"role":"moderator"}`,
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name:           "Invalid request body",
+			userRole:       "admin",
+			userID:         "user-id",
+			body:           "invalid json",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid request"},
+		},
+		{
+			name:           "Invalid role",
+			userRole:       "admin",
+			userID:         "user-id",
+			body:           `{"role":"superuser"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid role"},
+		},
+		{
+			name:           "Own account",
+			userRole:       "admin",
+			userID:         "admin-id",
+			body:           `{"role":"employee"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Cannot modify own account"},
+		},
+		{
+			name:     "User not found",
+			userRole: "admin",
+			userID:   "user-id",
+			body:     `{"role":"employee"}`,
+			setupMock: func(mockRepo *mockUserRepository) {
+				mockRepo.On("GetUserByID", "user-id").Return(nil, internalErrors.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   response.ErrorResponse{Message: "User not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mockUserRepository)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+			handler := New(services.NewUserService(mockRepo))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, createRequest(http.MethodPost, "/admin/users/"+tt.userID+"/role", tt.userRole, tt.userID, strings.NewReader(tt.body)))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp response.UserResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, tt.expectedResp, resp)
+			} else {
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package deactivateUser
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/services"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func New(service *services.UserService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		actor, _ := middleware.GetUserFromContext(r.Context())
+
+		user, err := service.DeactivateUser(actor, chi.URLParam(r, "userId"))
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		render.Write(w, r, http.StatusOK, response.UserResponse{
+			ID:     user.ID,
+			Email:  user.Email,
+			Role:   user.Role,
+			Status: user.Status,
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package eraseUser
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPrivacyService struct {
+	mock.Mock
+}
+
+var _ PrivacyService = (*mockPrivacyService)(nil)
+
+func (m *mockPrivacyService) EraseUser(actor models.User, userID string) (*models.User, error) {
+	args := m.Called(actor, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func createRequest(role string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/user-id/erase", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userId", "user-id")
+	ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserCtxKey, models.User{ID: "admin-id", Role: role})
+	return req.WithContext(ctx)
+}
+
+func TestEraseUserHandler(t *testing.T) {
+	admin := models.User{ID: "admin-id", Role: "admin"}
+
+	tests := []struct {
+		name           string
+		role           string
+		setupMock      func(mock *mockPrivacyService)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "User erased",
+			role: "admin",
+			setupMock: func(mock *mockPrivacyService) {
+				mock.On("EraseUser", admin, "user-id").Return(&models.User{
+					ID:     "user-id",
+					Email:  "erased-user-id@erased.invalid",
+					Role:   "employee",
+					Status: "erased",
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: response.UserResponse{
+				ID:     "user-id",
+				Email:  "erased-user-id@erased.invalid",
+				Role:   "employee",
+				Status: "erased",
+			},
+		},
+		{
+			name:           "Moderator cannot erase",
+			role:           "moderator",
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name: "Already erased",
+			role: "admin",
+			setupMock: func(mock *mockPrivacyService) {
+				mock.On("EraseUser", admin, "user-id").Return(nil, internalErrors.ErrInvalidUserStatus)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid user status"},
+		},
+		{
+			name: "Cannot erase self",
+			role: "admin",
+			setupMock: func(mock *mockPrivacyService) {
+				mock.On("EraseUser", admin, "user-id").Return(nil, internalErrors.ErrCannotModifySelf)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Cannot modify own account"},
+		},
+		{
+			name: "User not found",
+			role: "admin",
+			setupMock: func(mock *mockPrivacyService) {
+				mock.On("EraseUser", admin, "user-id").Return(nil, internalErrors.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   response.ErrorResponse{Message: "User not found"},
+		},
+		{
+			name: "Service error",
+			role: "admin",
+			setupMock: func(mock *mockPrivacyService) {
+				mock.On("EraseUser", admin, "user-id").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockPrivacyService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			w := httptest.NewRecorder()
+			New(mockService).ServeHTTP(w, createRequest(tt.role))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp response.UserResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, tt.expectedResp, resp)
+			} else {
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
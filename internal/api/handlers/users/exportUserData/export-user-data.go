@@ -0,0 +1,35 @@
+package exportUserData
+
+import (
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/models"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PrivacyService interface {
+	ExportUser(actor models.User, userID string) (*models.UserDataExport, error)
+}
+
+func New(service PrivacyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		actor, _ := middleware.GetUserFromContext(r.Context())
+
+		export, err := service.ExportUser(actor, chi.URLParam(r, "userId"))
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="user-`+export.Account.ID+`.json"`)
+		render.Write(w, r, http.StatusOK, export)
+	}
+}
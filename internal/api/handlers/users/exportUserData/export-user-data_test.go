@@ -0,0 +1,117 @@
+package exportUserData
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPrivacyService struct {
+	mock.Mock
+}
+
+var _ PrivacyService = (*mockPrivacyService)(nil)
+
+func (m *mockPrivacyService) ExportUser(actor models.User, userID string) (*models.UserDataExport, error) {
+	args := m.Called(actor, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserDataExport), args.Error(1)
+}
+
+func createRequest(role string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/admin/users/user-id/export", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userId", "user-id")
+	ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserCtxKey, models.User{ID: "admin-id", Role: role})
+	return req.WithContext(ctx)
+}
+
+func TestExportUserDataHandler(t *testing.T) {
+	admin := models.User{ID: "admin-id", Role: "admin"}
+
+	tests := []struct {
+		name           string
+		role           string
+		setupMock      func(mock *mockPrivacyService)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "Data exported",
+			role: "admin",
+			setupMock: func(mock *mockPrivacyService) {
+				mock.On("ExportUser", admin, "user-id").Return(&models.UserDataExport{
+					Account: &models.User{ID: "user-id", Email: "user@example.com", Password: "hash"},
+					PVZIDs:  []string{"pvz-id"},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Moderator cannot export",
+			role:           "moderator",
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name: "User not found",
+			role: "admin",
+			setupMock: func(mock *mockPrivacyService) {
+				mock.On("ExportUser", admin, "user-id").Return(nil, internalErrors.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   response.ErrorResponse{Message: "User not found"},
+		},
+		{
+			name: "Service error",
+			role: "admin",
+			setupMock: func(mock *mockPrivacyService) {
+				mock.On("ExportUser", admin, "user-id").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockPrivacyService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			w := httptest.NewRecorder()
+			New(mockService).ServeHTTP(w, createRequest(tt.role))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				require.Equal(t, `attachment; filename="user-user-id.json"`, w.Header().Get("Content-Disposition"))
+				require.NotContains(t, w.Body.String(), "hash")
+				var export models.UserDataExport
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&export))
+				require.Equal(t, "user@example.com", export.Account.Email)
+				require.Equal(t, []string{"pvz-id"}, export.PVZIDs)
+			} else {
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package impersonateUser
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/models"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ImpersonationService interface {
+	Start(actor models.User, userID string) (string, time.Time, error)
+}
+
+func New(service ImpersonationService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		actor, _ := middleware.GetUserFromContext(r.Context())
+
+		userID := chi.URLParam(r, "userId")
+		token, expiresAt, err := service.Start(actor, userID)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		render.Write(w, r, http.StatusOK, response.ImpersonationResponse{
+			Token:     token,
+			UserID:    userID,
+			ActorID:   actor.ID,
+			ExpiresAt: expiresAt,
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package impersonateUser
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockImpersonationService struct {
+	mock.Mock
+}
+
+var _ ImpersonationService = (*mockImpersonationService)(nil)
+
+func (m *mockImpersonationService) Start(actor models.User, userID string) (string, time.Time, error) {
+	args := m.Called(actor, userID)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func createRequest(role string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/admin/impersonate/user-id", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userId", "user-id")
+	ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserCtxKey, models.User{ID: "admin-id", Role: role})
+	return req.WithContext(ctx)
+}
+
+func TestImpersonateUserHandler(t *testing.T) {
+	admin := models.User{ID: "admin-id", Role: "admin"}
+	expiresAt := time.Date(2024, 1, 1, 12, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		role           string
+		setupMock      func(mock *mockImpersonationService)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "Impersonation started",
+			role: "admin",
+			setupMock: func(mock *mockImpersonationService) {
+				mock.On("Start", admin, "user-id").Return("token", expiresAt, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: response.ImpersonationResponse{
+				Token:     "token",
+				UserID:    "user-id",
+				ActorID:   "admin-id",
+				ExpiresAt: expiresAt,
+			},
+		},
+		{
+			name:           "Moderator cannot impersonate",
+			role:           "moderator",
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name: "Cannot impersonate administrators",
+			role: "admin",
+			setupMock: func(mock *mockImpersonationService) {
+				mock.On("Start", admin, "user-id").Return("", time.Time{}, internalErrors.ErrAccessDenied)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name: "Invalid user status",
+			role: "admin",
+			setupMock: func(mock *mockImpersonationService) {
+				mock.On("Start", admin, "user-id").Return("", time.Time{}, internalErrors.ErrInvalidUserStatus)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid user status"},
+		},
+		{
+			name: "User not found",
+			role: "admin",
+			setupMock: func(mock *mockImpersonationService) {
+				mock.On("Start", admin, "user-id").Return("", time.Time{}, internalErrors.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   response.ErrorResponse{Message: "User not found"},
+		},
+		{
+			name: "Service error",
+			role: "admin",
+			setupMock: func(mock *mockImpersonationService) {
+				mock.On("Start", admin, "user-id").Return("", time.Time{}, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockImpersonationService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			w := httptest.NewRecorder()
+			New(mockService).ServeHTTP(w, createRequest(tt.role))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp response.ImpersonationResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, tt.expectedResp, resp)
+			} else {
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
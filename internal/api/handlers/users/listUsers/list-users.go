@@ -0,0 +1,43 @@
+package listUsers
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/services"
+	"net/http"
+)
+
+func New(service *services.UserService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		query := r.URL.Query()
+		users, err := service.ListUsers(
+			query.Get("email"),
+			query.Get("role"),
+			query.Get("status"),
+			query.Get("limit"),
+			query.Get("page"),
+		)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		resp := make([]response.UserResponse, 0, len(users))
+		for _, user := range users {
+			resp = append(resp, response.UserResponse{
+				ID:     user.ID,
+				Email:  user.Email,
+				Role:   user.Role,
+				Status: user.Status,
+			})
+		}
+		render.List(w, r, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,34 @@
+package unlockUser
+
+import (
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/services"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func New(service *services.LoginAttemptService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "admin"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		// The optional ip clears the lockout of a client IP as well.
+		ip := r.URL.Query().Get("ip")
+		if ip != "" && net.ParseIP(ip) == nil {
+			problem.Write(w, r, problem.New(problem.InvalidRequest))
+			return
+		}
+
+		if err := service.UnlockUser(chi.URLParam(r, "userId"), ip); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
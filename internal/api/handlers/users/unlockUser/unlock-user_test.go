@@ -0,0 +1,198 @@
+package unlockUser
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"avito-intern/internal/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUserRepository struct {
+	mock.Mock
+}
+
+func (m *mockUserRepository) CreateUser(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) GetUserByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) GetUserByID(id string) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) UpdateUserStatus(id, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) ListUsers(email, role, status string, limit, offset int) ([]*models.User, error) {
+	args := m.Called(email, role, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *mockUserRepository) CountUsers(email, role, status string) (int, error) {
+	args := m.Called(email, role, status)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockUserRepository) UpdateUserRole(id, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) UpdateUserPassword(id, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+type mockLoginAttemptRepository struct {
+	mock.Mock
+}
+
+func (m *mockLoginAttemptRepository) GetLoginAttempt(key string) (*models.LoginAttempt, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoginAttempt), args.Error(1)
+}
+
+func (m *mockLoginAttemptRepository) RecordFailure(key string, at, windowStart time.Time) (int, error) {
+	args := m.Called(key, at, windowStart)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockLoginAttemptRepository) Lock(key string, until time.Time) error {
+	args := m.Called(key, until)
+	return args.Error(0)
+}
+
+func (m *mockLoginAttemptRepository) Reset(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func createRequest(method, target, role, userID string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userId", userID)
+	ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserCtxKey, models.User{ID: "admin-id", Role: role})
+	return req.WithContext(ctx)
+}
+
+func TestUnlockUserHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		userRole       string
+		query          string
+		setupMock      func(userRepo *mockUserRepository, attemptRepo *mockLoginAttemptRepository)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name:     "Successful unlock",
+			userRole: "admin",
+			setupMock: func(userRepo *mockUserRepository, attemptRepo *mockLoginAttemptRepository) {
+				userRepo.On("GetUserByID", "user-id").Return(&models.User{ID: "user-id", Email: "Employee@example.com"}, nil)
+				attemptRepo.On("Reset", "account:employee@example.com").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "Unlock with IP",
+			userRole: "admin",
+			query:    "?ip=10.0.0.1",
+			setupMock: func(userRepo *mockUserRepository, attemptRepo *mockLoginAttemptRepository) {
+				userRepo.On("GetUserByID", "user-id").Return(&models.User{ID: "user-id", Email: "employee@example.com"}, nil)
+				attemptRepo.On("Reset", "account:employee@example.com").Return(nil)
+				attemptRepo.On("Reset", "ip:10.0.0.1").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid IP",
+			userRole:       "admin",
+			query:          "?ip=localhost",
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid request"},
+		},
+		{
+			name:           "Moderator cannot unlock",
+			userRole:       "moderator",
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name:     "User not found",
+			userRole: "admin",
+			setupMock: func(userRepo *mockUserRepository, attemptRepo *mockLoginAttemptRepository) {
+				userRepo.On("GetUserByID", "user-id").Return(nil, internalErrors.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedResp:   response.ErrorResponse{Message: "User not found"},
+		},
+		{
+			name:     "Repository error",
+			userRole: "admin",
+			setupMock: func(userRepo *mockUserRepository, attemptRepo *mockLoginAttemptRepository) {
+				userRepo.On("GetUserByID", "user-id").Return(&models.User{ID: "user-id", Email: "employee@example.com"}, nil)
+				attemptRepo.On("Reset", "account:employee@example.com").Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mockUserRepository)
+			attemptRepo := new(mockLoginAttemptRepository)
+			if tt.setupMock != nil {
+				tt.setupMock(userRepo, attemptRepo)
+			}
+			handler := New(services.NewLoginAttemptService(userRepo, attemptRepo, services.LockoutPolicy{}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, createRequest(http.MethodPost, "/admin/users/user-id/unlock"+tt.query, tt.userRole, "user-id", nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			}
+
+			userRepo.AssertExpectations(t)
+			attemptRepo.AssertExpectations(t)
+		})
+	}
+}
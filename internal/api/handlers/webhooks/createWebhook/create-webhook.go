@@ -0,0 +1,45 @@
+package createWebhook
+
+import (
+	"avito-intern/internal/api/dto/request/webhookDto"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/models"
+	"net/http"
+)
+
+type WebhookService interface {
+	CreateWebhook(creator models.User, req webhookDto.CreateWebhookRequest) (*models.WebhookSubscription, error)
+}
+
+func New(service WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "moderator"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		creator, _ := middleware.GetUserFromContext(r.Context())
+
+		var req webhookDto.CreateWebhookRequest
+		if err := render.Decode(r, &req); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		if req.URL == "" {
+			problem.Write(w, r, problem.New(problem.InvalidRequest))
+			return
+		}
+
+		subscription, err := service.CreateWebhook(creator, req)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		resp := response.NewWebhookResponse(subscription)
+		resp.Secret = subscription.Secret
+		render.Write(w, r, http.StatusCreated, resp)
+	}
+}
@@ -0,0 +1,137 @@
+package createWebhook
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/webhookDto"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWebhookService struct {
+	mock.Mock
+}
+
+var _ WebhookService = (*mockWebhookService)(nil)
+
+func (m *mockWebhookService) CreateWebhook(creator models.User, req webhookDto.CreateWebhookRequest) (*models.WebhookSubscription, error) {
+	args := m.Called(creator, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func TestCreateWebhookHandler(t *testing.T) {
+	moderator := models.User{ID: "moderator-id", Role: "moderator"}
+	body := `{"url":"https://partner.example/hooks","eventTypes":["reception.closed"]}`
+	req := webhookDto.CreateWebhookRequest{URL: "https://partner.example/hooks", EventTypes: []string{models.EventReceptionClosed}}
+
+	tests := []struct {
+		name           string
+		user           models.User
+		body           string
+		setupMock      func(mock *mockWebhookService)
+		expectedStatus int
+		expectedResp   interface{}
+	}{
+		{
+			name: "Webhook created",
+			user: moderator,
+			body: body,
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("CreateWebhook", moderator, req).Return(&models.WebhookSubscription{
+					ID:         "webhook-id",
+					URL:        req.URL,
+					Secret:     "whsec_secret",
+					EventTypes: req.EventTypes,
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Employee cannot create webhooks",
+			user:           models.User{ID: "employee-id", Role: "employee"},
+			body:           body,
+			expectedStatus: http.StatusForbidden,
+			expectedResp:   response.ErrorResponse{Message: "Access denied"},
+		},
+		{
+			name:           "Missing URL",
+			user:           moderator,
+			body:           `{"eventTypes":["reception.closed"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid request"},
+		},
+		{
+			name: "Invalid URL",
+			user: moderator,
+			body: body,
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("CreateWebhook", moderator, req).Return(nil, internalErrors.ErrInvalidWebhookURL)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid webhook URL"},
+		},
+		{
+			name: "Invalid event type",
+			user: moderator,
+			body: body,
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("CreateWebhook", moderator, req).Return(nil, internalErrors.ErrInvalidEventType)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResp:   response.ErrorResponse{Message: "Invalid event type"},
+		},
+		{
+			name: "Service error",
+			user: moderator,
+			body: body,
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("CreateWebhook", moderator, req).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp:   response.ErrorResponse{Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockWebhookService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(tt.body))
+			r = r.WithContext(context.WithValue(r.Context(), middleware.UserCtxKey, tt.user))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResp != nil {
+				var errorResp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResp))
+				require.Equal(t, tt.expectedResp, errorResp)
+			} else {
+				var resp response.WebhookResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, "webhook-id", resp.ID)
+				require.Equal(t, "whsec_secret", resp.Secret)
+				require.Equal(t, []string{}, resp.PvzIDs)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package deleteWebhook
+
+import (
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhookService interface {
+	DeleteWebhook(id string) error
+}
+
+func New(service WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "moderator"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		if err := service.DeleteWebhook(chi.URLParam(r, "webhookId")); err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
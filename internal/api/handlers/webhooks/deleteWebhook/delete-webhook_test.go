@@ -0,0 +1,87 @@
+package deleteWebhook
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWebhookService struct {
+	mock.Mock
+}
+
+var _ WebhookService = (*mockWebhookService)(nil)
+
+func (m *mockWebhookService) DeleteWebhook(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestDeleteWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		setupMock      func(mock *mockWebhookService)
+		expectedStatus int
+	}{
+		{
+			name: "Webhook deleted",
+			role: "moderator",
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("DeleteWebhook", "webhook-id").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Employee cannot delete webhooks",
+			role:           "employee",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Webhook not found",
+			role: "moderator",
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("DeleteWebhook", "webhook-id").Return(internalErrors.ErrWebhookNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Service error",
+			role: "moderator",
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("DeleteWebhook", "webhook-id").Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockWebhookService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("webhookId", "webhook-id")
+			ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, middleware.UserCtxKey, models.User{ID: "user-id", Role: tt.role})
+			r := httptest.NewRequest(http.MethodDelete, "/webhooks/webhook-id", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
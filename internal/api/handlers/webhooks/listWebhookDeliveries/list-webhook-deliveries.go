@@ -0,0 +1,41 @@
+package listWebhookDeliveries
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/models"
+	"net/http"
+)
+
+type WebhookService interface {
+	ListDeliveries(webhookID, status, limit, page string) ([]*models.WebhookDelivery, error)
+}
+
+func New(service WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "moderator"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		query := r.URL.Query()
+		deliveries, err := service.ListDeliveries(
+			query.Get("webhookId"),
+			query.Get("status"),
+			query.Get("limit"),
+			query.Get("page"),
+		)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		resp := make([]response.WebhookDeliveryResponse, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			resp = append(resp, response.NewWebhookDeliveryResponse(delivery))
+		}
+		render.List(w, r, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,98 @@
+package listWebhookDeliveries
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWebhookService struct {
+	mock.Mock
+}
+
+var _ WebhookService = (*mockWebhookService)(nil)
+
+func (m *mockWebhookService) ListDeliveries(webhookID, status, limit, page string) ([]*models.WebhookDelivery, error) {
+	args := m.Called(webhookID, status, limit, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+func TestListWebhookDeliveriesHandler(t *testing.T) {
+	dead := &models.WebhookDelivery{
+		ID:             "delivery-id",
+		SubscriptionID: "webhook-id",
+		Payload:        json.RawMessage(`{"id":"event-id"}`),
+		Status:         models.WebhookDeliveryDead,
+		Attempts:       8,
+		NextAttemptAt:  time.Now(),
+		ResponseStatus: http.StatusServiceUnavailable,
+	}
+
+	tests := []struct {
+		name           string
+		role           string
+		setupMock      func(mock *mockWebhookService)
+		expectedStatus int
+	}{
+		{
+			name: "Dead letters listed",
+			role: "moderator",
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("ListDeliveries", "webhook-id", "dead", "10", "2").Return([]*models.WebhookDelivery{dead}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Employee cannot read the delivery log",
+			role:           "employee",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Service error",
+			role: "moderator",
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("ListDeliveries", "webhook-id", "dead", "10", "2").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockWebhookService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/webhooks/deliveries?webhookId=webhook-id&status=dead&limit=10&page=2", nil)
+			r = r.WithContext(context.WithValue(r.Context(), middleware.UserCtxKey, models.User{ID: "user-id", Role: tt.role}))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp []response.WebhookDeliveryResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(t, resp, 1)
+				require.Equal(t, "webhook-id", resp[0].WebhookID)
+				require.Nil(t, resp[0].NextAttemptAt, "dead letters are not retried")
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
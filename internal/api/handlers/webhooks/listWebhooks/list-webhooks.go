@@ -0,0 +1,35 @@
+package listWebhooks
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/models"
+	"net/http"
+)
+
+type WebhookService interface {
+	ListWebhooks() ([]*models.WebhookSubscription, error)
+}
+
+func New(service WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "moderator"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		subscriptions, err := service.ListWebhooks()
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		resp := make([]response.WebhookResponse, 0, len(subscriptions))
+		for _, subscription := range subscriptions {
+			resp = append(resp, response.NewWebhookResponse(subscription))
+		}
+		render.List(w, r, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,86 @@
+package listWebhooks
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWebhookService struct {
+	mock.Mock
+}
+
+var _ WebhookService = (*mockWebhookService)(nil)
+
+func (m *mockWebhookService) ListWebhooks() ([]*models.WebhookSubscription, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookSubscription), args.Error(1)
+}
+
+func TestListWebhooksHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		setupMock      func(mock *mockWebhookService)
+		expectedStatus int
+	}{
+		{
+			name: "Webhooks listed",
+			role: "moderator",
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("ListWebhooks").Return([]*models.WebhookSubscription{{ID: "webhook-id", Secret: "whsec_secret"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Admin cannot list webhooks",
+			role:           "admin",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Service error",
+			role: "moderator",
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("ListWebhooks").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockWebhookService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/webhooks", nil)
+			r = r.WithContext(context.WithValue(r.Context(), middleware.UserCtxKey, models.User{ID: "user-id", Role: tt.role}))
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				require.NotContains(t, w.Body.String(), "whsec_secret")
+				var resp []response.WebhookResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(t, resp, 1)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package resendWebhookDelivery
+
+import (
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/models"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhookService interface {
+	ResendDelivery(id string) (*models.WebhookDelivery, error)
+}
+
+func New(service WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.RequireRole(r.Context(), "moderator"); err != nil {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+
+		delivery, err := service.ResendDelivery(chi.URLParam(r, "deliveryId"))
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		render.Write(w, r, http.StatusAccepted, response.NewWebhookDeliveryResponse(delivery))
+	}
+}
@@ -0,0 +1,104 @@
+package resendWebhookDelivery
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWebhookService struct {
+	mock.Mock
+}
+
+var _ WebhookService = (*mockWebhookService)(nil)
+
+func (m *mockWebhookService) ResendDelivery(id string) (*models.WebhookDelivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func TestResendWebhookDeliveryHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		setupMock      func(mock *mockWebhookService)
+		expectedStatus int
+	}{
+		{
+			name: "Delivery queued again",
+			role: "moderator",
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("ResendDelivery", "delivery-id").Return(&models.WebhookDelivery{
+					ID:            "delivery-id",
+					Payload:       json.RawMessage(`{}`),
+					Status:        models.WebhookDeliveryPending,
+					NextAttemptAt: time.Now(),
+				}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "Employee cannot resend deliveries",
+			role:           "employee",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Delivery not found",
+			role: "moderator",
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("ResendDelivery", "delivery-id").Return(nil, internalErrors.ErrDeliveryNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Service error",
+			role: "moderator",
+			setupMock: func(mock *mockWebhookService) {
+				mock.On("ResendDelivery", "delivery-id").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockWebhookService)
+			if tt.setupMock != nil {
+				tt.setupMock(mockService)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("deliveryId", "delivery-id")
+			ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, middleware.UserCtxKey, models.User{ID: "user-id", Role: tt.role})
+			r := httptest.NewRequest(http.MethodPost, "/webhooks/deliveries/delivery-id/resend", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			New(mockService).ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusAccepted {
+				var resp response.WebhookDeliveryResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Equal(t, models.WebhookDeliveryPending, resp.Status)
+				require.NotNil(t, resp.NextAttemptAt)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -1,41 +1,120 @@
 package middleware
 
 import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/problem"
 	"avito-intern/internal/models"
 	"avito-intern/internal/utils"
+	"bytes"
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
 )
 
 type contextKey string
 
-const UserCtxKey = contextKey("user")
+const (
+	UserCtxKey    = contextKey("user")
+	ServiceCtxKey = contextKey("service")
+)
+
+// maxSignedBodySize bounds the body read into memory to check a signature.
+const maxSignedBodySize = 1 << 20
 
 func AuthMiddleware(next http.Handler) http.Handler {
+	return authenticate(nil, nil, next)
+}
+
+type ExternalAuthenticator interface {
+	Authenticate(rawToken string) (*models.User, error)
+}
+
+type APIKeyAuthenticator interface {
+	AuthenticateKey(rawKey string) (*models.APIKey, error)
+	VerifyRequest(req models.SignedRequest) (*models.APIKey, error)
+}
+
+// NewAuthMiddleware accepts our own tokens and, when configured, tokens of an
+// external identity provider and API keys. A key is sent either as
+// "Authorization: ApiKey <key>" or as an HMAC signature in the X-Api-Key-Id,
+// X-Timestamp, X-Nonce and X-Signature headers.
+func NewAuthMiddleware(external ExternalAuthenticator, keys APIKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return authenticate(external, keys, next)
+	}
+}
+
+func authenticate(external ExternalAuthenticator, keys APIKeyAuthenticator, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if keys != nil && r.Header.Get("X-Signature") != "" {
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBodySize))
+			if err != nil {
+				problem.Write(w, r, problem.Wrap(problem.InvalidRequest, err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			key, err := keys.VerifyRequest(models.SignedRequest{
+				KeyID:     r.Header.Get("X-Api-Key-Id"),
+				Timestamp: r.Header.Get("X-Timestamp"),
+				Nonce:     r.Header.Get("X-Nonce"),
+				Signature: r.Header.Get("X-Signature"),
+				Method:    r.Method,
+				URI:       r.URL.RequestURI(),
+				Body:      body,
+			})
+			serveAPIKey(w, r, next, key, err)
+			return
+		}
+
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Missing Authorization header", http.StatusUnauthorized)
+			problem.Write(w, r, problem.New(problem.MissingAuthorization))
 			return
 		}
 		parts := strings.Split(authHeader, " ")
+		if len(parts) == 2 && strings.ToLower(parts[0]) == "apikey" && keys != nil {
+			key, err := keys.AuthenticateKey(parts[1])
+			serveAPIKey(w, r, next, key, err)
+			return
+		}
 		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			http.Error(w, "Invalid Authorization header", http.StatusUnauthorized)
+			problem.Write(w, r, problem.New(problem.InvalidAuthorization))
 			return
 		}
 
 		claims, err := utils.ParseJWT(parts[1])
 		if err != nil {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			if external == nil {
+				problem.Write(w, r, problem.New(problem.InvalidToken))
+				return
+			}
+			externalUser, err := external.Authenticate(parts[1])
+			if err != nil {
+				if errors.Is(err, internalErrors.ErrInvalidCredentials) ||
+					errors.Is(err, internalErrors.ErrAccountDeactivated) ||
+					errors.Is(err, internalErrors.ErrAccountPending) {
+					err = problem.Wrap(problem.InvalidToken, err)
+				}
+				problem.Write(w, r, err)
+				return
+			}
+			// Second factors are enforced by the identity provider.
+			user := *externalUser
+			user.Password = ""
+			user.TwoFactor = true
+			ctx := context.WithValue(r.Context(), UserCtxKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
 
-		user := models.User{
-			ID:    claims["user_id"].(string),
-			Role:  claims["role"].(string),
-			Email: "",
+		user, ok := UserFromClaims(claims)
+		if !ok {
+			problem.Write(w, r, problem.New(problem.InvalidToken))
+			return
 		}
 
 		ctx := context.WithValue(r.Context(), UserCtxKey, user)
@@ -43,6 +122,134 @@ func AuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// UserFromClaims builds the user of one of our access tokens. It reports
+// false when the claims lack the user ID or role.
+func UserFromClaims(claims jwt.MapClaims) (models.User, bool) {
+	userID, idOk := claims["user_id"].(string)
+	role, roleOk := claims["role"].(string)
+	if !idOk || !roleOk {
+		return models.User{}, false
+	}
+
+	user := models.User{
+		ID:    userID,
+		Role:  role,
+		Email: "",
+	}
+	if version, ok := claims["token_version"].(float64); ok {
+		user.TokenVersion = int(version)
+	}
+	user.TwoFactor, _ = claims["two_factor"].(bool)
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		user.ImpersonatedBy, _ = act["sub"].(string)
+		if version, ok := act["token_version"].(float64); ok {
+			user.ImpersonatorTokenVersion = int(version)
+		}
+	}
+	return user, true
+}
+
+// serveAPIKey puts the key's service principal into the context. Handlers
+// that check roles see it as a user with the key's ID and role.
+func serveAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, key *models.APIKey, err error) {
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	ctx := context.WithValue(r.Context(), UserCtxKey, models.User{ID: key.ID, Role: key.Role, TwoFactor: true})
+	ctx = context.WithValue(ctx, ServiceCtxKey, models.ServicePrincipal{
+		KeyID:  key.ID,
+		Name:   key.Name,
+		Role:   key.Role,
+		Scopes: key.Scopes,
+	})
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+type SessionValidator interface {
+	ValidateSession(userID string, tokenVersion int) error
+}
+
+// SessionMiddleware rejects tokens of deactivated users and tokens issued
+// before the user's credentials or role last changed. It must run after
+// AuthMiddleware.
+func SessionMiddleware(validator SessionValidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := GetServiceFromContext(r.Context()); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			user, err := GetUserFromContext(r.Context())
+			if err != nil {
+				problem.Write(w, r, problem.New(problem.InvalidToken))
+				return
+			}
+			if err = validator.ValidateSession(user.ID, user.TokenVersion); err != nil {
+				if errors.Is(err, internalErrors.ErrAccountDeactivated) || errors.Is(err, internalErrors.ErrAccessDenied) {
+					err = problem.Wrap(problem.InvalidToken, err)
+				}
+				problem.Write(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type TwoFactorPolicy interface {
+	IsRequired(role string) bool
+}
+
+// RequireTwoFactor blocks tokens of roles with mandatory two-factor
+// authentication unless the login was completed with a second factor.
+func RequireTwoFactor(policy TwoFactorPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := GetUserFromContext(r.Context())
+			if err != nil {
+				problem.Write(w, r, problem.New(problem.InvalidToken))
+				return
+			}
+			if policy.IsRequired(user.Role) && !user.TwoFactor {
+				problem.Write(w, r, problem.New(problem.TwoFactorRequired))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope limits API keys to routes covered by their scopes. Requests of
+// users are passed through; their access is checked by role in handlers.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if principal, ok := GetServiceFromContext(r.Context()); ok && !principal.HasScope(scope) {
+				problem.Write(w, r, problem.New(problem.InsufficientScope))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DenyAPIKeys keeps API keys away from account and administration routes.
+func DenyAPIKeys(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := GetServiceFromContext(r.Context()); ok {
+			problem.Write(w, r, problem.New(problem.AccessDenied))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func GetServiceFromContext(ctx context.Context) (models.ServicePrincipal, bool) {
+	principal, ok := ctx.Value(ServiceCtxKey).(models.ServicePrincipal)
+	return principal, ok
+}
+
 func GetUserFromContext(ctx context.Context) (models.User, error) {
 	user, ok := ctx.Value(UserCtxKey).(models.User)
 	if !ok {
@@ -57,7 +264,20 @@ func RequireRole(ctx context.Context, required string) error {
 		return err
 	}
 	if user.Role != required {
-		return errors.New("access denied")
+		return internalErrors.ErrAccessDenied
 	}
 	return nil
 }
+
+func RequireAnyRole(ctx context.Context, roles ...string) error {
+	user, err := GetUserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if user.Role == role {
+			return nil
+		}
+	}
+	return internalErrors.ErrAccessDenied
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ETagHeader        = "ETag"
+	IfNoneMatchHeader = "If-None-Match"
+	IfMatchHeader     = "If-Match"
+)
+
+// ETag returns the strong entity tag of a resource state. It is computed
+// from the JSON encoding of v, so it changes whenever the state does and
+// does not depend on how the response is encoded.
+func ETag(v interface{}) (string, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// NotModified sets the ETag of the response and reports whether the
+// request's If-None-Match already holds it, in which case 304 is written
+// and the handler must not write a body.
+func NotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set(ETagHeader, etag)
+	header := r.Header.Get(IfNoneMatchHeader)
+	if header == "" || !matchETag(header, etag) {
+		return false
+	}
+	w.Header().Del("Content-Type")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// VersionETag returns the strong entity tag of a resource that carries a
+// version incremented on every change. Changes check If-Match against the
+// version with IfMatchVersion, in the same statement that writes them.
+func VersionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// IfMatchVersion returns the version the If-Match header of a change expects
+// the resource at, for the change to check as it is written. It is zero
+// without If-Match and for If-Match: *, which only needs the resource to
+// exist. Lists of several tags, weak tags and tags that are not versions
+// can never be checked and give ErrPreconditionFailed.
+func IfMatchVersion(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get(IfMatchHeader))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(header, `"`), `"`))
+	if err != nil || version < 1 || VersionETag(version) != header {
+		return 0, internalErrors.ErrPreconditionFailed
+	}
+	return version, nil
+}
+
+// PreconditionError returns ErrPreconditionFailed for the errors of a change
+// with If-Match that mean its precondition does not hold: a newer version
+// or a missing resource. Other errors are returned as they are.
+func PreconditionError(r *http.Request, err error) error {
+	if r.Header.Get(IfMatchHeader) == "" {
+		return err
+	}
+	if errors.Is(err, internalErrors.ErrVersionConflict) ||
+		errors.Is(err, internalErrors.ErrPVZNotFound) ||
+		errors.Is(err, internalErrors.ErrInvalidID) {
+		return internalErrors.ErrPreconditionFailed
+	}
+	return err
+}
+
+// matchETag reports whether the list of entity tags in header holds etag
+// by the weak comparison of If-None-Match, where W/ prefixes are ignored.
+func matchETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
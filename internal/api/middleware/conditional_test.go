@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETag(t *testing.T) {
+	first, err := ETag(models.PVZ{ID: "pvz-1", City: "Москва"})
+	require.NoError(t, err)
+	same, err := ETag(models.PVZ{ID: "pvz-1", City: "Москва"})
+	require.NoError(t, err)
+	other, err := ETag(models.PVZ{ID: "pvz-1", City: "Казань"})
+	require.NoError(t, err)
+
+	assert.Equal(t, first, same)
+	assert.NotEqual(t, first, other)
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, first)
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		notModified bool
+	}{
+		{name: "No header", ifNoneMatch: "", notModified: false},
+		{name: "Matching tag", ifNoneMatch: `"abc"`, notModified: true},
+		{name: "Weak matching tag", ifNoneMatch: `W/"abc"`, notModified: true},
+		{name: "Tag in list", ifNoneMatch: `"old", "abc"`, notModified: true},
+		{name: "Any tag", ifNoneMatch: "*", notModified: true},
+		{name: "Other tag", ifNoneMatch: `"old"`, notModified: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/pvz", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set(IfNoneMatchHeader, tt.ifNoneMatch)
+			}
+			rr := httptest.NewRecorder()
+
+			assert.Equal(t, tt.notModified, NotModified(rr, req, `"abc"`))
+			assert.Equal(t, `"abc"`, rr.Header().Get(ETagHeader))
+			if tt.notModified {
+				assert.Equal(t, http.StatusNotModified, rr.Code)
+			}
+		})
+	}
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		ifMatch  string
+		expected int
+		fails    bool
+	}{
+		{name: "No header", ifMatch: "", expected: 0},
+		{name: "Any tag", ifMatch: "*", expected: 0},
+		{name: "Version tag", ifMatch: VersionETag(3), expected: 3},
+		{name: "Weak tag", ifMatch: "W/" + VersionETag(3), fails: true},
+		{name: "Tag list", ifMatch: `"2", "3"`, fails: true},
+		{name: "Other tag", ifMatch: `"abc"`, fails: true},
+		{name: "Unquoted version", ifMatch: "3", fails: true},
+		{name: "Zero version", ifMatch: `"0"`, fails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/pvz/pvz-1/close_last_reception", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set(IfMatchHeader, tt.ifMatch)
+			}
+
+			version, err := IfMatchVersion(req)
+			if tt.fails {
+				assert.ErrorIs(t, err, internalErrors.ErrPreconditionFailed)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestPreconditionError(t *testing.T) {
+	conflict := &internalErrors.ConflictError{Err: internalErrors.ErrVersionConflict}
+	dbErr := errors.New("database error")
+
+	req := httptest.NewRequest(http.MethodPatch, "/pvz/pvz-1", nil)
+	assert.Equal(t, conflict, PreconditionError(req, conflict), "without If-Match a conflict stays a conflict")
+
+	req.Header.Set(IfMatchHeader, VersionETag(1))
+	assert.Equal(t, internalErrors.ErrPreconditionFailed, PreconditionError(req, conflict))
+	assert.Equal(t, internalErrors.ErrPreconditionFailed, PreconditionError(req, internalErrors.ErrVersionConflict))
+	assert.Equal(t, internalErrors.ErrPreconditionFailed, PreconditionError(req, internalErrors.ErrPVZNotFound), "missing resource does not match")
+	assert.Equal(t, dbErr, PreconditionError(req, dbErr))
+}
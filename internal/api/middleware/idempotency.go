@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/models"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBody  = 1 << 20
+	maxIdempotentResponseBody = 1 << 20
+)
+
+type IdempotencyStore interface {
+	Begin(scope, key, requestHash string) (*models.IdempotencyKey, error)
+	Complete(response *models.IdempotencyKey) error
+	Release(scope, key string) error
+}
+
+// IdempotencyMiddleware makes POST requests with an Idempotency-Key header
+// safe to retry. The first request with a key is served and its response
+// stored; retries get the stored response with Idempotent-Replayed: true.
+// Reusing the key for another request gives 422, retrying while the first
+// request is still served gives 409. Server errors are not stored, so that
+// the retry is served again.
+//
+// It must run after authentication: keys are scoped by the authenticated
+// principal, and requests without one are passed through. The responses are
+// stored, so routes whose responses carry credentials must not use it.
+func IdempotencyMiddleware(store IdempotencyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			scope, ok := idempotencyScope(r)
+			if r.Method != http.MethodPost || key == "" || !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				problem.Write(w, r, problem.New(problem.InvalidRequest))
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBody))
+			if err != nil {
+				problem.Write(w, r, problem.Wrap(problem.InvalidRequest, err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			stored, err := store.Begin(scope, key, requestHash(r, body))
+			if err != nil {
+				problem.Write(w, r, err)
+				return
+			}
+			if stored != nil {
+				for name, values := range stored.ResponseHeaders {
+					w.Header()[name] = values
+				}
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				w.WriteHeader(stored.StatusCode)
+				w.Write(stored.ResponseBody)
+				return
+			}
+
+			rec := &recordingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				if p := recover(); p != nil {
+					release(store, scope, key)
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= http.StatusInternalServerError || rec.truncated {
+				release(store, scope, key)
+				return
+			}
+			err = store.Complete(&models.IdempotencyKey{
+				Scope:           scope,
+				Key:             key,
+				StatusCode:      rec.status,
+				ResponseHeaders: w.Header().Clone(),
+				ResponseBody:    rec.body.Bytes(),
+			})
+			if err != nil {
+				log.Printf("Could not store response for idempotency key: %v", err)
+				release(store, scope, key)
+			}
+		})
+	}
+}
+
+func release(store IdempotencyStore, scope, key string) {
+	if err := store.Release(scope, key); err != nil {
+		log.Printf("Could not release idempotency key: %v", err)
+	}
+}
+
+// idempotencyScope identifies the authenticated caller: an API key, or a
+// user together with the administrator impersonating them.
+func idempotencyScope(r *http.Request) (string, bool) {
+	if service, ok := GetServiceFromContext(r.Context()); ok {
+		return "api-key:" + service.KeyID, true
+	}
+	user, err := GetUserFromContext(r.Context())
+	if err != nil || user.ID == "" {
+		return "", false
+	}
+	if user.ImpersonatedBy != "" {
+		return "user:" + user.ID + ":impersonated-by:" + user.ImpersonatedBy, true
+	}
+	return "user:" + user.ID, true
+}
+
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingResponseWriter passes the response through and keeps a copy of
+// it. Responses larger than maxIdempotentResponseBody are not kept.
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	truncated   bool
+}
+
+func (w *recordingResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	if w.body.Len()+len(b) > maxIdempotentResponseBody {
+		w.truncated = true
+	} else if !w.truncated {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
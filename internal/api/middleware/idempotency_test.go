@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryIdempotencyStore struct {
+	keys map[string]*models.IdempotencyKey
+}
+
+func (s *memoryIdempotencyStore) Begin(scope, key, requestHash string) (*models.IdempotencyKey, error) {
+	stored, exists := s.keys[scope+key]
+	switch {
+	case !exists:
+		s.keys[scope+key] = &models.IdempotencyKey{Scope: scope, Key: key, RequestHash: requestHash}
+		return nil, nil
+	case stored.RequestHash != requestHash:
+		return nil, internalErrors.ErrIdempotencyKeyReused
+	case !stored.Completed():
+		return nil, internalErrors.ErrIdempotencyInProgress
+	default:
+		return stored, nil
+	}
+}
+
+func (s *memoryIdempotencyStore) Complete(response *models.IdempotencyKey) error {
+	stored := s.keys[response.Scope+response.Key]
+	stored.StatusCode = response.StatusCode
+	stored.ResponseHeaders = response.ResponseHeaders
+	stored.ResponseBody = response.ResponseBody
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(scope, key string) error {
+	delete(s.keys, scope+key)
+	return nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	store := &memoryIdempotencyStore{keys: make(map[string]*models.IdempotencyKey)}
+	served := 0
+	status := http.StatusCreated
+	handler := IdempotencyMiddleware(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+
+	send := func(method, key, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/products", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		if token != "" {
+			req = req.WithContext(context.WithValue(req.Context(), UserCtxKey, models.User{ID: token}))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send(http.MethodPost, "key-1", "a", `{"type":"обувь"}`)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	assert.Empty(t, rr.Header().Get(IdempotentReplayedHeader))
+
+	rr = send(http.MethodPost, "key-1", "a", `{"type":"обувь"}`)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "true", rr.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `{"type":"обувь"}`, rr.Body.String())
+	assert.Equal(t, 1, served, "retry is not served again")
+
+	rr = send(http.MethodPost, "key-1", "a", `{"type":"одежда"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"idempotency_key_reused"`)
+
+	rr = send(http.MethodPost, "key-1", "b", `{"type":"одежда"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code, "keys are scoped by the authenticated user")
+	assert.Equal(t, 2, served)
+
+	_, err := store.Begin("user:a", "key-2", requestHash(httptest.NewRequest(http.MethodPost, "/products", nil), []byte("{}")))
+	require.NoError(t, err)
+	rr = send(http.MethodPost, "key-2", "a", "{}")
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	status = http.StatusInternalServerError
+	send(http.MethodPost, "key-3", "a", "{}")
+	status = http.StatusCreated
+	rr = send(http.MethodPost, "key-3", "a", "{}")
+	assert.Equal(t, http.StatusCreated, rr.Code, "server errors are not stored")
+	assert.Empty(t, rr.Header().Get(IdempotentReplayedHeader))
+
+	served = 0
+	send(http.MethodPost, "", "a", "{}")
+	send(http.MethodPost, "", "a", "{}")
+	send(http.MethodGet, "key-4", "a", "")
+	send(http.MethodGet, "key-4", "a", "")
+	assert.Equal(t, 4, served, "requests without a key and other methods are passed through")
+
+	served = 0
+	send(http.MethodPost, "key-5", "", "{}")
+	send(http.MethodPost, "key-5", "", "{}")
+	assert.Equal(t, 2, served, "anonymous requests share no scope and are passed through")
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	scope := func(ctx context.Context) string {
+		scope, _ := idempotencyScope(httptest.NewRequest(http.MethodPost, "/", nil).WithContext(ctx))
+		return scope
+	}
+	user := context.WithValue(context.Background(), UserCtxKey, models.User{ID: "user-id"})
+	impersonated := context.WithValue(context.Background(), UserCtxKey, models.User{ID: "user-id", ImpersonatedBy: "admin-id"})
+	apiKey := context.WithValue(user, ServiceCtxKey, models.ServicePrincipal{KeyID: "user-id"})
+
+	assert.Equal(t, "user:user-id", scope(user))
+	assert.NotEqual(t, scope(user), scope(impersonated))
+	assert.NotEqual(t, scope(user), scope(apiKey))
+
+	_, ok := idempotencyScope(httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.False(t, ok)
+}
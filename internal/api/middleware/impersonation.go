@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/problem"
+	"errors"
+	"net/http"
+)
+
+type ImpersonationAuditor interface {
+	ValidateActor(actorID string, tokenVersion int) error
+	RecordRequest(actorID, userID, method, path string, blocked bool) error
+}
+
+// ImpersonationMiddleware rejects impersonation tokens whose administrator
+// is no longer active, an administrator or signed in with the same token
+// version. It audits every other request made with them before it is
+// served, marks the response with X-Impersonated-By and refuses anything
+// but reads. It must run after AuthMiddleware.
+func ImpersonationMiddleware(auditor ImpersonationAuditor) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := GetUserFromContext(r.Context())
+			if err != nil || user.ImpersonatedBy == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err = auditor.ValidateActor(user.ImpersonatedBy, user.ImpersonatorTokenVersion); err != nil {
+				if errors.Is(err, internalErrors.ErrAccountDeactivated) || errors.Is(err, internalErrors.ErrAccessDenied) {
+					err = problem.Wrap(problem.InvalidToken, err)
+				}
+				problem.Write(w, r, err)
+				return
+			}
+
+			blocked := r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions
+			if err = auditor.RecordRequest(user.ImpersonatedBy, user.ID, r.Method, r.URL.Path, blocked); err != nil {
+				problem.Write(w, r, err)
+				return
+			}
+			w.Header().Set("X-Impersonated-By", user.ImpersonatedBy)
+			if blocked {
+				problem.Write(w, r, problem.New(problem.ImpersonationReadOnly))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
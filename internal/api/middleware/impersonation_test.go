@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryImpersonationAuditor struct {
+	actorErr error
+	requests []string
+}
+
+func (a *memoryImpersonationAuditor) ValidateActor(actorID string, tokenVersion int) error {
+	return a.actorErr
+}
+
+func (a *memoryImpersonationAuditor) RecordRequest(actorID, userID, method, path string, blocked bool) error {
+	a.requests = append(a.requests, method+" "+path)
+	return nil
+}
+
+func TestImpersonationMiddleware(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		actorErr         error
+		expectedStatus   int
+		expectedRequests int
+	}{
+		{name: "read", method: http.MethodGet, expectedStatus: http.StatusOK, expectedRequests: 1},
+		{name: "mutation", method: http.MethodPost, expectedStatus: http.StatusForbidden, expectedRequests: 1},
+		{name: "revoked actor", method: http.MethodGet, actorErr: internalErrors.ErrTokenRevoked, expectedStatus: http.StatusUnauthorized},
+		{name: "deactivated actor", method: http.MethodGet, actorErr: internalErrors.ErrAccountDeactivated, expectedStatus: http.StatusUnauthorized},
+		{name: "demoted actor", method: http.MethodGet, actorErr: internalErrors.ErrAccessDenied, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auditor := &memoryImpersonationAuditor{actorErr: tc.actorErr}
+			handler := ImpersonationMiddleware(auditor)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			user := models.User{ID: "user-id", Role: "employee", ImpersonatedBy: "admin-id", ImpersonatorTokenVersion: 2}
+			req := httptest.NewRequest(tc.method, "/pvz", nil)
+			req = req.WithContext(context.WithValue(req.Context(), UserCtxKey, user))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Len(t, auditor.requests, tc.expectedRequests)
+		})
+	}
+}
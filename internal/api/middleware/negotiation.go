@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"net/http"
+)
+
+// NegotiationMiddleware rejects requests whose Accept header allows none of
+// the formats render supports with 406 before they are served. Whether CSV is
+// available depends on the endpoint, so render can still answer 406 for it.
+func NegotiationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !render.Acceptable(r) {
+			problem.Write(w, r, internalErrors.ErrNotAcceptable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
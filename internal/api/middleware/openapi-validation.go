@@ -0,0 +1,270 @@
+package middleware
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// OpenAPIValidationMiddleware rejects requests that do not match the OpenAPI
+// document with an invalid_request problem listing what was wrong in errors. Requests to paths and
+// methods the document does not describe are passed through, so the router
+// still answers them with 404 and 405.
+//
+// With validateResponses, which tests turn on, responses are checked as
+// well: a handler whose response does not match the document gets an
+// internal_error problem instead, which makes drift between the handlers and
+// the document fail the tests. Streams are not held back for that.
+//
+// The document describes the unversioned paths; requests under one of
+// versionPrefixes are checked against the same operations.
+func OpenAPIValidationMiddleware(doc *openapi3.T, validateResponses bool, versionPrefixes ...string) func(http.Handler) http.Handler {
+	specRouter, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		panic("openapi validation: " + err.Error())
+	}
+	registerBodyDecoders.Do(func() {
+		for _, format := range []string{render.MessagePack, render.Protobuf, render.CSV} {
+			openapi3filter.RegisterBodyDecoder(format, documentDecoder(format))
+		}
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lookup := r
+			for _, prefix := range versionPrefixes {
+				if path, ok := strings.CutPrefix(r.URL.Path, prefix); ok && strings.HasPrefix(path, "/") {
+					lookup = r.Clone(r.Context())
+					lookup.URL.Path = path
+					lookup.URL.RawPath = ""
+					break
+				}
+			}
+
+			route, pathParams, err := specRouter.FindRoute(lookup)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    lookup,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+					MultiError:         true,
+				},
+			}
+			err = openapi3filter.ValidateRequest(r.Context(), input)
+			// Validation reads the body and leaves a fresh copy on the request it checked.
+			r.Body = lookup.Body
+			if err != nil {
+				if unsupportedContentType(err) {
+					problem.Write(w, r, internalErrors.ErrUnsupportedMediaType)
+					return
+				}
+				problem.Write(w, r, problem.Wrap(problem.InvalidRequest, err).WithDetail("errors", validationErrors(err)))
+				return
+			}
+
+			if !validateResponses || streams(route.Operation) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bw := &bufferedResponseWriter{header: w.Header().Clone(), status: http.StatusOK}
+			next.ServeHTTP(bw, r)
+
+			err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 bw.status,
+				Header:                 bw.header,
+				Body:                   io.NopCloser(bytes.NewReader(bw.body.Bytes())),
+				Options: &openapi3filter.Options{
+					IncludeResponseStatus: true,
+					MultiError:            true,
+				},
+			})
+			if err != nil {
+				problem.Write(w, r, problem.Wrap(problem.InternalError, err).
+					WithDetail("errors", responseValidationErrors(err)))
+				return
+			}
+
+			for key := range w.Header() {
+				w.Header().Del(key)
+			}
+			for key, values := range bw.header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(bw.status)
+			w.Write(bw.body.Bytes())
+		})
+	}
+}
+
+// streams reports whether the operation answers with a stream, an event
+// stream or a WebSocket, that is written while the handler runs.
+func streams(operation *openapi3.Operation) bool {
+	if operation.Responses.Status(http.StatusSwitchingProtocols) != nil {
+		return true
+	}
+	for _, ref := range operation.Responses.Map() {
+		if ref.Value != nil && ref.Value.Content.Get(render.EventStream) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+var registerBodyDecoders sync.Once
+
+// documentDecoder lets the validation check bodies in the formats render
+// supports against the schemas of their JSON documents. Protobuf bodies are
+// read as the message the schema names in render.MessageExtension.
+func documentDecoder(format string) openapi3filter.BodyDecoder {
+	return func(body io.Reader, _ http.Header, schema *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (any, error) {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		if format == render.Protobuf && schema != nil && schema.Value != nil {
+			if name, ok := schema.Value.Extensions[render.MessageExtension].(string); ok {
+				return render.DecodeMessage(name, data)
+			}
+		}
+		return render.DecodeDocument(format, data)
+	}
+}
+
+// unsupportedContentType reports whether the request was rejected because the
+// operation does not accept the format of its body.
+func unsupportedContentType(err error) bool {
+	if multi, ok := err.(openapi3.MultiError); ok {
+		for _, e := range multi {
+			if unsupportedContentType(e) {
+				return true
+			}
+		}
+		return false
+	}
+	var requestErr *openapi3filter.RequestError
+	return errors.As(err, &requestErr) && requestErr.RequestBody != nil &&
+		strings.HasPrefix(requestErr.Reason, "header Content-Type has unexpected value")
+}
+
+func validationErrors(err error) []response.ValidationError {
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		var result []response.ValidationError
+		for _, e := range err {
+			result = append(result, validationErrors(e)...)
+		}
+		return result
+	case *openapi3filter.RequestError:
+		switch {
+		case err.Parameter != nil:
+			return []response.ValidationError{{
+				Location: err.Parameter.In,
+				Name:     err.Parameter.Name,
+				Reason:   reason(err),
+			}}
+		case err.RequestBody != nil:
+			if schemaErrs, ok := err.Err.(openapi3.MultiError); ok {
+				var result []response.ValidationError
+				for _, e := range schemaErrs {
+					result = append(result, bodyError(e))
+				}
+				return result
+			}
+			return []response.ValidationError{bodyError(err)}
+		default:
+			return []response.ValidationError{{Location: "request", Reason: reason(err)}}
+		}
+	default:
+		return []response.ValidationError{{Location: "request", Reason: err.Error()}}
+	}
+}
+
+func bodyError(err error) response.ValidationError {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return response.ValidationError{
+			Location: "body",
+			Name:     "/" + strings.Join(schemaErr.JSONPointer(), "/"),
+			Reason:   schemaErr.Reason,
+		}
+	}
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) {
+		return response.ValidationError{Location: "body", Reason: reason(requestErr)}
+	}
+	return response.ValidationError{Location: "body", Reason: err.Error()}
+}
+
+func reason(err *openapi3filter.RequestError) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err.Err, &schemaErr) {
+		return schemaErr.Reason
+	}
+	if err.Err != nil {
+		return err.Err.Error()
+	}
+	return err.Reason
+}
+
+func responseValidationErrors(err error) []response.ValidationError {
+	if multi, ok := err.(openapi3.MultiError); ok {
+		var result []response.ValidationError
+		for _, e := range multi {
+			result = append(result, responseValidationErrors(e)...)
+		}
+		return result
+	}
+	var responseErr *openapi3filter.ResponseError
+	if errors.As(err, &responseErr) {
+		if multi, ok := responseErr.Err.(openapi3.MultiError); ok {
+			return responseValidationErrors(multi)
+		}
+	}
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return []response.ValidationError{{
+			Location: "response",
+			Name:     "/" + strings.Join(schemaErr.JSONPointer(), "/"),
+			Reason:   schemaErr.Reason,
+		}}
+	}
+	return []response.ValidationError{{Location: "response", Reason: err.Error()}}
+}
+
+// bufferedResponseWriter holds a response back until it has been validated.
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
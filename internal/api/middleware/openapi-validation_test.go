@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/grpc/pvzv1"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+const validationSpec = `
+openapi: 3.0.0
+info:
+  title: test
+  version: "1"
+paths:
+  /pvz:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id:
+                    type: string
+`
+
+func TestOpenAPIValidationMiddleware_Responses(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(validationSpec))
+	require.NoError(t, err)
+	// The handler answers with a status the document does not describe.
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(`{"id":"pvz-1"}`))
+	})
+
+	tests := []struct {
+		name              string
+		validateResponses bool
+		expectedStatus    int
+	}{
+		{name: "Responses passed through", validateResponses: false, expectedStatus: http.StatusTeapot},
+		{name: "Responses validated", validateResponses: true, expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/pvz", nil)
+			w := httptest.NewRecorder()
+
+			OpenAPIValidationMiddleware(doc, tt.validateResponses, "/v1")(handler).ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.validateResponses {
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				var body map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+				assert.Equal(t, string(problem.InternalError), body["code"])
+				assert.NotEmpty(t, body["errors"])
+			}
+		})
+	}
+}
+
+const protobufSpec = `
+openapi: 3.0.0
+info:
+  title: test
+  version: "1"
+paths:
+  /pvz/{pvzId}:
+    patch:
+      parameters:
+        - name: pvzId
+          in: path
+          required: true
+          schema:
+            type: string
+      requestBody:
+        required: true
+        content:
+          application/x-protobuf:
+            schema:
+              type: object
+              x-protobuf-message: pvz.v1.UpdatePVZRequest
+              required: [city, version]
+              properties:
+                city:
+                  type: string
+                version:
+                  type: integer
+                  minimum: 1
+      responses:
+        "200":
+          description: OK
+`
+
+func TestOpenAPIValidationMiddleware_ProtobufMessages(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(protobufSpec))
+	require.NoError(t, err)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		message        *pvzv1.UpdatePVZRequest
+		expectedStatus int
+	}{
+		{name: "Valid message", message: &pvzv1.UpdatePVZRequest{City: "Москва", Version: 2}, expectedStatus: http.StatusOK},
+		{name: "Version below minimum", message: &pvzv1.UpdatePVZRequest{City: "Москва"}, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := proto.Marshal(tt.message)
+			require.NoError(t, err)
+			r := httptest.NewRequest(http.MethodPatch, "/v1/pvz/pvz-1", bytes.NewReader(body))
+			r.Header.Set("Content-Type", render.Protobuf)
+			w := httptest.NewRecorder()
+
+			OpenAPIValidationMiddleware(doc, false, "/v1")(handler).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"avito-intern/internal/api/problem"
+	"avito-intern/internal/utils"
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// StreamTokenQuery is the query parameter that carries a stream token.
+const StreamTokenQuery = "access_token"
+
+// StreamTokenMiddleware authenticates the event streams of a pickup point
+// with a stream token in the query, which browsers use as EventSource and
+// WebSocket cannot send Authorization. Requests without one go through
+// authenticate. The route must have the pvzId parameter.
+func StreamTokenMiddleware(authenticate func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		authenticated := authenticate(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.URL.Query().Get(StreamTokenQuery)
+			if token == "" {
+				authenticated.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := utils.ParseStreamJWT(token, chi.URLParam(r, "pvzId"))
+			if err != nil {
+				problem.Write(w, r, problem.New(problem.InvalidToken))
+				return
+			}
+			user, ok := UserFromClaims(claims)
+			if !ok {
+				problem.Write(w, r, problem.New(problem.InvalidToken))
+				return
+			}
+			ctx := context.WithValue(r.Context(), UserCtxKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"avito-intern/internal/metrics"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// UnversionedAPI is the version label of requests to the root aliases.
+const UnversionedAPI = "unversioned"
+
+// APIVersionMiddleware counts requests served by the given API version.
+func APIVersionMiddleware(version string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			metrics.VersionRequestCount.WithLabelValues(
+				version,
+				r.Method,
+				strconv.Itoa(ww.Status()),
+			).Inc()
+		})
+	}
+}
+
+// DeprecationMiddleware marks responses of deprecated routes with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers and links the same
+// path under successorPrefix as the successor version. A zero deprecatedAt
+// or sunset omits its header.
+func DeprecationMiddleware(deprecatedAt, sunset time.Time, successorPrefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !deprecatedAt.IsZero() {
+				w.Header().Set("Deprecation", fmt.Sprintf("@%d", deprecatedAt.Unix()))
+			}
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			w.Header().Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successorPrefix, r.URL.Path))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Package openapi holds the OpenAPI 3 description of the HTTP API. The
+// document is embedded into the binary, served at /openapi.json and used by
+// the validation middleware, so it has to be kept in sync with the router.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+var (
+	loadOnce sync.Once
+	doc      *openapi3.T
+	docJSON  []byte
+	loadErr  error
+)
+
+func init() {
+	// SCIM clients send and expect application/scim+json, which is plain JSON.
+	openapi3filter.RegisterBodyDecoder("application/scim+json", openapi3filter.JSONBodyDecoder)
+}
+
+// Load parses and validates the embedded document. The result is cached, so
+// callers must not modify it.
+func Load() (*openapi3.T, error) {
+	loadOnce.Do(func() {
+		loader := openapi3.NewLoader()
+		doc, loadErr = loader.LoadFromData(spec)
+		if loadErr != nil {
+			return
+		}
+		if loadErr = doc.Validate(context.Background()); loadErr != nil {
+			return
+		}
+		docJSON, loadErr = json.Marshal(doc)
+	})
+	return doc, loadErr
+}
+
+// MustLoad is like Load but panics if the embedded document is invalid.
+func MustLoad() *openapi3.T {
+	doc, err := Load()
+	if err != nil {
+		panic("openapi: " + err.Error())
+	}
+	return doc
+}
+
+// Handler serves the document as JSON.
+func Handler() http.HandlerFunc {
+	MustLoad()
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(docJSON)
+	}
+}
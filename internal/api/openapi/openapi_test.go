@@ -0,0 +1,31 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+	assert.NotNil(t, doc.Paths.Find("/pvz"))
+	assert.NotNil(t, doc.Components.Schemas["Problem"])
+}
+
+func TestHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "3.0.3", body["openapi"])
+	assert.Contains(t, body["paths"], "/openapi.json")
+}
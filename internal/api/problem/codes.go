@@ -0,0 +1,144 @@
+package problem
+
+import "net/http"
+
+// Code is the stable, machine-readable identifier of a problem. Clients
+// should branch on it rather than on the localized title.
+type Code string
+
+const (
+	InvalidRequest        Code = "invalid_request"
+	AccessDenied          Code = "access_denied"
+	InternalError         Code = "internal_error"
+	MissingAuthorization  Code = "missing_authorization"
+	InvalidAuthorization  Code = "invalid_authorization"
+	InvalidToken          Code = "invalid_token"
+	InsufficientScope     Code = "insufficient_scope"
+	TwoFactorRequired     Code = "two_factor_required"
+	ImpersonationReadOnly Code = "impersonation_read_only"
+
+	InvalidCredentials   Code = "invalid_credentials"
+	TooManyLoginAttempts Code = "too_many_login_attempts"
+	AccountPending       Code = "account_pending"
+	AccountDeactivated   Code = "account_deactivated"
+	InviteRequired       Code = "invite_required"
+	InvalidInvite        Code = "invalid_invite"
+	InvalidRole          Code = "invalid_role"
+	EmailExists          Code = "email_exists"
+
+	InvalidCity           Code = "invalid_city"
+	InvalidProductType    Code = "invalid_product_type"
+	ActiveReceptionExists Code = "active_reception_exists"
+	NoActiveReception     Code = "no_active_reception"
+	NoProducts            Code = "no_products"
+	PVZNotFound           Code = "pvz_not_found"
+
+	UserNotFound      Code = "user_not_found"
+	UserNotPending    Code = "user_not_pending"
+	CannotModifySelf  Code = "cannot_modify_self"
+	InvalidUserStatus Code = "invalid_user_status"
+
+	TwoFactorNotEnrolled  Code = "two_factor_not_enrolled"
+	TwoFactorEnabled      Code = "two_factor_enabled"
+	InvalidTwoFactorCode  Code = "invalid_two_factor_code"
+	InvalidTwoFactorToken Code = "invalid_two_factor_token"
+	InvalidResetToken     Code = "invalid_reset_token"
+	InvalidPassword       Code = "invalid_password"
+
+	APIKeyNotFound    Code = "api_key_not_found"
+	InvalidAPIKey     Code = "invalid_api_key"
+	InvalidScope      Code = "invalid_scope"
+	InvalidExpiration Code = "invalid_expiration"
+	InvalidSignature  Code = "invalid_signature"
+	ReplayedRequest   Code = "replayed_request"
+
+	IdempotencyKeyReused  Code = "idempotency_key_reused"
+	IdempotencyInProgress Code = "idempotency_in_progress"
+	PreconditionFailed    Code = "precondition_failed"
+	VersionConflict       Code = "version_conflict"
+	NotAcceptable         Code = "not_acceptable"
+	UnsupportedMediaType  Code = "unsupported_media_type"
+
+	WebhookNotFound   Code = "webhook_not_found"
+	DeliveryNotFound  Code = "delivery_not_found"
+	InvalidWebhookURL Code = "invalid_webhook_url"
+	InvalidEventType  Code = "invalid_event_type"
+
+	AlreadyExists       Code = "already_exists"
+	ReferenceNotFound   Code = "reference_not_found"
+	ConstraintViolation Code = "constraint_violation"
+	InvalidID           Code = "invalid_id"
+)
+
+type definition struct {
+	status int
+	titles map[string]string
+}
+
+func def(status int, en, ru string) definition {
+	return definition{status: status, titles: map[string]string{"en": en, "ru": ru}}
+}
+
+var catalogue = map[Code]definition{
+	InvalidRequest:        def(http.StatusBadRequest, "Invalid request", "Некорректный запрос"),
+	AccessDenied:          def(http.StatusForbidden, "Access denied", "Доступ запрещён"),
+	InternalError:         def(http.StatusInternalServerError, "Internal server error", "Внутренняя ошибка сервера"),
+	MissingAuthorization:  def(http.StatusUnauthorized, "Missing Authorization header", "Отсутствует заголовок Authorization"),
+	InvalidAuthorization:  def(http.StatusUnauthorized, "Invalid Authorization header", "Некорректный заголовок Authorization"),
+	InvalidToken:          def(http.StatusUnauthorized, "Invalid token", "Недействительный токен"),
+	InsufficientScope:     def(http.StatusForbidden, "Insufficient scope", "Недостаточно прав у API-ключа"),
+	TwoFactorRequired:     def(http.StatusForbidden, "Two-factor authentication required", "Требуется двухфакторная аутентификация"),
+	ImpersonationReadOnly: def(http.StatusForbidden, "Impersonation sessions are read-only", "Сессии имперсонации доступны только для чтения"),
+
+	InvalidCredentials:   def(http.StatusUnauthorized, "Invalid credentials", "Неверный email или пароль"),
+	TooManyLoginAttempts: def(http.StatusTooManyRequests, "Too many login attempts", "Слишком много попыток входа"),
+	AccountPending:       def(http.StatusForbidden, "Account pending approval", "Учётная запись ожидает подтверждения"),
+	AccountDeactivated:   def(http.StatusForbidden, "Account deactivated", "Учётная запись деактивирована"),
+	InviteRequired:       def(http.StatusForbidden, "Invite required", "Требуется приглашение"),
+	InvalidInvite:        def(http.StatusForbidden, "Invalid invite", "Недействительное приглашение"),
+	InvalidRole:          def(http.StatusBadRequest, "Invalid role", "Недопустимая роль"),
+	EmailExists:          def(http.StatusBadRequest, "Email already exists", "Пользователь с таким email уже существует"),
+
+	InvalidCity:           def(http.StatusBadRequest, "City not allowed", "Город не поддерживается"),
+	InvalidProductType:    def(http.StatusBadRequest, "Invalid product type", "Недопустимый тип товара"),
+	ActiveReceptionExists: def(http.StatusBadRequest, "Active reception exists", "Уже есть незакрытая приёмка"),
+	NoActiveReception:     def(http.StatusBadRequest, "No active reception", "Нет незакрытой приёмки"),
+	NoProducts:            def(http.StatusBadRequest, "No products in reception", "В приёмке нет товаров"),
+	PVZNotFound:           def(http.StatusNotFound, "PVZ not found", "ПВЗ не найден"),
+
+	UserNotFound:      def(http.StatusNotFound, "User not found", "Пользователь не найден"),
+	UserNotPending:    def(http.StatusBadRequest, "User is not pending approval", "Пользователь не ожидает подтверждения"),
+	CannotModifySelf:  def(http.StatusBadRequest, "Cannot modify own account", "Нельзя изменить собственную учётную запись"),
+	InvalidUserStatus: def(http.StatusBadRequest, "Invalid user status", "Недопустимый статус пользователя"),
+
+	TwoFactorNotEnrolled:  def(http.StatusBadRequest, "Two-factor enrollment not started", "Подключение двухфакторной аутентификации не начато"),
+	TwoFactorEnabled:      def(http.StatusBadRequest, "Two-factor authentication already enabled", "Двухфакторная аутентификация уже включена"),
+	InvalidTwoFactorCode:  def(http.StatusBadRequest, "Invalid two-factor code", "Неверный код подтверждения"),
+	InvalidTwoFactorToken: def(http.StatusUnauthorized, "Invalid two-factor token", "Недействительный токен двухфакторной аутентификации"),
+	InvalidResetToken:     def(http.StatusBadRequest, "Invalid or expired reset token", "Недействительный или просроченный токен сброса пароля"),
+	InvalidPassword:       def(http.StatusBadRequest, "Invalid password", "Недопустимый пароль"),
+
+	APIKeyNotFound:    def(http.StatusNotFound, "API key not found", "API-ключ не найден"),
+	InvalidAPIKey:     def(http.StatusUnauthorized, "Invalid API key", "Недействительный API-ключ"),
+	InvalidScope:      def(http.StatusBadRequest, "Invalid scopes", "Недопустимые права API-ключа"),
+	InvalidExpiration: def(http.StatusBadRequest, "Invalid expiration", "Недопустимый срок действия"),
+	InvalidSignature:  def(http.StatusUnauthorized, "Invalid signature", "Неверная подпись запроса"),
+	ReplayedRequest:   def(http.StatusUnauthorized, "Replayed request", "Повторно отправленный запрос"),
+
+	IdempotencyKeyReused:  def(http.StatusUnprocessableEntity, "Idempotency key was used for a different request", "Ключ идемпотентности уже использован для другого запроса"),
+	IdempotencyInProgress: def(http.StatusConflict, "Request with this idempotency key is in progress", "Запрос с этим ключом идемпотентности ещё выполняется"),
+	PreconditionFailed:    def(http.StatusPreconditionFailed, "Resource was modified", "Ресурс был изменён"),
+	VersionConflict:       def(http.StatusConflict, "Resource was modified concurrently", "Ресурс был изменён параллельно"),
+	NotAcceptable:         def(http.StatusNotAcceptable, "None of the accepted formats is supported", "Ни один из принимаемых форматов не поддерживается"),
+	UnsupportedMediaType:  def(http.StatusUnsupportedMediaType, "Request format is not supported", "Формат запроса не поддерживается"),
+
+	WebhookNotFound:   def(http.StatusNotFound, "Webhook not found", "Подписка на события не найдена"),
+	DeliveryNotFound:  def(http.StatusNotFound, "Webhook delivery not found", "Доставка события не найдена"),
+	InvalidWebhookURL: def(http.StatusBadRequest, "Invalid webhook URL", "Некорректный адрес подписки"),
+	InvalidEventType:  def(http.StatusBadRequest, "Invalid event type", "Недопустимый тип события"),
+
+	AlreadyExists:       def(http.StatusConflict, "Resource already exists", "Ресурс уже существует"),
+	ReferenceNotFound:   def(http.StatusNotFound, "Referenced resource not found", "Связанный ресурс не найден"),
+	ConstraintViolation: def(http.StatusBadRequest, "Constraint violation", "Нарушено ограничение данных"),
+	InvalidID:           def(http.StatusBadRequest, "Invalid identifier", "Некорректный идентификатор"),
+}
@@ -0,0 +1,179 @@
+// Package problem is the error model of the HTTP API. Every error response is
+// an RFC 9457 application/problem+json document with a stable code, the HTTP
+// status and a title in the language the client asked for.
+package problem
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	ContentType = "application/problem+json"
+	typePrefix  = "urn:pvz:problem:"
+)
+
+// Error is an error with the problem it is reported as. Details are rendered
+// as extension members of the document.
+type Error struct {
+	Code    Code
+	Status  int
+	Details map[string]interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Code, e.Err)
+	}
+	return string(e.Code)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns the problem of code with its default status.
+func New(code Code) *Error {
+	return &Error{Code: code, Status: catalogue[code].status}
+}
+
+// Wrap returns the problem of code caused by err.
+func Wrap(code Code, err error) *Error {
+	e := New(code)
+	e.Err = err
+	return e
+}
+
+// WithDetail adds an extension member to the problem.
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+// sentinels maps service errors to problems. Specific errors come before the
+// translated database errors they may wrap.
+var sentinels = []struct {
+	err  error
+	code Code
+}{
+	{internalErrors.ErrAccessDenied, AccessDenied},
+	{internalErrors.ErrInvalidCredentials, InvalidCredentials},
+	{internalErrors.ErrTooManyLoginAttempts, TooManyLoginAttempts},
+	{internalErrors.ErrAccountPending, AccountPending},
+	{internalErrors.ErrAccountDeactivated, AccountDeactivated},
+	{internalErrors.ErrTokenRevoked, InvalidToken},
+	{internalErrors.ErrInviteRequired, InviteRequired},
+	{internalErrors.ErrInvalidInvite, InvalidInvite},
+	{internalErrors.ErrInvalidRole, InvalidRole},
+	{internalErrors.ErrEmailExists, EmailExists},
+	{internalErrors.ErrInvalidCity, InvalidCity},
+	{internalErrors.ErrInvalidProductType, InvalidProductType},
+	{internalErrors.ErrActiveReceptionExists, ActiveReceptionExists},
+	{internalErrors.ErrNoActiveReception, NoActiveReception},
+	{internalErrors.ErrProductNotFound, NoProducts},
+	{internalErrors.ErrPVZNotFound, PVZNotFound},
+	{internalErrors.ErrUserNotFound, UserNotFound},
+	{internalErrors.ErrUserNotPending, UserNotPending},
+	{internalErrors.ErrCannotModifySelf, CannotModifySelf},
+	{internalErrors.ErrInvalidUserStatus, InvalidUserStatus},
+	{internalErrors.ErrTwoFactorNotEnrolled, TwoFactorNotEnrolled},
+	{internalErrors.ErrTwoFactorEnabled, TwoFactorEnabled},
+	{internalErrors.ErrInvalidTwoFactorCode, InvalidTwoFactorCode},
+	{internalErrors.ErrInvalidTwoFactorToken, InvalidTwoFactorToken},
+	{internalErrors.ErrInvalidResetToken, InvalidResetToken},
+	{internalErrors.ErrInvalidPassword, InvalidPassword},
+	{internalErrors.ErrAPIKeyNotFound, APIKeyNotFound},
+	{internalErrors.ErrInvalidAPIKey, InvalidAPIKey},
+	{internalErrors.ErrInvalidScope, InvalidScope},
+	{internalErrors.ErrInvalidExpiration, InvalidExpiration},
+	{internalErrors.ErrInvalidSignature, InvalidSignature},
+	{internalErrors.ErrReplayedRequest, ReplayedRequest},
+	{internalErrors.ErrIdempotencyKeyReused, IdempotencyKeyReused},
+	{internalErrors.ErrIdempotencyInProgress, IdempotencyInProgress},
+	{internalErrors.ErrPreconditionFailed, PreconditionFailed},
+	{internalErrors.ErrVersionConflict, VersionConflict},
+	{internalErrors.ErrNotAcceptable, NotAcceptable},
+	{internalErrors.ErrUnsupportedMediaType, UnsupportedMediaType},
+	{internalErrors.ErrWebhookNotFound, WebhookNotFound},
+	{internalErrors.ErrDeliveryNotFound, DeliveryNotFound},
+	{internalErrors.ErrInvalidWebhookURL, InvalidWebhookURL},
+	{internalErrors.ErrInvalidEventType, InvalidEventType},
+	{internalErrors.ErrAlreadyExists, AlreadyExists},
+	{internalErrors.ErrReferenceNotFound, ReferenceNotFound},
+	{internalErrors.ErrConstraintViolation, ConstraintViolation},
+	{internalErrors.ErrInvalidID, InvalidID},
+}
+
+// From returns the problem err is reported as. Errors that are neither a
+// problem nor a known sentinel become internal_error.
+func From(err error) *Error {
+	var p *Error
+	if errors.As(err, &p) {
+		return p
+	}
+	for _, s := range sentinels {
+		if errors.Is(err, s.err) {
+			return Wrap(s.code, err)
+		}
+	}
+	return Wrap(InternalError, err)
+}
+
+var languages = language.NewMatcher([]language.Tag{language.English, language.Russian})
+
+// Write renders err as a problem document. The title is localized by the
+// Accept-Language header of r; internal errors are logged, as their cause is
+// not shown to the client.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	p := From(err)
+	if p.Code == InternalError && p.Err != nil {
+		log.Printf("%s %s failed: %v", r.Method, r.URL.Path, p.Err)
+	}
+
+	tags, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	_, index, _ := languages.Match(tags...)
+	lang := "en"
+	if index == 1 {
+		lang = "ru"
+	}
+	title := catalogue[p.Code].titles[lang]
+
+	var retryErr *internalErrors.RetryAfterError
+	if errors.As(err, &retryErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryErr.RetryAfter.Seconds()))))
+	}
+
+	doc := make(map[string]interface{}, len(p.Details)+7)
+	for key, value := range p.Details {
+		doc[key] = value
+	}
+	var conflictErr *internalErrors.ConflictError
+	if errors.As(err, &conflictErr) {
+		doc["current"] = conflictErr.Current
+	}
+	doc["type"] = typePrefix + string(p.Code)
+	doc["title"] = title
+	doc["status"] = p.Status
+	doc["code"] = p.Code
+	doc["instance"] = r.URL.Path
+	// message is kept for clients written against the previous error format.
+	doc["message"] = title
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(doc)
+}
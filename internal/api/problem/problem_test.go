@@ -0,0 +1,130 @@
+package problem
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedCode   Code
+		expectedStatus int
+	}{
+		{"Sentinel", internalErrors.ErrUserNotFound, UserNotFound, http.StatusNotFound},
+		{"Wrapped sentinel", fmt.Errorf("approve: %w", internalErrors.ErrNoActiveReception), NoActiveReception, http.StatusBadRequest},
+		{
+			"Specific error before the database error it wraps",
+			fmt.Errorf("%w: %w", internalErrors.ErrPVZNotFound, internalErrors.ErrReferenceNotFound),
+			PVZNotFound, http.StatusNotFound,
+		},
+		{"Translated database error", internalErrors.ErrAlreadyExists, AlreadyExists, http.StatusConflict},
+		{"Problem", New(InvalidRole), InvalidRole, http.StatusBadRequest},
+		{"Unknown error", errors.New("connection reset"), InternalError, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := From(tt.err)
+			assert.Equal(t, tt.expectedCode, p.Code)
+			assert.Equal(t, tt.expectedStatus, p.Status)
+		})
+	}
+}
+
+func TestCatalogueIsComplete(t *testing.T) {
+	for _, s := range sentinels {
+		def, ok := catalogue[s.code]
+		require.True(t, ok, s.code)
+		assert.NotZero(t, def.status, s.code)
+		assert.NotEmpty(t, def.titles["en"], s.code)
+		assert.NotEmpty(t, def.titles["ru"], s.code)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name             string
+		acceptLanguage   string
+		expectedLanguage string
+		expectedTitle    string
+	}{
+		{"Default", "", "en", "PVZ not found"},
+		{"English", "en-US,en;q=0.9", "en", "PVZ not found"},
+		{"Russian", "ru-RU,ru;q=0.9,en;q=0.8", "ru", "ПВЗ не найден"},
+		{"Preferred English", "de, en;q=0.8, ru;q=0.5", "en", "PVZ not found"},
+		{"Unsupported", "de", "en", "PVZ not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/receptions", nil)
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := httptest.NewRecorder()
+
+			Write(w, req, fmt.Errorf("create reception: %w", internalErrors.ErrPVZNotFound))
+
+			require.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedLanguage, w.Header().Get("Content-Language"))
+			assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+
+			var doc map[string]interface{}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&doc))
+			assert.Equal(t, map[string]interface{}{
+				"type":     "urn:pvz:problem:pvz_not_found",
+				"title":    tt.expectedTitle,
+				"status":   float64(http.StatusNotFound),
+				"code":     "pvz_not_found",
+				"instance": "/v1/receptions",
+				"message":  tt.expectedTitle,
+			}, doc)
+		})
+	}
+}
+
+func TestWrite_RetryAfter(t *testing.T) {
+	w := httptest.NewRecorder()
+	Write(w, httptest.NewRequest(http.MethodPost, "/login", nil), &internalErrors.RetryAfterError{
+		Err:        internalErrors.ErrTooManyLoginAttempts,
+		RetryAfter: 1500 * time.Millisecond,
+	})
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+}
+
+func TestWrite_Conflict(t *testing.T) {
+	w := httptest.NewRecorder()
+	Write(w, httptest.NewRequest(http.MethodPatch, "/pvz/pvz-1", nil), &internalErrors.ConflictError{
+		Err:     internalErrors.ErrVersionConflict,
+		Current: map[string]interface{}{"id": "pvz-1", "version": 3},
+	})
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	var doc map[string]interface{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&doc))
+	assert.Equal(t, "version_conflict", doc["code"])
+	assert.Equal(t, map[string]interface{}{"id": "pvz-1", "version": float64(3)}, doc["current"])
+}
+
+func TestWrite_Details(t *testing.T) {
+	w := httptest.NewRecorder()
+	Write(w, httptest.NewRequest(http.MethodGet, "/pvz", nil),
+		New(InvalidRequest).WithDetail("errors", []string{"limit"}).WithDetail("code", "overridden"))
+
+	var doc map[string]interface{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&doc))
+	assert.Equal(t, []interface{}{"limit"}, doc["errors"])
+	assert.Equal(t, "invalid_request", doc["code"])
+}
@@ -0,0 +1,136 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Encode returns v encoded in format. MessagePack encodes the JSON document of
+// v, Protobuf the pvzv1 message of v if it has one and a
+// google.protobuf.Value of the document otherwise.
+func Encode(format string, v interface{}) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case JSON:
+		return append(body, '\n'), nil
+	case CSV:
+		return encodeCSV(body)
+	case Protobuf:
+		return encodeProtobuf(v, body)
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, err
+	}
+	if format != MessagePack {
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	return msgpack.Marshal(compactNumbers(document))
+}
+
+// Unmarshal decodes a MessagePack or Protobuf body into v the way the JSON
+// document it holds would be decoded. A Protobuf body is read as the message
+// of v if it has one.
+func Unmarshal(format string, body []byte, v interface{}) error {
+	if m, ok := messageOf(reflect.TypeOf(v)); ok && format == Protobuf {
+		data, err := decodeMessage(m, body)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, v)
+	}
+
+	document, err := DecodeDocument(format, body)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// DecodeDocument decodes a body into the value encoding/json would give for
+// its JSON document: maps, slices, strings, float64, bools and nil. CSV is
+// returned as the text it is, Protobuf is read as a google.protobuf.Value.
+func DecodeDocument(format string, body []byte) (interface{}, error) {
+	var document interface{}
+	switch format {
+	case JSON:
+		if err := json.Unmarshal(body, &document); err != nil {
+			return nil, err
+		}
+		return document, nil
+	case CSV:
+		return string(body), nil
+	case MessagePack:
+		if err := msgpack.Unmarshal(body, &document); err != nil {
+			return nil, err
+		}
+	case Protobuf:
+		var value structpb.Value
+		if err := proto.Unmarshal(body, &value); err != nil {
+			return nil, err
+		}
+		document = value.AsInterface()
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	// Round trip through JSON to get the types encoding/json uses.
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	err = json.NewDecoder(bytes.NewReader(data)).Decode(&result)
+	return result, err
+}
+
+// DecodeMessage is DecodeDocument for a Protobuf body of the named pvzv1
+// message, such as pvz.v1.PVZ.
+func DecodeMessage(name string, body []byte) (interface{}, error) {
+	m, ok := messageNamed(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown message %q", name)
+	}
+	data, err := decodeMessage(m, body)
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	err = json.Unmarshal(data, &document)
+	return document, err
+}
+
+// compactNumbers turns whole numbers into integers, which MessagePack encodes
+// in fewer bytes than floats.
+func compactNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = compactNumbers(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = compactNumbers(value)
+		}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	}
+	return v
+}
@@ -0,0 +1,104 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// encodeCSV writes a JSON array as CSV with a row per element. Nested objects
+// are flattened into columns named by their path, e.g. reception.id, and
+// arrays are kept as JSON in one cell. Columns are in the order the fields
+// first appear.
+func encodeCSV(body []byte) ([]byte, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, errors.New("only lists can be rendered as CSV")
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	rows := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		row := make(map[string]string)
+		var names []string
+		if err := flatten("", item, row, &names); err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if len(columns) > 0 {
+		writer.Write(columns)
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func flatten(prefix string, value json.RawMessage, row map[string]string, names *[]string) error {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || value[0] != '{' {
+		name := strings.TrimSuffix(prefix, ".")
+		if name == "" {
+			name = "value"
+		}
+		row[name] = cell(value)
+		*names = append(*names, name)
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(value))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var field json.RawMessage
+		if err := dec.Decode(&field); err != nil {
+			return err
+		}
+		if err := flatten(prefix+token.(string)+".", field, row, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cell(value json.RawMessage) string {
+	switch {
+	case string(value) == "null":
+		return ""
+	case value[0] == '"':
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return string(value)
+		}
+		// Spreadsheets run cells starting with these as formulas.
+		if s != "" && strings.ContainsAny(s[:1], "=+-@") {
+			return "'" + s
+		}
+		return s
+	default:
+		return string(value)
+	}
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"avito-intern/internal/api/dto/request/productDto"
+	"avito-intern/internal/api/dto/request/pvzDto"
+	"avito-intern/internal/api/dto/request/receptionDto"
+	"avito-intern/internal/grpc/pvzv1"
+	"avito-intern/internal/models"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// MessageExtension is the OpenAPI schema extension naming the Protobuf
+// message of a document.
+const MessageExtension = "x-protobuf-message"
+
+// message is the pvzv1 message a document is encoded as in Protobuf. The
+// fields of the message have the JSON names of the document, so the two are
+// converted with protojson.
+type message struct {
+	typ protoreflect.MessageType
+	// list documents are the items field of the message.
+	list bool
+}
+
+// messages are the messages of the documents by their Go type. Documents of
+// other types are encoded as a google.protobuf.Value.
+var messages = map[reflect.Type]message{
+	reflect.TypeOf(models.PVZ{}):                          {typ: (&pvzv1.PVZ{}).ProtoReflect().Type()},
+	reflect.TypeOf(pvzDto.UpdatePVZRequest{}):             {typ: (&pvzv1.UpdatePVZRequest{}).ProtoReflect().Type()},
+	reflect.TypeOf([]*models.PVZ{}):                       {typ: (&pvzv1.PVZList{}).ProtoReflect().Type(), list: true},
+	reflect.TypeOf(models.Reception{}):                    {typ: (&pvzv1.Reception{}).ProtoReflect().Type()},
+	reflect.TypeOf([]*models.ReceptionWithProducts{}):     {typ: (&pvzv1.ReceptionWithProductsList{}).ProtoReflect().Type(), list: true},
+	reflect.TypeOf(models.Product{}):                      {typ: (&pvzv1.Product{}).ProtoReflect().Type()},
+	reflect.TypeOf(receptionDto.CreateReceptionRequest{}): {typ: (&pvzv1.CreateReceptionRequest{}).ProtoReflect().Type()},
+	reflect.TypeOf(productDto.CreateProductRequest{}):     {typ: (&pvzv1.AddProductRequest{}).ProtoReflect().Type()},
+}
+
+// messageOf returns the message of documents of type t or of what t points
+// to.
+func messageOf(t reflect.Type) (message, bool) {
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	m, ok := messages[t]
+	return m, ok
+}
+
+// messageNamed returns the message with the full name, such as pvz.v1.PVZ.
+func messageNamed(name string) (message, bool) {
+	for _, m := range messages {
+		if string(m.typ.Descriptor().FullName()) == name {
+			return m, true
+		}
+	}
+	return message{}, false
+}
+
+// encodeProtobuf encodes the JSON body of v as its message, or as a
+// google.protobuf.Value if it has none.
+func encodeProtobuf(v interface{}, body []byte) ([]byte, error) {
+	m, ok := messageOf(reflect.TypeOf(v))
+	if !ok {
+		var document interface{}
+		if err := json.Unmarshal(body, &document); err != nil {
+			return nil, err
+		}
+		value, err := structpb.NewValue(document)
+		if err != nil {
+			return nil, err
+		}
+		return proto.Marshal(value)
+	}
+
+	if m.list {
+		body, _ = json.Marshal(map[string]json.RawMessage{"items": body})
+	}
+	msg := m.typ.New().Interface()
+	if err := protojson.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("encode %s: %w", m.typ.Descriptor().FullName(), err)
+	}
+	return proto.Marshal(msg)
+}
+
+// decodeMessage decodes a body of message m into its JSON document. Unset
+// strings, numbers and lists are given as their zero values, unset messages
+// and optional fields such as version are left out.
+func decodeMessage(m message, body []byte) ([]byte, error) {
+	msg := m.typ.New().Interface()
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, err
+	}
+	data, err := protojson.MarshalOptions{EmitDefaultValues: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	if !m.list {
+		return data, nil
+	}
+	var items struct {
+		Items json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	return items.Items, nil
+}
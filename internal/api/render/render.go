@@ -0,0 +1,192 @@
+// Package render writes response bodies in the format the client asked for
+// in Accept and reads request bodies in the format given by Content-Type.
+//
+// JSON is the canonical representation: MessagePack bodies hold the same
+// document, Protobuf bodies the pvzv1 message with the fields of the document
+// or, for documents without one, a google.protobuf.Value of it, and CSV holds
+// a list flattened into rows, so that every format carries exactly what the
+// JSON body would.
+package render
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/problem"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	JSON        = "application/json"
+	MessagePack = "application/msgpack"
+	Protobuf    = "application/x-protobuf"
+	CSV         = "text/csv"
+	// EventStream is the format of the live event streams, which write
+	// their events themselves.
+	EventStream = "text/event-stream"
+
+	maxBody = 1 << 20
+)
+
+// aliases are other media types clients use for the supported formats. They
+// are understood in Accept; request bodies use the types above.
+var aliases = map[string]string{
+	"application/x-msgpack":           MessagePack,
+	"application/vnd.msgpack":         MessagePack,
+	"application/protobuf":            Protobuf,
+	"application/vnd.google.protobuf": Protobuf,
+}
+
+// Write encodes v in the format negotiated from the request's Accept header,
+// which may be JSON, MessagePack or Protobuf. If none of them is acceptable,
+// it writes a 406 problem instead.
+func Write(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	write(w, r, status, v, Negotiate(r, false))
+}
+
+// List is Write for list endpoints, which can also be rendered as CSV with
+// a row per element of v.
+func List(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	write(w, r, status, v, Negotiate(r, true))
+}
+
+func write(w http.ResponseWriter, r *http.Request, status int, v interface{}, format string) {
+	w.Header().Add("Vary", "Accept")
+	if format == "" {
+		problem.Write(w, r, internalErrors.ErrNotAcceptable)
+		return
+	}
+	body, err := Encode(format, v)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	contentType := format
+	if format == CSV {
+		contentType += "; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// Decode reads the request body into v according to its Content-Type. A
+// request without Content-Type is read as JSON. Bodies that cannot be decoded
+// give an invalid_request problem, unsupported formats give
+// ErrUnsupportedMediaType.
+func Decode(r *http.Request, v interface{}) error {
+	format := JSON
+	if header := r.Header.Get("Content-Type"); header != "" {
+		format = mediaType(header)
+	}
+	switch format {
+	case JSON:
+		if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+			return problem.Wrap(problem.InvalidRequest, err)
+		}
+		return nil
+	case MessagePack, Protobuf:
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBody))
+		if err != nil {
+			return problem.Wrap(problem.InvalidRequest, err)
+		}
+		if err := Unmarshal(format, body, v); err != nil {
+			return problem.Wrap(problem.InvalidRequest, err)
+		}
+		return nil
+	default:
+		return internalErrors.ErrUnsupportedMediaType
+	}
+}
+
+// Acceptable reports whether the request accepts at least one format of the
+// API, so that a request that cannot be answered is rejected before it is
+// served. EventStream counts, since EventSource asks only for it.
+func Acceptable(r *http.Request) bool {
+	return Negotiate(r, true) != "" || quality(parseAccept(r.Header.Get("Accept")), EventStream) > 0
+}
+
+// Negotiate returns the format the request's Accept header prefers, JSON if it
+// has none, and "" if no supported format is acceptable. Of formats accepted
+// with the same quality the server prefers JSON, then MessagePack, Protobuf
+// and CSV.
+func Negotiate(r *http.Request, allowCSV bool) string {
+	header := r.Header.Get("Accept")
+	if strings.TrimSpace(header) == "" {
+		return JSON
+	}
+	offers := []string{JSON, MessagePack, Protobuf}
+	if allowCSV {
+		offers = append(offers, CSV)
+	}
+
+	ranges := parseAccept(header)
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		if q := quality(ranges, offer); q > bestQuality {
+			best, bestQuality = offer, q
+		}
+	}
+	return best
+}
+
+type mediaRange struct {
+	typ     string
+	quality float64
+}
+
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		typ, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if alias, ok := aliases[typ]; ok {
+			typ = alias
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ, quality: q})
+	}
+	return ranges
+}
+
+// quality returns the quality of offer given by the most specific range that
+// matches it.
+func quality(ranges []mediaRange, offer string) float64 {
+	mainType := strings.SplitN(offer, "/", 2)[0]
+	q, specificity := 0.0, -1
+	for _, rng := range ranges {
+		var s int
+		switch rng.typ {
+		case offer:
+			s = 2
+		case mainType + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = rng.quality, s
+		}
+	}
+	return q
+}
+
+func mediaType(header string) string {
+	typ, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return header
+	}
+	return typ
+}
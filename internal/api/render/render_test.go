@@ -0,0 +1,223 @@
+package render
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/productDto"
+	"avito-intern/internal/grpc/pvzv1"
+	"avito-intern/internal/models"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		allowCSV bool
+		expected string
+	}{
+		{name: "No header", accept: "", expected: JSON},
+		{name: "Any type", accept: "*/*", expected: JSON},
+		{name: "MessagePack", accept: "application/msgpack", expected: MessagePack},
+		{name: "MessagePack alias", accept: "application/vnd.msgpack", expected: MessagePack},
+		{name: "Protobuf alias", accept: "application/protobuf", expected: Protobuf},
+		{name: "Quality order", accept: "application/json;q=0.4, application/x-protobuf;q=0.9", expected: Protobuf},
+		{name: "Specific range wins", accept: "application/*;q=0.1, application/msgpack", expected: MessagePack},
+		{name: "Excluded type", accept: "application/json;q=0, */*", expected: MessagePack},
+		{name: "CSV on a list", accept: "text/csv", allowCSV: true, expected: CSV},
+		{name: "CSV elsewhere", accept: "text/csv", allowCSV: false, expected: ""},
+		{name: "Text range", accept: "text/*", allowCSV: true, expected: CSV},
+		{name: "Unsupported type", accept: "text/html", allowCSV: true, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/pvz", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			assert.Equal(t, tt.expected, Negotiate(req, tt.allowCSV))
+		})
+	}
+}
+
+func TestAcceptable(t *testing.T) {
+	for accept, expected := range map[string]bool{
+		"":                  true,
+		"application/json":  true,
+		"text/event-stream": true,
+		"text/html":         false,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/pvz", nil)
+		req.Header.Set("Accept", accept)
+		assert.Equal(t, expected, Acceptable(req), accept)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	pvz := models.PVZ{ID: "pvz-1", City: "Москва", Version: 3}
+
+	for _, format := range []string{JSON, MessagePack, Protobuf} {
+		t.Run(format, func(t *testing.T) {
+			body, err := Encode(format, pvz)
+			require.NoError(t, err)
+
+			var decoded models.PVZ
+			require.NoError(t, Unmarshal(format, body, &decoded))
+			assert.Equal(t, pvz.ID, decoded.ID)
+			assert.Equal(t, pvz.City, decoded.City)
+			assert.Equal(t, pvz.Version, decoded.Version)
+		})
+	}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	items := []map[string]interface{}{
+		{
+			"reception": map[string]interface{}{"id": "r1", "status": "close"},
+			"products":  []string{"p1", "p2"},
+		},
+		{
+			"reception": map[string]interface{}{"id": "r2", "status": nil},
+			"note":      "=HYPERLINK(\"x\")",
+		},
+	}
+
+	body, err := Encode(CSV, items)
+	require.NoError(t, err)
+	assert.Equal(t, "products,reception.id,reception.status,note\n"+
+		`"[""p1"",""p2""]",r1,close,`+"\n"+
+		`,r2,,"'=HYPERLINK(""x"")"`+"\n", string(body))
+
+	_, err = Encode(CSV, models.PVZ{ID: "pvz-1"})
+	assert.Error(t, err, "only lists have rows")
+}
+
+func TestWrite(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/pvz", nil)
+	req.Header.Set("Accept", "text/csv")
+	rr := httptest.NewRecorder()
+
+	List(rr, req, http.StatusOK, []models.PVZ{{ID: "pvz-1", City: "Казань"}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", rr.Header().Get("Vary"))
+	assert.Contains(t, rr.Body.String(), "pvz-1")
+
+	rr = httptest.NewRecorder()
+	Write(rr, req, http.StatusOK, models.PVZ{ID: "pvz-1"})
+	assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+}
+
+func TestDecode(t *testing.T) {
+	msgpackBody, err := Encode(MessagePack, map[string]string{"city": "Москва"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		invalid     bool
+		expectedErr error
+	}{
+		{name: "JSON", contentType: "application/json; charset=utf-8", body: []byte(`{"city":"Москва"}`)},
+		{name: "No Content-Type", body: []byte(`{"city":"Москва"}`)},
+		{name: "MessagePack", contentType: MessagePack, body: msgpackBody},
+		{name: "Malformed JSON", contentType: JSON, body: []byte(`{"city":`), invalid: true},
+		{name: "Unsupported format", contentType: "text/plain", body: []byte("Москва"), expectedErr: internalErrors.ErrUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/pvz", bytes.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var v struct {
+				City string `json:"city"`
+			}
+			err := Decode(req, &v)
+			switch {
+			case tt.expectedErr != nil:
+				assert.ErrorIs(t, err, tt.expectedErr)
+			case tt.invalid:
+				assert.Error(t, err)
+				assert.False(t, errors.Is(err, internalErrors.ErrUnsupportedMediaType))
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, "Москва", v.City)
+			}
+		})
+	}
+}
+
+func TestDecodeDocument(t *testing.T) {
+	body, err := Encode(Protobuf, map[string]interface{}{"count": 2, "items": []string{"a"}})
+	require.NoError(t, err)
+
+	document, err := DecodeDocument(Protobuf, body)
+	require.NoError(t, err)
+	expected := map[string]interface{}{"count": float64(2), "items": []interface{}{"a"}}
+	assert.Equal(t, expected, document)
+
+	data, err := json.Marshal(document)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"count":2,"items":["a"]}`, string(data))
+}
+
+func TestEncodeProtobufMessages(t *testing.T) {
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+	pvz := &models.PVZ{ID: "pvz-1", RegistrationDate: now, City: "Москва", Version: 3}
+
+	body, err := Encode(Protobuf, pvz)
+	require.NoError(t, err)
+	var message pvzv1.PVZ
+	require.NoError(t, proto.Unmarshal(body, &message))
+	assert.Equal(t, "pvz-1", message.GetId())
+	assert.Equal(t, now, message.GetRegistrationDate().AsTime())
+	assert.Equal(t, "Москва", message.GetCity())
+	assert.Equal(t, int32(3), message.GetVersion())
+
+	receptions := []*models.ReceptionWithProducts{{
+		Reception: &models.Reception{ID: "reception-1", DateTime: now, PvzID: "pvz-1", Status: "in_progress", Version: 1},
+	}}
+	body, err = Encode(Protobuf, receptions)
+	require.NoError(t, err)
+	var list pvzv1.ReceptionWithProductsList
+	require.NoError(t, proto.Unmarshal(body, &list))
+	require.Len(t, list.GetItems(), 1)
+	assert.Equal(t, "reception-1", list.GetItems()[0].GetReception().GetId())
+
+	document, err := DecodeMessage("pvz.v1.ReceptionWithProductsList", body)
+	require.NoError(t, err)
+	data, err := json.Marshal(document)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"reception":{"id":"reception-1","dateTime":"2025-04-01T10:00:00Z","pvzId":"pvz-1","status":"in_progress","version":1},"products":[]}]`, string(data))
+}
+
+func TestUnmarshalProtobufMessage(t *testing.T) {
+	body, err := proto.Marshal(&pvzv1.AddProductRequest{PvzId: "pvz-1", Type: "обувь"})
+	require.NoError(t, err)
+
+	var req productDto.CreateProductRequest
+	require.NoError(t, Unmarshal(Protobuf, body, &req))
+	assert.Equal(t, productDto.CreateProductRequest{PvzID: "pvz-1", Type: "обувь"}, req)
+
+	// A request without the optional version leaves it out of the document.
+	body, err = proto.Marshal(&pvzv1.PVZ{City: "Казань"})
+	require.NoError(t, err)
+	document, err := DecodeMessage("pvz.v1.PVZ", body)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": "", "city": "Казань"}, document)
+}
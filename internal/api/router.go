@@ -1,50 +1,244 @@
 package api
 
 import (
+	"avito-intern/internal/api/handlers/apiKeys/createApiKey"
+	"avito-intern/internal/api/handlers/apiKeys/listApiKeys"
+	"avito-intern/internal/api/handlers/apiKeys/revokeApiKey"
+	"avito-intern/internal/api/handlers/audit/listAuditEvents"
 	"avito-intern/internal/api/handlers/auth/dummyLogin"
 	"avito-intern/internal/api/handlers/auth/login"
+	"avito-intern/internal/api/handlers/auth/loginTwoFactor"
 	"avito-intern/internal/api/handlers/auth/register"
+	"avito-intern/internal/api/handlers/invite/createInvite"
+	"avito-intern/internal/api/handlers/password/confirmPasswordReset"
+	"avito-intern/internal/api/handlers/password/forgotPassword"
 	"avito-intern/internal/api/handlers/product/createProduct"
 	"avito-intern/internal/api/handlers/pvz/closeReception"
 	"avito-intern/internal/api/handlers/pvz/createPvz"
 	"avito-intern/internal/api/handlers/pvz/deleteLastProduct"
+	"avito-intern/internal/api/handlers/pvz/getPvz"
 	"avito-intern/internal/api/handlers/pvz/listPvz"
+	"avito-intern/internal/api/handlers/pvz/listPvzReceptions"
+	"avito-intern/internal/api/handlers/pvz/streamPvzEvents"
+	"avito-intern/internal/api/handlers/pvz/updatePvz"
 	"avito-intern/internal/api/handlers/reception/createReception"
+	"avito-intern/internal/api/handlers/scim/createScimUser"
+	"avito-intern/internal/api/handlers/scim/deleteScimUser"
+	"avito-intern/internal/api/handlers/scim/getScimGroup"
+	"avito-intern/internal/api/handlers/scim/getScimUser"
+	"avito-intern/internal/api/handlers/scim/listScimGroups"
+	"avito-intern/internal/api/handlers/scim/listScimUsers"
+	"avito-intern/internal/api/handlers/scim/patchScimGroup"
+	"avito-intern/internal/api/handlers/scim/patchScimUser"
+	"avito-intern/internal/api/handlers/twoFactor/confirmTwoFactor"
+	"avito-intern/internal/api/handlers/twoFactor/enrollTwoFactor"
+	"avito-intern/internal/api/handlers/users/approveUser"
+	"avito-intern/internal/api/handlers/users/changeUserRole"
+	"avito-intern/internal/api/handlers/users/deactivateUser"
+	"avito-intern/internal/api/handlers/users/eraseUser"
+	"avito-intern/internal/api/handlers/users/exportUserData"
+	"avito-intern/internal/api/handlers/users/impersonateUser"
+	"avito-intern/internal/api/handlers/users/listUsers"
+	"avito-intern/internal/api/handlers/users/reactivateUser"
+	"avito-intern/internal/api/handlers/users/resetPassword"
+	"avito-intern/internal/api/handlers/users/unlockUser"
+	"avito-intern/internal/api/handlers/webhooks/createWebhook"
+	"avito-intern/internal/api/handlers/webhooks/deleteWebhook"
+	"avito-intern/internal/api/handlers/webhooks/listWebhookDeliveries"
+	"avito-intern/internal/api/handlers/webhooks/listWebhooks"
+	"avito-intern/internal/api/handlers/webhooks/resendWebhookDelivery"
 	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/api/openapi"
+	graphqlserver "avito-intern/internal/graphql/server"
 	"avito-intern/internal/services"
+	"avito-intern/internal/stream"
+	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func SetupRouter(
-	authService *services.AuthService,
-	pvzService *services.PVZService,
-	receptionService *services.ReceptionService,
-	productService *services.ProductService,
-) *chi.Mux {
+// defaultStreamTokenTTL is the validity of stream tokens when
+// Config.StreamTokenTTL is zero.
+const defaultStreamTokenTTL = time.Minute
+
+// LegacyRoutes describes the deprecation of the unversioned aliases of /v1
+// at the root. A zero DeprecatedAt or Sunset leaves its header out.
+type LegacyRoutes struct {
+	DeprecatedAt time.Time
+	Sunset       time.Time
+}
+
+// Config holds the services and settings the router is built from. The
+// routes of optional services are left out when they are nil: OIDC,
+// Idempotency, Webhooks, SCIM and Events.
+type Config struct {
+	Auth          *services.AuthService
+	PVZ           *services.PVZService
+	Receptions    *services.ReceptionService
+	Products      *services.ProductService
+	Users         *services.UserService
+	LoginAttempts *services.LoginAttemptService
+	TwoFactor     *services.TwoFactorService
+	OIDC          *services.OIDCService
+	APIKeys       *services.APIKeyService
+	Impersonation *services.ImpersonationService
+	Privacy       *services.PrivacyService
+	Idempotency   *services.IdempotencyService
+	Webhooks      *services.WebhookService
+	SCIM          *services.SCIMService
+	Events        *stream.Hub
+
+	// StreamTokenTTL is how long the stream tokens browsers open the event
+	// streams with are valid, a minute if zero. StreamOrigins are the
+	// origins of pages besides the API's own that may open the WebSocket.
+	StreamTokenTTL time.Duration
+	StreamOrigins  []string
+
+	// SCIMToken is the shared token the identity provider calls SCIM with.
+	SCIMToken string
+	// DummyLogin mounts /dummyLogin, which hands out tokens for any role.
+	// Only for development.
+	DummyLogin bool
+	GraphQL    graphqlserver.Limits
+	Legacy     LegacyRoutes
+
+	// Middleware wraps every request before it is validated against the
+	// API specification.
+	Middleware []func(http.Handler) http.Handler
+	// ValidateResponses checks responses against the API specification too
+	// and answers 500 when they do not match. Meant for tests.
+	ValidateResponses bool
+}
+
+func SetupRouter(config Config) *chi.Mux {
 	router := chi.NewRouter()
 	router.Use(chimw.Logger)
 	router.Use(chimw.Recoverer)
 	router.Use(chimw.URLFormat)
 	router.Use(middleware.MetricsMiddleware)
+	// Middleware of embedding applications runs before the request is
+	// validated, so it may also answer requests the API does not describe.
+	router.Use(config.Middleware...)
+	router.Use(chimw.SetHeader("Content-Type", "application/json"))
+	router.Use(middleware.OpenAPIValidationMiddleware(openapi.MustLoad(), config.ValidateResponses, "/v1"))
+
+	router.Method(http.MethodGet, "/metrics", promhttp.Handler())
+	// URLFormat strips the extension before routing, so this serves /openapi.json.
+	router.Get("/openapi", openapi.Handler())
+
+	var external middleware.ExternalAuthenticator
+	if config.OIDC != nil {
+		external = config.OIDC
+	}
+	auth := authStack{
+		authenticate:     middleware.NewAuthMiddleware(external, config.APIKeys),
+		session:          middleware.SessionMiddleware(config.Auth),
+		impersonation:    middleware.ImpersonationMiddleware(config.Impersonation),
+		requireTwoFactor: middleware.RequireTwoFactor(config.TwoFactor),
+		idempotent:       func(next http.Handler) http.Handler { return next },
+	}
+	if config.Idempotency != nil {
+		auth.idempotent = middleware.IdempotencyMiddleware(config.Idempotency)
+	}
 
-	router.Handle("/metrics", promhttp.Handler())
+	v1 := apiRoutes{
+		register:         register.New(config.Auth),
+		login:            login.New(config.Auth),
+		loginTwoFactor:   loginTwoFactor.New(config.TwoFactor),
+		forgotPassword:   forgotPassword.New(config.Auth),
+		resetPassword:    confirmPasswordReset.New(config.Auth),
+		enrollTwoFactor:  enrollTwoFactor.New(config.TwoFactor),
+		confirmTwoFactor: confirmTwoFactor.New(config.TwoFactor),
 
-	router.Post("/register", register.New(authService))
-	router.Post("/login", login.New(authService))
-	router.Post("/dummyLogin", dummyLogin.New())
+		createPVZ:         createPvz.New(config.PVZ),
+		listPVZ:           listPvz.New(config.PVZ),
+		getPVZ:            getPvz.New(config.PVZ),
+		updatePVZ:         updatePvz.New(config.PVZ),
+		listPVZReceptions: listPvzReceptions.New(config.PVZ, config.Products),
+		createReception:   createReception.New(config.Receptions),
+		closeReception:    closeReception.New(config.Receptions),
+		deleteLastProduct: deleteLastProduct.New(config.Products),
+		createProduct:     createProduct.New(config.Products),
+		graphQL:           graphqlserver.New(config.PVZ, config.Receptions, config.Products, config.Users, config.GraphQL),
 
+		createInvite:      createInvite.New(config.Auth),
+		approveUser:       approveUser.New(config.Auth),
+		listUsers:         listUsers.New(config.Users),
+		changeUserRole:    changeUserRole.New(config.Users),
+		deactivateUser:    deactivateUser.New(config.Users),
+		reactivateUser:    reactivateUser.New(config.Users),
+		resetUserPassword: resetPassword.New(config.Users),
+		unlockUser:        unlockUser.New(config.LoginAttempts),
+		exportUserData:    exportUserData.New(config.Privacy),
+		eraseUser:         eraseUser.New(config.Privacy),
+		impersonateUser:   impersonateUser.New(config.Impersonation),
+		listAuditEvents:   listAuditEvents.New(config.Impersonation),
+		createAPIKey:      createApiKey.New(config.APIKeys),
+		listAPIKeys:       listApiKeys.New(config.APIKeys),
+		revokeAPIKey:      revokeApiKey.New(config.APIKeys),
+	}
+	if config.DummyLogin {
+		v1.dummyLogin = dummyLogin.New()
+	}
+	if config.Events != nil {
+		v1.streamPVZEvents = streamPvzEvents.New(config.PVZ, config.Events)
+		v1.streamPVZEventsWebSocket = streamPvzEvents.NewWebSocket(config.PVZ, config.Events, config.StreamOrigins)
+		ttl := config.StreamTokenTTL
+		if ttl == 0 {
+			ttl = defaultStreamTokenTTL
+		}
+		v1.createStreamToken = streamPvzEvents.NewToken(config.PVZ, ttl)
+	}
+	if config.Webhooks != nil {
+		v1.createWebhook = createWebhook.New(config.Webhooks)
+		v1.listWebhooks = listWebhooks.New(config.Webhooks)
+		v1.deleteWebhook = deleteWebhook.New(config.Webhooks)
+		v1.listWebhookDeliveries = listWebhookDeliveries.New(config.Webhooks)
+		v1.resendWebhookDelivery = resendWebhookDelivery.New(config.Webhooks)
+	}
+
+	// A new version starts as a copy of the previous one with the handlers
+	// whose contract changes replaced, e.g. v2 := v1; v2.listPVZ = ..., and
+	// is added here. Its changed operations then need their own OpenAPI
+	// document, since the validation middleware checks them against v1.
+	versions := []apiVersion{
+		{name: "v1", routes: v1},
+	}
+	for _, version := range versions {
+		router.Route("/"+version.name, func(r chi.Router) {
+			r.Use(middleware.APIVersionMiddleware(version.name))
+			r.Use(middleware.NegotiationMiddleware)
+			version.routes.mount(r, auth)
+		})
+	}
+
+	// The root paths predate versioning and stay as aliases of /v1 until
+	// Legacy.Sunset.
 	router.Group(func(r chi.Router) {
-		r.Use(middleware.AuthMiddleware)
-		r.Post("/pvz", createPvz.New(pvzService))
-		r.Get("/pvz", listPvz.New(pvzService))
-		r.Post("/receptions", createReception.New(receptionService))
-		r.Post("/pvz/{pvzId}/close_last_reception", closeReception.New(receptionService))
-		r.Post("/pvz/{pvzId}/delete_last_product", deleteLastProduct.New(productService))
-		r.Post("/products", createProduct.New(productService))
+		r.Use(middleware.APIVersionMiddleware(middleware.UnversionedAPI))
+		r.Use(middleware.DeprecationMiddleware(config.Legacy.DeprecatedAt, config.Legacy.Sunset, "/v1"))
+		r.Use(middleware.NegotiationMiddleware)
+		v1.mount(r, auth)
 	})
 
+	// SCIM is called by the identity provider with its own shared token, not
+	// with user tokens.
+	if config.SCIM != nil {
+		router.Route("/scim/v2", func(r chi.Router) {
+			r.Use(middleware.StaticTokenMiddleware(config.SCIMToken))
+			r.Get("/Users", listScimUsers.New(config.SCIM))
+			r.Post("/Users", createScimUser.New(config.SCIM))
+			r.Get("/Users/{id}", getScimUser.New(config.SCIM))
+			r.Patch("/Users/{id}", patchScimUser.New(config.SCIM))
+			r.Delete("/Users/{id}", deleteScimUser.New(config.SCIM))
+			r.Get("/Groups", listScimGroups.New(config.SCIM))
+			r.Get("/Groups/{id}", getScimGroup.New(config.SCIM))
+			r.Patch("/Groups/{id}", patchScimGroup.New(config.SCIM))
+		})
+	}
+
 	return router
 }
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"avito-intern/internal/api/openapi"
+	"avito-intern/internal/api/render"
+	"avito-intern/internal/services"
+	"avito-intern/internal/stream"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter() *chi.Mux {
+	return SetupRouter(Config{
+		Webhooks:          services.NewWebhookService(nil),
+		Events:            stream.NewHub(0),
+		SCIM:              services.NewSCIMService(nil, nil, nil),
+		SCIMToken:         "scim-token",
+		DummyLogin:        true,
+		Legacy:            LegacyRoutes{DeprecatedAt: time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)},
+		ValidateResponses: true,
+	})
+}
+
+// specPath maps a chi route pattern to the path it is documented under.
+func specPath(route string) string {
+	if route == "/openapi" {
+		return "/openapi.json"
+	}
+	return strings.TrimPrefix(route, "/v1")
+}
+
+func TestRoutesAreDocumented(t *testing.T) {
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+
+	routed := make(map[string]bool)
+	err = chi.Walk(newTestRouter(), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		path := specPath(route)
+		routed[method+" "+path] = true
+
+		item := doc.Paths.Value(path)
+		if !assert.NotNil(t, item, "route %s %s is not described in openapi.yaml", method, route) {
+			return nil
+		}
+		assert.NotNil(t, item.GetOperation(method), "route %s %s is not described in openapi.yaml", method, route)
+		return nil
+	})
+	require.NoError(t, err)
+
+	var documented []string
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented = append(documented, method+" "+path)
+		}
+	}
+	sort.Strings(documented)
+	for _, operation := range documented {
+		assert.True(t, routed[operation], "%s is described in openapi.yaml but not routed", operation)
+	}
+}
+
+func TestRequestValidation(t *testing.T) {
+	router := newTestRouter()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedErrors []string
+	}{
+		{
+			name:           "missing required field",
+			method:         http.MethodPost,
+			path:           "/dummyLogin",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []string{"body /role"},
+		},
+		{
+			name:           "missing body",
+			method:         http.MethodPost,
+			path:           "/register",
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []string{"body"},
+		},
+		{
+			name:           "wrong field type",
+			method:         http.MethodPost,
+			path:           "/products",
+			body:           `{"type": 1, "pvzId": "p1"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []string{"body /type"},
+		},
+		{
+			name:           "query parameter out of range",
+			method:         http.MethodGet,
+			path:           "/pvz?limit=100&page=0",
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []string{"query limit", "query page"},
+		},
+		{
+			name:           "malformed date",
+			method:         http.MethodGet,
+			path:           "/pvz?startDate=yesterday",
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []string{"query startDate"},
+		},
+		{
+			name:           "valid request passes through",
+			method:         http.MethodPost,
+			path:           "/dummyLogin",
+			body:           `{"role": "employee"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "undocumented path passes through",
+			method:         http.MethodGet,
+			path:           "/unknown",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "spec is served",
+			method:         http.MethodGet,
+			path:           "/openapi.json",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var req *http.Request
+			if tc.body != "" {
+				req = httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+				req.Header.Set("Content-Type", "application/json")
+			} else {
+				req = httptest.NewRequest(tc.method, tc.path, nil)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code, rr.Body.String())
+			if tc.expectedErrors == nil {
+				return
+			}
+
+			assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+			var resp struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+				Errors  []struct {
+					Location string `json:"location"`
+					Name     string `json:"name"`
+					Reason   string `json:"reason"`
+				} `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			assert.Equal(t, "invalid_request", resp.Code)
+			assert.Equal(t, "Invalid request", resp.Message)
+
+			var got []string
+			for _, e := range resp.Errors {
+				assert.NotEmpty(t, e.Reason)
+				got = append(got, strings.TrimSpace(e.Location+" "+e.Name))
+			}
+			for _, expected := range tc.expectedErrors {
+				assert.Contains(t, got, expected)
+			}
+		})
+	}
+}
+
+func TestContentNegotiation(t *testing.T) {
+	router := newTestRouter()
+	msgpackBody, err := render.Encode(render.MessagePack, map[string]string{"role": "employee"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		body           string
+		contentType    string
+		accept         string
+		expectedStatus int
+		expectedType   string
+	}{
+		{
+			name:           "JSON by default",
+			body:           `{"role": "employee"}`,
+			contentType:    render.JSON,
+			expectedStatus: http.StatusOK,
+			expectedType:   render.JSON,
+		},
+		{
+			name:           "MessagePack response",
+			body:           `{"role": "employee"}`,
+			contentType:    render.JSON,
+			accept:         "application/x-msgpack",
+			expectedStatus: http.StatusOK,
+			expectedType:   render.MessagePack,
+		},
+		{
+			name:           "Protobuf response",
+			body:           `{"role": "employee"}`,
+			contentType:    render.JSON,
+			accept:         "application/x-protobuf, application/json;q=0.5",
+			expectedStatus: http.StatusOK,
+			expectedType:   render.Protobuf,
+		},
+		{
+			name:           "MessagePack request",
+			body:           string(msgpackBody),
+			contentType:    render.MessagePack,
+			expectedStatus: http.StatusOK,
+			expectedType:   render.JSON,
+		},
+		{
+			name:           "Unsupported Accept",
+			body:           `{"role": "employee"}`,
+			contentType:    render.JSON,
+			accept:         "text/html",
+			expectedStatus: http.StatusNotAcceptable,
+			expectedType:   "application/problem+json",
+		},
+		{
+			name:           "CSV of a single resource",
+			body:           `{"role": "employee"}`,
+			contentType:    render.JSON,
+			accept:         render.CSV,
+			expectedStatus: http.StatusNotAcceptable,
+			expectedType:   "application/problem+json",
+		},
+		{
+			name:           "Unsupported Content-Type",
+			body:           `role=employee`,
+			contentType:    "application/x-www-form-urlencoded",
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedType:   "application/problem+json",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/dummyLogin", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code, rr.Body.String())
+			assert.Equal(t, tc.expectedType, rr.Header().Get("Content-Type"))
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			document, err := render.DecodeDocument(tc.expectedType, rr.Body.Bytes())
+			require.NoError(t, err)
+			token, _ := document.(map[string]interface{})["token"].(string)
+			assert.NotEmpty(t, token)
+		})
+	}
+}
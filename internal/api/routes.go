@@ -0,0 +1,170 @@
+package api
+
+import (
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// apiVersion is one URL version of the API, served under /<name>.
+type apiVersion struct {
+	name   string
+	routes apiRoutes
+}
+
+// apiRoutes holds a handler for every versioned endpoint. Versions share the
+// paths and the access rules in mount and differ only in the handlers.
+type apiRoutes struct {
+	register       http.Handler
+	login          http.Handler
+	loginTwoFactor http.Handler
+	// dummyLogin is nil unless enabled for development.
+	dummyLogin       http.Handler
+	forgotPassword   http.Handler
+	resetPassword    http.Handler
+	enrollTwoFactor  http.Handler
+	confirmTwoFactor http.Handler
+
+	createPVZ         http.Handler
+	listPVZ           http.Handler
+	getPVZ            http.Handler
+	updatePVZ         http.Handler
+	listPVZReceptions http.Handler
+	createReception   http.Handler
+	closeReception    http.Handler
+	deleteLastProduct http.Handler
+	createProduct     http.Handler
+	graphQL           http.Handler
+
+	// The event streams are nil without an event hub.
+	streamPVZEvents          http.Handler
+	streamPVZEventsWebSocket http.Handler
+	createStreamToken        http.Handler
+
+	createInvite      http.Handler
+	approveUser       http.Handler
+	listUsers         http.Handler
+	changeUserRole    http.Handler
+	deactivateUser    http.Handler
+	reactivateUser    http.Handler
+	resetUserPassword http.Handler
+	unlockUser        http.Handler
+	exportUserData    http.Handler
+	eraseUser         http.Handler
+	impersonateUser   http.Handler
+	listAuditEvents   http.Handler
+	createAPIKey      http.Handler
+	listAPIKeys       http.Handler
+	revokeAPIKey      http.Handler
+
+	// The webhook handlers are nil when webhooks are not enabled.
+	createWebhook         http.Handler
+	listWebhooks          http.Handler
+	deleteWebhook         http.Handler
+	listWebhookDeliveries http.Handler
+	resendWebhookDelivery http.Handler
+}
+
+type authStack struct {
+	authenticate     func(http.Handler) http.Handler
+	session          func(http.Handler) http.Handler
+	impersonation    func(http.Handler) http.Handler
+	requireTwoFactor func(http.Handler) http.Handler
+	// idempotent makes POST requests with an Idempotency-Key safe to retry.
+	// It runs after authentication, as keys are scoped by the caller.
+	idempotent func(http.Handler) http.Handler
+}
+
+func (h apiRoutes) mount(r chi.Router, auth authStack) {
+	r.Method(http.MethodPost, "/register", h.register)
+	r.Method(http.MethodPost, "/login", h.login)
+	r.Method(http.MethodPost, "/login/2fa", h.loginTwoFactor)
+	if h.dummyLogin != nil {
+		r.Method(http.MethodPost, "/dummyLogin", h.dummyLogin)
+	}
+	r.Method(http.MethodPost, "/password/forgot", h.forgotPassword)
+	r.Method(http.MethodPost, "/password/reset", h.resetPassword)
+
+	// Browsers cannot send Authorization on EventSource and WebSocket
+	// requests, so the event streams also take a stream token in the query.
+	if h.streamPVZEvents != nil {
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.StreamTokenMiddleware(auth.authenticate))
+			r.Use(auth.session)
+			r.Use(auth.impersonation)
+			r.Use(auth.requireTwoFactor)
+			r.Use(middleware.RequireScope(models.ScopePVZRead))
+			r.Method(http.MethodGet, "/pvz/{pvzId}/events", h.streamPVZEvents)
+			r.Method(http.MethodGet, "/pvz/{pvzId}/events/ws", h.streamPVZEventsWebSocket)
+		})
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Use(auth.authenticate)
+		r.Use(auth.session)
+		r.Use(auth.impersonation)
+		r.With(middleware.DenyAPIKeys).Method(http.MethodPost, "/2fa/enroll", h.enrollTwoFactor)
+		r.With(middleware.DenyAPIKeys).Method(http.MethodPost, "/2fa/confirm", h.confirmTwoFactor)
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth.requireTwoFactor)
+			r.Group(func(r chi.Router) {
+				r.Use(auth.idempotent)
+				r.With(middleware.RequireScope(models.ScopePVZWrite)).Method(http.MethodPost, "/pvz", h.createPVZ)
+				r.With(middleware.RequireScope(models.ScopePVZRead)).Method(http.MethodGet, "/pvz", h.listPVZ)
+				r.With(middleware.RequireScope(models.ScopePVZRead)).Method(http.MethodGet, "/pvz/{pvzId}", h.getPVZ)
+				r.With(middleware.RequireScope(models.ScopePVZWrite)).Method(http.MethodPatch, "/pvz/{pvzId}", h.updatePVZ)
+				r.With(middleware.RequireScope(models.ScopePVZRead)).Method(http.MethodGet, "/pvz/{pvzId}/receptions", h.listPVZReceptions)
+				r.With(middleware.RequireScope(models.ScopeReceptionsWrite)).Method(http.MethodPost, "/receptions", h.createReception)
+				r.With(middleware.RequireScope(models.ScopeReceptionsWrite)).Method(http.MethodPost, "/pvz/{pvzId}/close_last_reception", h.closeReception)
+				r.With(middleware.RequireScope(models.ScopeProductsWrite)).Method(http.MethodPost, "/pvz/{pvzId}/delete_last_product", h.deleteLastProduct)
+				r.With(middleware.RequireScope(models.ScopeProductsWrite)).Method(http.MethodPost, "/products", h.createProduct)
+				// Queries are read-only, so GET lets impersonation sessions use them too.
+				r.Method(http.MethodGet, "/graphql", h.graphQL)
+				r.Method(http.MethodPost, "/graphql", h.graphQL)
+			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.DenyAPIKeys)
+				// These responses carry credentials, which must not be
+				// stored for idempotent retries.
+				r.Method(http.MethodPost, "/invites", h.createInvite)
+				r.Method(http.MethodPost, "/admin/users/{userId}/reset_password", h.resetUserPassword)
+				r.Method(http.MethodPost, "/admin/impersonate/{userId}", h.impersonateUser)
+				r.Method(http.MethodPost, "/admin/api_keys", h.createAPIKey)
+				if h.createStreamToken != nil {
+					r.Method(http.MethodPost, "/pvz/{pvzId}/events/token", h.createStreamToken)
+				}
+				if h.createWebhook != nil {
+					r.Method(http.MethodPost, "/webhooks", h.createWebhook)
+				}
+
+				r.Group(func(r chi.Router) {
+					r.Use(auth.idempotent)
+					r.Method(http.MethodPost, "/users/{userId}/approve", h.approveUser)
+
+					r.Method(http.MethodGet, "/admin/users", h.listUsers)
+					r.Method(http.MethodPost, "/admin/users/{userId}/role", h.changeUserRole)
+					r.Method(http.MethodPost, "/admin/users/{userId}/deactivate", h.deactivateUser)
+					r.Method(http.MethodPost, "/admin/users/{userId}/reactivate", h.reactivateUser)
+					r.Method(http.MethodPost, "/admin/users/{userId}/unlock", h.unlockUser)
+					r.Method(http.MethodGet, "/admin/users/{userId}/export", h.exportUserData)
+					r.Method(http.MethodPost, "/admin/users/{userId}/erase", h.eraseUser)
+					r.Method(http.MethodGet, "/admin/audit", h.listAuditEvents)
+
+					r.Method(http.MethodGet, "/admin/api_keys", h.listAPIKeys)
+					r.Method(http.MethodDelete, "/admin/api_keys/{keyId}", h.revokeAPIKey)
+
+					if h.createWebhook != nil {
+						r.Method(http.MethodGet, "/webhooks", h.listWebhooks)
+						r.Method(http.MethodDelete, "/webhooks/{webhookId}", h.deleteWebhook)
+						r.Method(http.MethodGet, "/webhooks/deliveries", h.listWebhookDeliveries)
+						r.Method(http.MethodPost, "/webhooks/deliveries/{deliveryId}/resend", h.resendWebhookDelivery)
+					}
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"avito-intern/internal/metrics"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegacyAliases(t *testing.T) {
+	router := newTestRouter()
+
+	tests := []struct {
+		name               string
+		path               string
+		version            string
+		expectedDeprecated bool
+	}{
+		{
+			name:    "versioned route",
+			path:    "/v1/dummyLogin",
+			version: "v1",
+		},
+		{
+			name:               "root alias",
+			path:               "/dummyLogin",
+			version:            "unversioned",
+			expectedDeprecated: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			counter := metrics.VersionRequestCount.WithLabelValues(tc.version, http.MethodPost, "200")
+			before := testutil.ToFloat64(counter)
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(`{"role": "employee"}`))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			assert.Equal(t, before+1, testutil.ToFloat64(counter))
+			if tc.expectedDeprecated {
+				assert.Equal(t, "@1792281600", rr.Header().Get("Deprecation"))
+				assert.Equal(t, `</v1/dummyLogin>; rel="successor-version"`, rr.Header().Get("Link"))
+			} else {
+				assert.Empty(t, rr.Header().Get("Deprecation"))
+				assert.Empty(t, rr.Header().Get("Link"))
+			}
+			assert.Empty(t, rr.Header().Get("Sunset"))
+		})
+	}
+}
+
+func TestLegacyAliases_WithoutDeprecationDate(t *testing.T) {
+	router := SetupRouter(Config{DummyLogin: true, ValidateResponses: true})
+
+	req := httptest.NewRequest(http.MethodPost, "/dummyLogin", strings.NewReader(`{"role": "employee"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Empty(t, rr.Header().Get("Deprecation"))
+	assert.Equal(t, `</v1/dummyLogin>; rel="successor-version"`, rr.Header().Get("Link"))
+}
+
+func TestVersionedRoutesAreValidated(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/dummyLogin", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"name":"/role"`)
+}
+
+func TestVersionOverridesHandlers(t *testing.T) {
+	respond := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, body)
+		})
+	}
+	pass := func(next http.Handler) http.Handler { return next }
+	auth := authStack{authenticate: pass, session: pass, impersonation: pass, requireTwoFactor: pass, idempotent: pass}
+
+	v1 := apiRoutes{dummyLogin: respond("v1 login"), listPVZ: respond("v1 pvz")}
+	v2 := v1
+	v2.listPVZ = respond("v2 pvz")
+
+	router := chi.NewRouter()
+	for _, version := range []apiVersion{{name: "v1", routes: v1}, {name: "v2", routes: v2}} {
+		router.Route("/"+version.name, func(r chi.Router) {
+			version.routes.mount(r, auth)
+		})
+	}
+
+	tests := []struct {
+		method       string
+		path         string
+		expectedBody string
+	}{
+		{http.MethodGet, "/v1/pvz", "v1 pvz"},
+		{http.MethodGet, "/v2/pvz", "v2 pvz"},
+		{http.MethodPost, "/v1/dummyLogin", "v1 login"},
+		{http.MethodPost, "/v2/dummyLogin", "v1 login"},
+	}
+	for _, tc := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(tc.method, tc.path, nil))
+		assert.Equal(t, tc.expectedBody, rr.Body.String(), tc.path)
+	}
+}
@@ -0,0 +1,10 @@
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_role_check CHECK (role IN ('employee', 'moderator', 'admin'));
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_status_check CHECK (status IN ('active', 'pending'));
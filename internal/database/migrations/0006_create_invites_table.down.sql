@@ -0,0 +1 @@
+DROP TABLE IF EXISTS invites CASCADE;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS invites
+(
+    id         UUID PRIMARY KEY,
+    tokenHash  TEXT UNIQUE NOT NULL,
+    email      TEXT        NOT NULL,
+    role       TEXT        NOT NULL CHECK (role IN ('employee', 'moderator', 'admin')),
+    createdBy  UUID        NOT NULL,
+    createdAt  TIMESTAMP   NOT NULL,
+    expiresAt  TIMESTAMP   NOT NULL,
+    usedAt     TIMESTAMP
+);
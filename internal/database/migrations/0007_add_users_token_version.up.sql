@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS tokenVersion INT NOT NULL DEFAULT 0;
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_status_check CHECK (status IN ('active', 'pending', 'deactivated'));
+CREATE INDEX IF NOT EXISTS users_role_status_idx ON users (role, status);
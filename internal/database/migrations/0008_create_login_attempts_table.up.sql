@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    attemptKey    TEXT PRIMARY KEY,
+    failures      INT       NOT NULL DEFAULT 0,
+    lastFailureAt TIMESTAMP NOT NULL,
+    lockedUntil   TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS recovery_codes CASCADE;
+DROP TABLE IF EXISTS user_totp CASCADE;
\ No newline at end of file
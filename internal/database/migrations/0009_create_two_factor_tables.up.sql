@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS user_totp
+(
+    userId       UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret       TEXT      NOT NULL,
+    confirmed    BOOLEAN   NOT NULL DEFAULT FALSE,
+    lastUsedStep BIGINT    NOT NULL DEFAULT 0,
+    createdAt    TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes
+(
+    id       UUID PRIMARY KEY,
+    userId   UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    codeHash TEXT NOT NULL,
+    usedAt   TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS recovery_codes_user_idx ON recovery_codes (userId);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS password_reset_tokens
+(
+    id        UUID PRIMARY KEY,
+    userId    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    tokenHash TEXT UNIQUE NOT NULL,
+    createdAt TIMESTAMP   NOT NULL,
+    expiresAt TIMESTAMP   NOT NULL,
+    usedAt    TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS password_reset_tokens_user_idx ON password_reset_tokens (userId);
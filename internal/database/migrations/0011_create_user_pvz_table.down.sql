@@ -0,0 +1 @@
+DROP TABLE IF EXISTS user_pvz CASCADE;
\ No newline at end of file
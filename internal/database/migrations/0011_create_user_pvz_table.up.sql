@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS user_pvz
+(
+    userId UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    pvzId  UUID NOT NULL REFERENCES pvz (id) ON DELETE CASCADE,
+    PRIMARY KEY (userId, pvzId)
+);
+CREATE INDEX IF NOT EXISTS user_pvz_pvz_idx ON user_pvz (pvzId);
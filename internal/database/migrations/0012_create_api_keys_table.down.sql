@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS api_key_nonces CASCADE;
+DROP TABLE IF EXISTS api_keys CASCADE;
\ No newline at end of file
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id               UUID PRIMARY KEY,
+    name             TEXT        NOT NULL,
+    keyPrefix        TEXT        NOT NULL,
+    keyHash          TEXT UNIQUE NOT NULL,
+    role             TEXT        NOT NULL,
+    scopes           TEXT[]      NOT NULL DEFAULT '{}',
+    requireSignature BOOLEAN     NOT NULL DEFAULT FALSE,
+    createdBy        UUID        NOT NULL REFERENCES users (id),
+    createdAt        TIMESTAMP   NOT NULL,
+    expiresAt        TIMESTAMP,
+    lastUsedAt       TIMESTAMP,
+    revokedAt        TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS api_key_nonces
+(
+    apiKeyId UUID      NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+    nonce    TEXT      NOT NULL,
+    usedAt   TIMESTAMP NOT NULL,
+    PRIMARY KEY (apiKeyId, nonce)
+);
+CREATE INDEX IF NOT EXISTS api_key_nonces_used_idx ON api_key_nonces (usedAt);
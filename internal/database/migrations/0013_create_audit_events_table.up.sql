@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS audit_events
+(
+    id        UUID PRIMARY KEY,
+    actorId   UUID      NOT NULL REFERENCES users (id),
+    userId    UUID REFERENCES users (id),
+    action    TEXT      NOT NULL,
+    details   TEXT      NOT NULL DEFAULT '',
+    createdAt TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actorId, createdAt);
+CREATE INDEX IF NOT EXISTS audit_events_user_idx ON audit_events (userId, createdAt);
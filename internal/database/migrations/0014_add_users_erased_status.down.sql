@@ -0,0 +1,6 @@
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_status_check CHECK (status IN ('active', 'pending', 'deactivated'));
+ALTER TABLE users
+    DROP COLUMN IF EXISTS erasedAt;
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS erasedAt TIMESTAMP;
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_status_check CHECK (status IN ('active', 'pending', 'deactivated', 'erased'));
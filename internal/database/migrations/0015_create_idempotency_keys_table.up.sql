@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    scope           TEXT      NOT NULL,
+    idempotencyKey  TEXT      NOT NULL,
+    requestHash     TEXT      NOT NULL,
+    statusCode      INT,
+    responseHeaders JSONB,
+    responseBody    BYTEA,
+    createdAt       TIMESTAMP NOT NULL,
+    expiresAt       TIMESTAMP NOT NULL,
+    PRIMARY KEY (scope, idempotencyKey)
+);
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_idx ON idempotency_keys (expiresAt);
@@ -0,0 +1,4 @@
+ALTER TABLE receptions
+    DROP COLUMN IF EXISTS version;
+ALTER TABLE pvz
+    DROP COLUMN IF EXISTS version;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE pvz
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE receptions
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
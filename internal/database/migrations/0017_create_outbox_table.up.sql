@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS outbox
+(
+    sequence      BIGSERIAL PRIMARY KEY,
+    eventId       UUID      NOT NULL UNIQUE,
+    eventType     TEXT      NOT NULL,
+    pvzId         UUID      NOT NULL,
+    payload       JSONB     NOT NULL,
+    occurredAt    TIMESTAMP NOT NULL,
+    attempts      INT       NOT NULL DEFAULT 0,
+    nextAttemptAt TIMESTAMP NOT NULL,
+    lastError     TEXT      NOT NULL DEFAULT '',
+    publishedAt   TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (pvzId, sequence) WHERE publishedAt IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_published_idx ON outbox (publishedAt) WHERE publishedAt IS NOT NULL;
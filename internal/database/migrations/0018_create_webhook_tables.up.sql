@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+    id         UUID PRIMARY KEY,
+    url        TEXT      NOT NULL,
+    secret     TEXT      NOT NULL,
+    eventTypes TEXT[]    NOT NULL DEFAULT '{}',
+    pvzIds     TEXT[]    NOT NULL DEFAULT '{}',
+    createdBy  UUID      NOT NULL REFERENCES users (id),
+    createdAt  TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id             UUID PRIMARY KEY,
+    subscriptionId UUID      NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    eventId        UUID      NOT NULL,
+    eventType      TEXT      NOT NULL,
+    pvzId          UUID      NOT NULL,
+    payload        JSONB     NOT NULL,
+    status         TEXT      NOT NULL,
+    attempts       INT       NOT NULL DEFAULT 0,
+    nextAttemptAt  TIMESTAMP NOT NULL,
+    lastAttemptAt  TIMESTAMP,
+    responseStatus INT       NOT NULL DEFAULT 0,
+    lastError      TEXT      NOT NULL DEFAULT '',
+    createdAt      TIMESTAMP NOT NULL,
+    deliveredAt    TIMESTAMP,
+    UNIQUE (subscriptionId, eventId)
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (nextAttemptAt) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_created_idx ON webhook_deliveries (createdAt);
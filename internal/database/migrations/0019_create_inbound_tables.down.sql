@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS inbound_dead_letters;
+DROP TABLE IF EXISTS inbound_messages;
\ No newline at end of file
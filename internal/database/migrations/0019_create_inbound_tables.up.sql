@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS inbound_messages
+(
+    id          TEXT PRIMARY KEY,
+    messageType TEXT      NOT NULL,
+    status      TEXT      NOT NULL,
+    attempts    INT       NOT NULL DEFAULT 1,
+    claimedAt   TIMESTAMP,
+    processedAt TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS inbound_messages_processed_idx ON inbound_messages (processedAt) WHERE processedAt IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS inbound_dead_letters
+(
+    id          UUID PRIMARY KEY,
+    messageId   TEXT      NOT NULL DEFAULT '',
+    messageType TEXT      NOT NULL DEFAULT '',
+    source      TEXT      NOT NULL,
+    body        TEXT      NOT NULL,
+    reason      TEXT      NOT NULL,
+    attempts    INT       NOT NULL,
+    createdAt   TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS inbound_dead_letters_created_idx ON inbound_dead_letters (createdAt);
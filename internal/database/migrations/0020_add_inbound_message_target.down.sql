@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS inbound_messages DROP COLUMN IF EXISTS target;
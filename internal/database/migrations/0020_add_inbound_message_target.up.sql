@@ -0,0 +1,2 @@
+ALTER TABLE inbound_messages
+    ADD COLUMN IF NOT EXISTS target TEXT;
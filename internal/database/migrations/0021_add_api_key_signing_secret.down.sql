@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS api_keys DROP COLUMN IF EXISTS signingSecret;
@@ -0,0 +1,3 @@
+-- Keys created before have no signing secret and cannot sign requests.
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS signingSecret TEXT;
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS pvz DROP COLUMN IF EXISTS receptionsVersion;
@@ -0,0 +1,4 @@
+-- Incremented with every change of the receptions and products of the
+-- pickup point, it is the ETag of GET /pvz/{pvzId}/receptions.
+ALTER TABLE pvz
+    ADD COLUMN IF NOT EXISTS receptionsVersion INT NOT NULL DEFAULT 1;
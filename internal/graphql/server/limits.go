@@ -0,0 +1,174 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// estimatedListSize is the number of items assumed for list fields without a
+// limit argument, such as the receptions of a pickup point.
+const estimatedListSize = 10
+
+// maxListSizes are the largest limits the services accept for the list
+// fields; a higher limit falls back to the default, so it is counted as the
+// maximum.
+var maxListSizes = map[string]int{
+	"pvzs":  30,
+	"users": 100,
+}
+
+// Limits bound the cost of a single query. Zero disables a limit.
+type Limits struct {
+	// MaxDepth is the deepest allowed field nesting; { pvzs { id } } has depth 2.
+	MaxDepth int
+	// MaxComplexity is the highest allowed estimate of resolved fields. Every
+	// field costs 1, and the cost of the selection under a list field is
+	// multiplied by its limit argument, clamped like the services do, or by
+	// estimatedListSize.
+	MaxComplexity int
+}
+
+// checkLimits measures the operation that will be executed. The document
+// must already be validated against the schema.
+func checkLimits(schema graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}, limits Limits) error {
+	var operation *ast.OperationDefinition
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range doc.Definitions {
+		switch def := definition.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		}
+	}
+	if operation == nil {
+		return fmt.Errorf("unknown operation %q", operationName)
+	}
+
+	m := &measurer{schema: schema, fragments: fragments, variables: variables}
+	depth, complexity := m.measure(operation.SelectionSet, schema.QueryType(), 1)
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", depth, limits.MaxDepth)
+	}
+	if limits.MaxComplexity > 0 && complexity > limits.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, limits.MaxComplexity)
+	}
+	return nil
+}
+
+type measurer struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+func (m *measurer) measure(set *ast.SelectionSet, parent graphql.Type, depth int) (maxDepth, complexity int) {
+	if set == nil {
+		return depth - 1, 0
+	}
+	maxDepth = depth
+
+	for _, selection := range set.Selections {
+		var d, c int
+		switch sel := selection.(type) {
+		case *ast.Field:
+			d, c = m.measureField(sel, parent, depth)
+		case *ast.InlineFragment:
+			d, c = m.measure(sel.SelectionSet, m.typeCondition(sel.TypeCondition, parent), depth)
+		case *ast.FragmentSpread:
+			fragment, ok := m.fragments[sel.Name.Value]
+			if !ok {
+				continue
+			}
+			d, c = m.measure(fragment.SelectionSet, m.typeCondition(fragment.TypeCondition, parent), depth)
+		}
+		maxDepth = max(maxDepth, d)
+		complexity += c
+	}
+	return maxDepth, complexity
+}
+
+func (m *measurer) measureField(field *ast.Field, parent graphql.Type, depth int) (int, int) {
+	object, ok := parent.(*graphql.Object)
+	// Introspection is cheap and deeply nested by design, so it is not counted.
+	if !ok || strings.HasPrefix(field.Name.Value, "__") {
+		return depth, 1
+	}
+	definition, ok := object.Fields()[field.Name.Value]
+	if !ok {
+		return depth, 1
+	}
+
+	multiplier := 1
+	fieldType := unwrapNonNull(definition.Type)
+	if list, ok := fieldType.(*graphql.List); ok {
+		multiplier = m.listSize(field, definition)
+		fieldType = unwrapNonNull(list.OfType)
+	}
+
+	d, c := m.measure(field.SelectionSet, fieldType, depth+1)
+	return d, 1 + multiplier*c
+}
+
+// listSize is the number of items the list field is counted as. Limits out
+// of range are clamped the way the services treat them: non-positive ones
+// fall back to the default and too high ones are capped.
+func (m *measurer) listSize(field *ast.Field, definition *graphql.FieldDefinition) int {
+	size := estimatedListSize
+	for _, arg := range definition.Args {
+		if n, ok := arg.DefaultValue.(int); ok && arg.Name() == "limit" {
+			size = n
+		}
+	}
+	limit, ok := m.limitArgument(field)
+	if !ok || limit < 1 {
+		return size
+	}
+	if maxSize, ok := maxListSizes[definition.Name]; ok && limit > maxSize {
+		return maxSize
+	}
+	return limit
+}
+
+// limitArgument returns the limit argument given to the field.
+func (m *measurer) limitArgument(field *ast.Field) (int, bool) {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil {
+				return n, true
+			}
+		case *ast.Variable:
+			switch n := m.variables[value.Name.Value].(type) {
+			case float64:
+				return int(n), true
+			case int:
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func (m *measurer) typeCondition(condition *ast.Named, parent graphql.Type) graphql.Type {
+	if condition == nil {
+		return parent
+	}
+	return m.schema.Type(condition.Name.Value)
+}
+
+func unwrapNonNull(t graphql.Type) graphql.Type {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		return nonNull.OfType
+	}
+	return t
+}
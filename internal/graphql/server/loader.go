@@ -0,0 +1,102 @@
+package server
+
+import (
+	"avito-intern/internal/models"
+	"context"
+	"sync"
+)
+
+// loader batches lookups by key within a single request. Load only records
+// the key; the first thunk that is called fetches every key recorded so far
+// in one call. graphql-go calls thunks breadth-first, so all siblings on a
+// level are recorded before any of them is fetched.
+type loader[V any] struct {
+	fetch func(keys []string) (map[string]V, error)
+
+	mu      sync.Mutex
+	pending []string
+	queued  map[string]bool
+	results map[string]V
+	errs    map[string]error
+}
+
+func newLoader[V any](fetch func(keys []string) (map[string]V, error)) *loader[V] {
+	return &loader[V]{
+		fetch:   fetch,
+		queued:  make(map[string]bool),
+		results: make(map[string]V),
+		errs:    make(map[string]error),
+	}
+}
+
+func (l *loader[V]) Load(key string) func() (V, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+			results, err := l.fetch(keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+					continue
+				}
+				l.results[k] = results[k]
+			}
+		}
+		return l.results[key], l.errs[key]
+	}
+}
+
+// loaders are created for every request, so results are never shared between
+// callers with different permissions.
+type loaders struct {
+	receptionsByPVZ     *loader[[]*models.Reception]
+	productsByReception *loader[[]*models.Product]
+}
+
+type loadersCtxKey struct{}
+
+func newLoaders(receptions ReceptionService, products ProductService) *loaders {
+	return &loaders{
+		receptionsByPVZ: newLoader(func(pvzIDs []string) (map[string][]*models.Reception, error) {
+			list, err := receptions.ListReceptions(pvzIDs)
+			if err != nil {
+				return nil, err
+			}
+			grouped := make(map[string][]*models.Reception, len(pvzIDs))
+			for _, reception := range list {
+				grouped[reception.PvzID] = append(grouped[reception.PvzID], reception)
+			}
+			return grouped, nil
+		}),
+		productsByReception: newLoader(func(receptionIDs []string) (map[string][]*models.Product, error) {
+			list, err := products.ListProducts(receptionIDs)
+			if err != nil {
+				return nil, err
+			}
+			grouped := make(map[string][]*models.Product, len(receptionIDs))
+			for _, product := range list {
+				grouped[product.ReceptionID] = append(grouped[product.ReceptionID], product)
+			}
+			return grouped, nil
+		}),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersCtxKey{}, l)
+}
+
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersCtxKey{}).(*loaders)
+}
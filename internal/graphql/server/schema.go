@@ -0,0 +1,208 @@
+package server
+
+import (
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// dateLayout is the date format PVZService.ListPVZ accepts.
+const dateLayout = "2006-01-02T15:04:05"
+
+var (
+	errAccessDenied = errors.New("Access denied")
+	errInternal     = errors.New("Internal server error")
+)
+
+type resolver struct {
+	pvzService  PVZService
+	userService UserService
+}
+
+func newSchema(r *resolver) (graphql.Schema, error) {
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"dateTime":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"type":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"receptionId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		},
+	})
+
+	receptionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Reception",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"dateTime": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"pvzId":    &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"status":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"products": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productType))),
+				Resolve: r.receptionProducts,
+			},
+		},
+	})
+
+	pvzType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PVZ",
+		Fields: graphql.Fields{
+			"id":               &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"registrationDate": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"city":             &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"receptions": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(receptionType))),
+				Resolve: r.pvzReceptions,
+			},
+		},
+	})
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":     &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"email":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"role":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"status": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"pvzs": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(pvzType))),
+				Description: "Pickup points, filtered by registration date. Employees and moderators only.",
+				Args: graphql.FieldConfigArgument{
+					"startDate": &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"endDate":   &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"page":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+					"limit":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 10},
+				},
+				Resolve: r.pvzs,
+			},
+			"users": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(userType))),
+				Description: "Users, filtered by email substring, role and status. Administrators only.",
+				Args: graphql.FieldConfigArgument{
+					"email":  &graphql.ArgumentConfig{Type: graphql.String},
+					"role":   &graphql.ArgumentConfig{Type: graphql.String},
+					"status": &graphql.ArgumentConfig{Type: graphql.String},
+					"page":   &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+					"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 20},
+				},
+				Resolve: r.users,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// pvzs follows GET /pvz: employees and moderators, API keys with pvz:read.
+func (r *resolver) pvzs(p graphql.ResolveParams) (interface{}, error) {
+	if err := middleware.RequireAnyRole(p.Context, "employee", "moderator"); err != nil {
+		return nil, errAccessDenied
+	}
+	if principal, ok := middleware.GetServiceFromContext(p.Context); ok && !principal.HasScope(models.ScopePVZRead) {
+		return nil, errAccessDenied
+	}
+
+	pvzs, err := r.pvzService.ListPVZ(
+		intArg(p.Args, "limit"),
+		intArg(p.Args, "page"),
+		dateArg(p.Args, "startDate"),
+		dateArg(p.Args, "endDate"),
+	)
+	if err != nil {
+		return nil, internalError(err)
+	}
+	if pvzs == nil {
+		pvzs = []*models.PVZ{}
+	}
+	return pvzs, nil
+}
+
+func (r *resolver) pvzReceptions(p graphql.ResolveParams) (interface{}, error) {
+	pvz := p.Source.(*models.PVZ)
+	thunk := loadersFromContext(p.Context).receptionsByPVZ.Load(pvz.ID)
+	return func() (interface{}, error) {
+		receptions, err := thunk()
+		if err != nil {
+			return nil, internalError(err)
+		}
+		if receptions == nil {
+			receptions = []*models.Reception{}
+		}
+		return receptions, nil
+	}, nil
+}
+
+func (r *resolver) receptionProducts(p graphql.ResolveParams) (interface{}, error) {
+	reception := p.Source.(*models.Reception)
+	thunk := loadersFromContext(p.Context).productsByReception.Load(reception.ID)
+	return func() (interface{}, error) {
+		products, err := thunk()
+		if err != nil {
+			return nil, internalError(err)
+		}
+		if products == nil {
+			products = []*models.Product{}
+		}
+		return products, nil
+	}, nil
+}
+
+// users follows GET /admin/users: administrators only, no API keys.
+func (r *resolver) users(p graphql.ResolveParams) (interface{}, error) {
+	if err := requireUser(p.Context, "admin"); err != nil {
+		return nil, err
+	}
+
+	email, _ := p.Args["email"].(string)
+	role, _ := p.Args["role"].(string)
+	status, _ := p.Args["status"].(string)
+	users, err := r.userService.ListUsers(email, role, status, intArg(p.Args, "limit"), intArg(p.Args, "page"))
+	if err != nil {
+		return nil, internalError(err)
+	}
+	if users == nil {
+		users = []*models.User{}
+	}
+	return users, nil
+}
+
+func requireUser(ctx context.Context, role string) error {
+	if _, ok := middleware.GetServiceFromContext(ctx); ok {
+		return errAccessDenied
+	}
+	if err := middleware.RequireRole(ctx, role); err != nil {
+		return errAccessDenied
+	}
+	return nil
+}
+
+func intArg(args map[string]interface{}, name string) string {
+	if v, ok := args[name].(int); ok {
+		return strconv.Itoa(v)
+	}
+	return ""
+}
+
+func dateArg(args map[string]interface{}, name string) string {
+	if v, ok := args[name].(time.Time); ok {
+		return v.UTC().Format(dateLayout)
+	}
+	return ""
+}
+
+func internalError(err error) error {
+	log.Printf("GraphQL query failed: %v", err)
+	return errInternal
+}
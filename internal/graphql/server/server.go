@@ -0,0 +1,125 @@
+package server
+
+import (
+	"avito-intern/internal/models"
+	"encoding/json"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+type PVZService interface {
+	ListPVZ(limitStr, pageStr, startDateStr, endDateStr string) ([]*models.PVZ, error)
+}
+
+type ReceptionService interface {
+	ListReceptions(pvzIDs []string) ([]*models.Reception, error)
+}
+
+type ProductService interface {
+	ListProducts(receptionIDs []string) ([]*models.Product, error)
+}
+
+type UserService interface {
+	ListUsers(email, role, status, limitStr, pageStr string) ([]*models.User, error)
+}
+
+// Handler serves read-only GraphQL queries over pickup points, receptions,
+// products and users. It must run behind the same authentication middleware
+// as the REST routes; every query field checks the caller's role itself.
+type Handler struct {
+	schema           graphql.Schema
+	receptionService ReceptionService
+	productService   ProductService
+	limits           Limits
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// New builds the schema; it panics only if the schema definition is broken.
+func New(
+	pvzService PVZService,
+	receptionService ReceptionService,
+	productService ProductService,
+	userService UserService,
+	limits Limits,
+) *Handler {
+	schema, err := newSchema(&resolver{pvzService: pvzService, userService: userService})
+	if err != nil {
+		panic("graphql: " + err.Error())
+	}
+	return &Handler{
+		schema:           schema,
+		receptionService: receptionService,
+		productService:   productService,
+		limits:           limits,
+	}
+}
+
+// ServeHTTP accepts queries as a JSON body in POST requests or as query,
+// operationName and variables parameters in GET requests. Requests that
+// cannot be executed get 400; errors of single fields are reported next to
+// the data with 200, as usual in GraphQL.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				writeErrors(w, http.StatusBadRequest, gqlerrors.FormatErrors(err))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrors(w, http.StatusBadRequest, gqlerrors.FormatErrors(err))
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, gqlerrors.FormatErrors(err))
+		return
+	}
+	if result := graphql.ValidateDocument(&h.schema, doc, nil); !result.IsValid {
+		writeErrors(w, http.StatusBadRequest, result.Errors)
+		return
+	}
+	if err = checkLimits(h.schema, doc, req.OperationName, req.Variables, h.limits); err != nil {
+		writeErrors(w, http.StatusBadRequest, gqlerrors.FormatErrors(err))
+		return
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(r.Context(), newLoaders(h.receptionService, h.productService)),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func writeErrors(w http.ResponseWriter, status int, errs []gqlerrors.FormattedError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(graphql.Result{Errors: errs})
+}
@@ -0,0 +1,349 @@
+package server
+
+import (
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPVZService struct {
+	mock.Mock
+}
+
+var _ PVZService = (*mockPVZService)(nil)
+
+func (m *mockPVZService) ListPVZ(limitStr, pageStr, startDateStr, endDateStr string) ([]*models.PVZ, error) {
+	args := m.Called(limitStr, pageStr, startDateStr, endDateStr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PVZ), args.Error(1)
+}
+
+type mockReceptionService struct {
+	mock.Mock
+}
+
+var _ ReceptionService = (*mockReceptionService)(nil)
+
+func (m *mockReceptionService) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	args := m.Called(pvzIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reception), args.Error(1)
+}
+
+type mockProductService struct {
+	mock.Mock
+}
+
+var _ ProductService = (*mockProductService)(nil)
+
+func (m *mockProductService) ListProducts(receptionIDs []string) ([]*models.Product, error) {
+	args := m.Called(receptionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
+type mockUserService struct {
+	mock.Mock
+}
+
+var _ UserService = (*mockUserService)(nil)
+
+func (m *mockUserService) ListUsers(email, role, status, limitStr, pageStr string) ([]*models.User, error) {
+	args := m.Called(email, role, status, limitStr, pageStr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+type testHandler struct {
+	handler    *Handler
+	pvz        *mockPVZService
+	receptions *mockReceptionService
+	products   *mockProductService
+	users      *mockUserService
+}
+
+func newTestHandler(limits Limits) *testHandler {
+	th := &testHandler{
+		pvz:        new(mockPVZService),
+		receptions: new(mockReceptionService),
+		products:   new(mockProductService),
+		users:      new(mockUserService),
+	}
+	th.handler = New(th.pvz, th.receptions, th.products, th.users, limits)
+	return th
+}
+
+type result struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (th *testHandler) post(t *testing.T, ctx context.Context, query string, variables map[string]interface{}) (int, result) {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))).WithContext(ctx)
+	rr := httptest.NewRecorder()
+	th.handler.ServeHTTP(rr, req)
+
+	var res result
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res), rr.Body.String())
+	return rr.Code, res
+}
+
+func userContext(role string) context.Context {
+	return context.WithValue(context.Background(), middleware.UserCtxKey, models.User{ID: "u1", Role: role})
+}
+
+func apiKeyContext(role string, scopes ...string) context.Context {
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, models.User{ID: "k1", Role: role})
+	return context.WithValue(ctx, middleware.ServiceCtxKey, models.ServicePrincipal{KeyID: "k1", Role: role, Scopes: scopes})
+}
+
+func TestPVZTreeIsBatched(t *testing.T) {
+	th := newTestHandler(Limits{})
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	th.pvz.On("ListPVZ", "5", "1", "2025-04-01T00:00:00", "").Return([]*models.PVZ{
+		{ID: "p1", City: "Москва", RegistrationDate: now},
+		{ID: "p2", City: "Казань", RegistrationDate: now},
+	}, nil).Once()
+	th.receptions.On("ListReceptions", []string{"p1", "p2"}).Return([]*models.Reception{
+		{ID: "r1", PvzID: "p1", Status: "close", DateTime: now},
+		{ID: "r2", PvzID: "p1", Status: "in_progress", DateTime: now},
+	}, nil).Once()
+	th.products.On("ListProducts", []string{"r1", "r2"}).Return([]*models.Product{
+		{ID: "pr1", ReceptionID: "r1", Type: "обувь", DateTime: now},
+	}, nil).Once()
+
+	code, res := th.post(t, userContext("moderator"), `
+		query($limit: Int) {
+			pvzs(startDate: "2025-04-01T00:00:00Z", limit: $limit) {
+				id
+				city
+				receptions { id status products { id type } }
+			}
+		}`, map[string]interface{}{"limit": 5})
+
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, res.Errors)
+	assert.JSONEq(t, `[
+		{"id": "p1", "city": "Москва", "receptions": [
+			{"id": "r1", "status": "close", "products": [{"id": "pr1", "type": "обувь"}]},
+			{"id": "r2", "status": "in_progress", "products": []}
+		]},
+		{"id": "p2", "city": "Казань", "receptions": []}
+	]`, string(res.Data["pvzs"]))
+
+	th.pvz.AssertExpectations(t)
+	th.receptions.AssertExpectations(t)
+	th.products.AssertExpectations(t)
+}
+
+func TestRoleChecks(t *testing.T) {
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		query         string
+		setupMocks    func(th *testHandler)
+		expectedError string
+	}{
+		{
+			name:  "employee lists pickup points",
+			ctx:   userContext("employee"),
+			query: `{ pvzs { id } }`,
+			setupMocks: func(th *testHandler) {
+				th.pvz.On("ListPVZ", "10", "1", "", "").Return([]*models.PVZ{}, nil)
+			},
+		},
+		{
+			name:          "admin cannot list pickup points",
+			ctx:           userContext("admin"),
+			query:         `{ pvzs { id } }`,
+			expectedError: "Access denied",
+		},
+		{
+			name:          "API key without pvz:read",
+			ctx:           apiKeyContext("employee", models.ScopeReceptionsWrite),
+			query:         `{ pvzs { id } }`,
+			expectedError: "Access denied",
+		},
+		{
+			name:  "API key with pvz:read",
+			ctx:   apiKeyContext("employee", models.ScopePVZRead),
+			query: `{ pvzs { id } }`,
+			setupMocks: func(th *testHandler) {
+				th.pvz.On("ListPVZ", "10", "1", "", "").Return([]*models.PVZ{}, nil)
+			},
+		},
+		{
+			name:  "admin lists users",
+			ctx:   userContext("admin"),
+			query: `{ users(role: "employee") { id email role status } }`,
+			setupMocks: func(th *testHandler) {
+				th.users.On("ListUsers", "", "employee", "", "20", "1").Return([]*models.User{
+					{ID: "u2", Email: "e@test.com", Role: "employee", Status: "active"},
+				}, nil)
+			},
+		},
+		{
+			name:          "moderator cannot list users",
+			ctx:           userContext("moderator"),
+			query:         `{ users { id } }`,
+			expectedError: "Access denied",
+		},
+		{
+			name:          "API keys cannot list users",
+			ctx:           apiKeyContext("admin", models.ScopePVZRead),
+			query:         `{ users { id } }`,
+			expectedError: "Access denied",
+		},
+		{
+			name:  "service error is hidden",
+			ctx:   userContext("moderator"),
+			query: `{ pvzs { id } }`,
+			setupMocks: func(th *testHandler) {
+				th.pvz.On("ListPVZ", "10", "1", "", "").Return(nil, errors.New("db down"))
+			},
+			expectedError: "Internal server error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			th := newTestHandler(Limits{})
+			if tc.setupMocks != nil {
+				tc.setupMocks(th)
+			}
+
+			code, res := th.post(t, tc.ctx, tc.query, nil)
+
+			assert.Equal(t, http.StatusOK, code)
+			if tc.expectedError == "" {
+				assert.Empty(t, res.Errors)
+			} else {
+				require.Len(t, res.Errors, 1)
+				assert.Equal(t, tc.expectedError, res.Errors[0].Message)
+			}
+			th.pvz.AssertExpectations(t)
+			th.users.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		limits        Limits
+		query         string
+		variables     map[string]interface{}
+		expectedError string
+	}{
+		{
+			name:          "too deep",
+			limits:        Limits{MaxDepth: 3},
+			query:         `{ pvzs { receptions { products { id } } } }`,
+			expectedError: "query depth 4 exceeds the limit of 3",
+		},
+		{
+			name:          "too deep through a fragment",
+			limits:        Limits{MaxDepth: 3},
+			query:         `{ pvzs { ...tree } } fragment tree on PVZ { receptions { products { id } } }`,
+			expectedError: "query depth 4 exceeds the limit of 3",
+		},
+		{
+			// 1 + 30 * (1 + 10 * (1 + 10 * 1))
+			name:          "too complex",
+			limits:        Limits{MaxComplexity: 3000},
+			query:         `{ pvzs(limit: 30) { receptions { products { id } } } }`,
+			expectedError: "query complexity 3331 exceeds the limit of 3000",
+		},
+		{
+			name:          "limit from a variable",
+			limits:        Limits{MaxComplexity: 3000},
+			query:         `query($n: Int) { pvzs(limit: $n) { receptions { products { id } } } }`,
+			variables:     map[string]interface{}{"n": 30},
+			expectedError: "query complexity 3331 exceeds the limit of 3000",
+		},
+		{
+			// A negative limit is counted as the default of 10:
+			// 1 + 10 * (1 + 10 * (1 + 10 * 1))
+			name:          "negative limit",
+			limits:        Limits{MaxComplexity: 1000},
+			query:         `{ pvzs(limit: -1000) { receptions { products { id } } } }`,
+			expectedError: "query complexity 1111 exceeds the limit of 1000",
+		},
+		{
+			name:          "negative limit from a variable",
+			limits:        Limits{MaxComplexity: 1000},
+			query:         `query($n: Int) { pvzs(limit: $n) { receptions { products { id } } } }`,
+			variables:     map[string]interface{}{"n": -1000},
+			expectedError: "query complexity 1111 exceeds the limit of 1000",
+		},
+		{
+			// A limit above the maximum is counted as 30.
+			name:          "limit above the maximum",
+			limits:        Limits{MaxComplexity: 3000},
+			query:         `{ pvzs(limit: 1000000) { receptions { products { id } } } }`,
+			expectedError: "query complexity 3331 exceeds the limit of 3000",
+		},
+		{
+			name:          "invalid query",
+			query:         `{ pvzs { unknown } }`,
+			expectedError: `Cannot query field "unknown" on type "PVZ".`,
+		},
+		{
+			name:          "syntax error",
+			query:         `{ pvzs {`,
+			expectedError: "Syntax Error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			th := newTestHandler(tc.limits)
+
+			code, res := th.post(t, userContext("moderator"), tc.query, tc.variables)
+
+			assert.Equal(t, http.StatusBadRequest, code)
+			require.NotEmpty(t, res.Errors)
+			assert.Contains(t, res.Errors[0].Message, tc.expectedError)
+			th.pvz.AssertNotCalled(t, "ListPVZ", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetRequest(t *testing.T) {
+	th := newTestHandler(Limits{MaxDepth: 6, MaxComplexity: 10000})
+	th.pvz.On("ListPVZ", "10", "1", "", "").Return([]*models.PVZ{{ID: "p1", City: "Москва"}}, nil)
+
+	query := url.Values{"query": {`{ pvzs { id } }`}}
+	req := httptest.NewRequest(http.MethodGet, "/graphql?"+query.Encode(), nil).WithContext(userContext("employee"))
+	rr := httptest.NewRecorder()
+	th.handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"data": {"pvzs": [{"id": "p1"}]}}`, rr.Body.String())
+}
@@ -0,0 +1,5 @@
+// Package grpc holds the protobuf definitions of the gRPC API. Regenerate the
+// code after changing api/proto with go generate.
+package grpc
+
+//go:generate protoc -I ../../api/proto --go_out=../.. --go_opt=module=avito-intern --go-grpc_out=../.. --go-grpc_opt=module=avito-intern pvz/v1/pvz.proto pvz/v1/reception.proto pvz/v1/product.proto
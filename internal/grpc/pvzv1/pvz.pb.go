@@ -0,0 +1,601 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        v5.29.3
+// source: pvz/v1/pvz.proto
+
+package pvzv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PVZ struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RegistrationDate *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=registration_date,json=registrationDate,proto3" json:"registration_date,omitempty"`
+	City             string                 `protobuf:"bytes,3,opt,name=city,proto3" json:"city,omitempty"`
+	// Incremented on every update. Unset in requests.
+	Version       *int32 `protobuf:"varint,4,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PVZ) Reset() {
+	*x = PVZ{}
+	mi := &file_pvz_v1_pvz_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PVZ) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PVZ) ProtoMessage() {}
+
+func (x *PVZ) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_v1_pvz_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PVZ.ProtoReflect.Descriptor instead.
+func (*PVZ) Descriptor() ([]byte, []int) {
+	return file_pvz_v1_pvz_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PVZ) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PVZ) GetRegistrationDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RegistrationDate
+	}
+	return nil
+}
+
+func (x *PVZ) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *PVZ) GetVersion() int32 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+// UpdatePVZRequest is the Protobuf body of PATCH /pvz/{pvzId}. Version is the
+// one the change is based on.
+type UpdatePVZRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	City          string                 `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePVZRequest) Reset() {
+	*x = UpdatePVZRequest{}
+	mi := &file_pvz_v1_pvz_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePVZRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePVZRequest) ProtoMessage() {}
+
+func (x *UpdatePVZRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_v1_pvz_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePVZRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePVZRequest) Descriptor() ([]byte, []int) {
+	return file_pvz_v1_pvz_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UpdatePVZRequest) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *UpdatePVZRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// PVZList is a page of pickup points, the Protobuf body of GET /pvz.
+type PVZList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*PVZ                 `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PVZList) Reset() {
+	*x = PVZList{}
+	mi := &file_pvz_v1_pvz_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PVZList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PVZList) ProtoMessage() {}
+
+func (x *PVZList) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_v1_pvz_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PVZList.ProtoReflect.Descriptor instead.
+func (*PVZList) Descriptor() ([]byte, []int) {
+	return file_pvz_v1_pvz_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PVZList) GetItems() []*PVZ {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ListPVZRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Page number starting from 1. Defaults to 1.
+	Page int32 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	// Page size from 1 to 30. Defaults to 10.
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Optional bounds of the registration date.
+	StartDate     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPVZRequest) Reset() {
+	*x = ListPVZRequest{}
+	mi := &file_pvz_v1_pvz_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPVZRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPVZRequest) ProtoMessage() {}
+
+func (x *ListPVZRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_v1_pvz_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPVZRequest.ProtoReflect.Descriptor instead.
+func (*ListPVZRequest) Descriptor() ([]byte, []int) {
+	return file_pvz_v1_pvz_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListPVZRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListPVZRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListPVZRequest) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *ListPVZRequest) GetEndDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+type ListPVZResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*PVZWithReceptions   `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPVZResponse) Reset() {
+	*x = ListPVZResponse{}
+	mi := &file_pvz_v1_pvz_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPVZResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPVZResponse) ProtoMessage() {}
+
+func (x *ListPVZResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_v1_pvz_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPVZResponse.ProtoReflect.Descriptor instead.
+func (*ListPVZResponse) Descriptor() ([]byte, []int) {
+	return file_pvz_v1_pvz_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListPVZResponse) GetItems() []*PVZWithReceptions {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type PVZWithReceptions struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Pvz           *PVZ                     `protobuf:"bytes,1,opt,name=pvz,proto3" json:"pvz,omitempty"`
+	Receptions    []*ReceptionWithProducts `protobuf:"bytes,2,rep,name=receptions,proto3" json:"receptions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PVZWithReceptions) Reset() {
+	*x = PVZWithReceptions{}
+	mi := &file_pvz_v1_pvz_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PVZWithReceptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PVZWithReceptions) ProtoMessage() {}
+
+func (x *PVZWithReceptions) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_v1_pvz_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PVZWithReceptions.ProtoReflect.Descriptor instead.
+func (*PVZWithReceptions) Descriptor() ([]byte, []int) {
+	return file_pvz_v1_pvz_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PVZWithReceptions) GetPvz() *PVZ {
+	if x != nil {
+		return x.Pvz
+	}
+	return nil
+}
+
+func (x *PVZWithReceptions) GetReceptions() []*ReceptionWithProducts {
+	if x != nil {
+		return x.Receptions
+	}
+	return nil
+}
+
+type ReceptionWithProducts struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reception     *Reception             `protobuf:"bytes,1,opt,name=reception,proto3" json:"reception,omitempty"`
+	Products      []*Product             `protobuf:"bytes,2,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceptionWithProducts) Reset() {
+	*x = ReceptionWithProducts{}
+	mi := &file_pvz_v1_pvz_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceptionWithProducts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceptionWithProducts) ProtoMessage() {}
+
+func (x *ReceptionWithProducts) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_v1_pvz_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceptionWithProducts.ProtoReflect.Descriptor instead.
+func (*ReceptionWithProducts) Descriptor() ([]byte, []int) {
+	return file_pvz_v1_pvz_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ReceptionWithProducts) GetReception() *Reception {
+	if x != nil {
+		return x.Reception
+	}
+	return nil
+}
+
+func (x *ReceptionWithProducts) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+// ReceptionWithProductsList is the Protobuf body of
+// GET /pvz/{pvzId}/receptions.
+type ReceptionWithProductsList struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Items         []*ReceptionWithProducts `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceptionWithProductsList) Reset() {
+	*x = ReceptionWithProductsList{}
+	mi := &file_pvz_v1_pvz_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceptionWithProductsList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceptionWithProductsList) ProtoMessage() {}
+
+func (x *ReceptionWithProductsList) ProtoReflect() protoreflect.Message {
+	mi := &file_pvz_v1_pvz_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceptionWithProductsList.ProtoReflect.Descriptor instead.
+func (*ReceptionWithProductsList) Descriptor() ([]byte, []int) {
+	return file_pvz_v1_pvz_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ReceptionWithProductsList) GetItems() []*ReceptionWithProducts {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+var File_pvz_v1_pvz_proto protoreflect.FileDescriptor
+
+var file_pvz_v1_pvz_proto_rawDesc = string([]byte{
+	0x0a, 0x10, 0x70, 0x76, 0x7a, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x76, 0x7a, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x06, 0x70, 0x76, 0x7a, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x14, 0x70, 0x76, 0x7a,
+	0x2f, 0x76, 0x31, 0x2f, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x16, 0x70, 0x76, 0x7a, 0x2f, 0x76, 0x31, 0x2f, 0x72, 0x65, 0x63, 0x65, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9d, 0x01, 0x0a, 0x03, 0x50, 0x56,
+	0x5a, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x47, 0x0a, 0x11, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x10, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x69,
+	0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x69, 0x74, 0x79, 0x12, 0x1d,
+	0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48,
+	0x00, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x0a, 0x0a,
+	0x08, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x40, 0x0a, 0x10, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x50, 0x56, 0x5a, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x63, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x69, 0x74,
+	0x79, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x2c, 0x0a, 0x07, 0x50,
+	0x56, 0x5a, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x76, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x56, 0x5a, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22, 0xac, 0x01, 0x0a, 0x0e, 0x4c, 0x69,
+	0x73, 0x74, 0x50, 0x56, 0x5a, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x70, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f,
+	0x64, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74,
+	0x65, 0x12, 0x35, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x07, 0x65, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x65, 0x22, 0x42, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74,
+	0x50, 0x56, 0x5a, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x05, 0x69,
+	0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x70, 0x76, 0x7a,
+	0x2e, 0x76, 0x31, 0x2e, 0x50, 0x56, 0x5a, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x63, 0x65, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x71, 0x0a, 0x11,
+	0x50, 0x56, 0x5a, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x63, 0x65, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x1d, 0x0a, 0x03, 0x70, 0x76, 0x7a, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b,
+	0x2e, 0x70, 0x76, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x56, 0x5a, 0x52, 0x03, 0x70, 0x76, 0x7a,
+	0x12, 0x3d, 0x0a, 0x0a, 0x72, 0x65, 0x63, 0x65, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x70, 0x76, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x63, 0x65, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x57, 0x69, 0x74, 0x68, 0x50, 0x72, 0x6f, 0x64, 0x75,
+	0x63, 0x74, 0x73, 0x52, 0x0a, 0x72, 0x65, 0x63, 0x65, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22,
+	0x75, 0x0a, 0x15, 0x52, 0x65, 0x63, 0x65, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x57, 0x69, 0x74, 0x68,
+	0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x73, 0x12, 0x2f, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x65,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x76,
+	0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63, 0x65, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09,
+	0x72, 0x65, 0x63, 0x65, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2b, 0x0a, 0x08, 0x70, 0x72, 0x6f,
+	0x64, 0x75, 0x63, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x76,
+	0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x52, 0x08, 0x70, 0x72,
+	0x6f, 0x64, 0x75, 0x63, 0x74, 0x73, 0x22, 0x50, 0x0a, 0x19, 0x52, 0x65, 0x63, 0x65, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x57, 0x69, 0x74, 0x68, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x73, 0x4c,
+	0x69, 0x73, 0x74, 0x12, 0x33, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x70, 0x76, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63, 0x65,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x57, 0x69, 0x74, 0x68, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74,
+	0x73, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x32, 0x48, 0x0a, 0x0a, 0x50, 0x56, 0x5a, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3a, 0x0a, 0x07, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x56,
+	0x5a, 0x12, 0x16, 0x2e, 0x70, 0x76, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50,
+	0x56, 0x5a, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x70, 0x76, 0x7a, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x56, 0x5a, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x42, 0x28, 0x5a, 0x26, 0x61, 0x76, 0x69, 0x74, 0x6f, 0x2d, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x6e, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x72, 0x70, 0x63,
+	0x2f, 0x70, 0x76, 0x7a, 0x76, 0x31, 0x3b, 0x70, 0x76, 0x7a, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_pvz_v1_pvz_proto_rawDescOnce sync.Once
+	file_pvz_v1_pvz_proto_rawDescData []byte
+)
+
+func file_pvz_v1_pvz_proto_rawDescGZIP() []byte {
+	file_pvz_v1_pvz_proto_rawDescOnce.Do(func() {
+		file_pvz_v1_pvz_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pvz_v1_pvz_proto_rawDesc), len(file_pvz_v1_pvz_proto_rawDesc)))
+	})
+	return file_pvz_v1_pvz_proto_rawDescData
+}
+
+var file_pvz_v1_pvz_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_pvz_v1_pvz_proto_goTypes = []any{
+	(*PVZ)(nil),                       // 0: pvz.v1.PVZ
+	(*UpdatePVZRequest)(nil),          // 1: pvz.v1.UpdatePVZRequest
+	(*PVZList)(nil),                   // 2: pvz.v1.PVZList
+	(*ListPVZRequest)(nil),            // 3: pvz.v1.ListPVZRequest
+	(*ListPVZResponse)(nil),           // 4: pvz.v1.ListPVZResponse
+	(*PVZWithReceptions)(nil),         // 5: pvz.v1.PVZWithReceptions
+	(*ReceptionWithProducts)(nil),     // 6: pvz.v1.ReceptionWithProducts
+	(*ReceptionWithProductsList)(nil), // 7: pvz.v1.ReceptionWithProductsList
+	(*timestamppb.Timestamp)(nil),     // 8: google.protobuf.Timestamp
+	(*Reception)(nil),                 // 9: pvz.v1.Reception
+	(*Product)(nil),                   // 10: pvz.v1.Product
+}
+var file_pvz_v1_pvz_proto_depIdxs = []int32{
+	8,  // 0: pvz.v1.PVZ.registration_date:type_name -> google.protobuf.Timestamp
+	0,  // 1: pvz.v1.PVZList.items:type_name -> pvz.v1.PVZ
+	8,  // 2: pvz.v1.ListPVZRequest.start_date:type_name -> google.protobuf.Timestamp
+	8,  // 3: pvz.v1.ListPVZRequest.end_date:type_name -> google.protobuf.Timestamp
+	5,  // 4: pvz.v1.ListPVZResponse.items:type_name -> pvz.v1.PVZWithReceptions
+	0,  // 5: pvz.v1.PVZWithReceptions.pvz:type_name -> pvz.v1.PVZ
+	6,  // 6: pvz.v1.PVZWithReceptions.receptions:type_name -> pvz.v1.ReceptionWithProducts
+	9,  // 7: pvz.v1.ReceptionWithProducts.reception:type_name -> pvz.v1.Reception
+	10, // 8: pvz.v1.ReceptionWithProducts.products:type_name -> pvz.v1.Product
+	6,  // 9: pvz.v1.ReceptionWithProductsList.items:type_name -> pvz.v1.ReceptionWithProducts
+	3,  // 10: pvz.v1.PVZService.ListPVZ:input_type -> pvz.v1.ListPVZRequest
+	4,  // 11: pvz.v1.PVZService.ListPVZ:output_type -> pvz.v1.ListPVZResponse
+	11, // [11:12] is the sub-list for method output_type
+	10, // [10:11] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_pvz_v1_pvz_proto_init() }
+func file_pvz_v1_pvz_proto_init() {
+	if File_pvz_v1_pvz_proto != nil {
+		return
+	}
+	file_pvz_v1_product_proto_init()
+	file_pvz_v1_reception_proto_init()
+	file_pvz_v1_pvz_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pvz_v1_pvz_proto_rawDesc), len(file_pvz_v1_pvz_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pvz_v1_pvz_proto_goTypes,
+		DependencyIndexes: file_pvz_v1_pvz_proto_depIdxs,
+		MessageInfos:      file_pvz_v1_pvz_proto_msgTypes,
+	}.Build()
+	File_pvz_v1_pvz_proto = out.File
+	file_pvz_v1_pvz_proto_goTypes = nil
+	file_pvz_v1_pvz_proto_depIdxs = nil
+}
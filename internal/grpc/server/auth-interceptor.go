@@ -0,0 +1,107 @@
+package server
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/middleware"
+	"avito-intern/internal/utils"
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthInterceptor authenticates calls with our access tokens sent as
+// "authorization: Bearer <token>" metadata. The user is stored in the context
+// under middleware.UserCtxKey, so role checks work as in the REST handlers.
+// Health checks and reflection are public.
+type AuthInterceptor struct {
+	sessions  middleware.SessionValidator
+	twoFactor middleware.TwoFactorPolicy
+}
+
+// NewAuthInterceptor creates the interceptor. sessions and twoFactor may be
+// nil to skip session revocation and two-factor checks.
+func NewAuthInterceptor(sessions middleware.SessionValidator, twoFactor middleware.TwoFactorPolicy) *AuthInterceptor {
+	return &AuthInterceptor{
+		sessions:  sessions,
+		twoFactor: twoFactor,
+	}
+}
+
+func (a *AuthInterceptor) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if isPublicMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *AuthInterceptor) Stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if isPublicMethod(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	ctx, err := a.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a *AuthInterceptor) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "Missing authorization metadata")
+	}
+	parts := strings.Split(values[0], " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return nil, status.Error(codes.Unauthenticated, "Invalid authorization metadata")
+	}
+
+	claims, err := utils.ParseJWT(parts[1])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Invalid token")
+	}
+	user, ok := middleware.UserFromClaims(claims)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Invalid token")
+	}
+	// Impersonated requests must be audited, which only the REST API does.
+	if user.ImpersonatedBy != "" {
+		return nil, status.Error(codes.PermissionDenied, "Impersonation sessions are not supported")
+	}
+	if a.sessions != nil {
+		if err = a.sessions.ValidateSession(user.ID, user.TokenVersion); err != nil {
+			if errors.Is(err, internalErrors.ErrTokenRevoked) || errors.Is(err, internalErrors.ErrAccountDeactivated) ||
+				errors.Is(err, internalErrors.ErrAccessDenied) {
+				return nil, status.Error(codes.Unauthenticated, "Invalid token")
+			}
+			return nil, status.Error(codes.Internal, "Internal server error")
+		}
+	}
+	if a.twoFactor != nil && a.twoFactor.IsRequired(user.Role) && !user.TwoFactor {
+		return nil, status.Error(codes.PermissionDenied, "Two-factor authentication required")
+	}
+	return context.WithValue(ctx, middleware.UserCtxKey, user), nil
+}
+
+func isPublicMethod(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.Health/") ||
+		strings.HasPrefix(method, "/grpc.reflection.")
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/middleware"
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errAccessDenied   = status.Error(codes.PermissionDenied, "Access denied")
+	errInvalidRequest = status.Error(codes.InvalidArgument, "Invalid request")
+)
+
+// statusError translates service errors into the gRPC counterparts of the
+// REST responses.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, internalErrors.ErrActiveReceptionExists):
+		return status.Error(codes.FailedPrecondition, "Active reception exists")
+	case errors.Is(err, internalErrors.ErrNoActiveReception):
+		return status.Error(codes.FailedPrecondition, "No active reception")
+	case errors.Is(err, internalErrors.ErrProductNotFound):
+		return status.Error(codes.FailedPrecondition, "No products in reception")
+	case errors.Is(err, internalErrors.ErrInvalidProductType):
+		return status.Error(codes.InvalidArgument, "Invalid product type")
+	case errors.Is(err, internalErrors.ErrPVZNotFound):
+		return status.Error(codes.NotFound, "PVZ not found")
+	case errors.Is(err, internalErrors.ErrInvalidID):
+		return status.Error(codes.InvalidArgument, "Invalid identifier")
+	default:
+		log.Printf("gRPC call failed: %v", err)
+		return status.Error(codes.Internal, "Internal server error")
+	}
+}
+
+func requireRole(ctx context.Context, roles ...string) error {
+	if err := middleware.RequireAnyRole(ctx, roles...); err != nil {
+		return errAccessDenied
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"avito-intern/internal/metrics"
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+func MetricsUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observe(info.FullMethod, start, err)
+	return resp, err
+}
+
+func MetricsStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observe(info.FullMethod, start, err)
+	return err
+}
+
+func observe(method string, start time.Time, err error) {
+	metrics.GRPCRequestCount.WithLabelValues(method, status.Code(err).String()).Inc()
+	metrics.GRPCResponseTime.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
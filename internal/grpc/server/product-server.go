@@ -0,0 +1,58 @@
+package server
+
+import (
+	"avito-intern/internal/api/dto/request/productDto"
+	"avito-intern/internal/grpc/pvzv1"
+	"avito-intern/internal/metrics"
+	"avito-intern/internal/models"
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type productServer struct {
+	pvzv1.UnimplementedProductServiceServer
+	service ProductService
+}
+
+func (s *productServer) AddProduct(ctx context.Context, req *pvzv1.AddProductRequest) (*pvzv1.Product, error) {
+	if err := requireRole(ctx, "employee"); err != nil {
+		return nil, err
+	}
+	if req.GetPvzId() == "" {
+		return nil, errInvalidRequest
+	}
+
+	product, err := s.service.AddProduct(&productDto.CreateProductRequest{
+		PvzID: req.GetPvzId(),
+		Type:  req.GetType(),
+	}, 0)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	metrics.ProductAddedCount.Inc()
+	return productToProto(product), nil
+}
+
+func (s *productServer) DeleteLastProduct(ctx context.Context, req *pvzv1.DeleteLastProductRequest) (*pvzv1.DeleteLastProductResponse, error) {
+	if err := requireRole(ctx, "employee"); err != nil {
+		return nil, err
+	}
+	if req.GetPvzId() == "" {
+		return nil, errInvalidRequest
+	}
+
+	if err := s.service.DeleteLastProduct(req.GetPvzId(), 0); err != nil {
+		return nil, statusError(err)
+	}
+	return &pvzv1.DeleteLastProductResponse{}, nil
+}
+
+func productToProto(product *models.Product) *pvzv1.Product {
+	return &pvzv1.Product{
+		Id:          product.ID,
+		DateTime:    timestamppb.New(product.DateTime),
+		Type:        product.Type,
+		ReceptionId: product.ReceptionID,
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"avito-intern/internal/grpc/pvzv1"
+	"context"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// dateLayout is the date format PVZService.ListPVZ accepts.
+const dateLayout = "2006-01-02T15:04:05"
+
+type pvzServer struct {
+	pvzv1.UnimplementedPVZServiceServer
+	pvzService       PVZService
+	receptionService ReceptionService
+	productService   ProductService
+}
+
+func (s *pvzServer) ListPVZ(ctx context.Context, req *pvzv1.ListPVZRequest) (*pvzv1.ListPVZResponse, error) {
+	if err := requireRole(ctx, "employee", "moderator"); err != nil {
+		return nil, err
+	}
+
+	pvzs, err := s.pvzService.ListPVZ(
+		strconv.Itoa(int(req.GetLimit())),
+		strconv.Itoa(int(req.GetPage())),
+		formatDate(req.GetStartDate()),
+		formatDate(req.GetEndDate()),
+	)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	pvzIDs := make([]string, 0, len(pvzs))
+	for _, pvz := range pvzs {
+		pvzIDs = append(pvzIDs, pvz.ID)
+	}
+	receptions, err := s.receptionService.ListReceptions(pvzIDs)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	receptionIDs := make([]string, 0, len(receptions))
+	for _, reception := range receptions {
+		receptionIDs = append(receptionIDs, reception.ID)
+	}
+	products, err := s.productService.ListProducts(receptionIDs)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	productsByReception := make(map[string][]*pvzv1.Product)
+	for _, product := range products {
+		productsByReception[product.ReceptionID] = append(productsByReception[product.ReceptionID], productToProto(product))
+	}
+	receptionsByPVZ := make(map[string][]*pvzv1.ReceptionWithProducts)
+	for _, reception := range receptions {
+		receptionsByPVZ[reception.PvzID] = append(receptionsByPVZ[reception.PvzID], &pvzv1.ReceptionWithProducts{
+			Reception: receptionToProto(reception),
+			Products:  productsByReception[reception.ID],
+		})
+	}
+
+	resp := &pvzv1.ListPVZResponse{Items: make([]*pvzv1.PVZWithReceptions, 0, len(pvzs))}
+	for _, pvz := range pvzs {
+		resp.Items = append(resp.Items, &pvzv1.PVZWithReceptions{
+			Pvz: &pvzv1.PVZ{
+				Id:               pvz.ID,
+				RegistrationDate: timestamppb.New(pvz.RegistrationDate),
+				City:             pvz.City,
+				Version:          proto.Int32(int32(pvz.Version)),
+			},
+			Receptions: receptionsByPVZ[pvz.ID],
+		})
+	}
+	return resp, nil
+}
+
+func formatDate(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	return ts.AsTime().UTC().Format(dateLayout)
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"avito-intern/internal/grpc/pvzv1"
+	"avito-intern/internal/metrics"
+	"avito-intern/internal/models"
+	"context"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type receptionServer struct {
+	pvzv1.UnimplementedReceptionServiceServer
+	service ReceptionService
+}
+
+func (s *receptionServer) CreateReception(ctx context.Context, req *pvzv1.CreateReceptionRequest) (*pvzv1.Reception, error) {
+	if err := requireRole(ctx, "employee"); err != nil {
+		return nil, err
+	}
+	if req.GetPvzId() == "" {
+		return nil, errInvalidRequest
+	}
+
+	reception := models.Reception{
+		PvzID:  req.GetPvzId(),
+		Status: "in_progress",
+	}
+	if err := s.service.CreateReception(&reception, 0); err != nil {
+		return nil, statusError(err)
+	}
+	metrics.ReceptionCreatedCount.Inc()
+	return receptionToProto(&reception), nil
+}
+
+func (s *receptionServer) CloseLastReception(ctx context.Context, req *pvzv1.CloseLastReceptionRequest) (*pvzv1.Reception, error) {
+	if err := requireRole(ctx, "employee"); err != nil {
+		return nil, err
+	}
+	if req.GetPvzId() == "" {
+		return nil, errInvalidRequest
+	}
+
+	reception, err := s.service.CloseLastReception(req.GetPvzId(), 0)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return receptionToProto(reception), nil
+}
+
+func receptionToProto(reception *models.Reception) *pvzv1.Reception {
+	return &pvzv1.Reception{
+		Id:       reception.ID,
+		DateTime: timestamppb.New(reception.DateTime),
+		PvzId:    reception.PvzID,
+		Status:   reception.Status,
+		Version:  proto.Int32(int32(reception.Version)),
+	}
+}
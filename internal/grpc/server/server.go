@@ -0,0 +1,60 @@
+package server
+
+import (
+	"avito-intern/internal/api/dto/request/productDto"
+	"avito-intern/internal/grpc/pvzv1"
+	"avito-intern/internal/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type PVZService interface {
+	ListPVZ(limitStr, pageStr, startDateStr, endDateStr string) ([]*models.PVZ, error)
+}
+
+type ReceptionService interface {
+	CreateReception(reception *models.Reception, receptionsVersion int) error
+	CloseLastReception(pvzID string, receptionsVersion int) (*models.Reception, error)
+	ListReceptions(pvzIDs []string) ([]*models.Reception, error)
+}
+
+type ProductService interface {
+	AddProduct(req *productDto.CreateProductRequest, receptionsVersion int) (*models.Product, error)
+	DeleteLastProduct(pvzID string, receptionsVersion int) error
+	ListProducts(receptionIDs []string) ([]*models.Product, error)
+}
+
+// New creates the gRPC server with the PVZ, reception and product services,
+// the standard health service and reflection. Calls are authenticated with
+// the same access tokens as the REST API.
+func New(
+	pvzService PVZService,
+	receptionService ReceptionService,
+	productService ProductService,
+	auth *AuthInterceptor,
+) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(MetricsUnaryInterceptor, auth.Unary),
+		grpc.ChainStreamInterceptor(MetricsStreamInterceptor, auth.Stream),
+	)
+
+	pvzv1.RegisterPVZServiceServer(srv, &pvzServer{
+		pvzService:       pvzService,
+		receptionService: receptionService,
+		productService:   productService,
+	})
+	pvzv1.RegisterReceptionServiceServer(srv, &receptionServer{service: receptionService})
+	pvzv1.RegisterProductServiceServer(srv, &productServer{service: productService})
+
+	healthServer := health.NewServer()
+	for name := range srv.GetServiceInfo() {
+		healthServer.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthpb.RegisterHealthServer(srv, healthServer)
+	reflection.Register(srv)
+
+	return srv
+}
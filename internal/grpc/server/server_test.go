@@ -0,0 +1,410 @@
+package server
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/productDto"
+	"avito-intern/internal/grpc/pvzv1"
+	"avito-intern/internal/models"
+	"avito-intern/internal/utils"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type mockPVZService struct {
+	mock.Mock
+}
+
+var _ PVZService = (*mockPVZService)(nil)
+
+func (m *mockPVZService) ListPVZ(limitStr, pageStr, startDateStr, endDateStr string) ([]*models.PVZ, error) {
+	args := m.Called(limitStr, pageStr, startDateStr, endDateStr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PVZ), args.Error(1)
+}
+
+type mockReceptionService struct {
+	mock.Mock
+}
+
+var _ ReceptionService = (*mockReceptionService)(nil)
+
+func (m *mockReceptionService) CreateReception(reception *models.Reception, receptionsVersion int) error {
+	args := m.Called(reception, receptionsVersion)
+	return args.Error(0)
+}
+
+func (m *mockReceptionService) CloseLastReception(pvzID string, receptionsVersion int) (*models.Reception, error) {
+	args := m.Called(pvzID, receptionsVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reception), args.Error(1)
+}
+
+func (m *mockReceptionService) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	args := m.Called(pvzIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reception), args.Error(1)
+}
+
+type mockProductService struct {
+	mock.Mock
+}
+
+var _ ProductService = (*mockProductService)(nil)
+
+func (m *mockProductService) AddProduct(req *productDto.CreateProductRequest, receptionsVersion int) (*models.Product, error) {
+	args := m.Called(req, receptionsVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *mockProductService) DeleteLastProduct(pvzID string, receptionsVersion int) error {
+	args := m.Called(pvzID, receptionsVersion)
+	return args.Error(0)
+}
+
+func (m *mockProductService) ListProducts(receptionIDs []string) ([]*models.Product, error) {
+	args := m.Called(receptionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
+type mockSessionValidator struct {
+	err error
+}
+
+func (m *mockSessionValidator) ValidateSession(userID string, tokenVersion int) error {
+	return m.err
+}
+
+type testServer struct {
+	conn       *grpc.ClientConn
+	pvz        *mockPVZService
+	receptions *mockReceptionService
+	products   *mockProductService
+}
+
+func startServer(t *testing.T, sessions *mockSessionValidator) *testServer {
+	ts := &testServer{
+		pvz:        new(mockPVZService),
+		receptions: new(mockReceptionService),
+		products:   new(mockProductService),
+	}
+	srv := New(ts.pvz, ts.receptions, ts.products, NewAuthInterceptor(sessions, nil))
+
+	listener := bufconn.Listen(1 << 20)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	ts.conn = conn
+	return ts
+}
+
+func withToken(t *testing.T, role string) context.Context {
+	token, err := utils.GenerateJWT("user-id", role, 0, false)
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestListPVZ(t *testing.T) {
+	ts := startServer(t, &mockSessionValidator{})
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	ts.pvz.On("ListPVZ", "5", "2", "2025-03-01T00:00:00", "").Return([]*models.PVZ{
+		{ID: "pvz-1", City: "Москва", RegistrationDate: now},
+		{ID: "pvz-2", City: "Казань", RegistrationDate: now},
+	}, nil)
+	ts.receptions.On("ListReceptions", []string{"pvz-1", "pvz-2"}).Return([]*models.Reception{
+		{ID: "reception-1", PvzID: "pvz-1", Status: "close", DateTime: now},
+	}, nil)
+	ts.products.On("ListProducts", []string{"reception-1"}).Return([]*models.Product{
+		{ID: "product-1", Type: "обувь", ReceptionID: "reception-1", DateTime: now},
+		{ID: "product-2", Type: "одежда", ReceptionID: "reception-1", DateTime: now},
+	}, nil)
+
+	resp, err := pvzv1.NewPVZServiceClient(ts.conn).ListPVZ(withToken(t, "moderator"), &pvzv1.ListPVZRequest{
+		Page:      2,
+		Limit:     5,
+		StartDate: timestamppb.New(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 2)
+	require.Equal(t, "pvz-1", resp.Items[0].Pvz.Id)
+	require.Equal(t, now, resp.Items[0].Pvz.RegistrationDate.AsTime())
+	require.Len(t, resp.Items[0].Receptions, 1)
+	require.Equal(t, "reception-1", resp.Items[0].Receptions[0].Reception.Id)
+	require.Len(t, resp.Items[0].Receptions[0].Products, 2)
+	require.Equal(t, "product-2", resp.Items[0].Receptions[0].Products[1].Id)
+	require.Empty(t, resp.Items[1].Receptions)
+	ts.pvz.AssertExpectations(t)
+	ts.receptions.AssertExpectations(t)
+	ts.products.AssertExpectations(t)
+}
+
+func TestListPVZ_ServiceError(t *testing.T) {
+	ts := startServer(t, &mockSessionValidator{})
+	ts.pvz.On("ListPVZ", "0", "0", "", "").Return(nil, errors.New("database error"))
+
+	_, err := pvzv1.NewPVZServiceClient(ts.conn).ListPVZ(withToken(t, "employee"), &pvzv1.ListPVZRequest{})
+
+	require.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestAuthentication(t *testing.T) {
+	tests := []struct {
+		name         string
+		ctx          func(t *testing.T) context.Context
+		sessionErr   error
+		expectedCode codes.Code
+	}{
+		{
+			name:         "Missing token",
+			ctx:          func(t *testing.T) context.Context { return context.Background() },
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name: "Invalid token",
+			ctx: func(t *testing.T) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer invalid")
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "Revoked session",
+			ctx:          func(t *testing.T) context.Context { return withToken(t, "employee") },
+			sessionErr:   internalErrors.ErrTokenRevoked,
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "Unknown user",
+			ctx:          func(t *testing.T) context.Context { return withToken(t, "employee") },
+			sessionErr:   internalErrors.ErrAccessDenied,
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name: "Impersonation token",
+			ctx: func(t *testing.T) context.Context {
+				token, err := utils.GenerateImpersonationJWT("user-id", "employee", 0, "admin-id", 0, time.Now().Add(time.Minute))
+				require.NoError(t, err)
+				return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "Wrong role",
+			ctx:          func(t *testing.T) context.Context { return withToken(t, "moderator") },
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := startServer(t, &mockSessionValidator{err: tt.sessionErr})
+
+			_, err := pvzv1.NewReceptionServiceClient(ts.conn).CreateReception(tt.ctx(t), &pvzv1.CreateReceptionRequest{PvzId: "pvz-1"})
+
+			require.Equal(t, tt.expectedCode, status.Code(err))
+			ts.receptions.AssertNotCalled(t, "CreateReception", mock.Anything)
+		})
+	}
+}
+
+func TestHealthIsPublic(t *testing.T) {
+	ts := startServer(t, &mockSessionValidator{})
+
+	resp, err := healthpb.NewHealthClient(ts.conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: pvzv1.PVZService_ServiceDesc.ServiceName,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+func TestReceptionService(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name         string
+		call         func(ctx context.Context, client pvzv1.ReceptionServiceClient) (*pvzv1.Reception, error)
+		setupMock    func(mock *mockReceptionService)
+		expectedCode codes.Code
+		expectedID   string
+	}{
+		{
+			name: "Reception created",
+			call: func(ctx context.Context, client pvzv1.ReceptionServiceClient) (*pvzv1.Reception, error) {
+				return client.CreateReception(ctx, &pvzv1.CreateReceptionRequest{PvzId: "pvz-1"})
+			},
+			setupMock: func(m *mockReceptionService) {
+				m.On("CreateReception", mock.MatchedBy(func(r *models.Reception) bool {
+					return r.PvzID == "pvz-1" && r.Status == "in_progress"
+				}), 0).Run(func(args mock.Arguments) {
+					args.Get(0).(*models.Reception).ID = "reception-1"
+				}).Return(nil)
+			},
+			expectedCode: codes.OK,
+			expectedID:   "reception-1",
+		},
+		{
+			name: "Active reception exists",
+			call: func(ctx context.Context, client pvzv1.ReceptionServiceClient) (*pvzv1.Reception, error) {
+				return client.CreateReception(ctx, &pvzv1.CreateReceptionRequest{PvzId: "pvz-1"})
+			},
+			setupMock: func(m *mockReceptionService) {
+				m.On("CreateReception", mock.Anything, 0).Return(internalErrors.ErrActiveReceptionExists)
+			},
+			expectedCode: codes.FailedPrecondition,
+		},
+		{
+			name: "Missing PVZ ID",
+			call: func(ctx context.Context, client pvzv1.ReceptionServiceClient) (*pvzv1.Reception, error) {
+				return client.CreateReception(ctx, &pvzv1.CreateReceptionRequest{})
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "Reception closed",
+			call: func(ctx context.Context, client pvzv1.ReceptionServiceClient) (*pvzv1.Reception, error) {
+				return client.CloseLastReception(ctx, &pvzv1.CloseLastReceptionRequest{PvzId: "pvz-1"})
+			},
+			setupMock: func(m *mockReceptionService) {
+				m.On("CloseLastReception", "pvz-1", 0).Return(&models.Reception{
+					ID: "reception-1", PvzID: "pvz-1", Status: "close", DateTime: now,
+				}, nil)
+			},
+			expectedCode: codes.OK,
+			expectedID:   "reception-1",
+		},
+		{
+			name: "No active reception",
+			call: func(ctx context.Context, client pvzv1.ReceptionServiceClient) (*pvzv1.Reception, error) {
+				return client.CloseLastReception(ctx, &pvzv1.CloseLastReceptionRequest{PvzId: "pvz-1"})
+			},
+			setupMock: func(m *mockReceptionService) {
+				m.On("CloseLastReception", "pvz-1", 0).Return(nil, internalErrors.ErrNoActiveReception)
+			},
+			expectedCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := startServer(t, &mockSessionValidator{})
+			if tt.setupMock != nil {
+				tt.setupMock(ts.receptions)
+			}
+
+			reception, err := tt.call(withToken(t, "employee"), pvzv1.NewReceptionServiceClient(ts.conn))
+
+			require.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode == codes.OK {
+				require.Equal(t, tt.expectedID, reception.Id)
+				require.Equal(t, "pvz-1", reception.PvzId)
+			}
+			ts.receptions.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProductService(t *testing.T) {
+	tests := []struct {
+		name         string
+		call         func(ctx context.Context, client pvzv1.ProductServiceClient) error
+		setupMock    func(mock *mockProductService)
+		expectedCode codes.Code
+	}{
+		{
+			name: "Product added",
+			call: func(ctx context.Context, client pvzv1.ProductServiceClient) error {
+				product, err := client.AddProduct(ctx, &pvzv1.AddProductRequest{PvzId: "pvz-1", Type: "обувь"})
+				if err == nil && product.Id != "product-1" {
+					return errors.New("unexpected product")
+				}
+				return err
+			},
+			setupMock: func(m *mockProductService) {
+				m.On("AddProduct", &productDto.CreateProductRequest{PvzID: "pvz-1", Type: "обувь"}, 0).Return(&models.Product{
+					ID: "product-1", Type: "обувь", ReceptionID: "reception-1", DateTime: time.Now(),
+				}, nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "Invalid product type",
+			call: func(ctx context.Context, client pvzv1.ProductServiceClient) error {
+				_, err := client.AddProduct(ctx, &pvzv1.AddProductRequest{PvzId: "pvz-1", Type: "мебель"})
+				return err
+			},
+			setupMock: func(m *mockProductService) {
+				m.On("AddProduct", mock.Anything, 0).Return(nil, internalErrors.ErrInvalidProductType)
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "Last product deleted",
+			call: func(ctx context.Context, client pvzv1.ProductServiceClient) error {
+				_, err := client.DeleteLastProduct(ctx, &pvzv1.DeleteLastProductRequest{PvzId: "pvz-1"})
+				return err
+			},
+			setupMock: func(m *mockProductService) {
+				m.On("DeleteLastProduct", "pvz-1", 0).Return(nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "No products in reception",
+			call: func(ctx context.Context, client pvzv1.ProductServiceClient) error {
+				_, err := client.DeleteLastProduct(ctx, &pvzv1.DeleteLastProductRequest{PvzId: "pvz-1"})
+				return err
+			},
+			setupMock: func(m *mockProductService) {
+				m.On("DeleteLastProduct", "pvz-1", 0).Return(internalErrors.ErrProductNotFound)
+			},
+			expectedCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := startServer(t, &mockSessionValidator{})
+			tt.setupMock(ts.products)
+
+			err := tt.call(withToken(t, "employee"), pvzv1.NewProductServiceClient(ts.conn))
+
+			require.Equal(t, tt.expectedCode, status.Code(err))
+			ts.products.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+// Package ingest takes the commands of the warehouse management system from
+// a message broker and carries them out with the reception and product
+// services, as the REST API would.
+package ingest
+
+import "context"
+
+// Message is a message received from the broker. Source tells where it came
+// from, like the subject or the topic and offset, and is kept with dead
+// letters.
+type Message struct {
+	Source string
+	Body   []byte
+}
+
+// Handler processes a message. A nil error acknowledges it.
+type Handler func(ctx context.Context, msg Message) error
+
+// Consumer receives messages from a broker. Consume passes them to handle
+// one at a time, in the order of the broker, until ctx is done, when it
+// returns nil. When handle fails or the connection is lost, Consume returns
+// the error; the unacknowledged message is delivered again once consuming
+// starts over.
+type Consumer interface {
+	Consume(ctx context.Context, handle Handler) error
+}
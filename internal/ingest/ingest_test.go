@@ -0,0 +1,563 @@
+package ingest
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/productDto"
+	"avito-intern/internal/models"
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type inboundMessage struct {
+	status   string
+	attempts int
+	claimed  bool
+}
+
+// mockInboundRepository keeps the claims like the table, without leases.
+type mockInboundRepository struct {
+	mu          sync.Mutex
+	messages    map[string]*inboundMessage
+	targets     map[string]string
+	deadLetters []*models.InboundDeadLetter
+	// markErr fails MarkInboundMessageProcessed, like a crash after the
+	// command was carried out.
+	markErr error
+}
+
+func newMockInboundRepository() *mockInboundRepository {
+	return &mockInboundRepository{messages: make(map[string]*inboundMessage), targets: make(map[string]string)}
+}
+
+func (m *mockInboundRepository) ClaimInboundMessage(id, _ string, _, _ time.Time) (int, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	message, ok := m.messages[id]
+	if !ok {
+		m.messages[id] = &inboundMessage{status: models.InboundMessageProcessing, attempts: 1, claimed: true}
+		return 1, true, nil
+	}
+	if message.status != models.InboundMessageProcessing || message.claimed {
+		return 0, false, nil
+	}
+	message.attempts++
+	message.claimed = true
+	return message.attempts, true, nil
+}
+
+func (m *mockInboundRepository) MarkInboundMessageProcessed(id string, _ time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.markErr != nil {
+		return m.markErr
+	}
+	m.messages[id].status = models.InboundMessageProcessed
+	m.messages[id].claimed = false
+	return nil
+}
+
+func (m *mockInboundRepository) ReleaseInboundMessage(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages[id].claimed = false
+	return nil
+}
+
+func (m *mockInboundRepository) CreateInboundDeadLetter(letter *models.InboundDeadLetter) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deadLetters = append(m.deadLetters, letter)
+	if letter.MessageID != "" {
+		m.messages[letter.MessageID] = &inboundMessage{status: models.InboundMessageDead, attempts: letter.Attempts}
+	}
+	return nil
+}
+
+func (m *mockInboundRepository) DeleteProcessedInboundMessages(time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockInboundRepository) SetInboundMessageTarget(id, target string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.targets[id] = target
+	return nil
+}
+
+func (m *mockInboundRepository) GetInboundMessageTarget(id string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.targets[id], nil
+}
+
+func (m *mockInboundRepository) status(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if message, ok := m.messages[id]; ok {
+		return message.status
+	}
+	return ""
+}
+
+type mockReceptionService struct {
+	mock.Mock
+}
+
+var _ ReceptionService = (*mockReceptionService)(nil)
+
+func (m *mockReceptionService) CreateReception(reception *models.Reception, receptionsVersion int) error {
+	args := m.Called(reception, receptionsVersion)
+	return args.Error(0)
+}
+
+func (m *mockReceptionService) CloseLastReception(pvzID string, receptionsVersion int) (*models.Reception, error) {
+	args := m.Called(pvzID, receptionsVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reception), args.Error(1)
+}
+
+func (m *mockReceptionService) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	args := m.Called(pvzIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reception), args.Error(1)
+}
+
+type mockProductService struct {
+	mock.Mock
+}
+
+var _ ProductService = (*mockProductService)(nil)
+
+func (m *mockProductService) AddProduct(req *productDto.CreateProductRequest, receptionsVersion int) (*models.Product, error) {
+	args := m.Called(req, receptionsVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *mockProductService) ListProducts(receptionIDs []string) ([]*models.Product, error) {
+	args := m.Called(receptionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
+// activeReception is the reception in progress at pvz-1.
+var activeReception = []*models.Reception{{ID: "reception-1", PvzID: "pvz-1", Status: "in_progress"}}
+
+func command(id, commandType string) []byte {
+	body, _ := json.Marshal(Command{ID: id, Type: commandType, PvzID: "pvz-1", ProductType: "обувь"})
+	return body
+}
+
+func TestIngestor_Handle(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          []byte
+		setupMocks    func(receptions *mockReceptionService, products *mockProductService)
+		expectedError bool
+		status        string
+		deadReason    string
+	}{
+		{
+			name: "Reception started",
+			body: command("message-1", CommandReceptionStarted),
+			setupMocks: func(receptions *mockReceptionService, products *mockProductService) {
+				receptions.On("CreateReception", &models.Reception{
+					ID:     targetID(Command{ID: "message-1"}),
+					PvzID:  "pvz-1",
+					Status: "in_progress",
+				}, 0).Return(nil)
+			},
+			status: models.InboundMessageProcessed,
+		},
+		{
+			name: "Item scanned",
+			body: command("message-1", CommandItemScanned),
+			setupMocks: func(receptions *mockReceptionService, products *mockProductService) {
+				products.On("AddProduct", &productDto.CreateProductRequest{
+					ID:    targetID(Command{ID: "message-1"}),
+					Type:  "обувь",
+					PvzID: "pvz-1",
+				}, 0).
+					Return(&models.Product{ID: "product-1"}, nil)
+			},
+			status: models.InboundMessageProcessed,
+		},
+		{
+			name: "Reception closed",
+			body: command("message-1", CommandReceptionClosed),
+			setupMocks: func(receptions *mockReceptionService, products *mockProductService) {
+				receptions.On("ListReceptions", []string{"pvz-1"}).Return(activeReception, nil)
+				receptions.On("CloseLastReception", "pvz-1", 0).Return(&models.Reception{ID: "reception-1"}, nil)
+			},
+			status: models.InboundMessageProcessed,
+		},
+		{
+			name:       "Invalid JSON",
+			body:       []byte("not json"),
+			deadReason: "invalid command",
+		},
+		{
+			name:       "Missing ID",
+			body:       command("", CommandReceptionStarted),
+			deadReason: "invalid command: id is required",
+		},
+		{
+			name:       "Unknown type",
+			body:       command("message-1", "reception.cancelled"),
+			status:     models.InboundMessageDead,
+			deadReason: `invalid command: unknown type "reception.cancelled"`,
+		},
+		{
+			name: "Rejected by the service",
+			body: command("message-1", CommandItemScanned),
+			setupMocks: func(receptions *mockReceptionService, products *mockProductService) {
+				products.On("AddProduct", mock.Anything, 0).Return(nil, internalErrors.ErrNoActiveReception)
+			},
+			status:     models.InboundMessageDead,
+			deadReason: internalErrors.ErrNoActiveReception.Error(),
+		},
+		{
+			name: "Database failure",
+			body: command("message-1", CommandReceptionClosed),
+			setupMocks: func(receptions *mockReceptionService, products *mockProductService) {
+				receptions.On("ListReceptions", []string{"pvz-1"}).Return(activeReception, nil)
+				receptions.On("CloseLastReception", "pvz-1", 0).Return(nil, sql.ErrConnDone)
+			},
+			expectedError: true,
+			status:        models.InboundMessageProcessing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockInboundRepository()
+			receptions := new(mockReceptionService)
+			products := new(mockProductService)
+			if tt.setupMocks != nil {
+				tt.setupMocks(receptions, products)
+			}
+			ingestor := NewIngestor(nil, repo, receptions, products, IngestorPolicy{})
+
+			err := ingestor.Handle(context.Background(), Message{Source: "pvz.commands", Body: tt.body})
+
+			if tt.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.status, repo.status("message-1"))
+			if tt.deadReason == "" {
+				assert.Empty(t, repo.deadLetters)
+			} else if assert.Len(t, repo.deadLetters, 1) {
+				assert.Contains(t, repo.deadLetters[0].Reason, tt.deadReason)
+				assert.Equal(t, "pvz.commands", repo.deadLetters[0].Source)
+				assert.Equal(t, string(tt.body), repo.deadLetters[0].Body)
+			}
+			receptions.AssertExpectations(t)
+			products.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIngestor_Handle_Duplicate(t *testing.T) {
+	repo := newMockInboundRepository()
+	receptions := new(mockReceptionService)
+	receptions.On("CreateReception", mock.Anything, 0).Return(nil).Once()
+	ingestor := NewIngestor(nil, repo, receptions, new(mockProductService), IngestorPolicy{})
+
+	msg := Message{Source: "pvz.commands", Body: command("message-1", CommandReceptionStarted)}
+	require.NoError(t, ingestor.Handle(context.Background(), msg))
+	require.NoError(t, ingestor.Handle(context.Background(), msg))
+
+	receptions.AssertExpectations(t)
+}
+
+func TestIngestor_Handle_MaxAttempts(t *testing.T) {
+	repo := newMockInboundRepository()
+	receptions := new(mockReceptionService)
+	receptions.On("ListReceptions", []string{"pvz-1"}).Return(activeReception, nil)
+	receptions.On("CloseLastReception", "pvz-1", 0).Return(nil, sql.ErrConnDone).Times(2)
+	ingestor := NewIngestor(nil, repo, receptions, new(mockProductService), IngestorPolicy{MaxAttempts: 2})
+
+	msg := Message{Source: "pvz.commands", Body: command("message-1", CommandReceptionClosed)}
+	require.Error(t, ingestor.Handle(context.Background(), msg), "the first failure is retried")
+	require.NoError(t, ingestor.Handle(context.Background(), msg), "the last attempt goes to the dead letters")
+
+	assert.Equal(t, models.InboundMessageDead, repo.status("message-1"))
+	if assert.Len(t, repo.deadLetters, 1) {
+		assert.Equal(t, 2, repo.deadLetters[0].Attempts)
+		assert.Equal(t, "message-1", repo.deadLetters[0].MessageID)
+	}
+	receptions.AssertExpectations(t)
+}
+
+func TestIngestor_Handle_Redelivered(t *testing.T) {
+	created := &models.Reception{ID: targetID(Command{ID: "message-1"}), PvzID: "pvz-1", Status: "in_progress"}
+	tests := []struct {
+		name        string
+		commandType string
+		setupMocks  func(receptions *mockReceptionService, products *mockProductService)
+	}{
+		{
+			name:        "Reception started",
+			commandType: CommandReceptionStarted,
+			setupMocks: func(receptions *mockReceptionService, products *mockProductService) {
+				receptions.On("CreateReception", mock.Anything, 0).Return(nil).Once()
+				receptions.On("ListReceptions", []string{"pvz-1"}).Return([]*models.Reception{created}, nil)
+			},
+		},
+		{
+			name:        "Item scanned",
+			commandType: CommandItemScanned,
+			setupMocks: func(receptions *mockReceptionService, products *mockProductService) {
+				products.On("AddProduct", mock.Anything, 0).Return(&models.Product{}, nil).Once()
+				receptions.On("ListReceptions", []string{"pvz-1"}).Return(activeReception, nil)
+				products.On("ListProducts", []string{"reception-1"}).
+					Return([]*models.Product{{ID: targetID(Command{ID: "message-1"})}}, nil)
+			},
+		},
+		{
+			name:        "Reception closed",
+			commandType: CommandReceptionClosed,
+			setupMocks: func(receptions *mockReceptionService, products *mockProductService) {
+				receptions.On("ListReceptions", []string{"pvz-1"}).Return(activeReception, nil).Once()
+				receptions.On("CloseLastReception", "pvz-1", 0).Return(&models.Reception{ID: "reception-1"}, nil).Once()
+				receptions.On("ListReceptions", []string{"pvz-1"}).Return([]*models.Reception{}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockInboundRepository()
+			receptions := new(mockReceptionService)
+			products := new(mockProductService)
+			tt.setupMocks(receptions, products)
+			ingestor := NewIngestor(nil, repo, receptions, products, IngestorPolicy{})
+			msg := Message{Source: "pvz.commands", Body: command("message-1", tt.commandType)}
+
+			repo.markErr = sql.ErrConnDone
+			require.Error(t, ingestor.Handle(context.Background(), msg))
+			// The lease of the crashed replica runs out.
+			require.NoError(t, repo.ReleaseInboundMessage("message-1"))
+			repo.markErr = nil
+			require.NoError(t, ingestor.Handle(context.Background(), msg))
+
+			assert.Equal(t, models.InboundMessageProcessed, repo.status("message-1"))
+			receptions.AssertExpectations(t)
+			products.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIngestor_Run(t *testing.T) {
+	repo := newMockInboundRepository()
+	receptions := new(mockReceptionService)
+	products := new(mockProductService)
+	receptions.On("CreateReception", mock.Anything, 0).Return(nil)
+	receptions.On("ListReceptions", []string{"pvz-1"}).Return(activeReception, nil)
+	products.On("ListProducts", []string{"reception-1"}).Return([]*models.Product{}, nil)
+	// The first attempt fails and the message is delivered again.
+	products.On("AddProduct", mock.Anything, 0).Return(nil, sql.ErrConnDone).Once()
+	products.On("AddProduct", mock.Anything, 0).Return(&models.Product{ID: "product-1"}, nil)
+	receptions.On("CloseLastReception", "pvz-1", 0).Return(&models.Reception{ID: "reception-1"}, nil)
+
+	consumer := NewMemoryConsumer()
+	ingestor := NewIngestor(consumer, repo, receptions, products, IngestorPolicy{RetryDelay: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ingestor.Run(ctx)
+		close(done)
+	}()
+
+	consumer.Send("memory", command("message-1", CommandReceptionStarted))
+	consumer.Send("memory", command("message-2", CommandItemScanned))
+	consumer.Send("memory", command("message-2", CommandItemScanned))
+	consumer.Send("memory", command("message-3", CommandReceptionClosed))
+	require.Eventually(t, func() bool { return consumer.Pending() == 0 }, 5*time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	for _, id := range []string{"message-1", "message-2", "message-3"} {
+		assert.Equal(t, models.InboundMessageProcessed, repo.status(id))
+	}
+	products.AssertNumberOfCalls(t, "AddProduct", 2)
+	receptions.AssertExpectations(t)
+}
+
+// fakeNATSServer accepts one client, sends it the messages of one
+// subscription and records what the client writes.
+func fakeNATSServer(t *testing.T, messages ...string) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	lines := make(chan string, 100)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			lines <- line
+			if line == "PING" {
+				fmt.Fprint(conn, "PONG\r\n")
+				for _, message := range messages {
+					fmt.Fprint(conn, message)
+				}
+			}
+		}
+	}()
+	return "nats://token@" + listener.Addr().String(), lines
+}
+
+func TestNATSConsumer_Consume(t *testing.T) {
+	body := `{"id":"message-1"}`
+	serverURL, lines := fakeNATSServer(t,
+		fmt.Sprintf("MSG pvz.commands 1 $JS.ACK.commands.1 %d\r\n%s\r\n", len(body), body),
+		"PING\r\n",
+		"MSG pvz.commands 1 4\r\nlast\r\n",
+	)
+	consumer := NewNATSConsumer(serverURL, "pvz.commands", "pvz-service")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var received []Message
+	err := consumer.Consume(ctx, func(ctx context.Context, msg Message) error {
+		received = append(received, msg)
+		if len(received) == 2 {
+			cancel()
+		}
+		return nil
+	})
+
+	require.NoError(t, err, "a cancelled consumer stops without an error")
+	require.Len(t, received, 2)
+	assert.Equal(t, Message{Source: "pvz.commands", Body: []byte(body)}, received[0])
+	assert.Equal(t, "last", string(received[1].Body))
+
+	connect := <-lines
+	assert.True(t, strings.HasPrefix(connect, "CONNECT "))
+	assert.Contains(t, connect, `"auth_token":"token"`)
+	assert.Equal(t, "SUB pvz.commands pvz-service 1", <-lines)
+	assert.Equal(t, "PING", <-lines)
+	assert.Equal(t, "PUB $JS.ACK.commands.1 0", <-lines, "the message is acknowledged once handled")
+	assert.Equal(t, "", <-lines)
+	assert.Equal(t, "PONG", <-lines)
+}
+
+func TestNATSConsumer_Consume_HandlerError(t *testing.T) {
+	serverURL, lines := fakeNATSServer(t, "MSG pvz.commands 1 $JS.ACK.commands.1 2\r\n{}\r\n")
+	consumer := NewNATSConsumer(serverURL, "pvz.commands", "")
+
+	err := consumer.Consume(context.Background(), func(ctx context.Context, msg Message) error {
+		return sql.ErrConnDone
+	})
+
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	<-lines
+	assert.Equal(t, "SUB pvz.commands 1", <-lines)
+	assert.Equal(t, "PING", <-lines)
+	_, open := <-lines
+	assert.False(t, open, "the message is not acknowledged")
+}
+
+func TestKafkaConsumer_Consume(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	var committed []kafkaOffset
+	polled := false
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", kafkaContentType)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/consumers/pvz-service":
+			var config map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&config))
+			assert.Equal(t, "binary", config["format"])
+			assert.Equal(t, "false", config["auto.commit.enable"])
+			fmt.Fprintf(w, `{"instance_id":"i1","base_uri":"%s/consumers/pvz-service/instances/i1"}`, server.URL)
+		case r.URL.Path == "/consumers/pvz-service/instances/i1/subscription":
+			var subscription map[string][]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&subscription))
+			assert.Equal(t, []string{"pvz.commands"}, subscription["topics"])
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/consumers/pvz-service/instances/i1/records":
+			assert.Equal(t, kafkaRecordsType, r.Header.Get("Accept"))
+			if polled {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			polled = true
+			// The values are base64 of {"id":"1"} and {"id":"2"}.
+			fmt.Fprint(w, `[{"topic":"pvz.commands","key":null,"value":"eyJpZCI6IjEifQ==","partition":0,"offset":7},`+
+				`{"topic":"pvz.commands","key":null,"value":"eyJpZCI6IjIifQ==","partition":1,"offset":3}]`)
+		case r.URL.Path == "/consumers/pvz-service/instances/i1/offsets":
+			var offsets map[string][]kafkaOffset
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&offsets))
+			committed = append(committed, offsets["offsets"]...)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	consumer := NewKafkaConsumer(server.URL, "pvz-service", "pvz.commands", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var received []Message
+	err := consumer.Consume(ctx, func(ctx context.Context, msg Message) error {
+		received = append(received, msg)
+		if len(received) == 2 {
+			return sql.ErrConnDone
+		}
+		return nil
+	})
+	cancel()
+
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Len(t, received, 2)
+	assert.Equal(t, Message{Source: "pvz.commands/0/7", Body: []byte(`{"id":"1"}`)}, received[0])
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []kafkaOffset{{Topic: "pvz.commands", Partition: 0, Offset: 7}}, committed,
+		"only the handled record is committed")
+	assert.Equal(t, "DELETE /consumers/pvz-service/instances/i1", calls[len(calls)-1],
+		"the instance is removed, so that a new one starts from the committed offset")
+}
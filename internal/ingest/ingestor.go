@@ -0,0 +1,359 @@
+package ingest
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/productDto"
+	"avito-intern/internal/metrics"
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Commands of the warehouse management system.
+const (
+	CommandReceptionStarted = "reception.started"
+	CommandItemScanned      = "item.scanned"
+	CommandReceptionClosed  = "reception.closed"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultLease       = time.Minute
+	defaultRetryDelay  = 5 * time.Second
+	cleanupInterval    = time.Hour
+)
+
+var errInvalidCommand = errors.New("invalid command")
+
+// targetNamespace derives the IDs of the receptions and products commands
+// create from the command IDs.
+var targetNamespace = uuid.MustParse("1741ff8c-fcbc-4d08-a39c-6f20ef2835e1")
+
+// Command is the JSON body of a message, like
+// {"id":"...","type":"item.scanned","pvzId":"...","productType":"обувь"}.
+// The sender chooses the ID; a command is carried out once per ID.
+type Command struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	PvzID       string `json:"pvzId"`
+	ProductType string `json:"productType,omitempty"`
+}
+
+type ReceptionService interface {
+	CreateReception(reception *models.Reception, receptionsVersion int) error
+	CloseLastReception(pvzID string, receptionsVersion int) (*models.Reception, error)
+	ListReceptions(pvzIDs []string) ([]*models.Reception, error)
+}
+
+type ProductService interface {
+	AddProduct(req *productDto.CreateProductRequest, receptionsVersion int) (*models.Product, error)
+	ListProducts(receptionIDs []string) ([]*models.Product, error)
+}
+
+// IngestorPolicy tunes the ingestor. Zero fields get the defaults; a zero
+// Retention remembers processed messages forever.
+type IngestorPolicy struct {
+	// MaxAttempts is how often a message that fails for other reasons than
+	// its content, like an unavailable database, is tried before it goes to
+	// the dead letters.
+	MaxAttempts int
+	// Lease is how long a message is reserved for the replica processing
+	// it. A redelivered message is skipped while another replica holds it.
+	Lease time.Duration
+	// RetryDelay is the wait before consuming again after a failure.
+	RetryDelay time.Duration
+	// Retention is how long the IDs of processed messages are kept to
+	// detect duplicates.
+	Retention time.Duration
+}
+
+// Ingestor carries out the commands a Consumer receives. Messages are
+// deduplicated by the command ID; poison messages, which no retry can
+// process, are kept as dead letters and acknowledged.
+//
+// A crash after a command is carried out but before its message is marked
+// processed leads to a retry. Commands are therefore keyed on their ID: the
+// reception or product a command creates gets an ID derived from it, and
+// the reception a command closes is recorded before. A retry first checks
+// whether the change was made.
+type Ingestor struct {
+	consumer    Consumer
+	repo        repository.InboundRepositoryInterface
+	receptions  ReceptionService
+	products    ProductService
+	policy      IngestorPolicy
+	now         func() time.Time
+	newID       func() string
+	lastCleanup time.Time
+}
+
+func NewIngestor(
+	consumer Consumer,
+	repo repository.InboundRepositoryInterface,
+	receptions ReceptionService,
+	products ProductService,
+	policy IngestorPolicy,
+) *Ingestor {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultMaxAttempts
+	}
+	if policy.Lease <= 0 {
+		policy.Lease = defaultLease
+	}
+	if policy.RetryDelay <= 0 {
+		policy.RetryDelay = defaultRetryDelay
+	}
+	return &Ingestor{
+		consumer:   consumer,
+		repo:       repo,
+		receptions: receptions,
+		products:   products,
+		policy:     policy,
+		now:        time.Now,
+		newID:      func() string { return uuid.New().String() },
+	}
+}
+
+// Run consumes messages until ctx is done. When the consumer fails, it
+// starts over after RetryDelay.
+func (i *Ingestor) Run(ctx context.Context) {
+	for {
+		err := i.consumer.Consume(ctx, i.Handle)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("Command consumer: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(i.policy.RetryDelay):
+		}
+	}
+}
+
+// Handle processes a message. A message whose command was processed before
+// is acknowledged without effect. Messages that cannot be decoded or that
+// the services reject go to the dead letters, as do those that failed
+// MaxAttempts times; other failures release the message and return the
+// error, so that the broker delivers it again.
+func (i *Ingestor) Handle(ctx context.Context, msg Message) error {
+	i.cleanup()
+
+	var command Command
+	if err := json.Unmarshal(msg.Body, &command); err != nil {
+		return i.deadLetter(msg, command, 0, fmt.Errorf("%w: %w", errInvalidCommand, err))
+	}
+	if command.ID == "" {
+		return i.deadLetter(msg, command, 0, fmt.Errorf("%w: id is required", errInvalidCommand))
+	}
+	now := i.now()
+	attempts, claimed, err := i.repo.ClaimInboundMessage(command.ID, command.Type, now, now.Add(-i.policy.Lease))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		metrics.InboundMessageCount.WithLabelValues(metricType(command.Type), "duplicate").Inc()
+		return nil
+	}
+
+	err = i.execute(command, attempts)
+	switch {
+	case err == nil:
+		metrics.InboundMessageCount.WithLabelValues(metricType(command.Type), "processed").Inc()
+		return i.repo.MarkInboundMessageProcessed(command.ID, i.now())
+	case rejected(err) || attempts >= i.policy.MaxAttempts:
+		return i.deadLetter(msg, command, attempts, err)
+	default:
+		metrics.InboundMessageCount.WithLabelValues(metricType(command.Type), "failed").Inc()
+		if releaseErr := i.repo.ReleaseInboundMessage(command.ID); releaseErr != nil {
+			log.Printf("Command consumer: could not release %s: %v", command.ID, releaseErr)
+		}
+		return fmt.Errorf("command %s: %w", command.ID, err)
+	}
+}
+
+// execute carries out the command like the REST API does for an employee
+// of the pickup point, unless an earlier attempt did.
+func (i *Ingestor) execute(command Command, attempts int) error {
+	if command.PvzID == "" {
+		return fmt.Errorf("%w: pvzId is required", errInvalidCommand)
+	}
+	if attempts > 1 {
+		done, err := i.applied(command)
+		if err != nil || done {
+			return err
+		}
+	}
+	switch command.Type {
+	case CommandReceptionStarted:
+		reception := models.Reception{
+			ID:     targetID(command),
+			PvzID:  command.PvzID,
+			Status: "in_progress",
+		}
+		if err := i.receptions.CreateReception(&reception, 0); err != nil {
+			return err
+		}
+		metrics.ReceptionCreatedCount.Inc()
+	case CommandItemScanned:
+		_, err := i.products.AddProduct(&productDto.CreateProductRequest{
+			ID:    targetID(command),
+			Type:  command.ProductType,
+			PvzID: command.PvzID,
+		}, 0)
+		if err != nil {
+			return err
+		}
+		metrics.ProductAddedCount.Inc()
+	case CommandReceptionClosed:
+		active, err := i.activeReception(command.PvzID)
+		if err != nil {
+			return err
+		}
+		if err = i.repo.SetInboundMessageTarget(command.ID, active.ID); err != nil {
+			return err
+		}
+		if _, err = i.receptions.CloseLastReception(command.PvzID, 0); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", errInvalidCommand, command.Type)
+	}
+	return nil
+}
+
+// applied reports whether an earlier attempt carried out the command.
+func (i *Ingestor) applied(command Command) (bool, error) {
+	switch command.Type {
+	case CommandReceptionStarted:
+		receptions, err := i.receptions.ListReceptions([]string{command.PvzID})
+		if err != nil {
+			return false, err
+		}
+		return slices.ContainsFunc(receptions, func(r *models.Reception) bool { return r.ID == targetID(command) }), nil
+	case CommandItemScanned:
+		receptions, err := i.receptions.ListReceptions([]string{command.PvzID})
+		if err != nil {
+			return false, err
+		}
+		receptionIDs := make([]string, len(receptions))
+		for n, reception := range receptions {
+			receptionIDs[n] = reception.ID
+		}
+		products, err := i.products.ListProducts(receptionIDs)
+		if err != nil {
+			return false, err
+		}
+		return slices.ContainsFunc(products, func(p *models.Product) bool { return p.ID == targetID(command) }), nil
+	case CommandReceptionClosed:
+		target, err := i.repo.GetInboundMessageTarget(command.ID)
+		if err != nil || target == "" {
+			return false, err
+		}
+		active, err := i.activeReception(command.PvzID)
+		if errors.Is(err, internalErrors.ErrNoActiveReception) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return active.ID != target, nil
+	default:
+		return false, nil
+	}
+}
+
+// activeReception returns the reception of the pickup point in progress.
+func (i *Ingestor) activeReception(pvzID string) (*models.Reception, error) {
+	receptions, err := i.receptions.ListReceptions([]string{pvzID})
+	if err != nil {
+		return nil, err
+	}
+	for _, reception := range receptions {
+		if reception.Status == "in_progress" {
+			return reception, nil
+		}
+	}
+	return nil, internalErrors.ErrNoActiveReception
+}
+
+// targetID is the ID of the reception or product the command creates.
+func targetID(command Command) string {
+	return uuid.NewSHA1(targetNamespace, []byte(command.ID)).String()
+}
+
+// deadLetter keeps the message with the reason it failed.
+func (i *Ingestor) deadLetter(msg Message, command Command, attempts int, reason error) error {
+	letter := &models.InboundDeadLetter{
+		ID:          i.newID(),
+		MessageID:   command.ID,
+		MessageType: command.Type,
+		Source:      msg.Source,
+		// Postgres text takes neither NUL nor invalid UTF-8.
+		Body:      strings.ToValidUTF8(strings.ReplaceAll(string(msg.Body), "\x00", ""), "\uFFFD"),
+		Reason:    reason.Error(),
+		Attempts:  attempts,
+		CreatedAt: i.now(),
+	}
+	if err := i.repo.CreateInboundDeadLetter(letter); err != nil {
+		return err
+	}
+	metrics.InboundMessageCount.WithLabelValues(metricType(command.Type), "dead").Inc()
+	log.Printf("Command consumer: message %q from %s sent to the dead letters: %v", command.ID, msg.Source, reason)
+	return nil
+}
+
+// cleanup forgets messages processed longer than Retention ago, at most
+// once per hour.
+func (i *Ingestor) cleanup() {
+	now := i.now()
+	if i.policy.Retention <= 0 || now.Sub(i.lastCleanup) < cleanupInterval {
+		return
+	}
+	i.lastCleanup = now
+	if _, err := i.repo.DeleteProcessedInboundMessages(now.Add(-i.policy.Retention)); err != nil {
+		log.Printf("Command consumer: could not delete processed messages: %v", err)
+	}
+}
+
+// rejected tells errors that fail the command however often it is tried
+// from failures of the database.
+func rejected(err error) bool {
+	for _, target := range []error{
+		errInvalidCommand,
+		internalErrors.ErrActiveReceptionExists,
+		internalErrors.ErrNoActiveReception,
+		internalErrors.ErrInvalidProductType,
+		internalErrors.ErrPVZNotFound,
+		internalErrors.ErrInvalidID,
+		internalErrors.ErrAlreadyExists,
+		internalErrors.ErrReferenceNotFound,
+		internalErrors.ErrConstraintViolation,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// metricType keeps the type label to the known commands.
+func metricType(commandType string) string {
+	switch commandType {
+	case CommandReceptionStarted, CommandItemScanned, CommandReceptionClosed:
+		return commandType
+	default:
+		return "unknown"
+	}
+}
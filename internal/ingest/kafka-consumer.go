@@ -0,0 +1,171 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	kafkaContentType    = "application/vnd.kafka.v2+json"
+	kafkaRecordsType    = "application/vnd.kafka.binary.v2+json"
+	defaultKafkaTimeout = 30 * time.Second
+	kafkaPollInterval   = time.Second
+)
+
+// KafkaConsumer reads a Kafka topic through the REST proxy API v2, as
+// served by Confluent REST Proxy and Redpanda. Replicas of the service
+// share the partitions as members of one consumer group. The offset of a
+// record is committed once it is handled; after a failure a new consumer
+// instance starts from the last committed offset.
+type KafkaConsumer struct {
+	proxyURL string
+	group    string
+	topic    string
+	client   *http.Client
+}
+
+// NewKafkaConsumer creates a consumer of topic in group through the proxy
+// at proxyURL. A nil client gets a 30 second timeout.
+func NewKafkaConsumer(proxyURL, group, topic string, client *http.Client) *KafkaConsumer {
+	if client == nil {
+		client = &http.Client{Timeout: defaultKafkaTimeout}
+	}
+	return &KafkaConsumer{
+		proxyURL: strings.TrimSuffix(proxyURL, "/"),
+		group:    group,
+		topic:    topic,
+		client:   client,
+	}
+}
+
+type kafkaInstance struct {
+	InstanceID string `json:"instance_id"`
+	BaseURI    string `json:"base_uri"`
+}
+
+type kafkaConsumedRecord struct {
+	Topic     string `json:"topic"`
+	Value     []byte `json:"value"`
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+type kafkaOffset struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+func (c *KafkaConsumer) Consume(ctx context.Context, handle Handler) error {
+	var instance kafkaInstance
+	err := c.call(ctx, http.MethodPost, c.proxyURL+"/consumers/"+url.PathEscape(c.group), map[string]string{
+		"format":             "binary",
+		"auto.offset.reset":  "earliest",
+		"auto.commit.enable": "false",
+	}, &instance)
+	if err != nil {
+		return c.stopped(ctx, err)
+	}
+	defer func() {
+		// The instance is removed even when ctx is done, so that its
+		// partitions go to the other members right away.
+		ctx, cancel := context.WithTimeout(context.Background(), defaultKafkaTimeout)
+		defer cancel()
+		_ = c.call(ctx, http.MethodDelete, instance.BaseURI, nil, nil)
+	}()
+
+	err = c.call(ctx, http.MethodPost, instance.BaseURI+"/subscription", map[string][]string{"topics": {c.topic}}, nil)
+	if err != nil {
+		return c.stopped(ctx, err)
+	}
+	for {
+		var records []kafkaConsumedRecord
+		if err = c.call(ctx, http.MethodGet, instance.BaseURI+"/records", nil, &records); err != nil {
+			return c.stopped(ctx, err)
+		}
+		for _, record := range records {
+			msg := Message{
+				Source: fmt.Sprintf("%s/%d/%d", record.Topic, record.Partition, record.Offset),
+				Body:   record.Value,
+			}
+			if err = handle(ctx, msg); err != nil {
+				return err
+			}
+			offsets := map[string][]kafkaOffset{
+				"offsets": {{Topic: record.Topic, Partition: record.Partition, Offset: record.Offset}},
+			}
+			if err = c.call(ctx, http.MethodPost, instance.BaseURI+"/offsets", offsets, nil); err != nil {
+				return c.stopped(ctx, err)
+			}
+		}
+		if len(records) > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(kafkaPollInterval):
+		}
+	}
+}
+
+// stopped returns nil for the error of a request cancelled because ctx is
+// done.
+func (c *KafkaConsumer) stopped(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// call sends body as JSON to the proxy and decodes the response into out,
+// when not nil.
+func (c *KafkaConsumer) call(ctx context.Context, method, endpoint string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", kafkaContentType)
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Accept", kafkaRecordsType)
+	} else {
+		req.Header.Set("Accept", kafkaContentType)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s answered %d: %s", endpoint, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	if out == nil {
+		return nil
+	}
+	if err = json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("invalid response of the Kafka REST proxy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package ingest
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryConsumer is a Consumer of messages sent in the same process, for
+// tests and local runs.
+type MemoryConsumer struct {
+	mu    sync.Mutex
+	queue []Message
+	ready chan struct{}
+}
+
+func NewMemoryConsumer() *MemoryConsumer {
+	return &MemoryConsumer{ready: make(chan struct{}, 1)}
+}
+
+// Send queues a message.
+func (c *MemoryConsumer) Send(source string, body []byte) {
+	c.mu.Lock()
+	c.queue = append(c.queue, Message{Source: source, Body: body})
+	c.mu.Unlock()
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Pending returns the number of messages not acknowledged yet.
+func (c *MemoryConsumer) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.queue)
+}
+
+// Consume passes the queued messages to handle. A message stays first in
+// the queue until handle succeeds.
+func (c *MemoryConsumer) Consume(ctx context.Context, handle Handler) error {
+	for {
+		c.mu.Lock()
+		if len(c.queue) == 0 {
+			c.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-c.ready:
+				continue
+			}
+		}
+		msg := c.queue[0]
+		c.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := handle(ctx, msg); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.queue = c.queue[1:]
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileMailer appends messages to a local file, which is handy for inspecting
+// mail in development and tests.
+type FileMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileMailer(path string) *FileMailer {
+	return &FileMailer{path: path}
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC1123Z), msg.To, msg.Subject, msg.Body)
+	return err
+}
@@ -0,0 +1,12 @@
+package mailer
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers plain-text messages to users.
+type Mailer interface {
+	Send(msg Message) error
+}
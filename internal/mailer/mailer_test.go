@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer_Send(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	m := NewFileMailer(path)
+
+	require.NoError(t, m.Send(Message{To: "a@example.com", Subject: "First", Body: "hello"}))
+	require.NoError(t, m.Send(Message{To: "b@example.com", Subject: "Second", Body: "world"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "To: a@example.com\nSubject: First\n\nhello")
+	assert.Contains(t, content, "To: b@example.com\nSubject: Second\n\nworld")
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go serveSMTP(listener, received)
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	m := NewSMTPMailer(host, port, "", "", "noreply@example.com")
+
+	require.NoError(t, m.Send(Message{To: "user@example.com", Subject: "Reset", Body: "line1\nline2"}))
+
+	data := <-received
+	assert.Contains(t, data, "From: noreply@example.com\r\n")
+	assert.Contains(t, data, "To: user@example.com\r\n")
+	assert.Contains(t, data, "Subject: Reset\r\n")
+	assert.Contains(t, data, "line1\r\nline2")
+}
+
+// serveSMTP is a minimal SMTP server that accepts a single message.
+func serveSMTP(listener net.Listener, received chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	write := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	write("220 localhost ESMTP")
+	var data strings.Builder
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if inData {
+			if line == ".\r\n" {
+				inData = false
+				received <- data.String()
+				write("250 OK")
+				continue
+			}
+			data.WriteString(line)
+			continue
+		}
+		switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			write("250 localhost")
+		case cmd == "DATA":
+			inData = true
+			write("354 End data with <CR><LF>.<CR><LF>")
+		case cmd == "QUIT":
+			write("221 Bye")
+			return
+		default:
+			write("250 OK")
+		}
+	}
+}
@@ -23,6 +23,31 @@ var (
 		[]string{"method", "path"},
 	)
 
+	VersionRequestCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pvz_http_version_requests_total",
+			Help: "Total number of HTTP requests by API version",
+		},
+		[]string{"version", "method", "status"},
+	)
+
+	GRPCRequestCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pvz_grpc_requests_total",
+			Help: "Total number of gRPC requests",
+		},
+		[]string{"method", "code"},
+	)
+
+	GRPCResponseTime = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "pvz_grpc_response_time_seconds",
+			Help:    "gRPC response time in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method"},
+	)
+
 	PvzCreatedCount = promauto.NewCounter(
 		prometheus.CounterOpts{
 			Name: "pvz_created_total",
@@ -43,4 +68,66 @@ var (
 			Help: "Total number of added products",
 		},
 	)
+
+	LoginFailedCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_failed_total",
+			Help: "Total number of rejected login attempts",
+		},
+		[]string{"reason"},
+	)
+
+	AccountLockoutCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_lockout_total",
+			Help: "Total number of temporary login lockouts",
+		},
+		[]string{"scope"},
+	)
+
+	OutboxPublishedCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_published_total",
+			Help: "Total number of domain events published from the outbox",
+		},
+		[]string{"type"},
+	)
+
+	OutboxPublishFailedCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_publish_failed_total",
+			Help: "Total number of failed attempts to publish a domain event",
+		},
+		[]string{"type"},
+	)
+
+	WebhookDeliveryCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Total number of attempts to deliver a webhook by result: delivered, failed or dead",
+		},
+		[]string{"result"},
+	)
+
+	InboundMessageCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "inbound_messages_total",
+			Help: "Total number of commands consumed from the broker by result: processed, duplicate, failed or dead",
+		},
+		[]string{"type", "result"},
+	)
+
+	StreamSubscribers = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "stream_subscribers",
+			Help: "Number of live subscribers to the event streams of pickup points",
+		},
+	)
+
+	StreamSubscribersDroppedCount = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "stream_subscribers_dropped_total",
+			Help: "Total number of stream subscribers dropped for falling behind or missed events",
+		},
+	)
 )
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+const (
+	ScopePVZRead         = "pvz:read"
+	ScopePVZWrite        = "pvz:write"
+	ScopeReceptionsWrite = "receptions:write"
+	ScopeProductsWrite   = "products:write"
+)
+
+type APIKey struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	KeyPrefix        string     `json:"keyPrefix"`
+	KeyHash          string     `json:"-"`
+	SigningSecret    string     `json:"-"`
+	Role             string     `json:"role"`
+	Scopes           []string   `json:"scopes"`
+	RequireSignature bool       `json:"requireSignature"`
+	CreatedBy        string     `json:"createdBy"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt       *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+}
+
+// ServicePrincipal is the caller of a request authenticated with an API key.
+type ServicePrincipal struct {
+	KeyID  string
+	Name   string
+	Role   string
+	Scopes []string
+}
+
+func (p ServicePrincipal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// SignedRequest holds the parts of an HMAC-signed request that take part in
+// the signature.
+type SignedRequest struct {
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Method    string
+	URI       string
+	Body      []byte
+}
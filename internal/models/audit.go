@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+const (
+	AuditImpersonationStart   = "impersonation.start"
+	AuditImpersonationRequest = "impersonation.request"
+	AuditImpersonationBlocked = "impersonation.blocked"
+)
+
+type AuditEvent struct {
+	ID        string    `json:"id"`
+	ActorID   string    `json:"actorId"`
+	UserID    string    `json:"userId,omitempty"`
+	Action    string    `json:"action"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
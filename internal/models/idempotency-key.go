@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// IdempotencyKey is a request made with an Idempotency-Key header and, once
+// it has completed, the response to replay for its retries. StatusCode is
+// zero while the request is in progress.
+type IdempotencyKey struct {
+	Scope           string
+	Key             string
+	RequestHash     string
+	StatusCode      int
+	ResponseHeaders map[string][]string
+	ResponseBody    []byte
+	CreatedAt       time.Time
+	ExpiresAt       time.Time
+}
+
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
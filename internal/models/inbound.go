@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Statuses of an inbound message. A processing message is claimed by the
+// consumer handling it; the claim lapses if the consumer fails or stops.
+const (
+	InboundMessageProcessing = "processing"
+	InboundMessageProcessed  = "processed"
+	InboundMessageDead       = "dead"
+)
+
+// InboundDeadLetter is a message from the broker that could not be
+// processed, kept for investigation. MessageID and MessageType are empty
+// when the message could not be decoded.
+type InboundDeadLetter struct {
+	ID          string    `json:"id"`
+	MessageID   string    `json:"messageId"`
+	MessageType string    `json:"messageType"`
+	Source      string    `json:"source"`
+	Body        string    `json:"body"`
+	Reason      string    `json:"reason"`
+	Attempts    int       `json:"attempts"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
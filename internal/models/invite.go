@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Invite struct {
+	ID        string     `json:"id,omitempty"`
+	TokenHash string     `json:"-"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	CreatedBy string     `json:"createdBy"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+}
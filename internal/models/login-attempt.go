@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type LoginAttempt struct {
+	Key           string     `json:"key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"lastFailureAt"`
+	LockedUntil   *time.Time `json:"lockedUntil,omitempty"`
+}
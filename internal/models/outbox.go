@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of the domain events written to the outbox.
+const (
+	EventPVZCreated      = "pvz.created"
+	EventReceptionOpened = "reception.opened"
+	EventReceptionClosed = "reception.closed"
+	EventProductAdded    = "product.added"
+	EventProductRemoved  = "product.removed"
+)
+
+// OutboxEvent is a domain event, written in the transaction of the change
+// it describes and published afterwards by the outbox relay. Sequence
+// orders the events; the fields without JSON names are relay state and not
+// published.
+type OutboxEvent struct {
+	Sequence   int64           `json:"sequence"`
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	PvzID      string          `json:"pvzId"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Attempts   int             `json:"-"`
+	LastError  string          `json:"-"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type PasswordResetToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"userId"`
+	TokenHash string     `json:"-"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+}
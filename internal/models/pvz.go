@@ -6,4 +6,8 @@ type PVZ struct {
 	ID               string    `json:"id,omitempty"`
 	RegistrationDate time.Time `json:"registrationDate,omitempty"`
 	City             string    `json:"city"`
+	Version          int       `json:"version"`
+	// ReceptionsVersion is incremented with every change of the receptions
+	// and products of the pickup point.
+	ReceptionsVersion int `json:"-"`
 }
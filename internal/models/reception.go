@@ -7,4 +7,11 @@ type Reception struct {
 	DateTime time.Time `json:"dateTime"`
 	PvzID    string    `json:"pvzId"`
 	Status   string    `json:"status"`
+	Version  int       `json:"version"`
+}
+
+// ReceptionWithProducts is a reception together with its products.
+type ReceptionWithProducts struct {
+	Reception *Reception `json:"reception"`
+	Products  []*Product `json:"products"`
 }
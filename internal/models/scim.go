@@ -0,0 +1,14 @@
+package models
+
+// SCIMUser is a user together with the PVZs they are assigned to.
+type SCIMUser struct {
+	User   *User
+	PVZIDs []string
+}
+
+// SCIMGroup is either a role or a PVZ. Members are user IDs.
+type SCIMGroup struct {
+	ID          string
+	DisplayName string
+	Members     []string
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type TOTPSecret struct {
+	UserID       string    `json:"userId"`
+	Secret       string    `json:"-"`
+	Confirmed    bool      `json:"confirmed"`
+	LastUsedStep int64     `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
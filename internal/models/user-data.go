@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	AuditUserExported = "user.exported"
+	AuditUserErased   = "user.erased"
+)
+
+// UserDataExport is everything stored about a user, as handed out on a
+// data access request. Secrets and token hashes are omitted by their JSON
+// tags.
+type UserDataExport struct {
+	ExportedAt      time.Time             `json:"exportedAt"`
+	Account         *User                 `json:"account"`
+	TwoFactor       *TOTPSecret           `json:"twoFactor,omitempty"`
+	LoginAttempts   []*LoginAttempt       `json:"loginAttempts"`
+	PasswordResets  []*PasswordResetToken `json:"passwordResets"`
+	InvitesCreated  []*Invite             `json:"invitesCreated"`
+	InvitesReceived []*Invite             `json:"invitesReceived"`
+	APIKeys         []*APIKey             `json:"apiKeys"`
+	PVZIDs          []string              `json:"pvzIds"`
+	AuditEvents     []*AuditEvent         `json:"auditEvents"`
+}
+
+// UserErasure describes how a user row is anonymized. The row and its ID
+// stay so that audit records and keys created by the user keep pointing at
+// it.
+type UserErasure struct {
+	UserID         string
+	Email          string
+	AttemptKey     string
+	PseudonymEmail string
+	PasswordHash   string
+	ErasedAt       time.Time
+	// Audit is the audit record of the erasure, written with it.
+	Audit *AuditEvent
+}
@@ -1,8 +1,15 @@
 package models
 
 type User struct {
-	ID       string `json:"id,omitempty"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
-	Password string `json:"-"`
+	ID             string `json:"id,omitempty"`
+	Email          string `json:"email"`
+	Role           string `json:"role"`
+	Status         string `json:"status,omitempty"`
+	Password       string `json:"-"`
+	TokenVersion   int    `json:"-"`
+	TwoFactor      bool   `json:"-"`
+	ImpersonatedBy string `json:"-"`
+	// ImpersonatorTokenVersion is the token version of the administrator
+	// that started an impersonation session.
+	ImpersonatorTokenVersion int `json:"-"`
 }
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// Statuses of a webhook delivery. Pending deliveries are retried until they
+// succeed or run out of attempts and go to the dead letters.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookEventTypes are the domain events partners can subscribe to.
+var WebhookEventTypes = []string{
+	EventPVZCreated,
+	EventReceptionOpened,
+	EventReceptionClosed,
+	EventProductAdded,
+	EventProductRemoved,
+}
+
+// WebhookSubscription sends the domain events that pass its filters to URL.
+// Empty EventTypes or PvzIDs match every event type or pickup point.
+type WebhookSubscription struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	EventTypes []string  `json:"eventTypes"`
+	PvzIDs     []string  `json:"pvzIds"`
+	CreatedBy  string    `json:"createdBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func (s *WebhookSubscription) Matches(event *OutboxEvent) bool {
+	return (len(s.EventTypes) == 0 || slices.Contains(s.EventTypes, event.Type)) &&
+		(len(s.PvzIDs) == 0 || slices.Contains(s.PvzIDs, event.PvzID))
+}
+
+// WebhookDelivery is an event sent to one subscription, with the outcome of
+// the last attempt. Payload is the event document posted to the URL.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscriptionId"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	PvzID          string          `json:"pvzId"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastAttemptAt  *time.Time      `json:"lastAttemptAt,omitempty"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
@@ -0,0 +1,83 @@
+// Package oidctest provides a local OIDC issuer for tests. It serves the
+// discovery document and JWKS and signs RS256 tokens with an in-memory key.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const KeyID = "test-key"
+
+type Issuer struct {
+	URL string
+
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	jwksCalls atomic.Int32
+}
+
+func NewIssuer() (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	issuer := &Issuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.URL,
+			"jwks_uri": issuer.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		issuer.jwksCalls.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": KeyID,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	issuer.URL = issuer.server.URL
+	return issuer, nil
+}
+
+func (i *Issuer) Close() {
+	i.server.Close()
+}
+
+// JWKSCalls reports how many times the key set was fetched.
+func (i *Issuer) JWKSCalls() int {
+	return int(i.jwksCalls.Load())
+}
+
+// Token signs claims with the issuer key. iss and exp are filled in when
+// missing.
+func (i *Issuer) Token(claims jwt.MapClaims) (string, error) {
+	if _, ok := claims["iss"]; !ok {
+		claims["iss"] = i.URL
+	}
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = KeyID
+	return token.SignedString(i.key)
+}
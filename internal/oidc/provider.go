@@ -0,0 +1,256 @@
+package oidc
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	defaultCacheTTL = time.Hour
+	// minRefreshInterval limits how often an unknown key id can force a JWKS
+	// refetch, so garbage tokens cannot be used to hammer the issuer.
+	minRefreshInterval = 10 * time.Second
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrAudienceRequired = errors.New("oidc: audience is required")
+)
+
+type Config struct {
+	Issuer     string
+	Audience   string
+	CacheTTL   time.Duration
+	HTTPClient *http.Client
+}
+
+// Provider validates RS256 tokens of a single OIDC issuer. Discovery metadata
+// and signing keys are fetched lazily and cached for CacheTTL; an unknown key
+// id triggers an early refresh to pick up rotated keys.
+type Provider struct {
+	issuer   string
+	audience string
+	cacheTTL time.Duration
+	client   *http.Client
+	now      func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	refreshedAt time.Time
+	// refreshing is the fetch in flight. It runs without mu, and requests
+	// that need keys meanwhile wait for it instead of fetching again.
+	refreshing *refreshCall
+}
+
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewProvider fails without an audience, since the issuer also signs tokens
+// for its other clients.
+func NewProvider(cfg Config) (*Provider, error) {
+	if cfg.Audience == "" {
+		return nil, ErrAudienceRequired
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultCacheTTL
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &Provider{
+		issuer:   strings.TrimSuffix(cfg.Issuer, "/"),
+		audience: cfg.Audience,
+		cacheTTL: cfg.CacheTTL,
+		client:   cfg.HTTPClient,
+		now:      time.Now,
+	}, nil
+}
+
+// Verify checks the signature, issuer, audience and expiry of an ID or access
+// token and returns its claims.
+func (p *Provider) Verify(rawToken string) (jwt.MapClaims, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
+	token, err := parser.Parse(rawToken, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(kid)
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	now := p.now().Unix()
+	if !claims.VerifyExpiresAt(now, true) {
+		return nil, ErrInvalidToken
+	}
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != p.issuer {
+		return nil, ErrInvalidToken
+	}
+	if !claims.VerifyAudience(p.audience, true) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (p *Provider) key(kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	now := p.now()
+	key, found := p.lookup(kid)
+	if found && now.Sub(p.fetchedAt) <= p.cacheTTL {
+		p.mu.Unlock()
+		return key, nil
+	}
+	call := p.refreshing
+	if call == nil {
+		if now.Sub(p.refreshedAt) < minRefreshInterval {
+			p.mu.Unlock()
+			if found {
+				return key, nil
+			}
+			return nil, ErrInvalidToken
+		}
+		p.refreshedAt = now
+		call = &refreshCall{done: make(chan struct{})}
+		p.refreshing = call
+		p.mu.Unlock()
+		p.refresh(call, now)
+	} else {
+		p.mu.Unlock()
+		<-call.done
+	}
+
+	if call.err != nil {
+		// Keep using cached keys while the issuer is unreachable.
+		if found {
+			return key, nil
+		}
+		return nil, call.err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, found = p.lookup(kid); found {
+		return key, nil
+	}
+	return nil, ErrInvalidToken
+}
+
+// refresh fetches the keys outside mu and swaps them in under it.
+func (p *Provider) refresh(call *refreshCall, now time.Time) {
+	keys, err := p.fetchKeys()
+
+	p.mu.Lock()
+	if err == nil {
+		p.keys = keys
+		p.fetchedAt = now
+	}
+	p.refreshing = nil
+	p.mu.Unlock()
+
+	call.err = err
+	close(call.done)
+}
+
+// lookup finds a key by id. Tokens without a kid are accepted only when the
+// issuer publishes a single key.
+func (p *Provider) lookup(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+type discoveryDocument struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (p *Provider) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	var doc discoveryDocument
+	if err := p.getJSON(p.issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("oidc: discovery issuer %q does not match %q", doc.Issuer, p.issuer)
+	}
+	if doc.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document has no jwks_uri")
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(doc.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := parseRSAKey(jwk)
+		if err != nil {
+			return nil, err
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (p *Provider) getJSON(url string, target interface{}) error {
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+func parseRSAKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: key %q: invalid modulus: %w", jwk.Kid, err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: key %q: invalid exponent: %w", jwk.Kid, err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("oidc: key %q: exponent too large", jwk.Kid)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
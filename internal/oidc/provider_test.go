@@ -0,0 +1,172 @@
+package oidc
+
+import (
+	"avito-intern/internal/oidc/oidctest"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Issuer) {
+	issuer, err := oidctest.NewIssuer()
+	require.NoError(t, err)
+	t.Cleanup(issuer.Close)
+	provider, err := NewProvider(Config{Issuer: issuer.URL, Audience: "pvz"})
+	require.NoError(t, err)
+	return provider, issuer
+}
+
+func TestNewProvider_RequiresAudience(t *testing.T) {
+	_, err := NewProvider(Config{Issuer: "https://idp.example.com"})
+	assert.ErrorIs(t, err, ErrAudienceRequired)
+}
+
+func TestProvider_Verify(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+
+	token, err := issuer.Token(jwt.MapClaims{"sub": "user-1", "aud": "pvz", "email": "user@example.com"})
+	require.NoError(t, err)
+
+	claims, err := provider.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", claims["email"])
+
+	_, err = provider.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, 1, issuer.JWKSCalls(), "keys must be cached")
+}
+
+func TestProvider_VerifyRejects(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": issuer.URL, "aud": "pvz", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = oidctest.KeyID
+	forgedToken, err := forged.SignedString(otherKey)
+	require.NoError(t, err)
+
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss": issuer.URL, "aud": "pvz", "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		raw    string
+	}{
+		{name: "Wrong audience", claims: jwt.MapClaims{"aud": "other"}},
+		{name: "Wrong issuer", claims: jwt.MapClaims{"aud": "pvz", "iss": "https://evil.example.com"}},
+		{name: "Expired", claims: jwt.MapClaims{"aud": "pvz", "exp": time.Now().Add(-time.Minute).Unix()}},
+		{name: "Wrong key", raw: forgedToken},
+		{name: "HS256", raw: hmacToken},
+		{name: "Garbage", raw: "not-a-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := tt.raw
+			if raw == "" {
+				raw, err = issuer.Token(tt.claims)
+				require.NoError(t, err)
+			}
+			_, err := provider.Verify(raw)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestProvider_RefreshesExpiredCache(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+	clock := time.Now()
+	provider.now = func() time.Time { return clock }
+
+	token, err := issuer.Token(jwt.MapClaims{"aud": "pvz", "exp": clock.Add(3 * time.Hour).Unix()})
+	require.NoError(t, err)
+
+	_, err = provider.Verify(token)
+	require.NoError(t, err)
+
+	clock = clock.Add(2 * time.Hour)
+	_, err = provider.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, 2, issuer.JWKSCalls())
+}
+
+// heldTransport holds requests until release is closed once hold is set.
+type heldTransport struct {
+	hold    bool
+	held    chan struct{}
+	release chan struct{}
+}
+
+func (t *heldTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.hold {
+		select {
+		case t.held <- struct{}{}:
+		default:
+		}
+		<-t.release
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestProvider_RefreshDoesNotBlockCachedKeys(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+	transport := &heldTransport{held: make(chan struct{}, 1), release: make(chan struct{})}
+	provider.client = &http.Client{Transport: transport}
+	clock := time.Now()
+	provider.now = func() time.Time { return clock }
+
+	token, err := issuer.Token(jwt.MapClaims{"aud": "pvz"})
+	require.NoError(t, err)
+	_, err = provider.Verify(token)
+	require.NoError(t, err)
+
+	rotatedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rotated := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": issuer.URL, "aud": "pvz", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	rotated.Header["kid"] = "rotated-key"
+	rotatedToken, err := rotated.SignedString(rotatedKey)
+	require.NoError(t, err)
+
+	clock = clock.Add(minRefreshInterval + time.Second)
+	transport.hold = true
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := provider.Verify(rotatedToken)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		}()
+	}
+	<-transport.held
+
+	verified := make(chan error, 1)
+	go func() {
+		_, err := provider.Verify(token)
+		verified <- err
+	}()
+	select {
+	case err = <-verified:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("a cached key waited for the refresh")
+	}
+
+	close(transport.release)
+	wg.Wait()
+	assert.Equal(t, 2, issuer.JWKSCalls(), "concurrent refreshes must share one fetch")
+}
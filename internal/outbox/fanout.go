@@ -0,0 +1,22 @@
+package outbox
+
+import (
+	"avito-intern/internal/models"
+	"context"
+	"errors"
+)
+
+// Fanout publishes every event to all of its publishers. When one of them
+// fails, the relay retries the event with all of them, so the others see it
+// again.
+type Fanout []Publisher
+
+func (f Fanout) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	var errs []error
+	for _, publisher := range f {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// FilePublisher appends events to a file as newline-delimited JSON, one
+// event per line.
+type FilePublisher struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFilePublisher(path string) *FilePublisher {
+	return &FilePublisher{path: path}
+}
+
+func (p *FilePublisher) Publish(_ context.Context, event *models.OutboxEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	file, err := os.OpenFile(p.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	// The event is marked published right after, so it has to be on disk.
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"avito-intern/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const defaultPublishTimeout = 10 * time.Second
+
+// HTTPPublisher posts each event as JSON to a URL. The event ID is sent as
+// the Idempotency-Key header, so the receiver can drop redeliveries.
+type HTTPPublisher struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+}
+
+// NewHTTPPublisher creates a publisher posting to url with the extra
+// headers, e.g. Authorization. A nil client gets a 10 second timeout.
+func NewHTTPPublisher(url string, headers http.Header, client *http.Client) *HTTPPublisher {
+	if client == nil {
+		client = &http.Client{Timeout: defaultPublishTimeout}
+	}
+	return &HTTPPublisher{url: url, headers: headers, client: client}
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	header := p.headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+	header.Set("Idempotency-Key", event.ID)
+	header.Set("X-Event-Type", event.Type)
+	_, err = post(ctx, p.client, p.url, header, body)
+	return err
+}
+
+// post sends body and returns the response body of a 2xx response; other
+// statuses are errors.
+func post(ctx context.Context, client *http.Client, url string, header http.Header, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s answered %d: %s", url, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return respBody, nil
+}
@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// KafkaPublisher produces events to a Kafka topic through the REST proxy
+// API v2, as served by Confluent REST Proxy and Redpanda. The record key is
+// the pickup point ID, so the events of a pickup point land in one
+// partition and keep their order.
+type KafkaPublisher struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewKafkaPublisher creates a publisher to topic through the proxy at
+// proxyURL. A nil client gets a 10 second timeout.
+func NewKafkaPublisher(proxyURL, topic string, client *http.Client) *KafkaPublisher {
+	if client == nil {
+		client = &http.Client{Timeout: defaultPublishTimeout}
+	}
+	return &KafkaPublisher{
+		endpoint: strings.TrimSuffix(proxyURL, "/") + "/topics/" + url.PathEscape(topic),
+		client:   client,
+	}
+}
+
+type kafkaRecord struct {
+	Key   string              `json:"key"`
+	Value *models.OutboxEvent `json:"value"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int     `json:"partition"`
+		Offset    int64   `json:"offset"`
+		ErrorCode *int    `json:"error_code"`
+		Error     *string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	body, err := json.Marshal(map[string][]kafkaRecord{
+		"records": {{Key: event.PvzID, Value: event}},
+	})
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	header.Set("Accept", "application/vnd.kafka.v2+json")
+	respBody, err := post(ctx, p.client, p.endpoint, header, body)
+	if err != nil {
+		return err
+	}
+
+	// The proxy answers 200 even when the broker rejected the record.
+	var resp kafkaProduceResponse
+	if err = json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("invalid response of the Kafka REST proxy: %w", err)
+	}
+	for _, offset := range resp.Offsets {
+		if offset.ErrorCode != nil || offset.Error != nil {
+			message := ""
+			if offset.Error != nil {
+				message = *offset.Error
+			}
+			return fmt.Errorf("kafka rejected the event: %s", message)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package outbox
+
+import (
+	"avito-intern/internal/models"
+	"context"
+	"log"
+)
+
+// LogPublisher writes events to the application log instead of publishing
+// them.
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, event *models.OutboxEvent) error {
+	log.Printf("Event %s %s of PVZ %s: %s", event.Type, event.ID, event.PvzID, event.Payload)
+	return nil
+}
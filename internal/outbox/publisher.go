@@ -0,0 +1,13 @@
+package outbox
+
+import (
+	"avito-intern/internal/models"
+	"context"
+)
+
+// Publisher delivers domain events to other teams. The relay retries an
+// event until Publish returns nil, so consumers may see an event more than
+// once and should deduplicate by its ID.
+type Publisher interface {
+	Publish(ctx context.Context, event *models.OutboxEvent) error
+}
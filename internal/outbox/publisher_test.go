@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"avito-intern/internal/models"
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent(id string) *models.OutboxEvent {
+	return &models.OutboxEvent{
+		Sequence:   1,
+		ID:         id,
+		Type:       models.EventProductAdded,
+		PvzID:      "pvz-1",
+		Payload:    json.RawMessage(`{"id":"product-1","type":"обувь"}`),
+		OccurredAt: time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC),
+		Attempts:   3,
+		LastError:  "timeout",
+	}
+}
+
+func TestFilePublisher_Publish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	p := NewFilePublisher(path)
+
+	require.NoError(t, p.Publish(context.Background(), testEvent("event-1")))
+	require.NoError(t, p.Publish(context.Background(), testEvent("event-2")))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{
+		"sequence": 1,
+		"id": "event-1",
+		"type": "product.added",
+		"pvzId": "pvz-1",
+		"payload": {"id": "product-1", "type": "обувь"},
+		"occurredAt": "2026-10-18T12:00:00Z"
+	}`, lines[0])
+}
+
+func TestHTTPPublisher_Publish(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	p := NewHTTPPublisher(server.URL+"/events", http.Header{"Authorization": {"Bearer secret"}}, nil)
+	require.NoError(t, p.Publish(context.Background(), testEvent("event-1")))
+
+	assert.Equal(t, "/events", received.URL.Path)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer secret", received.Header.Get("Authorization"))
+	assert.Equal(t, "event-1", received.Header.Get("Idempotency-Key"))
+	assert.Equal(t, models.EventProductAdded, received.Header.Get("X-Event-Type"))
+	var event models.OutboxEvent
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, "pvz-1", event.PvzID)
+}
+
+func TestHTTPPublisher_PublishRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := NewHTTPPublisher(server.URL, nil, nil).Publish(context.Background(), testEvent("event-1"))
+
+	assert.ErrorContains(t, err, "answered 503: overloaded")
+}
+
+func TestKafkaPublisher_Publish(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/vnd.kafka.v2+json")
+		w.Write([]byte(`{"offsets":[{"partition":2,"offset":41,"error_code":null,"error":null}]}`))
+	}))
+	defer server.Close()
+
+	p := NewKafkaPublisher(server.URL+"/", "pvz.events", nil)
+	require.NoError(t, p.Publish(context.Background(), testEvent("event-1")))
+
+	assert.Equal(t, "/topics/pvz.events", received.URL.Path)
+	assert.Equal(t, "application/vnd.kafka.json.v2+json", received.Header.Get("Content-Type"))
+	var produce struct {
+		Records []struct {
+			Key   string             `json:"key"`
+			Value models.OutboxEvent `json:"value"`
+		} `json:"records"`
+	}
+	require.NoError(t, json.Unmarshal(body, &produce))
+	require.Len(t, produce.Records, 1)
+	assert.Equal(t, "pvz-1", produce.Records[0].Key, "the pickup point keys the partition")
+	assert.Equal(t, "event-1", produce.Records[0].Value.ID)
+}
+
+func TestKafkaPublisher_PublishRecordError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"offsets":[{"partition":null,"offset":null,"error_code":50301,"error":"leader not available"}]}`))
+	}))
+	defer server.Close()
+
+	err := NewKafkaPublisher(server.URL, "pvz.events", nil).Publish(context.Background(), testEvent("event-1"))
+
+	assert.EqualError(t, err, "kafka rejected the event: leader not available")
+}
+
+func TestFanout_Publish(t *testing.T) {
+	first := &mockPublisher{}
+	second := &mockPublisher{fail: map[string]int{"event-1": 1}}
+	fanout := Fanout{first, second}
+
+	err := fanout.Publish(context.Background(), testEvent("event-1"))
+	assert.EqualError(t, err, "broker unavailable")
+	assert.Equal(t, []string{"event-1"}, first.published, "a failing publisher does not stop the others")
+
+	require.NoError(t, fanout.Publish(context.Background(), testEvent("event-1")))
+	assert.Equal(t, []string{"event-1", "event-1"}, first.published)
+	assert.Equal(t, []string{"event-1"}, second.published)
+}
@@ -0,0 +1,142 @@
+// Package outbox publishes the domain events that repositories write to the
+// outbox table in the transactions of the changes they describe.
+package outbox
+
+import (
+	"avito-intern/internal/metrics"
+	"avito-intern/internal/repository"
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	defaultBatchSize    = 100
+	defaultPollInterval = time.Second
+	defaultLease        = time.Minute
+	defaultBaseBackoff  = time.Second
+	defaultMaxBackoff   = 5 * time.Minute
+	cleanupInterval     = time.Hour
+)
+
+// RelayPolicy tunes the relay. Zero fields get the defaults; a zero
+// Retention keeps published events.
+type RelayPolicy struct {
+	BatchSize    int
+	PollInterval time.Duration
+	// Lease is how long a claimed event is reserved for this relay. It has
+	// to be longer than publishing a batch takes, or another relay may
+	// publish the same events too.
+	Lease       time.Duration
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Retention   time.Duration
+}
+
+// Relay moves events from the outbox to a Publisher. Delivery is at least
+// once: an event is marked published only after Publish succeeded. Events
+// of one pickup point are published in the order they were written; a
+// failing event holds back the later events of its pickup point until a
+// retry succeeds.
+type Relay struct {
+	repo        repository.OutboxRepositoryInterface
+	publisher   Publisher
+	policy      RelayPolicy
+	now         func() time.Time
+	lastCleanup time.Time
+}
+
+func NewRelay(repo repository.OutboxRepositoryInterface, publisher Publisher, policy RelayPolicy) *Relay {
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = defaultBatchSize
+	}
+	if policy.PollInterval <= 0 {
+		policy.PollInterval = defaultPollInterval
+	}
+	if policy.Lease <= 0 {
+		policy.Lease = defaultLease
+	}
+	if policy.BaseBackoff <= 0 {
+		policy.BaseBackoff = defaultBaseBackoff
+	}
+	if policy.MaxBackoff < policy.BaseBackoff {
+		policy.MaxBackoff = max(defaultMaxBackoff, policy.BaseBackoff)
+	}
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+		policy:    policy,
+		now:       time.Now,
+	}
+}
+
+// Run relays events until ctx is done. Full batches are followed by the
+// next one right away, otherwise the relay waits for PollInterval.
+func (r *Relay) Run(ctx context.Context) {
+	for {
+		claimed, err := r.relay(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Outbox relay: %v", err)
+		}
+		r.cleanup()
+		if err == nil && claimed == r.policy.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.policy.PollInterval):
+		}
+	}
+}
+
+// relay publishes one batch of due events and returns how many it claimed.
+func (r *Relay) relay(ctx context.Context) (int, error) {
+	now := r.now()
+	events, err := r.repo.ClaimOutboxEvents(r.policy.BatchSize, now, now.Add(r.policy.Lease))
+	if err != nil {
+		return 0, err
+	}
+	for _, event := range events {
+		// Unpublished events of a stopped relay are claimed again once
+		// their lease expires.
+		if err = ctx.Err(); err != nil {
+			return len(events), err
+		}
+		if err = r.publisher.Publish(ctx, event); err != nil {
+			metrics.OutboxPublishFailedCount.WithLabelValues(event.Type).Inc()
+			retryAt := r.now().Add(r.backoff(event.Attempts + 1))
+			if err = r.repo.RecordOutboxFailure(event.Sequence, retryAt, err.Error()); err != nil {
+				return len(events), err
+			}
+			continue
+		}
+		metrics.OutboxPublishedCount.WithLabelValues(event.Type).Inc()
+		if err = r.repo.MarkOutboxEventPublished(event.Sequence, r.now()); err != nil {
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+// backoff doubles the delay with each failed attempt, up to MaxBackoff.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.policy.BaseBackoff
+	for i := 1; i < attempts && delay < r.policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.policy.MaxBackoff)
+}
+
+// cleanup deletes events published longer than Retention ago, at most once
+// per hour.
+func (r *Relay) cleanup() {
+	now := r.now()
+	if r.policy.Retention <= 0 || now.Sub(r.lastCleanup) < cleanupInterval {
+		return
+	}
+	r.lastCleanup = now
+	if _, err := r.repo.DeletePublishedOutboxEvents(now.Add(-r.policy.Retention)); err != nil {
+		log.Printf("Outbox relay: could not delete published events: %v", err)
+	}
+}
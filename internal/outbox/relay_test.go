@@ -0,0 +1,209 @@
+package outbox
+
+import (
+	"avito-intern/internal/models"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockOutboxRepository struct {
+	mu        sync.Mutex
+	events    []*models.OutboxEvent
+	due       map[int64]time.Time
+	published map[int64]time.Time
+	deleted   []time.Time
+}
+
+func newMockOutboxRepository(events ...*models.OutboxEvent) *mockOutboxRepository {
+	repo := &mockOutboxRepository{due: map[int64]time.Time{}, published: map[int64]time.Time{}}
+	for i, event := range events {
+		event.Sequence = int64(i + 1)
+		repo.events = append(repo.events, event)
+	}
+	return repo
+}
+
+// ClaimOutboxEvents claims the oldest unpublished event of each pickup
+// point, like the SQL query.
+func (m *mockOutboxRepository) ClaimOutboxEvents(limit int, now, leaseUntil time.Time) ([]*models.OutboxEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seen := map[string]bool{}
+	var claimed []*models.OutboxEvent
+	for _, event := range m.events {
+		if _, ok := m.published[event.Sequence]; ok || seen[event.PvzID] {
+			continue
+		}
+		seen[event.PvzID] = true
+		if m.due[event.Sequence].After(now) || len(claimed) == limit {
+			continue
+		}
+		m.due[event.Sequence] = leaseUntil
+		copied := *event
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (m *mockOutboxRepository) MarkOutboxEventPublished(sequence int64, publishedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.published[sequence] = publishedAt
+	return nil
+}
+
+func (m *mockOutboxRepository) RecordOutboxFailure(sequence int64, nextAttemptAt time.Time, lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, event := range m.events {
+		if event.Sequence == sequence {
+			event.Attempts++
+			event.LastError = lastError
+		}
+	}
+	m.due[sequence] = nextAttemptAt
+	return nil
+}
+
+func (m *mockOutboxRepository) DeletePublishedOutboxEvents(before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted = append(m.deleted, before)
+	return 0, nil
+}
+
+// mockPublisher records published event IDs and fails the events in fail
+// as many times as given.
+type mockPublisher struct {
+	mu        sync.Mutex
+	published []string
+	fail      map[string]int
+}
+
+func (p *mockPublisher) Publish(_ context.Context, event *models.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail[event.ID] > 0 {
+		p.fail[event.ID]--
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, event.ID)
+	return nil
+}
+
+func TestRelay_OrdersEventsPerPVZ(t *testing.T) {
+	repo := newMockOutboxRepository(
+		&models.OutboxEvent{ID: "a1", Type: models.EventPVZCreated, PvzID: "pvz-a"},
+		&models.OutboxEvent{ID: "b1", Type: models.EventPVZCreated, PvzID: "pvz-b"},
+		&models.OutboxEvent{ID: "a2", Type: models.EventReceptionOpened, PvzID: "pvz-a"},
+		&models.OutboxEvent{ID: "b2", Type: models.EventReceptionOpened, PvzID: "pvz-b"},
+	)
+	publisher := &mockPublisher{fail: map[string]int{"a1": 2}}
+	relay := NewRelay(repo, publisher, RelayPolicy{BaseBackoff: time.Second, MaxBackoff: time.Minute})
+	clock := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	relay.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	claimed, err := relay.relay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, claimed, "one event per pickup point")
+	assert.Equal(t, []string{"b1"}, publisher.published)
+	assert.Equal(t, 1, repo.events[0].Attempts)
+	assert.Equal(t, "broker unavailable", repo.events[0].LastError)
+	assert.Equal(t, clock.Add(time.Second), repo.due[1])
+
+	_, err = relay.relay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b1", "b2"}, publisher.published, "a2 waits for a1")
+
+	clock = clock.Add(time.Second)
+	_, err = relay.relay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Add(2*time.Second), repo.due[1], "the backoff doubles")
+
+	clock = clock.Add(2 * time.Second)
+	_, err = relay.relay(ctx)
+	require.NoError(t, err)
+	_, err = relay.relay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b1", "b2", "a1", "a2"}, publisher.published)
+	assert.Len(t, repo.published, 4)
+
+	claimed, err = relay.relay(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, claimed)
+}
+
+func TestRelay_StoppedRelayLeavesEventsClaimed(t *testing.T) {
+	repo := newMockOutboxRepository(&models.OutboxEvent{ID: "a1", PvzID: "pvz-a"})
+	publisher := &mockPublisher{}
+	relay := NewRelay(repo, publisher, RelayPolicy{Lease: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := relay.relay(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, publisher.published)
+	assert.Empty(t, repo.published)
+	assert.Zero(t, repo.events[0].Attempts, "the lease expires instead of counting a failure")
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := NewRelay(nil, nil, RelayPolicy{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 2*time.Second, relay.backoff(2))
+	assert.Equal(t, 8*time.Second, relay.backoff(4))
+	assert.Equal(t, 10*time.Second, relay.backoff(5))
+	assert.Equal(t, 10*time.Second, relay.backoff(60))
+}
+
+func TestRelay_Cleanup(t *testing.T) {
+	repo := newMockOutboxRepository()
+	relay := NewRelay(repo, &mockPublisher{}, RelayPolicy{Retention: 24 * time.Hour})
+	clock := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	relay.now = func() time.Time { return clock }
+
+	relay.cleanup()
+	relay.cleanup()
+	assert.Equal(t, []time.Time{clock.Add(-24 * time.Hour)}, repo.deleted, "at most once per hour")
+
+	clock = clock.Add(time.Hour)
+	relay.cleanup()
+	assert.Len(t, repo.deleted, 2)
+
+	NewRelay(repo, &mockPublisher{}, RelayPolicy{}).cleanup()
+	assert.Len(t, repo.deleted, 2, "no retention keeps published events")
+}
+
+func TestRelay_Run(t *testing.T) {
+	repo := newMockOutboxRepository(
+		&models.OutboxEvent{ID: "a1", PvzID: "pvz-a"},
+		&models.OutboxEvent{ID: "a2", PvzID: "pvz-a"},
+		&models.OutboxEvent{ID: "a3", PvzID: "pvz-a"},
+	)
+	publisher := &mockPublisher{}
+	relay := NewRelay(repo, publisher, RelayPolicy{BatchSize: 1, PollInterval: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		publisher.mu.Lock()
+		defer publisher.mu.Unlock()
+		return len(publisher.published) == 3
+	}, time.Second, time.Millisecond)
+	cancel()
+	<-done
+	assert.Equal(t, []string{"a1", "a2", "a3"}, publisher.published)
+}
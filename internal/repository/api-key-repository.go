@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+)
+
+type APIKeyRepositoryInterface interface {
+	CreateAPIKey(key *models.APIKey) error
+	GetAPIKeyByID(id string) (*models.APIKey, error)
+	GetAPIKeyByHash(keyHash string) (*models.APIKey, error)
+	ListAPIKeys() ([]*models.APIKey, error)
+	RevokeAPIKey(id string, revokedAt time.Time) error
+	TouchAPIKey(id string, usedAt time.Time) error
+	UseNonce(keyID, nonce string, usedAt, expireBefore time.Time) error
+}
+
+type APIKeyRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+var apiKeyColumns = []string{
+	"id", "name", "keyPrefix", "keyHash", "signingSecret", "role", "scopes", "requireSignature",
+	"createdBy", "createdAt", "expiresAt", "lastUsedAt", "revokedAt",
+}
+
+func (r *APIKeyRepository) CreateAPIKey(key *models.APIKey) error {
+	query, args, err := r.sqlBuilder.
+		Insert("api_keys").
+		Columns("id", "name", "keyPrefix", "keyHash", "signingSecret", "role", "scopes", "requireSignature",
+			"createdBy", "createdAt", "expiresAt").
+		Values(key.ID, key.Name, key.KeyPrefix, key.KeyHash, key.SigningSecret, key.Role, pq.Array(key.Scopes), key.RequireSignature,
+			key.CreatedBy, key.CreatedAt, key.ExpiresAt).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+func (r *APIKeyRepository) GetAPIKeyByID(id string) (*models.APIKey, error) {
+	return r.getAPIKey(squirrel.Eq{"id": id})
+}
+
+func (r *APIKeyRepository) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	return r.getAPIKey(squirrel.Eq{"keyHash": keyHash})
+}
+
+func (r *APIKeyRepository) getAPIKey(where squirrel.Eq) (*models.APIKey, error) {
+	query, args, err := r.sqlBuilder.
+		Select(apiKeyColumns...).
+		From("api_keys").
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	key, err := scanAPIKey(r.db.QueryRow(query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internalErrors.ErrAPIKeyNotFound
+		}
+		return nil, translateError(err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) ListAPIKeys() ([]*models.APIKey, error) {
+	query, args, err := r.sqlBuilder.
+		Select(apiKeyColumns...).
+		From("api_keys").
+		OrderBy("createdAt DESC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(id string, revokedAt time.Time) error {
+	query, args, err := r.sqlBuilder.
+		Update("api_keys").
+		Set("revokedAt", revokedAt).
+		Where(squirrel.Eq{"id": id, "revokedAt": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return internalErrors.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) TouchAPIKey(id string, usedAt time.Time) error {
+	query, args, err := r.sqlBuilder.
+		Update("api_keys").
+		Set("lastUsedAt", usedAt).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+// UseNonce records a nonce of a signed request and fails with
+// ErrReplayedRequest if the key has already used it. Nonces older than
+// expireBefore are dropped first; their timestamps are rejected anyway.
+func (r *APIKeyRepository) UseNonce(keyID, nonce string, usedAt, expireBefore time.Time) error {
+	query, args, err := r.sqlBuilder.
+		Delete("api_key_nonces").
+		Where(squirrel.Eq{"apiKeyId": keyID}).
+		Where(squirrel.Lt{"usedAt": expireBefore}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err = r.db.Exec(query, args...); err != nil {
+		return err
+	}
+
+	query, args, err = r.sqlBuilder.
+		Insert("api_key_nonces").
+		Columns("apiKeyId", "nonce", "usedAt").
+		Values(keyID, nonce, usedAt).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return internalErrors.ErrReplayedRequest
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var signingSecret sql.NullString
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(
+		&key.ID, &key.Name, &key.KeyPrefix, &key.KeyHash, &signingSecret, &key.Role, pq.Array(&key.Scopes), &key.RequireSignature,
+		&key.CreatedBy, &key.CreatedAt, &expiresAt, &lastUsedAt, &revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	key.SigningSecret = signingSecret.String
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
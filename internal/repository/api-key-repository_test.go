@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var apiKeyRowColumns = []string{
+	"id", "name", "keyPrefix", "keyHash", "signingSecret", "role", "scopes", "requireSignature",
+	"createdBy", "createdAt", "expiresAt", "lastUsedAt", "revokedAt",
+}
+
+func TestAPIKeyRepository_CreateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+	now := time.Now()
+	key := &models.APIKey{
+		ID:            "key-id",
+		Name:          "warehouse",
+		KeyPrefix:     "pvz_12345678",
+		KeyHash:       "hash",
+		SigningSecret: "pvzsig_secret",
+		Role:          "employee",
+		Scopes:        []string{models.ScopeReceptionsWrite},
+		CreatedBy:     "admin-id",
+		CreatedAt:     now,
+	}
+
+	mock.ExpectExec("INSERT INTO api_keys").
+		WithArgs("key-id", "warehouse", "pvz_12345678", "hash", "pvzsig_secret", "employee", pq.Array(key.Scopes), false, "admin-id", now, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.CreateAPIKey(key))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_GetAPIKeyByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE keyHash = \\$1").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(apiKeyRowColumns).
+			AddRow("key-id", "warehouse", "pvz_12345678", "hash", "pvzsig_secret", "employee",
+				"{pvz:read,receptions:write}", true, "admin-id", now, now.Add(time.Hour), nil, nil))
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE keyHash = \\$1").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	key, err := repo.GetAPIKeyByHash("hash")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.ScopePVZRead, models.ScopeReceptionsWrite}, key.Scopes)
+	assert.True(t, key.RequireSignature)
+	assert.Equal(t, "pvzsig_secret", key.SigningSecret)
+	assert.NotNil(t, key.ExpiresAt)
+	assert.Nil(t, key.RevokedAt)
+
+	_, err = repo.GetAPIKeyByHash("missing")
+	assert.ErrorIs(t, err, internalErrors.ErrAPIKeyNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_RevokeAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE api_keys SET revokedAt = \\$1 WHERE id = \\$2 AND revokedAt IS NULL").
+		WithArgs(now, "key-id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE api_keys").
+		WithArgs(now, "revoked-id").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.RevokeAPIKey("key-id", now))
+	assert.ErrorIs(t, repo.RevokeAPIKey("revoked-id", now), internalErrors.ErrAPIKeyNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_UseNonce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+	now := time.Now()
+	expireBefore := now.Add(-10 * time.Minute)
+
+	mock.ExpectExec("DELETE FROM api_key_nonces WHERE apiKeyId = \\$1 AND usedAt < \\$2").
+		WithArgs("key-id", expireBefore).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO api_key_nonces (.+) ON CONFLICT DO NOTHING").
+		WithArgs("key-id", "nonce", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM api_key_nonces").
+		WithArgs("key-id", expireBefore).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO api_key_nonces").
+		WithArgs("key-id", "nonce", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.UseNonce("key-id", "nonce", now, expireBefore))
+	assert.ErrorIs(t, repo.UseNonce("key-id", "nonce", now, expireBefore), internalErrors.ErrReplayedRequest)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
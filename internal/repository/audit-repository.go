@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"avito-intern/internal/models"
+	"database/sql"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type AuditRepositoryInterface interface {
+	CreateAuditEvent(event *models.AuditEvent) error
+	ListAuditEvents(actorID, userID string, limit, offset int) ([]*models.AuditEvent, error)
+}
+
+type AuditRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *AuditRepository) CreateAuditEvent(event *models.AuditEvent) error {
+	query, args, err := auditEventInsert(r.sqlBuilder, event).ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return err
+}
+
+func (r *AuditRepository) ListAuditEvents(actorID, userID string, limit, offset int) ([]*models.AuditEvent, error) {
+	q := r.sqlBuilder.
+		Select("id", "actorId", "userId", "action", "details", "createdAt").
+		From("audit_events")
+	if actorID != "" {
+		q = q.Where(squirrel.Eq{"actorId": actorID})
+	}
+	if userID != "" {
+		q = q.Where(squirrel.Eq{"userId": userID})
+	}
+	query, args, err := q.
+		OrderBy("createdAt DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*models.AuditEvent, 0)
+	for rows.Next() {
+		var event models.AuditEvent
+		var userID sql.NullString
+		if err = rows.Scan(&event.ID, &event.ActorID, &userID, &event.Action, &event.Details, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.UserID = userID.String
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
+
+// auditEventInsert is the statement that stores event, so that changes
+// which are audited can write their record in their own transaction.
+func auditEventInsert(builder squirrel.StatementBuilderType, event *models.AuditEvent) squirrel.InsertBuilder {
+	var userID interface{}
+	if event.UserID != "" {
+		userID = event.UserID
+	}
+	return builder.
+		Insert("audit_events").
+		Columns("id", "actorId", "userId", "action", "details", "createdAt").
+		Values(event.ID, event.ActorID, userID, event.Action, event.Details, event.CreatedAt)
+}
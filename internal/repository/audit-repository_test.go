@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"avito-intern/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRepository_CreateAuditEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewAuditRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("INSERT INTO audit_events").
+		WithArgs("event-id", "admin-id", "user-id", models.AuditImpersonationRequest, "GET /pvz", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO audit_events").
+		WithArgs("event-id-2", "admin-id", nil, "login", "", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.CreateAuditEvent(&models.AuditEvent{
+		ID:        "event-id",
+		ActorID:   "admin-id",
+		UserID:    "user-id",
+		Action:    models.AuditImpersonationRequest,
+		Details:   "GET /pvz",
+		CreatedAt: now,
+	}))
+	assert.NoError(t, repo.CreateAuditEvent(&models.AuditEvent{
+		ID:        "event-id-2",
+		ActorID:   "admin-id",
+		Action:    "login",
+		CreatedAt: now,
+	}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepository_ListAuditEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewAuditRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT id, actorId, userId, action, details, createdAt FROM audit_events WHERE actorId = \\$1 ORDER BY createdAt DESC LIMIT 20 OFFSET 0").
+		WithArgs("admin-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actorId", "userId", "action", "details", "createdAt"}).
+			AddRow("event-id", "admin-id", "user-id", models.AuditImpersonationStart, "", now).
+			AddRow("event-id-2", "admin-id", nil, "login", "", now))
+
+	events, err := repo.ListAuditEvents("admin-id", "", 20, 0)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "user-id", events[0].UserID)
+	assert.Empty(t, events[1].UserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
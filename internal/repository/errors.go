@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Postgres error codes, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const (
+	pgUniqueViolation           = "23505"
+	pgForeignKeyViolation       = "23503"
+	pgCheckViolation            = "23514"
+	pgInvalidTextRepresentation = "22P02"
+)
+
+// translateError wraps Postgres constraint and input errors with the matching
+// internalErrors sentinel, so that callers can tell them from failures of the
+// database itself. The original error stays in the chain for logging.
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch pqErr.Code {
+	case pgUniqueViolation:
+		return fmt.Errorf("%w: %w", internalErrors.ErrAlreadyExists, err)
+	case pgForeignKeyViolation:
+		return fmt.Errorf("%w: %w", internalErrors.ErrReferenceNotFound, err)
+	case pgCheckViolation:
+		return fmt.Errorf("%w: %w", internalErrors.ErrConstraintViolation, err)
+	case pgInvalidTextRepresentation:
+		return fmt.Errorf("%w: %w", internalErrors.ErrInvalidID, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"Unique violation", &pq.Error{Code: "23505"}, internalErrors.ErrAlreadyExists},
+		{"Foreign key violation", &pq.Error{Code: "23503"}, internalErrors.ErrReferenceNotFound},
+		{"Check violation", &pq.Error{Code: "23514"}, internalErrors.ErrConstraintViolation},
+		{"Invalid UUID", &pq.Error{Code: "22P02"}, internalErrors.ErrInvalidID},
+		{"Wrapped error", fmt.Errorf("insert: %w", &pq.Error{Code: "23505"}), internalErrors.ErrAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateError(tt.err)
+			assert.ErrorIs(t, err, tt.expected)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestTranslateError_PassThrough(t *testing.T) {
+	assert.NoError(t, translateError(nil))
+	assert.Equal(t, sql.ErrConnDone, translateError(sql.ErrConnDone))
+
+	deadlock := &pq.Error{Code: "40P01"}
+	assert.Equal(t, error(deadlock), translateError(deadlock))
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"avito-intern/internal/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type IdempotencyRepositoryInterface interface {
+	CreateIdempotencyKey(key *models.IdempotencyKey) (bool, error)
+	GetIdempotencyKey(scope, key string) (*models.IdempotencyKey, error)
+	CompleteIdempotencyKey(key *models.IdempotencyKey) error
+	DeleteIdempotencyKey(scope, key string) error
+}
+
+type IdempotencyRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+// CreateIdempotencyKey reserves the key for a request in progress. Expired
+// keys are removed first, so that they can be reused. It reports false when
+// the key is already taken.
+func (r *IdempotencyRepository) CreateIdempotencyKey(key *models.IdempotencyKey) (bool, error) {
+	query, args, err := r.sqlBuilder.
+		Delete("idempotency_keys").
+		Where(squirrel.Lt{"expiresAt": key.CreatedAt}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+	if _, err = r.db.Exec(query, args...); err != nil {
+		return false, err
+	}
+
+	query, args, err = r.sqlBuilder.
+		Insert("idempotency_keys").
+		Columns("scope", "idempotencyKey", "requestHash", "createdAt", "expiresAt").
+		Values(key.Scope, key.Key, key.RequestHash, key.CreatedAt, key.ExpiresAt).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return false, translateError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+// GetIdempotencyKey returns nil when the key is not stored.
+func (r *IdempotencyRepository) GetIdempotencyKey(scope, key string) (*models.IdempotencyKey, error) {
+	query, args, err := r.sqlBuilder.
+		Select("requestHash", "statusCode", "responseHeaders", "responseBody", "createdAt", "expiresAt").
+		From("idempotency_keys").
+		Where(squirrel.Eq{"scope": scope, "idempotencyKey": key}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	record := models.IdempotencyKey{Scope: scope, Key: key}
+	var statusCode sql.NullInt64
+	var headers []byte
+	err = r.db.QueryRow(query, args...).Scan(
+		&record.RequestHash,
+		&statusCode,
+		&headers,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	record.StatusCode = int(statusCode.Int64)
+	if len(headers) > 0 {
+		if err = json.Unmarshal(headers, &record.ResponseHeaders); err != nil {
+			return nil, err
+		}
+	}
+	return &record, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request.
+func (r *IdempotencyRepository) CompleteIdempotencyKey(key *models.IdempotencyKey) error {
+	headers, err := json.Marshal(key.ResponseHeaders)
+	if err != nil {
+		return err
+	}
+	query, args, err := r.sqlBuilder.
+		Update("idempotency_keys").
+		Set("statusCode", key.StatusCode).
+		Set("responseHeaders", headers).
+		Set("responseBody", key.ResponseBody).
+		Where(squirrel.Eq{"scope": key.Scope, "idempotencyKey": key.Key}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+func (r *IdempotencyRepository) DeleteIdempotencyKey(scope, key string) error {
+	query, args, err := r.sqlBuilder.
+		Delete("idempotency_keys").
+		Where(squirrel.Eq{"scope": scope, "idempotencyKey": key}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return err
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"avito-intern/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyRepository_CreateIdempotencyKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewIdempotencyRepository(db)
+	now := time.Now()
+	key := &models.IdempotencyKey{
+		Scope:       "caller",
+		Key:         "key-1",
+		RequestHash: "hash",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE expiresAt < \\$1").
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO idempotency_keys (.+) ON CONFLICT DO NOTHING").
+		WithArgs("caller", "key-1", "hash", now, now.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM idempotency_keys").
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO idempotency_keys").
+		WithArgs("caller", "key-1", "hash", now, now.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	created, err := repo.CreateIdempotencyKey(key)
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = repo.CreateIdempotencyKey(key)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_GetIdempotencyKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewIdempotencyRepository(db)
+	now := time.Now()
+	columns := []string{"requestHash", "statusCode", "responseHeaders", "responseBody", "createdAt", "expiresAt"}
+
+	mock.ExpectQuery("SELECT (.+) FROM idempotency_keys WHERE idempotencyKey = \\$1 AND scope = \\$2").
+		WithArgs("key-1", "caller").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("hash", 201, []byte(`{"Content-Type":["application/json"]}`), []byte(`{"id":"1"}`), now, now.Add(time.Hour)))
+	mock.ExpectQuery("SELECT (.+) FROM idempotency_keys").
+		WithArgs("key-2", "caller").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("hash", nil, nil, nil, now, now.Add(time.Hour)))
+	mock.ExpectQuery("SELECT (.+) FROM idempotency_keys").
+		WithArgs("key-3", "caller").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	stored, err := repo.GetIdempotencyKey("caller", "key-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 201, stored.StatusCode)
+	assert.Equal(t, map[string][]string{"Content-Type": {"application/json"}}, stored.ResponseHeaders)
+	assert.Equal(t, `{"id":"1"}`, string(stored.ResponseBody))
+
+	stored, err = repo.GetIdempotencyKey("caller", "key-2")
+	assert.NoError(t, err)
+	assert.False(t, stored.Completed())
+
+	stored, err = repo.GetIdempotencyKey("caller", "key-3")
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_CompleteIdempotencyKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewIdempotencyRepository(db)
+
+	mock.ExpectExec("UPDATE idempotency_keys SET statusCode = \\$1, responseHeaders = \\$2, responseBody = \\$3").
+		WithArgs(201, []byte(`{"Content-Type":["application/json"]}`), []byte(`{"id":"1"}`), "key-1", "caller").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.CompleteIdempotencyKey(&models.IdempotencyKey{
+		Scope:           "caller",
+		Key:             "key-1",
+		StatusCode:      201,
+		ResponseHeaders: map[string][]string{"Content-Type": {"application/json"}},
+		ResponseBody:    []byte(`{"id":"1"}`),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"avito-intern/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type InboundRepositoryInterface interface {
+	ClaimInboundMessage(id, messageType string, now, staleBefore time.Time) (int, bool, error)
+	MarkInboundMessageProcessed(id string, at time.Time) error
+	SetInboundMessageTarget(id, target string) error
+	GetInboundMessageTarget(id string) (string, error)
+	ReleaseInboundMessage(id string) error
+	CreateInboundDeadLetter(letter *models.InboundDeadLetter) error
+	DeleteProcessedInboundMessages(before time.Time) (int64, error)
+}
+
+type InboundRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewInboundRepository(db *sql.DB) *InboundRepository {
+	return &InboundRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+// ClaimInboundMessage reserves the message with id for processing and
+// returns the number of the attempt. A message seen for the first time is
+// claimed, as is one whose claim was released or taken before staleBefore.
+// Processed and dead messages, and those claimed by another consumer, are
+// not.
+func (r *InboundRepository) ClaimInboundMessage(id, messageType string, now, staleBefore time.Time) (int, bool, error) {
+	query, args, err := r.sqlBuilder.
+		Insert("inbound_messages").
+		Columns("id", "messageType", "status", "claimedAt").
+		Values(id, messageType, models.InboundMessageProcessing, now).
+		Suffix("ON CONFLICT (id) DO UPDATE SET attempts = inbound_messages.attempts + 1, claimedAt = EXCLUDED.claimedAt "+
+			"WHERE inbound_messages.status = ? AND (inbound_messages.claimedAt IS NULL OR inbound_messages.claimedAt < ?) "+
+			"RETURNING attempts", models.InboundMessageProcessing, staleBefore).
+		ToSql()
+	if err != nil {
+		return 0, false, err
+	}
+	var attempts int
+	if err = r.db.QueryRow(query, args...).Scan(&attempts); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, translateError(err)
+	}
+	return attempts, true, nil
+}
+
+func (r *InboundRepository) MarkInboundMessageProcessed(id string, at time.Time) error {
+	query, args, err := r.sqlBuilder.
+		Update("inbound_messages").
+		Set("status", models.InboundMessageProcessed).
+		Set("claimedAt", nil).
+		Set("processedAt", at).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+// SetInboundMessageTarget records the ID of the record a message changes,
+// when it cannot be derived from the message, so that a retry can tell
+// whether the change was made.
+func (r *InboundRepository) SetInboundMessageTarget(id, target string) error {
+	query, args, err := r.sqlBuilder.
+		Update("inbound_messages").
+		Set("target", target).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+// GetInboundMessageTarget returns the target recorded for the message, or
+// an empty string.
+func (r *InboundRepository) GetInboundMessageTarget(id string) (string, error) {
+	query, args, err := r.sqlBuilder.
+		Select("target").
+		From("inbound_messages").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return "", err
+	}
+	var target sql.NullString
+	if err = r.db.QueryRow(query, args...).Scan(&target); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", translateError(err)
+	}
+	return target.String, nil
+}
+
+// ReleaseInboundMessage drops the claim of a message that failed, so that
+// it is claimed again when the broker redelivers it.
+func (r *InboundRepository) ReleaseInboundMessage(id string) error {
+	query, args, err := r.sqlBuilder.
+		Update("inbound_messages").
+		Set("claimedAt", nil).
+		Where(squirrel.Eq{"id": id, "status": models.InboundMessageProcessing}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+// CreateInboundDeadLetter stores the letter and, when it has a message ID,
+// marks the message dead in the same transaction, so that redeliveries of
+// it are skipped.
+func (r *InboundRepository) CreateInboundDeadLetter(letter *models.InboundDeadLetter) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []squirrel.Sqlizer{
+		r.sqlBuilder.
+			Insert("inbound_dead_letters").
+			Columns("id", "messageId", "messageType", "source", "body", "reason", "attempts", "createdAt").
+			Values(letter.ID, letter.MessageID, letter.MessageType, letter.Source, letter.Body, letter.Reason,
+				letter.Attempts, letter.CreatedAt),
+	}
+	if letter.MessageID != "" {
+		statements = append(statements, r.sqlBuilder.
+			Insert("inbound_messages").
+			Columns("id", "messageType", "status", "attempts", "processedAt").
+			Values(letter.MessageID, letter.MessageType, models.InboundMessageDead, max(letter.Attempts, 1), letter.CreatedAt).
+			Suffix("ON CONFLICT (id) DO UPDATE SET status = EXCLUDED.status, claimedAt = NULL, processedAt = EXCLUDED.processedAt"))
+	}
+	for _, statement := range statements {
+		query, args, err := statement.ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err = tx.Exec(query, args...); err != nil {
+			return translateError(err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteProcessedInboundMessages forgets the messages processed or dead
+// before the given time. Redeliveries of them are processed again.
+func (r *InboundRepository) DeleteProcessedInboundMessages(before time.Time) (int64, error) {
+	query, args, err := r.sqlBuilder.
+		Delete("inbound_messages").
+		Where(squirrel.Lt{"processedAt": before}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"avito-intern/internal/models"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInboundRepository_ClaimInboundMessage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewInboundRepository(db)
+	now := time.Now()
+	staleBefore := now.Add(-time.Minute)
+	claim := "INSERT INTO inbound_messages \\(id,messageType,status,claimedAt\\) VALUES \\(\\$1,\\$2,\\$3,\\$4\\) " +
+		"ON CONFLICT \\(id\\) DO UPDATE SET attempts = inbound_messages.attempts \\+ 1, claimedAt = EXCLUDED.claimedAt " +
+		"WHERE inbound_messages.status = \\$5 AND \\(inbound_messages.claimedAt IS NULL OR inbound_messages.claimedAt < \\$6\\) " +
+		"RETURNING attempts"
+
+	mock.ExpectQuery(claim).
+		WithArgs("message-1", "item.scanned", models.InboundMessageProcessing, now, models.InboundMessageProcessing, staleBefore).
+		WillReturnRows(sqlmock.NewRows([]string{"attempts"}).AddRow(2))
+	mock.ExpectQuery(claim).
+		WithArgs("message-2", "item.scanned", models.InboundMessageProcessing, now, models.InboundMessageProcessing, staleBefore).
+		WillReturnRows(sqlmock.NewRows([]string{"attempts"}))
+
+	attempts, claimed, err := repo.ClaimInboundMessage("message-1", "item.scanned", now, staleBefore)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, 2, attempts)
+
+	attempts, claimed, err = repo.ClaimInboundMessage("message-2", "item.scanned", now, staleBefore)
+	assert.NoError(t, err)
+	assert.False(t, claimed, "the message was processed or is claimed by another consumer")
+	assert.Zero(t, attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInboundRepository_ClaimInboundMessage_DatabaseError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewInboundRepository(db)
+
+	mock.ExpectQuery("INSERT INTO inbound_messages").WillReturnError(sql.ErrConnDone)
+
+	_, claimed, err := repo.ClaimInboundMessage("message-1", "item.scanned", time.Now(), time.Now())
+
+	assert.Equal(t, sql.ErrConnDone, err)
+	assert.False(t, claimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInboundRepository_MarkAndReleaseInboundMessage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewInboundRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE inbound_messages SET status = \\$1, claimedAt = \\$2, processedAt = \\$3 WHERE id = \\$4").
+		WithArgs(models.InboundMessageProcessed, nil, now, "message-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE inbound_messages SET claimedAt = \\$1 WHERE id = \\$2 AND status = \\$3").
+		WithArgs(nil, "message-2", models.InboundMessageProcessing).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.MarkInboundMessageProcessed("message-1", now))
+	assert.NoError(t, repo.ReleaseInboundMessage("message-2"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInboundRepository_CreateInboundDeadLetter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewInboundRepository(db)
+	letter := &models.InboundDeadLetter{
+		ID:          "letter-1",
+		MessageID:   "message-1",
+		MessageType: "item.scanned",
+		Source:      "pvz.commands",
+		Body:        `{"id":"message-1"}`,
+		Reason:      "no active reception",
+		Attempts:    1,
+		CreatedAt:   time.Now(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO inbound_dead_letters \\(id,messageId,messageType,source,body,reason,attempts,createdAt\\)").
+		WithArgs(letter.ID, letter.MessageID, letter.MessageType, letter.Source, letter.Body, letter.Reason, 1, letter.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO inbound_messages \\(id,messageType,status,attempts,processedAt\\) .+ ON CONFLICT \\(id\\) DO UPDATE SET status = EXCLUDED.status").
+		WithArgs("message-1", "item.scanned", models.InboundMessageDead, 1, letter.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.CreateInboundDeadLetter(letter))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInboundRepository_CreateInboundDeadLetter_WithoutMessageID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewInboundRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO inbound_dead_letters").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.CreateInboundDeadLetter(&models.InboundDeadLetter{ID: "letter-1", Body: "not json"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInboundRepository_DeleteProcessedInboundMessages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewInboundRepository(db)
+	before := time.Now()
+
+	mock.ExpectExec("DELETE FROM inbound_messages WHERE processedAt < \\$1").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	deleted, err := repo.DeleteProcessedInboundMessages(before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInboundRepository_InboundMessageTarget(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewInboundRepository(db)
+
+	mock.ExpectExec("UPDATE inbound_messages SET target = \\$1 WHERE id = \\$2").
+		WithArgs("reception-1", "message-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT target FROM inbound_messages WHERE id = \\$1").
+		WithArgs("message-1").
+		WillReturnRows(sqlmock.NewRows([]string{"target"}).AddRow("reception-1"))
+	mock.ExpectQuery("SELECT target FROM inbound_messages").
+		WithArgs("message-2").
+		WillReturnRows(sqlmock.NewRows([]string{"target"}).AddRow(nil))
+
+	assert.NoError(t, repo.SetInboundMessageTarget("message-1", "reception-1"))
+	target, err := repo.GetInboundMessageTarget("message-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "reception-1", target)
+	target, err = repo.GetInboundMessageTarget("message-2")
+	assert.NoError(t, err)
+	assert.Empty(t, target)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type InviteRepositoryInterface interface {
+	CreateInvite(invite *models.Invite) error
+	GetInviteByTokenHash(tokenHash string) (*models.Invite, error)
+	AcceptInvite(id string, usedAt time.Time, user *models.User) error
+}
+
+type InviteRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewInviteRepository(db *sql.DB) *InviteRepository {
+	return &InviteRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *InviteRepository) CreateInvite(invite *models.Invite) error {
+	query, args, err := r.sqlBuilder.
+		Insert("invites").
+		Columns("id", "tokenHash", "email", "role", "createdBy", "createdAt", "expiresAt").
+		Values(invite.ID, invite.TokenHash, invite.Email, invite.Role, invite.CreatedBy, invite.CreatedAt, invite.ExpiresAt).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+func (r *InviteRepository) GetInviteByTokenHash(tokenHash string) (*models.Invite, error) {
+	var invite models.Invite
+	var usedAt sql.NullTime
+	query, args, err := r.sqlBuilder.
+		Select("id", "tokenHash", "email", "role", "createdBy", "createdAt", "expiresAt", "usedAt").
+		From("invites").
+		Where(squirrel.Eq{"tokenHash": tokenHash}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.QueryRow(query, args...).Scan(
+		&invite.ID, &invite.TokenHash, &invite.Email, &invite.Role,
+		&invite.CreatedBy, &invite.CreatedAt, &invite.ExpiresAt, &usedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internalErrors.ErrInvalidInvite
+		}
+		return nil, err
+	}
+	if usedAt.Valid {
+		invite.UsedAt = &usedAt.Time
+	}
+	return &invite, nil
+}
+
+// AcceptInvite consumes the invite and creates the invited user in one
+// transaction. The invite is consumed only if nobody has used it yet, so two
+// concurrent registrations with the same token cannot both succeed, and it
+// stays unused when the user cannot be created.
+func (r *InviteRepository) AcceptInvite(id string, usedAt time.Time, user *models.User) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query, args, err := r.sqlBuilder.
+		Update("invites").
+		Set("usedAt", usedAt).
+		Where(squirrel.Eq{"id": id, "usedAt": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return internalErrors.ErrInvalidInvite
+	}
+
+	query, args, err = r.sqlBuilder.
+		Insert("users").
+		Columns("id", "email", "password", "role", "status").
+		Values(user.ID, user.Email, user.Password, user.Role, user.Status).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(query, args...)
+	if err = translateError(err); err != nil {
+		if errors.Is(err, internalErrors.ErrAlreadyExists) {
+			return fmt.Errorf("%w: %w", internalErrors.ErrEmailExists, err)
+		}
+		return err
+	}
+	return tx.Commit()
+}
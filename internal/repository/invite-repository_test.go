@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInviteRepository_CreateInvite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewInviteRepository(db)
+	now := time.Now()
+	invite := &models.Invite{
+		ID:        "invite-id",
+		TokenHash: "hash",
+		Email:     "moderator@example.com",
+		Role:      "moderator",
+		CreatedBy: "admin-id",
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	}
+
+	mock.ExpectExec("INSERT INTO invites").
+		WithArgs("invite-id", "hash", "moderator@example.com", "moderator", "admin-id", now, now.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.CreateInvite(invite)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInviteRepository_GetInviteByTokenHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewInviteRepository(db)
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "tokenHash", "email", "role", "createdBy", "createdAt", "expiresAt", "usedAt"}).
+		AddRow("invite-id", "hash", "moderator@example.com", "moderator", "admin-id", now, now.Add(time.Hour), now)
+	mock.ExpectQuery("SELECT (.+) FROM invites").
+		WithArgs("hash").
+		WillReturnRows(rows)
+	mock.ExpectQuery("SELECT (.+) FROM invites").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	invite, err := repo.GetInviteByTokenHash("hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "moderator", invite.Role)
+	assert.NotNil(t, invite.UsedAt)
+
+	invite, err = repo.GetInviteByTokenHash("missing")
+	assert.Equal(t, internalErrors.ErrInvalidInvite, err)
+	assert.Nil(t, invite)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInviteRepository_AcceptInvite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewInviteRepository(db)
+	now := time.Now()
+	user := &models.User{ID: "user-id", Email: "moderator@example.com", Password: "hash", Role: "moderator", Status: "active"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE invites SET usedAt = \\$1 WHERE id = \\$2 AND usedAt IS NULL").
+		WithArgs(now, "invite-id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO users \\(id,email,password,role,status\\)").
+		WithArgs("user-id", "moderator@example.com", "hash", "moderator", "active").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE invites SET usedAt").
+		WithArgs(now, "invite-id").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE invites SET usedAt").
+		WithArgs(now, "invite-id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO users").
+		WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	assert.NoError(t, repo.AcceptInvite("invite-id", now, user))
+	assert.Equal(t, internalErrors.ErrInvalidInvite, repo.AcceptInvite("invite-id", now, user))
+	assert.ErrorIs(t, repo.AcceptInvite("invite-id", now, user), internalErrors.ErrEmailExists,
+		"the invite is not consumed when the user cannot be created")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
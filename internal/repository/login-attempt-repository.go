@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"avito-intern/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type LoginAttemptRepositoryInterface interface {
+	GetLoginAttempt(key string) (*models.LoginAttempt, error)
+	RecordFailure(key string, at, windowStart time.Time) (int, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+type LoginAttemptRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+// GetLoginAttempt returns an empty attempt rather than an error when the key
+// has no recorded failures.
+func (r *LoginAttemptRepository) GetLoginAttempt(key string) (*models.LoginAttempt, error) {
+	attempt := models.LoginAttempt{Key: key}
+	var lockedUntil sql.NullTime
+	query, args, err := r.sqlBuilder.
+		Select("failures", "lastFailureAt", "lockedUntil").
+		From("login_attempts").
+		Where(squirrel.Eq{"attemptKey": key}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.QueryRow(query, args...).Scan(&attempt.Failures, &attempt.LastFailureAt, &lockedUntil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &attempt, nil
+		}
+		return nil, err
+	}
+	if lockedUntil.Valid {
+		attempt.LockedUntil = &lockedUntil.Time
+	}
+	return &attempt, nil
+}
+
+// RecordFailure atomically increments the failure counter for key and
+// returns the new value. A counter whose last failure is before windowStart
+// starts over at 1.
+func (r *LoginAttemptRepository) RecordFailure(key string, at, windowStart time.Time) (int, error) {
+	query, args, err := r.sqlBuilder.
+		Insert("login_attempts").
+		Columns("attemptKey", "failures", "lastFailureAt").
+		Values(key, 1, at).
+		Suffix("ON CONFLICT (attemptKey) DO UPDATE SET "+
+			"failures = CASE WHEN login_attempts.lastFailureAt < ? THEN 1 ELSE login_attempts.failures + 1 END, "+
+			"lastFailureAt = EXCLUDED.lastFailureAt RETURNING failures", windowStart).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+	var failures int
+	if err = r.db.QueryRow(query, args...).Scan(&failures); err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
+// Lock starts a lockout period and clears the failure counter, so that the
+// next lockout needs another full series of failures.
+func (r *LoginAttemptRepository) Lock(key string, until time.Time) error {
+	query, args, err := r.sqlBuilder.
+		Update("login_attempts").
+		Set("lockedUntil", until).
+		Set("failures", 0).
+		Where(squirrel.Eq{"attemptKey": key}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return err
+}
+
+func (r *LoginAttemptRepository) Reset(key string) error {
+	query, args, err := r.sqlBuilder.
+		Delete("login_attempts").
+		Where(squirrel.Eq{"attemptKey": key}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return err
+}
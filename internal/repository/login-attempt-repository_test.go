@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginAttemptRepository_GetLoginAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewLoginAttemptRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT failures, lastFailureAt, lockedUntil FROM login_attempts").
+		WithArgs("account:test@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"failures", "lastFailureAt", "lockedUntil"}).AddRow(3, now, now))
+	mock.ExpectQuery("SELECT failures, lastFailureAt, lockedUntil FROM login_attempts").
+		WithArgs("ip:10.0.0.1").
+		WillReturnError(sql.ErrNoRows)
+
+	attempt, err := repo.GetLoginAttempt("account:test@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempt.Failures)
+	assert.NotNil(t, attempt.LockedUntil)
+
+	attempt, err = repo.GetLoginAttempt("ip:10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ip:10.0.0.1", attempt.Key)
+	assert.Equal(t, 0, attempt.Failures)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttemptRepository_RecordFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewLoginAttemptRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO login_attempts (.+) ON CONFLICT \\(attemptKey\\) DO UPDATE SET "+
+		"failures = CASE WHEN login_attempts.lastFailureAt < \\$4 THEN 1 ELSE login_attempts.failures \\+ 1 END").
+		WithArgs("account:test@example.com", 1, now, now.Add(-time.Hour)).
+		WillReturnRows(sqlmock.NewRows([]string{"failures"}).AddRow(4))
+
+	failures, err := repo.RecordFailure("account:test@example.com", now, now.Add(-time.Hour))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, failures)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttemptRepository_LockAndReset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewLoginAttemptRepository(db)
+	until := time.Now().Add(time.Minute)
+
+	mock.ExpectExec("UPDATE login_attempts SET lockedUntil = \\$1, failures = \\$2 WHERE attemptKey = \\$3").
+		WithArgs(until, 0, "account:test@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM login_attempts WHERE attemptKey = \\$1").
+		WithArgs("account:test@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.Lock("account:test@example.com", until))
+	assert.NoError(t, repo.Reset("account:test@example.com"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"avito-intern/internal/models"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// OutboxRepositoryInterface is the side of the outbox the relay works on.
+// Events are written by the repositories of the changes they describe.
+type OutboxRepositoryInterface interface {
+	ClaimOutboxEvents(limit int, now, leaseUntil time.Time) ([]*models.OutboxEvent, error)
+	MarkOutboxEventPublished(sequence int64, publishedAt time.Time) error
+	RecordOutboxFailure(sequence int64, nextAttemptAt time.Time, lastError string) error
+	DeletePublishedOutboxEvents(before time.Time) (int64, error)
+}
+
+type OutboxRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+// ClaimOutboxEvents reserves up to limit unpublished events that are due at
+// now until leaseUntil, in sequence order. Only the oldest unpublished event
+// of each pickup point is claimed, so that events of a pickup point are
+// published in order even when an earlier one is being retried. Events
+// claimed by another relay are skipped.
+func (r *OutboxRepository) ClaimOutboxEvents(limit int, now, leaseUntil time.Time) ([]*models.OutboxEvent, error) {
+	// The subquery keeps ? placeholders, they are numbered with the update.
+	due, dueArgs, err := squirrel.
+		Select("o.sequence").
+		From("outbox o").
+		Where("o.publishedAt IS NULL").
+		Where(squirrel.LtOrEq{"o.nextAttemptAt": now}).
+		Where("NOT EXISTS (SELECT 1 FROM outbox e WHERE e.pvzId = o.pvzId AND e.publishedAt IS NULL AND e.sequence < o.sequence)").
+		OrderBy("o.sequence").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := r.sqlBuilder.
+		Update("outbox").
+		Set("nextAttemptAt", leaseUntil).
+		Where(squirrel.Expr("sequence IN ("+due+")", dueArgs...)).
+		Suffix("RETURNING sequence, eventId, eventType, pvzId, payload, occurredAt, attempts, lastError").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	events := make([]*models.OutboxEvent, 0)
+	for rows.Next() {
+		var event models.OutboxEvent
+		var payload []byte
+		err := rows.Scan(&event.Sequence, &event.ID, &event.Type, &event.PvzID, &payload,
+			&event.OccurredAt, &event.Attempts, &event.LastError)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, &event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING does not keep the order of the subquery.
+	sort.Slice(events, func(i, j int) bool { return events[i].Sequence < events[j].Sequence })
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkOutboxEventPublished(sequence int64, publishedAt time.Time) error {
+	query, args, err := r.sqlBuilder.
+		Update("outbox").
+		Set("publishedAt", publishedAt).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("lastError", "").
+		Where(squirrel.Eq{"sequence": sequence}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+// RecordOutboxFailure counts a failed attempt to publish the event and
+// releases it for a retry at nextAttemptAt.
+func (r *OutboxRepository) RecordOutboxFailure(sequence int64, nextAttemptAt time.Time, lastError string) error {
+	query, args, err := r.sqlBuilder.
+		Update("outbox").
+		Set("nextAttemptAt", nextAttemptAt).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("lastError", lastError).
+		Where(squirrel.Eq{"sequence": sequence}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+// DeletePublishedOutboxEvents removes events published before the given
+// time and returns how many were removed.
+func (r *OutboxRepository) DeletePublishedOutboxEvents(before time.Time) (int64, error) {
+	query, args, err := r.sqlBuilder.
+		Delete("outbox").
+		Where(squirrel.Lt{"publishedAt": before}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return res.RowsAffected()
+}
+
+// OutboxChannel is the Postgres channel on which every written event is
+// notified as the JSON of models.OutboxEvent once its transaction commits.
+const OutboxChannel = "outbox_events"
+
+// maxNotification keeps notifications under the 8000 bytes Postgres
+// allows. Events with larger payloads are notified without the payload.
+const maxNotification = 7900
+
+// insertOutboxEvent writes a domain event about pvzID in the transaction of
+// the change and notifies it on OutboxChannel. Changes of one pickup point
+// are serialized until commit, so that the sequence of their events follows
+// the order of the commits.
+func insertOutboxEvent(tx *sql.Tx, builder squirrel.StatementBuilderType, eventType, pvzID string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", pvzID); err != nil {
+		return translateError(err)
+	}
+	event := models.OutboxEvent{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		PvzID:      pvzID,
+		Payload:    body,
+		OccurredAt: time.Now(),
+	}
+	query, args, err := builder.
+		Insert("outbox").
+		Columns("eventId", "eventType", "pvzId", "payload", "occurredAt", "nextAttemptAt").
+		Values(event.ID, eventType, pvzID, string(body), event.OccurredAt, event.OccurredAt).
+		Suffix("RETURNING sequence").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	if err = tx.QueryRow(query, args...).Scan(&event.Sequence); err != nil {
+		return translateError(err)
+	}
+
+	notification, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(notification) > maxNotification {
+		event.Payload = nil
+		if notification, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec("SELECT pg_notify($1, $2)", OutboxChannel, string(notification))
+	return translateError(err)
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"avito-intern/internal/models"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectOutboxEvent expects the event of a change to be written and
+// notified in its transaction.
+func expectOutboxEvent(mock sqlmock.Sqlmock, eventType, pvzID string) {
+	mock.ExpectExec("SELECT pg_advisory_xact_lock\\(hashtext\\(\\$1\\)\\)").
+		WithArgs(pvzID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO outbox \\(eventId,eventType,pvzId,payload,occurredAt,nextAttemptAt\\) .+ RETURNING sequence").
+		WithArgs(sqlmock.AnyArg(), eventType, pvzID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(1))
+	mock.ExpectExec("SELECT pg_notify\\(\\$1, \\$2\\)").
+		WithArgs(OutboxChannel, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestOutboxRepository_ClaimOutboxEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	now := time.Now()
+	leaseUntil := now.Add(time.Minute)
+
+	columns := []string{"sequence", "eventId", "eventType", "pvzId", "payload", "occurredAt", "attempts", "lastError"}
+	mock.ExpectQuery("UPDATE outbox SET nextAttemptAt = \\$1 WHERE sequence IN \\(SELECT o.sequence FROM outbox o "+
+		"WHERE o.publishedAt IS NULL AND o.nextAttemptAt <= \\$2 AND NOT EXISTS \\(.+\\) "+
+		"ORDER BY o.sequence LIMIT 10 FOR UPDATE SKIP LOCKED\\) RETURNING sequence").
+		WithArgs(leaseUntil, now).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(7, "event-7", models.EventProductAdded, "pvz-2", []byte(`{"id":"product"}`), now, 0, "").
+			AddRow(3, "event-3", models.EventPVZCreated, "pvz-1", []byte(`{"id":"pvz-1"}`), now, 2, "timeout"))
+
+	events, err := repo.ClaimOutboxEvents(10, now, leaseUntil)
+
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, int64(3), events[0].Sequence, "events are in sequence order")
+		assert.Equal(t, "pvz-1", events[0].PvzID)
+		assert.Equal(t, 2, events[0].Attempts)
+		assert.Equal(t, "timeout", events[0].LastError)
+		assert.JSONEq(t, `{"id":"product"}`, string(events[1].Payload))
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_ClaimOutboxEvents_DatabaseError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+
+	mock.ExpectQuery("UPDATE outbox").WillReturnError(sql.ErrConnDone)
+
+	events, err := repo.ClaimOutboxEvents(10, time.Now(), time.Now())
+
+	assert.Equal(t, sql.ErrConnDone, err)
+	assert.Nil(t, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_MarkOutboxEventPublished(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE outbox SET publishedAt = \\$1, attempts = attempts \\+ 1, lastError = \\$2 WHERE sequence = \\$3").
+		WithArgs(now, "", int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.MarkOutboxEventPublished(3, now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_RecordOutboxFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	retryAt := time.Now().Add(time.Second)
+
+	mock.ExpectExec("UPDATE outbox SET nextAttemptAt = \\$1, attempts = attempts \\+ 1, lastError = \\$2 WHERE sequence = \\$3").
+		WithArgs(retryAt, "connection refused", int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.RecordOutboxFailure(3, retryAt, "connection refused"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_DeletePublishedOutboxEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	before := time.Now()
+
+	mock.ExpectExec("DELETE FROM outbox WHERE publishedAt < \\$1").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 12))
+
+	deleted, err := repo.DeletePublishedOutboxEvents(before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
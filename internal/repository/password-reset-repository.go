@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type PasswordResetRepositoryInterface interface {
+	CreateResetToken(token *models.PasswordResetToken) error
+	GetResetTokenByHash(tokenHash string) (*models.PasswordResetToken, error)
+	MarkResetTokenUsed(id string, usedAt time.Time) error
+	InvalidateResetTokens(userID string, usedAt time.Time) error
+}
+
+type PasswordResetRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *PasswordResetRepository) CreateResetToken(token *models.PasswordResetToken) error {
+	query, args, err := r.sqlBuilder.
+		Insert("password_reset_tokens").
+		Columns("id", "userId", "tokenHash", "createdAt", "expiresAt").
+		Values(token.ID, token.UserID, token.TokenHash, token.CreatedAt, token.ExpiresAt).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return err
+}
+
+func (r *PasswordResetRepository) GetResetTokenByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	var usedAt sql.NullTime
+	query, args, err := r.sqlBuilder.
+		Select("id", "userId", "tokenHash", "createdAt", "expiresAt", "usedAt").
+		From("password_reset_tokens").
+		Where(squirrel.Eq{"tokenHash": tokenHash}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.QueryRow(query, args...).Scan(
+		&token.ID, &token.UserID, &token.TokenHash, &token.CreatedAt, &token.ExpiresAt, &usedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internalErrors.ErrInvalidResetToken
+		}
+		return nil, err
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	return &token, nil
+}
+
+// MarkResetTokenUsed consumes the token only if it has not been used yet, so
+// the same link cannot reset the password twice.
+func (r *PasswordResetRepository) MarkResetTokenUsed(id string, usedAt time.Time) error {
+	query, args, err := r.sqlBuilder.
+		Update("password_reset_tokens").
+		Set("usedAt", usedAt).
+		Where(squirrel.Eq{"id": id, "usedAt": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return internalErrors.ErrInvalidResetToken
+	}
+	return nil
+}
+
+// InvalidateResetTokens marks all outstanding tokens of the user as used.
+func (r *PasswordResetRepository) InvalidateResetTokens(userID string, usedAt time.Time) error {
+	query, args, err := r.sqlBuilder.
+		Update("password_reset_tokens").
+		Set("usedAt", usedAt).
+		Where(squirrel.Eq{"userId": userID, "usedAt": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return err
+}
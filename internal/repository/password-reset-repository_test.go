@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordResetRepository_CreateResetToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPasswordResetRepository(db)
+	now := time.Now()
+	token := &models.PasswordResetToken{
+		ID:        "reset-id",
+		UserID:    "user-id",
+		TokenHash: "hash",
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	}
+
+	mock.ExpectExec("INSERT INTO password_reset_tokens").
+		WithArgs("reset-id", "user-id", "hash", now, now.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.CreateResetToken(token))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPasswordResetRepository_GetResetTokenByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPasswordResetRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT id, userId, tokenHash, createdAt, expiresAt, usedAt FROM password_reset_tokens WHERE tokenHash = \\$1").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "userId", "tokenHash", "createdAt", "expiresAt", "usedAt"}).
+			AddRow("reset-id", "user-id", "hash", now, now.Add(time.Hour), now))
+	mock.ExpectQuery("SELECT (.+) FROM password_reset_tokens").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	token, err := repo.GetResetTokenByHash("hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-id", token.UserID)
+	assert.NotNil(t, token.UsedAt)
+
+	_, err = repo.GetResetTokenByHash("missing")
+	assert.Equal(t, internalErrors.ErrInvalidResetToken, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPasswordResetRepository_MarkResetTokenUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPasswordResetRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE password_reset_tokens SET usedAt = \\$1 WHERE id = \\$2 AND usedAt IS NULL").
+		WithArgs(now, "reset-id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE password_reset_tokens SET usedAt").
+		WithArgs(now, "reset-id").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.MarkResetTokenUsed("reset-id", now))
+	assert.Equal(t, internalErrors.ErrInvalidResetToken, repo.MarkResetTokenUsed("reset-id", now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPasswordResetRepository_InvalidateResetTokens(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPasswordResetRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE password_reset_tokens SET usedAt = \\$1 WHERE usedAt IS NULL AND userId = \\$2").
+		WithArgs(now, "user-id").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.InvalidateResetTokens("user-id", now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
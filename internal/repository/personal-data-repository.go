@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"avito-intern/internal/models"
+	"database/sql"
+	"errors"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// PersonalDataRepositoryInterface collects and erases everything stored
+// about a single user across tables.
+type PersonalDataRepositoryInterface interface {
+	ExportUserData(user *models.User, attemptKey string) (*models.UserDataExport, error)
+	EraseUser(erasure models.UserErasure) error
+}
+
+type PersonalDataRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewPersonalDataRepository(db *sql.DB) *PersonalDataRepository {
+	return &PersonalDataRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *PersonalDataRepository) ExportUserData(user *models.User, attemptKey string) (*models.UserDataExport, error) {
+	export := &models.UserDataExport{Account: user}
+	var err error
+	if export.TwoFactor, err = r.exportTwoFactor(user.ID); err != nil {
+		return nil, err
+	}
+	if export.LoginAttempts, err = r.exportLoginAttempts(attemptKey); err != nil {
+		return nil, err
+	}
+	if export.PasswordResets, err = r.exportPasswordResets(user.ID); err != nil {
+		return nil, err
+	}
+	if export.InvitesCreated, err = r.exportInvites(squirrel.Eq{"createdBy": user.ID}); err != nil {
+		return nil, err
+	}
+	if export.InvitesReceived, err = r.exportInvites(squirrel.Eq{"email": user.Email}); err != nil {
+		return nil, err
+	}
+	if export.APIKeys, err = r.exportAPIKeys(user.ID); err != nil {
+		return nil, err
+	}
+	if export.PVZIDs, err = r.exportPVZIDs(user.ID); err != nil {
+		return nil, err
+	}
+	if export.AuditEvents, err = r.exportAuditEvents(user.ID); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// EraseUser replaces the email and password of the user, drops credentials
+// and login history, revokes the API keys the user created and rewrites
+// invites sent to the old email, all in one transaction with its audit
+// record.
+func (r *PersonalDataRepository) EraseUser(erasure models.UserErasure) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []squirrel.Sqlizer{
+		r.sqlBuilder.
+			Update("users").
+			Set("email", erasure.PseudonymEmail).
+			Set("password", erasure.PasswordHash).
+			Set("status", "erased").
+			Set("erasedAt", erasure.ErasedAt).
+			Set("tokenVersion", squirrel.Expr("tokenVersion + 1")).
+			Where(squirrel.Eq{"id": erasure.UserID}),
+		r.sqlBuilder.Delete("recovery_codes").Where(squirrel.Eq{"userId": erasure.UserID}),
+		r.sqlBuilder.Delete("user_totp").Where(squirrel.Eq{"userId": erasure.UserID}),
+		r.sqlBuilder.Delete("password_reset_tokens").Where(squirrel.Eq{"userId": erasure.UserID}),
+		r.sqlBuilder.Delete("login_attempts").Where(squirrel.Eq{"attemptKey": erasure.AttemptKey}),
+		r.sqlBuilder.
+			Update("invites").
+			Set("email", erasure.PseudonymEmail).
+			Where(squirrel.Eq{"email": erasure.Email}),
+		r.sqlBuilder.
+			Update("api_keys").
+			Set("revokedAt", erasure.ErasedAt).
+			Where(squirrel.Eq{"createdBy": erasure.UserID, "revokedAt": nil}),
+	}
+	if erasure.Audit != nil {
+		statements = append(statements, auditEventInsert(r.sqlBuilder, erasure.Audit))
+	}
+	for _, statement := range statements {
+		query, args, err := statement.ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err = tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PersonalDataRepository) exportTwoFactor(userID string) (*models.TOTPSecret, error) {
+	query, args, err := r.sqlBuilder.
+		Select("userId", "confirmed", "createdAt").
+		From("user_totp").
+		Where(squirrel.Eq{"userId": userID}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var secret models.TOTPSecret
+	err = r.db.QueryRow(query, args...).Scan(&secret.UserID, &secret.Confirmed, &secret.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &secret, nil
+}
+
+func (r *PersonalDataRepository) exportLoginAttempts(attemptKey string) ([]*models.LoginAttempt, error) {
+	query, args, err := r.sqlBuilder.
+		Select("attemptKey", "failures", "lastFailureAt", "lockedUntil").
+		From("login_attempts").
+		Where(squirrel.Eq{"attemptKey": attemptKey}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := make([]*models.LoginAttempt, 0)
+	for rows.Next() {
+		var attempt models.LoginAttempt
+		var lockedUntil sql.NullTime
+		if err = rows.Scan(&attempt.Key, &attempt.Failures, &attempt.LastFailureAt, &lockedUntil); err != nil {
+			return nil, err
+		}
+		if lockedUntil.Valid {
+			attempt.LockedUntil = &lockedUntil.Time
+		}
+		attempts = append(attempts, &attempt)
+	}
+	return attempts, rows.Err()
+}
+
+func (r *PersonalDataRepository) exportPasswordResets(userID string) ([]*models.PasswordResetToken, error) {
+	query, args, err := r.sqlBuilder.
+		Select("id", "userId", "createdAt", "expiresAt", "usedAt").
+		From("password_reset_tokens").
+		Where(squirrel.Eq{"userId": userID}).
+		OrderBy("createdAt").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]*models.PasswordResetToken, 0)
+	for rows.Next() {
+		var token models.PasswordResetToken
+		var usedAt sql.NullTime
+		if err = rows.Scan(&token.ID, &token.UserID, &token.CreatedAt, &token.ExpiresAt, &usedAt); err != nil {
+			return nil, err
+		}
+		if usedAt.Valid {
+			token.UsedAt = &usedAt.Time
+		}
+		tokens = append(tokens, &token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *PersonalDataRepository) exportInvites(where squirrel.Eq) ([]*models.Invite, error) {
+	query, args, err := r.sqlBuilder.
+		Select("id", "email", "role", "createdBy", "createdAt", "expiresAt", "usedAt").
+		From("invites").
+		Where(where).
+		OrderBy("createdAt").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := make([]*models.Invite, 0)
+	for rows.Next() {
+		var invite models.Invite
+		var usedAt sql.NullTime
+		err = rows.Scan(&invite.ID, &invite.Email, &invite.Role, &invite.CreatedBy, &invite.CreatedAt, &invite.ExpiresAt, &usedAt)
+		if err != nil {
+			return nil, err
+		}
+		if usedAt.Valid {
+			invite.UsedAt = &usedAt.Time
+		}
+		invites = append(invites, &invite)
+	}
+	return invites, rows.Err()
+}
+
+func (r *PersonalDataRepository) exportAPIKeys(userID string) ([]*models.APIKey, error) {
+	query, args, err := r.sqlBuilder.
+		Select(apiKeyColumns...).
+		From("api_keys").
+		Where(squirrel.Eq{"createdBy": userID}).
+		OrderBy("createdAt").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *PersonalDataRepository) exportPVZIDs(userID string) ([]string, error) {
+	query, args, err := r.sqlBuilder.
+		Select("pvzId").
+		From("user_pvz").
+		Where(squirrel.Eq{"userId": userID}).
+		OrderBy("pvzId").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pvzIDs := make([]string, 0)
+	for rows.Next() {
+		var pvzID string
+		if err = rows.Scan(&pvzID); err != nil {
+			return nil, err
+		}
+		pvzIDs = append(pvzIDs, pvzID)
+	}
+	return pvzIDs, rows.Err()
+}
+
+// exportAuditEvents returns both actions performed by the user and actions
+// performed on them, e.g. impersonated sessions.
+func (r *PersonalDataRepository) exportAuditEvents(userID string) ([]*models.AuditEvent, error) {
+	query, args, err := r.sqlBuilder.
+		Select("id", "actorId", "userId", "action", "details", "createdAt").
+		From("audit_events").
+		Where(squirrel.Or{squirrel.Eq{"actorId": userID}, squirrel.Eq{"userId": userID}}).
+		OrderBy("createdAt").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*models.AuditEvent, 0)
+	for rows.Next() {
+		var event models.AuditEvent
+		var subjectID sql.NullString
+		if err = rows.Scan(&event.ID, &event.ActorID, &subjectID, &event.Action, &event.Details, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.UserID = subjectID.String
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
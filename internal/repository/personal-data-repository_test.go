@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"avito-intern/internal/models"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersonalDataRepository_ExportUserData(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPersonalDataRepository(db)
+	now := time.Now()
+	user := &models.User{ID: "user-id", Email: "user@example.com", Role: "employee", Status: "active"}
+
+	mock.ExpectQuery("SELECT userId, confirmed, createdAt FROM user_totp WHERE userId = \\$1").
+		WithArgs("user-id").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT (.+) FROM login_attempts WHERE attemptKey = \\$1").
+		WithArgs("account:user@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"attemptKey", "failures", "lastFailureAt", "lockedUntil"}).
+			AddRow("account:user@example.com", 2, now, nil))
+	mock.ExpectQuery("SELECT (.+) FROM password_reset_tokens WHERE userId = \\$1").
+		WithArgs("user-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "userId", "createdAt", "expiresAt", "usedAt"}).
+			AddRow("reset-id", "user-id", now, now.Add(time.Hour), now))
+	mock.ExpectQuery("SELECT (.+) FROM invites WHERE createdBy = \\$1").
+		WithArgs("user-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role", "createdBy", "createdAt", "expiresAt", "usedAt"}))
+	mock.ExpectQuery("SELECT (.+) FROM invites WHERE email = \\$1").
+		WithArgs("user@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role", "createdBy", "createdAt", "expiresAt", "usedAt"}).
+			AddRow("invite-id", "user@example.com", "employee", "admin-id", now, now.Add(time.Hour), now))
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE createdBy = \\$1").
+		WithArgs("user-id").
+		WillReturnRows(sqlmock.NewRows(apiKeyRowColumns))
+	mock.ExpectQuery("SELECT pvzId FROM user_pvz WHERE userId = \\$1").
+		WithArgs("user-id").
+		WillReturnRows(sqlmock.NewRows([]string{"pvzId"}).AddRow("pvz-id"))
+	mock.ExpectQuery("SELECT (.+) FROM audit_events WHERE \\(actorId = \\$1 OR userId = \\$2\\)").
+		WithArgs("user-id", "user-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actorId", "userId", "action", "details", "createdAt"}).
+			AddRow("event-id", "admin-id", "user-id", models.AuditImpersonationStart, "", now))
+
+	export, err := repo.ExportUserData(user, "account:user@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, user, export.Account)
+	assert.Nil(t, export.TwoFactor)
+	assert.Len(t, export.LoginAttempts, 1)
+	assert.Len(t, export.PasswordResets, 1)
+	assert.Empty(t, export.InvitesCreated)
+	assert.Len(t, export.InvitesReceived, 1)
+	assert.Empty(t, export.APIKeys)
+	assert.Equal(t, []string{"pvz-id"}, export.PVZIDs)
+	assert.Len(t, export.AuditEvents, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPersonalDataRepository_EraseUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPersonalDataRepository(db)
+	now := time.Now()
+	erasure := models.UserErasure{
+		UserID:         "user-id",
+		Email:          "user@example.com",
+		AttemptKey:     "account:user@example.com",
+		PseudonymEmail: "erased-user-id@erased.invalid",
+		PasswordHash:   "hash",
+		ErasedAt:       now,
+		Audit: &models.AuditEvent{
+			ID:        "event-id",
+			ActorID:   "admin-id",
+			UserID:    "user-id",
+			Action:    models.AuditUserErased,
+			CreatedAt: now,
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET email = \\$1, password = \\$2, status = \\$3, erasedAt = \\$4, tokenVersion = tokenVersion \\+ 1 WHERE id = \\$5").
+		WithArgs("erased-user-id@erased.invalid", "hash", "erased", now, "user-id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM recovery_codes WHERE userId = \\$1").WithArgs("user-id").WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectExec("DELETE FROM user_totp WHERE userId = \\$1").WithArgs("user-id").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM password_reset_tokens WHERE userId = \\$1").WithArgs("user-id").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM login_attempts WHERE attemptKey = \\$1").WithArgs("account:user@example.com").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE invites SET email = \\$1 WHERE email = \\$2").
+		WithArgs("erased-user-id@erased.invalid", "user@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE api_keys SET revokedAt = \\$1 WHERE createdBy = \\$2 AND revokedAt IS NULL").
+		WithArgs(now, "user-id").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO audit_events \\(id,actorId,userId,action,details,createdAt\\) VALUES \\(\\$1,\\$2,\\$3,\\$4,\\$5,\\$6\\)").
+		WithArgs("event-id", "admin-id", "user-id", models.AuditUserErased, "", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.EraseUser(erasure))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPersonalDataRepository_EraseUser_Rollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPersonalDataRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM recovery_codes").WillReturnError(errors.New("database error"))
+	mock.ExpectRollback()
+
+	assert.Error(t, repo.EraseUser(models.UserErasure{UserID: "user-id"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
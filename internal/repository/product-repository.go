@@ -12,9 +12,10 @@ import (
 )
 
 type ProductRepositoryInterface interface {
-	AddProduct(product *models.Product) error
+	AddProduct(product *models.Product, receptionsVersion int) error
 	GetLastProduct(receptionID string) (*models.Product, error)
-	DeleteProduct(id string) error
+	DeleteProduct(id string, receptionsVersion int) error
+	ListProducts(receptionIDs []string) ([]*models.Product, error)
 }
 
 type ProductRepository struct {
@@ -29,7 +30,10 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 	}
 }
 
-func (r *ProductRepository) AddProduct(product *models.Product) error {
+// AddProduct saves product together with its product.added event. A
+// positive receptionsVersion has to be the receptions version of the pickup
+// point, see bumpReceptionsVersion.
+func (r *ProductRepository) AddProduct(product *models.Product, receptionsVersion int) error {
 	if product.ID == "" {
 		product.ID = uuid.New().String()
 	}
@@ -44,8 +48,19 @@ func (r *ProductRepository) AddProduct(product *models.Product) error {
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec(query, args...)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(query, args...); err != nil {
+		return translateError(err)
+	}
+	if err = r.recordChange(tx, models.EventProductAdded, product, receptionsVersion); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *ProductRepository) GetLastProduct(receptionID string) (*models.Product, error) {
@@ -65,29 +80,92 @@ func (r *ProductRepository) GetLastProduct(receptionID string) (*models.Product,
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, internalErrors.ErrProductNotFound
 		}
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &product, nil
 }
 
-func (r *ProductRepository) DeleteProduct(productID string) error {
+// DeleteProduct removes the product together with its product.removed
+// event. A positive receptionsVersion has to be the receptions version of
+// the pickup point, see bumpReceptionsVersion.
+func (r *ProductRepository) DeleteProduct(productID string, receptionsVersion int) error {
 	query, args, err := r.sqlBuilder.
 		Delete("products").
 		Where(squirrel.Eq{"id": productID}).
+		Suffix("RETURNING id, dateTime, type, receptionId").
 		ToSql()
 	if err != nil {
 		return err
 	}
-	res, err := r.db.Exec(query, args...)
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
-	rowsAffected, err := res.RowsAffected()
+	defer tx.Rollback()
+
+	var product models.Product
+	err = tx.QueryRow(query, args...).Scan(&product.ID, &product.DateTime, &product.Type, &product.ReceptionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("no product deleted")
+	}
 	if err != nil {
+		return translateError(err)
+	}
+	if err = r.recordChange(tx, models.EventProductRemoved, &product, receptionsVersion); err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
-		return errors.New("no product deleted")
+	return tx.Commit()
+}
+
+// recordChange bumps the receptions version of the pickup point of the
+// product's reception and writes an event about product under it.
+func (r *ProductRepository) recordChange(tx *sql.Tx, eventType string, product *models.Product, receptionsVersion int) error {
+	query, args, err := r.sqlBuilder.
+		Select("pvzId").
+		From("receptions").
+		Where(squirrel.Eq{"id": product.ReceptionID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	var pvzID string
+	if err = tx.QueryRow(query, args...).Scan(&pvzID); err != nil {
+		return translateError(err)
+	}
+	if err = bumpReceptionsVersion(tx, r.sqlBuilder, pvzID, receptionsVersion); err != nil {
+		return err
+	}
+	return insertOutboxEvent(tx, r.sqlBuilder, eventType, pvzID, product)
+}
+
+// ListProducts returns the products of the given receptions in the order
+// they were added.
+func (r *ProductRepository) ListProducts(receptionIDs []string) ([]*models.Product, error) {
+	products := make([]*models.Product, 0)
+	if len(receptionIDs) == 0 {
+		return products, nil
+	}
+	query, args, err := r.sqlBuilder.
+		Select("id", "dateTime", "type", "receptionId").
+		From("products").
+		Where(squirrel.Eq{"receptionId": receptionIDs}).
+		OrderBy("dateTime").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var product models.Product
+		if err := rows.Scan(&product.ID, &product.DateTime, &product.Type, &product.ReceptionID); err != nil {
+			return nil, err
+		}
+		products = append(products, &product)
 	}
-	return nil
+	return products, rows.Err()
 }
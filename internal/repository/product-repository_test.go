@@ -29,11 +29,18 @@ func TestProductRepository_AddProduct_Success(t *testing.T) {
 		ReceptionID: "test-reception",
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO products").
 		WithArgs("test-id", sqlmock.AnyArg(), "электроника", "test-reception").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT pvzId FROM receptions WHERE id = \\$1").
+		WithArgs("test-reception").
+		WillReturnRows(sqlmock.NewRows([]string{"pvzId"}).AddRow("test-pvz"))
+	expectReceptionsVersion(mock, "test-pvz")
+	expectOutboxEvent(mock, models.EventProductAdded, "test-pvz")
+	mock.ExpectCommit()
 
-	err = repo.AddProduct(product)
+	err = repo.AddProduct(product, 0)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -56,11 +63,13 @@ func TestProductRepository_AddProduct_DatabaseError(t *testing.T) {
 		ReceptionID: "test-reception",
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO products").
 		WithArgs("test-id", sqlmock.AnyArg(), "электроника", "test-reception").
 		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
 
-	err = repo.AddProduct(product)
+	err = repo.AddProduct(product, 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -148,11 +157,19 @@ func TestProductRepository_DeleteProduct_Success(t *testing.T) {
 
 	repo := NewProductRepository(db)
 
-	mock.ExpectExec("DELETE FROM products").
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM products WHERE id = \\$1 RETURNING id, dateTime, type, receptionId").
 		WithArgs("test-id").
-		WillReturnResult(sqlmock.NewResult(1, 1))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dateTime", "type", "receptionId"}).
+			AddRow("test-id", time.Now(), "обувь", "test-reception"))
+	mock.ExpectQuery("SELECT pvzId FROM receptions").
+		WithArgs("test-reception").
+		WillReturnRows(sqlmock.NewRows([]string{"pvzId"}).AddRow("test-pvz"))
+	expectReceptionsVersion(mock, "test-pvz")
+	expectOutboxEvent(mock, models.EventProductRemoved, "test-pvz")
+	mock.ExpectCommit()
 
-	err = repo.DeleteProduct("test-id")
+	err = repo.DeleteProduct("test-id", 0)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -168,11 +185,13 @@ func TestProductRepository_DeleteProduct_NoRowsAffected(t *testing.T) {
 
 	repo := NewProductRepository(db)
 
-	mock.ExpectExec("DELETE FROM products").
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM products").
 		WithArgs("nonexistent-id").
-		WillReturnResult(sqlmock.NewResult(0, 0))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dateTime", "type", "receptionId"}))
+	mock.ExpectRollback()
 
-	err = repo.DeleteProduct("nonexistent-id")
+	err = repo.DeleteProduct("nonexistent-id", 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, "no product deleted", err.Error())
@@ -189,18 +208,20 @@ func TestProductRepository_DeleteProduct_DatabaseError(t *testing.T) {
 
 	repo := NewProductRepository(db)
 
-	mock.ExpectExec("DELETE FROM products").
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM products").
 		WithArgs("test-id").
 		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
 
-	err = repo.DeleteProduct("test-id")
+	err = repo.DeleteProduct("test-id", 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestProductRepository_DeleteProduct_RowsAffectedError(t *testing.T) {
+func TestProductRepository_DeleteProduct_OutboxError(t *testing.T) {
 
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -210,15 +231,97 @@ func TestProductRepository_DeleteProduct_RowsAffectedError(t *testing.T) {
 
 	repo := NewProductRepository(db)
 
-	result := sqlmock.NewErrorResult(errors.New("rows affected error"))
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM products").
+		WithArgs("test-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dateTime", "type", "receptionId"}).
+			AddRow("test-id", time.Now(), "обувь", "test-reception"))
+	mock.ExpectQuery("SELECT pvzId FROM receptions").
+		WithArgs("test-reception").
+		WillReturnRows(sqlmock.NewRows([]string{"pvzId"}).AddRow("test-pvz"))
+	expectReceptionsVersion(mock, "test-pvz")
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO outbox").WillReturnError(errors.New("outbox error"))
+	mock.ExpectRollback()
+
+	err = repo.DeleteProduct("test-id", 0)
 
-	mock.ExpectExec("DELETE FROM products").
+	assert.EqualError(t, err, "outbox error")
+	assert.NoError(t, mock.ExpectationsWereMet(), "the product is not deleted without its event")
+}
+
+func TestProductRepository_DeleteProduct_ReceptionsVersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewProductRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM products").
 		WithArgs("test-id").
-		WillReturnResult(result)
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dateTime", "type", "receptionId"}).
+			AddRow("test-id", time.Now(), "обувь", "test-reception"))
+	mock.ExpectQuery("SELECT pvzId FROM receptions").
+		WithArgs("test-reception").
+		WillReturnRows(sqlmock.NewRows([]string{"pvzId"}).AddRow("test-pvz"))
+	mock.ExpectExec("UPDATE pvz SET receptionsVersion = receptionsVersion \\+ 1 WHERE id = \\$1 AND receptionsVersion = \\$2").
+		WithArgs("test-pvz", 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.DeleteProduct("test-id", 3)
+
+	assert.ErrorIs(t, err, internalErrors.ErrVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet(), "the product is not deleted")
+}
+
+func TestProductRepository_ListProducts_Success(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewProductRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "dateTime", "type", "receptionId"}).
+		AddRow("product-1", now, "обувь", "reception-1").
+		AddRow("product-2", now, "одежда", "reception-1")
+
+	mock.ExpectQuery("SELECT id, dateTime, type, receptionId FROM products WHERE receptionId IN").
+		WithArgs("reception-1").
+		WillReturnRows(rows)
+
+	products, err := repo.ListProducts([]string{"reception-1"})
+
+	assert.NoError(t, err)
+	assert.Len(t, products, 2)
+	assert.Equal(t, "product-2", products[1].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductRepository_ListProducts_DatabaseError(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewProductRepository(db)
+
+	mock.ExpectQuery("SELECT id, dateTime, type, receptionId FROM products").
+		WithArgs("reception-1").
+		WillReturnError(sql.ErrConnDone)
 
-	err = repo.DeleteProduct("test-id")
+	products, err := repo.ListProducts([]string{"reception-1"})
 
 	assert.Error(t, err)
-	assert.Equal(t, "rows affected error", err.Error())
+	assert.Nil(t, products)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type PVZAssignmentRepositoryInterface interface {
+	AssignPVZ(userID, pvzID string) error
+	UnassignPVZ(userID, pvzID string) error
+	ListUserPVZs(userID string) ([]string, error)
+	ListPVZUsers(pvzID string) ([]string, error)
+}
+
+type PVZAssignmentRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewPVZAssignmentRepository(db *sql.DB) *PVZAssignmentRepository {
+	return &PVZAssignmentRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+func (r *PVZAssignmentRepository) AssignPVZ(userID, pvzID string) error {
+	query, args, err := r.sqlBuilder.
+		Insert("user_pvz").
+		Columns("userId", "pvzId").
+		Values(userID, pvzID).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+func (r *PVZAssignmentRepository) UnassignPVZ(userID, pvzID string) error {
+	query, args, err := r.sqlBuilder.
+		Delete("user_pvz").
+		Where(squirrel.Eq{"userId": userID, "pvzId": pvzID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+func (r *PVZAssignmentRepository) ListUserPVZs(userID string) ([]string, error) {
+	return r.list("pvzId", squirrel.Eq{"userId": userID})
+}
+
+func (r *PVZAssignmentRepository) ListPVZUsers(pvzID string) ([]string, error) {
+	return r.list("userId", squirrel.Eq{"pvzId": pvzID})
+}
+
+func (r *PVZAssignmentRepository) list(column string, where squirrel.Eq) ([]string, error) {
+	query, args, err := r.sqlBuilder.
+		Select(column).
+		From("user_pvz").
+		Where(where).
+		OrderBy(column).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
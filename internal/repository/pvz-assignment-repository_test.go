@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPVZAssignmentRepository_AssignAndUnassign(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPVZAssignmentRepository(db)
+
+	mock.ExpectExec("INSERT INTO user_pvz \\(userId,pvzId\\) VALUES \\(\\$1,\\$2\\) ON CONFLICT DO NOTHING").
+		WithArgs("user-id", "pvz-id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM user_pvz WHERE pvzId = \\$1 AND userId = \\$2").
+		WithArgs("pvz-id", "user-id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.AssignPVZ("user-id", "pvz-id"))
+	assert.NoError(t, repo.UnassignPVZ("user-id", "pvz-id"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZAssignmentRepository_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPVZAssignmentRepository(db)
+
+	mock.ExpectQuery("SELECT pvzId FROM user_pvz WHERE userId = \\$1 ORDER BY pvzId").
+		WithArgs("user-id").
+		WillReturnRows(sqlmock.NewRows([]string{"pvzId"}).AddRow("pvz-1").AddRow("pvz-2"))
+	mock.ExpectQuery("SELECT userId FROM user_pvz WHERE pvzId = \\$1 ORDER BY userId").
+		WithArgs("pvz-1").
+		WillReturnRows(sqlmock.NewRows([]string{"userId"}))
+
+	pvzs, err := repo.ListUserPVZs("user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pvz-1", "pvz-2"}, pvzs)
+
+	users, err := repo.ListPVZUsers("pvz-1")
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"avito-intern/internal/api/dto/internalErrors"
 	"avito-intern/internal/models"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -11,6 +13,9 @@ import (
 type PVZRepositoryInterface interface {
 	CreatePVZ(pvz *models.PVZ) error
 	ListPVZ(limit, offset int, startDate, endDate *time.Time) ([]*models.PVZ, error)
+	GetPVZByID(id string) (*models.PVZ, error)
+	CountPVZ() (int, error)
+	UpdatePVZ(pvz *models.PVZ, expectedVersion int) error
 }
 
 type PVZRepository struct {
@@ -25,6 +30,7 @@ func NewPVZRepository(db *sql.DB) *PVZRepository {
 	}
 }
 
+// CreatePVZ saves pvz together with its pvz.created event.
 func (r *PVZRepository) CreatePVZ(pvz *models.PVZ) error {
 	query, args, err := r.sqlBuilder.
 		Insert("pvz").
@@ -34,13 +40,24 @@ func (r *PVZRepository) CreatePVZ(pvz *models.PVZ) error {
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec(query, args...)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(query, args...); err != nil {
+		return translateError(err)
+	}
+	if err = insertOutboxEvent(tx, r.sqlBuilder, models.EventPVZCreated, pvz.ID, pvz); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *PVZRepository) ListPVZ(limit, offset int, startDate, endDate *time.Time) ([]*models.PVZ, error) {
 	q := r.sqlBuilder.
-		Select("id", "registrationDate", "city").
+		Select("id", "registrationDate", "city", "version").
 		From("pvz")
 	if startDate != nil {
 		q = q.Where("registrationDate >= ?", *startDate)
@@ -63,10 +80,72 @@ func (r *PVZRepository) ListPVZ(limit, offset int, startDate, endDate *time.Time
 	pvzs := make([]*models.PVZ, 0)
 	for rows.Next() {
 		var pvz models.PVZ
-		if err := rows.Scan(&pvz.ID, &pvz.RegistrationDate, &pvz.City); err != nil {
+		if err := rows.Scan(&pvz.ID, &pvz.RegistrationDate, &pvz.City, &pvz.Version); err != nil {
 			continue
 		}
 		pvzs = append(pvzs, &pvz)
 	}
 	return pvzs, nil
 }
+
+func (r *PVZRepository) GetPVZByID(id string) (*models.PVZ, error) {
+	var pvz models.PVZ
+	query, args, err := r.sqlBuilder.
+		Select("id", "registrationDate", "city", "version", "receptionsVersion").
+		From("pvz").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.QueryRow(query, args...).Scan(&pvz.ID, &pvz.RegistrationDate, &pvz.City, &pvz.Version, &pvz.ReceptionsVersion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internalErrors.ErrPVZNotFound
+		}
+		return nil, translateError(err)
+	}
+	return &pvz, nil
+}
+
+func (r *PVZRepository) CountPVZ() (int, error) {
+	query, args, err := r.sqlBuilder.Select("COUNT(*)").From("pvz").ToSql()
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = r.db.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
+
+// UpdatePVZ saves the changed city of pvz if its stored version is still
+// expectedVersion and increments the version. A newer version gives a
+// ConflictError with the stored pickup point.
+func (r *PVZRepository) UpdatePVZ(pvz *models.PVZ, expectedVersion int) error {
+	query, args, err := r.sqlBuilder.
+		Update("pvz").
+		Set("city", pvz.City).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": pvz.ID, "version": expectedVersion}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		current, err := r.GetPVZByID(pvz.ID)
+		if err != nil {
+			return err
+		}
+		return &internalErrors.ConflictError{Err: internalErrors.ErrVersionConflict, Current: current}
+	}
+	pvz.Version = expectedVersion + 1
+	return nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"avito-intern/internal/api/dto/internalErrors"
 	"avito-intern/internal/models"
 	"database/sql"
 	"testing"
@@ -33,9 +34,12 @@ func TestPVZRepository_CreatePVZ(t *testing.T) {
 				City:             "Москва",
 			},
 			mock: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO pvz").
 					WithArgs("test-id", sqlmock.AnyArg(), "Москва").
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectOutboxEvent(mock, models.EventPVZCreated, "test-id")
+				mock.ExpectCommit()
 			},
 			wantErr: false,
 		},
@@ -47,9 +51,11 @@ func TestPVZRepository_CreatePVZ(t *testing.T) {
 				City:             "Москва",
 			},
 			mock: func() {
+				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO pvz").
 					WithArgs("test-id", sqlmock.AnyArg(), "Москва").
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			wantErr: true,
 		},
@@ -98,10 +104,10 @@ func TestPVZRepository_ListPVZ(t *testing.T) {
 			startDate: nil,
 			endDate:   nil,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "registrationDate", "city"}).
-					AddRow("1", now, "Москва").
-					AddRow("2", now, "Санкт-Петербург")
-				mock.ExpectQuery("SELECT id, registrationDate, city FROM pvz").
+				rows := sqlmock.NewRows([]string{"id", "registrationDate", "city", "version"}).
+					AddRow("1", now, "Москва", 1).
+					AddRow("2", now, "Санкт-Петербург", 1)
+				mock.ExpectQuery("SELECT id, registrationDate, city, version FROM pvz").
 					WillReturnRows(rows)
 			},
 			wantErr: false,
@@ -113,9 +119,9 @@ func TestPVZRepository_ListPVZ(t *testing.T) {
 			startDate: &startDate,
 			endDate:   &endDate,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "registrationDate", "city"}).
-					AddRow("1", now, "Москва")
-				mock.ExpectQuery("SELECT id, registrationDate, city FROM pvz").
+				rows := sqlmock.NewRows([]string{"id", "registrationDate", "city", "version"}).
+					AddRow("1", now, "Москва", 1)
+				mock.ExpectQuery("SELECT id, registrationDate, city, version FROM pvz").
 					WithArgs(startDate, endDate).
 					WillReturnRows(rows)
 			},
@@ -128,7 +134,7 @@ func TestPVZRepository_ListPVZ(t *testing.T) {
 			startDate: nil,
 			endDate:   nil,
 			mock: func() {
-				mock.ExpectQuery("SELECT id, registrationDate, city FROM pvz").
+				mock.ExpectQuery("SELECT id, registrationDate, city, version FROM pvz").
 					WillReturnError(sql.ErrConnDone)
 			},
 			wantErr: true,
@@ -150,3 +156,79 @@ func TestPVZRepository_ListPVZ(t *testing.T) {
 		})
 	}
 }
+
+func TestPVZRepository_GetPVZByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPVZRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT id, registrationDate, city, version, receptionsVersion FROM pvz WHERE id = \\$1").
+		WithArgs("pvz-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "registrationDate", "city", "version", "receptionsVersion"}).AddRow("pvz-id", now, "Москва", 1, 4))
+	mock.ExpectQuery("SELECT id, registrationDate, city, version, receptionsVersion FROM pvz WHERE id = \\$1").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM pvz").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	pvz, err := repo.GetPVZByID("pvz-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "Москва", pvz.City)
+	assert.Equal(t, 4, pvz.ReceptionsVersion)
+
+	_, err = repo.GetPVZByID("missing")
+	assert.Equal(t, internalErrors.ErrPVZNotFound, err)
+
+	count, err := repo.CountPVZ()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZRepository_UpdatePVZ(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPVZRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE pvz SET city = \\$1, version = version \\+ 1 WHERE id = \\$2 AND version = \\$3").
+		WithArgs("Казань", "pvz-id", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE pvz").
+		WithArgs("Казань", "pvz-id", 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT id, registrationDate, city, version, receptionsVersion FROM pvz WHERE id = \\$1").
+		WithArgs("pvz-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "registrationDate", "city", "version", "receptionsVersion"}).AddRow("pvz-id", now, "Москва", 2, 1))
+	mock.ExpectExec("UPDATE pvz").
+		WithArgs("Казань", "missing", 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT (.+) FROM pvz").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	pvz := &models.PVZ{ID: "pvz-id", City: "Казань", Version: 1}
+	err = repo.UpdatePVZ(pvz, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pvz.Version)
+
+	err = repo.UpdatePVZ(&models.PVZ{ID: "pvz-id", City: "Казань"}, 1)
+	assert.ErrorIs(t, err, internalErrors.ErrVersionConflict)
+	var conflictErr *internalErrors.ConflictError
+	if assert.ErrorAs(t, err, &conflictErr) {
+		assert.Equal(t, 2, conflictErr.Current.(*models.PVZ).Version)
+	}
+
+	err = repo.UpdatePVZ(&models.PVZ{ID: "missing", City: "Казань"}, 1)
+	assert.Equal(t, internalErrors.ErrPVZNotFound, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
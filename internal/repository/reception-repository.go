@@ -5,14 +5,16 @@ import (
 	"avito-intern/internal/models"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/Masterminds/squirrel"
 )
 
 type ReceptionRepositoryInterface interface {
-	CreateReception(reception *models.Reception) error
+	CreateReception(reception *models.Reception, receptionsVersion int) error
 	GetActiveReception(pvzID string) (*models.Reception, error)
-	CloseReception(receptionID string) error
+	CloseReception(receptionID string, expectedVersion, receptionsVersion int) error
+	ListReceptions(pvzIDs []string) ([]*models.Reception, error)
 }
 
 type ReceptionRepository struct {
@@ -27,7 +29,10 @@ func NewReceptionRepository(db *sql.DB) *ReceptionRepository {
 	}
 }
 
-func (r *ReceptionRepository) CreateReception(reception *models.Reception) error {
+// CreateReception saves reception together with its reception.opened event.
+// A positive receptionsVersion has to be the receptions version of the
+// pickup point, see bumpReceptionsVersion.
+func (r *ReceptionRepository) CreateReception(reception *models.Reception, receptionsVersion int) error {
 	query, args, err := r.sqlBuilder.
 		Insert("receptions").
 		Columns("id", "dateTime", "pvzId", "status").
@@ -36,14 +41,31 @@ func (r *ReceptionRepository) CreateReception(reception *models.Reception) error
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec(query, args...)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(query, args...)
+	if err = translateError(err); errors.Is(err, internalErrors.ErrReferenceNotFound) {
+		return fmt.Errorf("%w: %w", internalErrors.ErrPVZNotFound, err)
+	} else if err != nil {
+		return err
+	}
+	if err = bumpReceptionsVersion(tx, r.sqlBuilder, reception.PvzID, receptionsVersion); err != nil {
+		return err
+	}
+	if err = insertOutboxEvent(tx, r.sqlBuilder, models.EventReceptionOpened, reception.PvzID, reception); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *ReceptionRepository) GetActiveReception(pvzId string) (*models.Reception, error) {
 	var reception models.Reception
 	query, args, err := r.sqlBuilder.
-		Select("id", "dateTime", "pvzId", "status").
+		Select("id", "dateTime", "pvzId", "status", "version").
 		From("receptions").
 		Where(squirrel.Eq{"pvzId": pvzId, "status": "in_progress"}).
 		OrderBy("dateTime DESC").
@@ -52,35 +74,144 @@ func (r *ReceptionRepository) GetActiveReception(pvzId string) (*models.Receptio
 	if err != nil {
 		return nil, err
 	}
-	err = r.db.QueryRow(query, args...).Scan(&reception.ID, &reception.DateTime, &reception.PvzID, &reception.Status)
+	err = r.db.QueryRow(query, args...).Scan(&reception.ID, &reception.DateTime, &reception.PvzID, &reception.Status, &reception.Version)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, internalErrors.ErrNoActiveReception
 		}
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &reception, nil
 }
 
-func (r *ReceptionRepository) CloseReception(receptionID string) error {
+// CloseReception closes the reception if its stored version is still
+// expectedVersion and increments the version, together with the
+// reception.closed event. A newer version gives a ConflictError with the
+// stored reception. A positive receptionsVersion has to be the receptions
+// version of the pickup point, see bumpReceptionsVersion.
+func (r *ReceptionRepository) CloseReception(receptionID string, expectedVersion, receptionsVersion int) error {
 	query, args, err := r.sqlBuilder.
 		Update("receptions").
 		Set("status", "close").
-		Where(squirrel.Eq{"id": receptionID}).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": receptionID, "version": expectedVersion}).
+		Suffix("RETURNING id, dateTime, pvzId, status, version").
 		ToSql()
 	if err != nil {
 		return err
 	}
-	res, err := r.db.Exec(query, args...)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var reception models.Reception
+	err = tx.QueryRow(query, args...).Scan(&reception.ID, &reception.DateTime, &reception.PvzID, &reception.Status, &reception.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		tx.Rollback()
+		current, err := r.getReception(receptionID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return errors.New("no reception updated")
+		}
+		return &internalErrors.ConflictError{Err: internalErrors.ErrVersionConflict, Current: current}
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	if err = bumpReceptionsVersion(tx, r.sqlBuilder, reception.PvzID, receptionsVersion); err != nil {
+		return err
+	}
+	if err = insertOutboxEvent(tx, r.sqlBuilder, models.EventReceptionClosed, reception.PvzID, &reception); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *ReceptionRepository) getReception(id string) (*models.Reception, error) {
+	var reception models.Reception
+	query, args, err := r.sqlBuilder.
+		Select("id", "dateTime", "pvzId", "status", "version").
+		From("receptions").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.QueryRow(query, args...).Scan(&reception.ID, &reception.DateTime, &reception.PvzID, &reception.Status, &reception.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, translateError(err)
+	}
+	return &reception, nil
+}
+
+// ListReceptions returns the receptions of the given pickup points, oldest
+// first.
+func (r *ReceptionRepository) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	receptions := make([]*models.Reception, 0)
+	if len(pvzIDs) == 0 {
+		return receptions, nil
+	}
+	query, args, err := r.sqlBuilder.
+		Select("id", "dateTime", "pvzId", "status", "version").
+		From("receptions").
+		Where(squirrel.Eq{"pvzId": pvzIDs}).
+		OrderBy("dateTime").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reception models.Reception
+		if err := rows.Scan(&reception.ID, &reception.DateTime, &reception.PvzID, &reception.Status, &reception.Version); err != nil {
+			return nil, err
+		}
+		receptions = append(receptions, &reception)
+	}
+	return receptions, rows.Err()
+}
+
+// bumpReceptionsVersion increments the receptions version of the pickup
+// point in tx, which every change of its receptions and products does. A
+// positive expectedVersion has to be the stored version, otherwise the
+// change is based on a state that is gone and ErrVersionConflict is
+// returned. Concurrent changes wait for the row lock and then see the new
+// version, so the check holds until tx ends.
+func bumpReceptionsVersion(tx *sql.Tx, builder squirrel.StatementBuilderType, pvzID string, expectedVersion int) error {
+	where := squirrel.Eq{"id": pvzID}
+	if expectedVersion > 0 {
+		where["receptionsVersion"] = expectedVersion
+	}
+	query, args, err := builder.
+		Update("pvz").
+		Set("receptionsVersion", squirrel.Expr("receptionsVersion + 1")).
+		Where(where).
+		ToSql()
 	if err != nil {
 		return err
 	}
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return translateError(err)
+	}
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
-		return errors.New("no reception updated")
+	if rowsAffected == 0 && expectedVersion > 0 {
+		return internalErrors.ErrVersionConflict
 	}
 	return nil
 }
@@ -8,9 +8,18 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
+// expectReceptionsVersion expects the receptions version of the pickup point
+// to be bumped without a check.
+func expectReceptionsVersion(mock sqlmock.Sqlmock, pvzID string) {
+	mock.ExpectExec("UPDATE pvz SET receptionsVersion = receptionsVersion \\+ 1 WHERE id = \\$1$").
+		WithArgs(pvzID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
 func TestReceptionRepository_CreateReception_Success(t *testing.T) {
 
 	db, mock, err := sqlmock.New()
@@ -28,11 +37,15 @@ func TestReceptionRepository_CreateReception_Success(t *testing.T) {
 		Status:   "in_progress",
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO receptions").
 		WithArgs("test-id", sqlmock.AnyArg(), "test-pvz", "in_progress").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectReceptionsVersion(mock, "test-pvz")
+	expectOutboxEvent(mock, models.EventReceptionOpened, "test-pvz")
+	mock.ExpectCommit()
 
-	err = repo.CreateReception(reception)
+	err = repo.CreateReception(reception, 0)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -55,17 +68,80 @@ func TestReceptionRepository_CreateReception_DatabaseError(t *testing.T) {
 		Status:   "in_progress",
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO receptions").
 		WithArgs("test-id", sqlmock.AnyArg(), "test-pvz", "in_progress").
 		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
 
-	err = repo.CreateReception(reception)
+	err = repo.CreateReception(reception, 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestReceptionRepository_CreateReception_PVZNotFound(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewReceptionRepository(db)
+
+	reception := &models.Reception{
+		ID:       "test-id",
+		DateTime: time.Now(),
+		PvzID:    "test-pvz",
+		Status:   "in_progress",
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO receptions").
+		WithArgs("test-id", sqlmock.AnyArg(), "test-pvz", "in_progress").
+		WillReturnError(&pq.Error{Code: "23503"})
+	mock.ExpectRollback()
+
+	err = repo.CreateReception(reception, 0)
+
+	assert.ErrorIs(t, err, internalErrors.ErrPVZNotFound)
+	assert.ErrorIs(t, err, internalErrors.ErrReferenceNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceptionRepository_CreateReception_ReceptionsVersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewReceptionRepository(db)
+
+	reception := &models.Reception{
+		ID:       "test-id",
+		DateTime: time.Now(),
+		PvzID:    "test-pvz",
+		Status:   "in_progress",
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO receptions").
+		WithArgs("test-id", sqlmock.AnyArg(), "test-pvz", "in_progress").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE pvz SET receptionsVersion = receptionsVersion \\+ 1 WHERE id = \\$1 AND receptionsVersion = \\$2").
+		WithArgs("test-pvz", 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.CreateReception(reception, 3)
+
+	assert.ErrorIs(t, err, internalErrors.ErrVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet(), "the reception is not saved")
+}
+
 func TestReceptionRepository_GetActiveReception_Success(t *testing.T) {
 
 	db, mock, err := sqlmock.New()
@@ -78,9 +154,9 @@ func TestReceptionRepository_GetActiveReception_Success(t *testing.T) {
 
 	now := time.Now()
 
-	rows := sqlmock.NewRows([]string{"id", "dateTime", "pvzId", "status"}).
-		AddRow("test-id", now, "test-pvz", "in_progress")
-	mock.ExpectQuery("SELECT id, dateTime, pvzId, status FROM receptions").
+	rows := sqlmock.NewRows([]string{"id", "dateTime", "pvzId", "status", "version"}).
+		AddRow("test-id", now, "test-pvz", "in_progress", 1)
+	mock.ExpectQuery("SELECT id, dateTime, pvzId, status, version FROM receptions").
 		WithArgs("test-pvz", "in_progress").
 		WillReturnRows(rows)
 
@@ -105,7 +181,7 @@ func TestReceptionRepository_GetActiveReception_NotFound(t *testing.T) {
 
 	repo := NewReceptionRepository(db)
 
-	mock.ExpectQuery("SELECT id, dateTime, pvzId, status FROM receptions").
+	mock.ExpectQuery("SELECT id, dateTime, pvzId, status, version FROM receptions").
 		WithArgs("test-pvz", "in_progress").
 		WillReturnError(sql.ErrNoRows)
 
@@ -127,7 +203,7 @@ func TestReceptionRepository_GetActiveReception_DatabaseError(t *testing.T) {
 
 	repo := NewReceptionRepository(db)
 
-	mock.ExpectQuery("SELECT id, dateTime, pvzId, status FROM receptions").
+	mock.ExpectQuery("SELECT id, dateTime, pvzId, status, version FROM receptions").
 		WithArgs("test-pvz", "in_progress").
 		WillReturnError(sql.ErrConnDone)
 
@@ -149,11 +225,16 @@ func TestReceptionRepository_CloseReception_Success(t *testing.T) {
 
 	repo := NewReceptionRepository(db)
 
-	mock.ExpectExec("UPDATE receptions").
-		WithArgs("close", "test-id").
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE receptions SET status = \\$1, version = version \\+ 1 WHERE id = \\$2 AND version = \\$3 RETURNING id, dateTime, pvzId, status, version").
+		WithArgs("close", "test-id", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dateTime", "pvzId", "status", "version"}).
+			AddRow("test-id", time.Now(), "test-pvz", "close", 2))
+	expectReceptionsVersion(mock, "test-pvz")
+	expectOutboxEvent(mock, models.EventReceptionClosed, "test-pvz")
+	mock.ExpectCommit()
 
-	err = repo.CloseReception("test-id")
+	err = repo.CloseReception("test-id", 1, 0)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -169,17 +250,55 @@ func TestReceptionRepository_CloseReception_NoRowsAffected(t *testing.T) {
 
 	repo := NewReceptionRepository(db)
 
-	mock.ExpectExec("UPDATE receptions").
-		WithArgs("close", "nonexistent-id").
-		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE receptions").
+		WithArgs("close", "nonexistent-id", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dateTime", "pvzId", "status", "version"}))
+	mock.ExpectRollback()
 
-	err = repo.CloseReception("nonexistent-id")
+	mock.ExpectQuery("SELECT id, dateTime, pvzId, status, version FROM receptions WHERE id = \\$1").
+		WithArgs("nonexistent-id").
+		WillReturnError(sql.ErrNoRows)
+
+	err = repo.CloseReception("nonexistent-id", 1, 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, "no reception updated", err.Error())
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestReceptionRepository_CloseReception_VersionConflict(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewReceptionRepository(db)
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE receptions SET status = \\$1, version = version \\+ 1 WHERE id = \\$2 AND version = \\$3").
+		WithArgs("close", "test-id", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dateTime", "pvzId", "status", "version"}))
+	mock.ExpectRollback()
+	mock.ExpectQuery("SELECT id, dateTime, pvzId, status, version FROM receptions WHERE id = \\$1").
+		WithArgs("test-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dateTime", "pvzId", "status", "version"}).
+			AddRow("test-id", now, "test-pvz", "close", 2))
+
+	err = repo.CloseReception("test-id", 1, 0)
+
+	assert.ErrorIs(t, err, internalErrors.ErrVersionConflict)
+	var conflictErr *internalErrors.ConflictError
+	if assert.ErrorAs(t, err, &conflictErr) {
+		assert.Equal(t, "close", conflictErr.Current.(*models.Reception).Status)
+		assert.Equal(t, 2, conflictErr.Current.(*models.Reception).Version)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestReceptionRepository_CloseReception_DatabaseError(t *testing.T) {
 
 	db, mock, err := sqlmock.New()
@@ -190,13 +309,81 @@ func TestReceptionRepository_CloseReception_DatabaseError(t *testing.T) {
 
 	repo := NewReceptionRepository(db)
 
-	mock.ExpectExec("UPDATE receptions").
-		WithArgs("close", "test-id").
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE receptions").
+		WithArgs("close", "test-id", 1).
 		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
 
-	err = repo.CloseReception("test-id")
+	err = repo.CloseReception("test-id", 1, 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestReceptionRepository_ListReceptions_Success(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewReceptionRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "dateTime", "pvzId", "status", "version"}).
+		AddRow("reception-1", now, "pvz-1", "close", 1).
+		AddRow("reception-2", now, "pvz-2", "in_progress", 1)
+
+	mock.ExpectQuery("SELECT id, dateTime, pvzId, status, version FROM receptions WHERE pvzId IN").
+		WithArgs("pvz-1", "pvz-2").
+		WillReturnRows(rows)
+
+	receptions, err := repo.ListReceptions([]string{"pvz-1", "pvz-2"})
+
+	assert.NoError(t, err)
+	assert.Len(t, receptions, 2)
+	assert.Equal(t, "reception-1", receptions[0].ID)
+	assert.Equal(t, "pvz-2", receptions[1].PvzID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceptionRepository_ListReceptions_NoPVZ(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewReceptionRepository(db)
+
+	receptions, err := repo.ListReceptions(nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, receptions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceptionRepository_ListReceptions_DatabaseError(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewReceptionRepository(db)
+
+	mock.ExpectQuery("SELECT id, dateTime, pvzId, status, version FROM receptions").
+		WithArgs("pvz-1").
+		WillReturnError(sql.ErrConnDone)
+
+	receptions, err := repo.ListReceptions([]string{"pvz-1"})
+
+	assert.Error(t, err)
+	assert.Nil(t, receptions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+type TwoFactorRepositoryInterface interface {
+	SaveTOTPSecret(secret *models.TOTPSecret) error
+	GetTOTPSecret(userID string) (*models.TOTPSecret, error)
+	ConfirmTOTPSecret(userID string) error
+	UseTOTPStep(userID string, step int64) error
+	ReplaceRecoveryCodes(userID string, codeHashes []string) error
+	UseRecoveryCode(userID, codeHash string, usedAt time.Time) error
+}
+
+type TwoFactorRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+// SaveTOTPSecret stores a new unconfirmed secret, replacing a previous
+// unconfirmed one. Confirmed secrets are never overwritten.
+func (r *TwoFactorRepository) SaveTOTPSecret(secret *models.TOTPSecret) error {
+	query, args, err := r.sqlBuilder.
+		Insert("user_totp").
+		Columns("userId", "secret", "confirmed", "lastUsedStep", "createdAt").
+		Values(secret.UserID, secret.Secret, false, 0, secret.CreatedAt).
+		Suffix("ON CONFLICT (userId) DO UPDATE SET secret = EXCLUDED.secret, createdAt = EXCLUDED.createdAt, lastUsedStep = 0 WHERE user_totp.confirmed = FALSE").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return internalErrors.ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) GetTOTPSecret(userID string) (*models.TOTPSecret, error) {
+	var secret models.TOTPSecret
+	query, args, err := r.sqlBuilder.
+		Select("userId", "secret", "confirmed", "lastUsedStep", "createdAt").
+		From("user_totp").
+		Where(squirrel.Eq{"userId": userID}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.QueryRow(query, args...).Scan(&secret.UserID, &secret.Secret, &secret.Confirmed, &secret.LastUsedStep, &secret.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internalErrors.ErrTwoFactorNotEnrolled
+		}
+		return nil, err
+	}
+	return &secret, nil
+}
+
+func (r *TwoFactorRepository) ConfirmTOTPSecret(userID string) error {
+	query, args, err := r.sqlBuilder.
+		Update("user_totp").
+		Set("confirmed", true).
+		Where(squirrel.Eq{"userId": userID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return err
+}
+
+// UseTOTPStep records the time step of an accepted code. A step that is not
+// newer than the last accepted one is rejected, so each code works only once.
+func (r *TwoFactorRepository) UseTOTPStep(userID string, step int64) error {
+	query, args, err := r.sqlBuilder.
+		Update("user_totp").
+		Set("lastUsedStep", step).
+		Where(squirrel.Eq{"userId": userID}).
+		Where(squirrel.Lt{"lastUsedStep": step}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return internalErrors.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query, args, err := r.sqlBuilder.
+		Delete("recovery_codes").
+		Where(squirrel.Eq{"userId": userID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(query, args...); err != nil {
+		return err
+	}
+
+	if len(codeHashes) == 0 {
+		return tx.Commit()
+	}
+	insert := r.sqlBuilder.
+		Insert("recovery_codes").
+		Columns("id", "userId", "codeHash")
+	for _, hash := range codeHashes {
+		insert = insert.Values(uuid.New().String(), userID, hash)
+	}
+	query, args, err = insert.ToSql()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(query, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TwoFactorRepository) UseRecoveryCode(userID, codeHash string, usedAt time.Time) error {
+	query, args, err := r.sqlBuilder.
+		Update("recovery_codes").
+		Set("usedAt", usedAt).
+		Where(squirrel.Eq{"userId": userID, "codeHash": codeHash, "usedAt": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return internalErrors.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorRepository_SaveTOTPSecret(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTwoFactorRepository(db)
+	now := time.Now()
+	secret := &models.TOTPSecret{UserID: "user-id", Secret: "SECRET", CreatedAt: now}
+
+	mock.ExpectExec("INSERT INTO user_totp (.+) ON CONFLICT \\(userId\\) DO UPDATE (.+) WHERE user_totp.confirmed = FALSE").
+		WithArgs("user-id", "SECRET", false, 0, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO user_totp").
+		WithArgs("user-id", "SECRET", false, 0, now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.SaveTOTPSecret(secret))
+	assert.ErrorIs(t, repo.SaveTOTPSecret(secret), internalErrors.ErrTwoFactorEnabled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTwoFactorRepository_GetTOTPSecret(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTwoFactorRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT userId, secret, confirmed, lastUsedStep, createdAt FROM user_totp WHERE userId = \\$1").
+		WithArgs("user-id").
+		WillReturnRows(sqlmock.NewRows([]string{"userId", "secret", "confirmed", "lastUsedStep", "createdAt"}).
+			AddRow("user-id", "SECRET", true, 42, now))
+	mock.ExpectQuery("SELECT (.+) FROM user_totp").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	secret, err := repo.GetTOTPSecret("user-id")
+	assert.NoError(t, err)
+	assert.True(t, secret.Confirmed)
+	assert.Equal(t, int64(42), secret.LastUsedStep)
+
+	_, err = repo.GetTOTPSecret("missing")
+	assert.ErrorIs(t, err, internalErrors.ErrTwoFactorNotEnrolled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTwoFactorRepository_UseTOTPStep(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTwoFactorRepository(db)
+
+	mock.ExpectExec("UPDATE user_totp SET lastUsedStep = \\$1 WHERE userId = \\$2 AND lastUsedStep < \\$3").
+		WithArgs(int64(100), "user-id", int64(100)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE user_totp SET lastUsedStep").
+		WithArgs(int64(100), "user-id", int64(100)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.UseTOTPStep("user-id", 100))
+	assert.ErrorIs(t, repo.UseTOTPStep("user-id", 100), internalErrors.ErrInvalidTwoFactorCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTwoFactorRepository_ReplaceRecoveryCodes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTwoFactorRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM recovery_codes WHERE userId = \\$1").
+		WithArgs("user-id").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO recovery_codes \\(id,userId,codeHash\\)").
+		WithArgs(sqlmock.AnyArg(), "user-id", "hash1", sqlmock.AnyArg(), "user-id", "hash2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.ReplaceRecoveryCodes("user-id", []string{"hash1", "hash2"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTwoFactorRepository_UseRecoveryCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTwoFactorRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE recovery_codes SET usedAt = \\$1 WHERE (.+)usedAt IS NULL").
+		WithArgs(now, "hash", "user-id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE recovery_codes SET usedAt").
+		WithArgs(now, "hash", "user-id").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.UseRecoveryCode("user-id", "hash", now))
+	assert.ErrorIs(t, repo.UseRecoveryCode("user-id", "hash", now), internalErrors.ErrInvalidTwoFactorCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
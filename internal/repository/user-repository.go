@@ -5,6 +5,7 @@ import (
 	"avito-intern/internal/models"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/Masterminds/squirrel"
 )
@@ -12,8 +13,16 @@ import (
 type UserRepositoryInterface interface {
 	CreateUser(user *models.User) error
 	GetUserByEmail(email string) (*models.User, error)
+	GetUserByID(id string) (*models.User, error)
+	ListUsers(email, role, status string, limit, offset int) ([]*models.User, error)
+	CountUsers(email, role, status string) (int, error)
+	UpdateUserStatus(id, status string) error
+	UpdateUserRole(id, role string) error
+	UpdateUserPassword(id, password string) error
 }
 
+var userColumns = []string{"id", "email", "password", "role", "status", "tokenVersion"}
+
 type UserRepository struct {
 	db         *sql.DB
 	sqlBuilder squirrel.StatementBuilderType
@@ -27,35 +36,137 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 }
 
 func (r *UserRepository) CreateUser(user *models.User) error {
+	if user.Status == "" {
+		user.Status = "active"
+	}
 	query, args, err := r.sqlBuilder.
 		Insert("users").
-		Columns("id", "email", "password", "role").
-		Values(user.ID, user.Email, user.Password, user.Role).
+		Columns("id", "email", "password", "role", "status").
+		Values(user.ID, user.Email, user.Password, user.Role, user.Status).
 		ToSql()
 	if err != nil {
 		return err
 	}
 	_, err = r.db.Exec(query, args...)
+	if err = translateError(err); errors.Is(err, internalErrors.ErrAlreadyExists) {
+		return fmt.Errorf("%w: %w", internalErrors.ErrEmailExists, err)
+	}
 	return err
 }
 
 func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
+	return r.getUser(squirrel.Eq{"email": email})
+}
+
+func (r *UserRepository) GetUserByID(id string) (*models.User, error) {
+	return r.getUser(squirrel.Eq{"id": id})
+}
+
+func (r *UserRepository) ListUsers(email, role, status string, limit, offset int) ([]*models.User, error) {
+	q := filterUsers(r.sqlBuilder.Select(userColumns...).From("users"), email, role, status).
+		OrderBy("email").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Email, &user.Password, &user.Role, &user.Status, &user.TokenVersion); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+// UpdateUserStatus, UpdateUserRole and UpdateUserPassword bump tokenVersion so
+// that tokens issued before the change stop being accepted.
+func (r *UserRepository) UpdateUserStatus(id, status string) error {
+	return r.updateUser(id, "status", status)
+}
+
+func (r *UserRepository) UpdateUserRole(id, role string) error {
+	return r.updateUser(id, "role", role)
+}
+
+func (r *UserRepository) UpdateUserPassword(id, password string) error {
+	return r.updateUser(id, "password", password)
+}
+
+func (r *UserRepository) updateUser(id, column string, value interface{}) error {
+	query, args, err := r.sqlBuilder.
+		Update("users").
+		Set(column, value).
+		Set("tokenVersion", squirrel.Expr("tokenVersion + 1")).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return internalErrors.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) CountUsers(email, role, status string) (int, error) {
+	query, args, err := filterUsers(r.sqlBuilder.Select("COUNT(*)").From("users"), email, role, status).ToSql()
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = r.db.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
+
+func filterUsers(q squirrel.SelectBuilder, email, role, status string) squirrel.SelectBuilder {
+	if email != "" {
+		q = q.Where(squirrel.ILike{"email": "%" + email + "%"})
+	}
+	if role != "" {
+		q = q.Where(squirrel.Eq{"role": role})
+	}
+	if status != "" {
+		q = q.Where(squirrel.Eq{"status": status})
+	}
+	return q
+}
+
+func (r *UserRepository) getUser(where squirrel.Eq) (*models.User, error) {
 	var user models.User
 	query, args, err := r.sqlBuilder.
-		Select("id", "email", "password", "role").
+		Select(userColumns...).
 		From("users").
-		Where(squirrel.Eq{"email": email}).
+		Where(where).
 		ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	err = r.db.QueryRow(query, args...).Scan(&user.ID, &user.Email, &user.Password, &user.Role)
+	err = r.db.QueryRow(query, args...).Scan(&user.ID, &user.Email, &user.Password, &user.Role, &user.Status, &user.TokenVersion)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, internalErrors.ErrUserNotFound
 		}
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &user, nil
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,7 +28,7 @@ func TestUserRepository_CreateUser_Success(t *testing.T) {
 	}
 
 	mock.ExpectExec("INSERT INTO users").
-		WithArgs("test-id", "test@example.com", "hashed_password", "moderator").
+		WithArgs("test-id", "test@example.com", "hashed_password", "moderator", "active").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err = repo.CreateUser(user)
@@ -54,7 +55,7 @@ func TestUserRepository_CreateUser_DatabaseError(t *testing.T) {
 	}
 
 	mock.ExpectExec("INSERT INTO users").
-		WithArgs("test-id", "test@example.com", "hashed_password", "moderator").
+		WithArgs("test-id", "test@example.com", "hashed_password", "moderator", "active").
 		WillReturnError(sql.ErrConnDone)
 
 	err = repo.CreateUser(user)
@@ -64,6 +65,33 @@ func TestUserRepository_CreateUser_DatabaseError(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUserRepository_CreateUser_EmailExists(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	user := &models.User{
+		ID:       "test-id",
+		Email:    "test@example.com",
+		Password: "hashed_password",
+		Role:     "moderator",
+	}
+
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs("test-id", "test@example.com", "hashed_password", "moderator", "active").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	err = repo.CreateUser(user)
+
+	assert.ErrorIs(t, err, internalErrors.ErrEmailExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserRepository_GetUserByEmail_Success(t *testing.T) {
 
 	db, mock, err := sqlmock.New()
@@ -74,9 +102,9 @@ func TestUserRepository_GetUserByEmail_Success(t *testing.T) {
 
 	repo := NewUserRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "email", "password", "role"}).
-		AddRow("test-id", "test@example.com", "hashed_password", "moderator")
-	mock.ExpectQuery("SELECT id, email, password, role FROM users").
+	rows := sqlmock.NewRows([]string{"id", "email", "password", "role", "status", "tokenVersion"}).
+		AddRow("test-id", "test@example.com", "hashed_password", "moderator", "active", 0)
+	mock.ExpectQuery("SELECT id, email, password, role, status, tokenVersion FROM users").
 		WithArgs("test@example.com").
 		WillReturnRows(rows)
 
@@ -88,6 +116,7 @@ func TestUserRepository_GetUserByEmail_Success(t *testing.T) {
 	assert.Equal(t, "test@example.com", user.Email)
 	assert.Equal(t, "hashed_password", user.Password)
 	assert.Equal(t, "moderator", user.Role)
+	assert.Equal(t, "active", user.Status)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -101,7 +130,7 @@ func TestUserRepository_GetUserByEmail_NotFound(t *testing.T) {
 
 	repo := NewUserRepository(db)
 
-	mock.ExpectQuery("SELECT id, email, password, role FROM users").
+	mock.ExpectQuery("SELECT id, email, password, role, status, tokenVersion FROM users").
 		WithArgs("nonexistent@example.com").
 		WillReturnError(sql.ErrNoRows)
 
@@ -123,7 +152,7 @@ func TestUserRepository_GetUserByEmail_DatabaseError(t *testing.T) {
 
 	repo := NewUserRepository(db)
 
-	mock.ExpectQuery("SELECT id, email, password, role FROM users").
+	mock.ExpectQuery("SELECT id, email, password, role, status, tokenVersion FROM users").
 		WithArgs("test@example.com").
 		WillReturnError(sql.ErrConnDone)
 
@@ -134,3 +163,116 @@ func TestUserRepository_GetUserByEmail_DatabaseError(t *testing.T) {
 	assert.Nil(t, user)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUserRepository_GetUserByID_Success(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "email", "password", "role", "status", "tokenVersion"}).
+		AddRow("test-id", "test@example.com", "hashed_password", "employee", "pending", 2)
+	mock.ExpectQuery("SELECT id, email, password, role, status, tokenVersion FROM users WHERE id = ").
+		WithArgs("test-id").
+		WillReturnRows(rows)
+
+	user, err := repo.GetUserByID("test-id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", user.Status)
+	assert.Equal(t, 2, user.TokenVersion)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_UpdateUserStatus(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectExec("UPDATE users SET status = \\$1, tokenVersion = tokenVersion \\+ 1 WHERE id = \\$2").
+		WithArgs("active", "test-id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET status").
+		WithArgs("active", "missing-id").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.UpdateUserStatus("test-id", "active"))
+	assert.Equal(t, internalErrors.ErrUserNotFound, repo.UpdateUserStatus("missing-id", "active"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_UpdateUserRoleAndPassword(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectExec("UPDATE users SET role = \\$1, tokenVersion = tokenVersion \\+ 1").
+		WithArgs("moderator", "test-id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET password = \\$1, tokenVersion = tokenVersion \\+ 1").
+		WithArgs("new_hash", "test-id").
+		WillReturnError(sql.ErrConnDone)
+
+	assert.NoError(t, repo.UpdateUserRole("test-id", "moderator"))
+	assert.Equal(t, sql.ErrConnDone, repo.UpdateUserPassword("test-id", "new_hash"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_ListUsers(t *testing.T) {
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "email", "password", "role", "status", "tokenVersion"}).
+		AddRow("id-1", "a@example.com", "hash", "employee", "active", 0).
+		AddRow("id-2", "b@example.com", "hash", "employee", "deactivated", 1)
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE email ILIKE \\$1 AND role = \\$2 ORDER BY email LIMIT 10 OFFSET 20").
+		WithArgs("%example%", "employee").
+		WillReturnRows(rows)
+
+	users, err := repo.ListUsers("example", "employee", "", 10, 20)
+
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "deactivated", users[1].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_CountUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users WHERE email ILIKE \\$1 AND status = \\$2").
+		WithArgs("%example%", "active").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+	count, err := repo.CountUsers("example", "", "active")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
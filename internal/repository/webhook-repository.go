@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+)
+
+type WebhookRepositoryInterface interface {
+	CreateWebhookSubscription(subscription *models.WebhookSubscription) error
+	GetWebhookSubscription(id string) (*models.WebhookSubscription, error)
+	ListWebhookSubscriptions() ([]*models.WebhookSubscription, error)
+	DeleteWebhookSubscription(id string) error
+	CreateWebhookDeliveries(deliveries []*models.WebhookDelivery) error
+	ClaimWebhookDeliveries(limit int, now, leaseUntil time.Time) ([]*models.WebhookDelivery, error)
+	RecordWebhookAttempt(delivery *models.WebhookDelivery) error
+	ListWebhookDeliveries(subscriptionID, status string, limit, offset int) ([]*models.WebhookDelivery, error)
+	ResendWebhookDelivery(id string, at time.Time) (*models.WebhookDelivery, error)
+}
+
+type WebhookRepository struct {
+	db         *sql.DB
+	sqlBuilder squirrel.StatementBuilderType
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db:         db,
+		sqlBuilder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+	}
+}
+
+var webhookSubscriptionColumns = []string{"id", "url", "secret", "eventTypes", "pvzIds", "createdBy", "createdAt"}
+
+var webhookDeliveryColumns = []string{
+	"id", "subscriptionId", "eventId", "eventType", "pvzId", "payload", "status", "attempts",
+	"nextAttemptAt", "lastAttemptAt", "responseStatus", "lastError", "createdAt", "deliveredAt",
+}
+
+func (r *WebhookRepository) CreateWebhookSubscription(subscription *models.WebhookSubscription) error {
+	query, args, err := r.sqlBuilder.
+		Insert("webhook_subscriptions").
+		Columns(webhookSubscriptionColumns...).
+		Values(subscription.ID, subscription.URL, subscription.Secret, pq.Array(subscription.EventTypes),
+			pq.Array(subscription.PvzIDs), subscription.CreatedBy, subscription.CreatedAt).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+func (r *WebhookRepository) GetWebhookSubscription(id string) (*models.WebhookSubscription, error) {
+	query, args, err := r.sqlBuilder.
+		Select(webhookSubscriptionColumns...).
+		From("webhook_subscriptions").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	subscription, err := scanWebhookSubscription(r.db.QueryRow(query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internalErrors.ErrWebhookNotFound
+		}
+		return nil, translateError(err)
+	}
+	return subscription, nil
+}
+
+func (r *WebhookRepository) ListWebhookSubscriptions() ([]*models.WebhookSubscription, error) {
+	query, args, err := r.sqlBuilder.
+		Select(webhookSubscriptionColumns...).
+		From("webhook_subscriptions").
+		OrderBy("createdAt DESC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := make([]*models.WebhookSubscription, 0)
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+// DeleteWebhookSubscription removes the subscription together with its
+// deliveries.
+func (r *WebhookRepository) DeleteWebhookSubscription(id string) error {
+	query, args, err := r.sqlBuilder.
+		Delete("webhook_subscriptions").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return translateError(err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return internalErrors.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// CreateWebhookDeliveries queues deliveries of one event. An event is queued
+// for a subscription only once, so that the outbox relay may publish it
+// again.
+func (r *WebhookRepository) CreateWebhookDeliveries(deliveries []*models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	insert := r.sqlBuilder.
+		Insert("webhook_deliveries").
+		Columns("id", "subscriptionId", "eventId", "eventType", "pvzId", "payload", "status", "nextAttemptAt", "createdAt")
+	for _, delivery := range deliveries {
+		insert = insert.Values(delivery.ID, delivery.SubscriptionID, delivery.EventID, delivery.EventType,
+			delivery.PvzID, string(delivery.Payload), delivery.Status, delivery.NextAttemptAt, delivery.CreatedAt)
+	}
+	query, args, err := insert.Suffix("ON CONFLICT (subscriptionId, eventId) DO NOTHING").ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+// ClaimWebhookDeliveries reserves up to limit pending deliveries that are due
+// at now until leaseUntil, the longest waiting first. Deliveries claimed by
+// another dispatcher are skipped.
+func (r *WebhookRepository) ClaimWebhookDeliveries(limit int, now, leaseUntil time.Time) ([]*models.WebhookDelivery, error) {
+	// The subquery keeps ? placeholders, they are numbered with the update.
+	due, dueArgs, err := squirrel.
+		Select("id").
+		From("webhook_deliveries").
+		Where(squirrel.Eq{"status": models.WebhookDeliveryPending}).
+		Where(squirrel.LtOrEq{"nextAttemptAt": now}).
+		OrderBy("nextAttemptAt").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := r.sqlBuilder.
+		Update("webhook_deliveries").
+		Set("nextAttemptAt", leaseUntil).
+		Where(squirrel.Expr("id IN ("+due+")", dueArgs...)).
+		Suffix("RETURNING " + strings.Join(webhookDeliveryColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return r.queryWebhookDeliveries(query, args)
+}
+
+// RecordWebhookAttempt stores the outcome of an attempt to send the
+// delivery: its status, attempt count, response and next attempt.
+func (r *WebhookRepository) RecordWebhookAttempt(delivery *models.WebhookDelivery) error {
+	query, args, err := r.sqlBuilder.
+		Update("webhook_deliveries").
+		Set("status", delivery.Status).
+		Set("attempts", delivery.Attempts).
+		Set("nextAttemptAt", delivery.NextAttemptAt).
+		Set("lastAttemptAt", delivery.LastAttemptAt).
+		Set("responseStatus", delivery.ResponseStatus).
+		Set("lastError", delivery.LastError).
+		Set("deliveredAt", delivery.DeliveredAt).
+		Where(squirrel.Eq{"id": delivery.ID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(query, args...)
+	return translateError(err)
+}
+
+// ListWebhookDeliveries returns a page of the delivery log, newest first.
+// Empty subscriptionID and status match every delivery.
+func (r *WebhookRepository) ListWebhookDeliveries(subscriptionID, status string, limit, offset int) ([]*models.WebhookDelivery, error) {
+	q := r.sqlBuilder.
+		Select(webhookDeliveryColumns...).
+		From("webhook_deliveries")
+	if subscriptionID != "" {
+		q = q.Where(squirrel.Eq{"subscriptionId": subscriptionID})
+	}
+	if status != "" {
+		q = q.Where(squirrel.Eq{"status": status})
+	}
+	query, args, err := q.
+		OrderBy("createdAt DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return r.queryWebhookDeliveries(query, args)
+}
+
+// ResendWebhookDelivery queues the delivery again with a fresh attempt
+// count, whatever its status.
+func (r *WebhookRepository) ResendWebhookDelivery(id string, at time.Time) (*models.WebhookDelivery, error) {
+	query, args, err := r.sqlBuilder.
+		Update("webhook_deliveries").
+		Set("status", models.WebhookDeliveryPending).
+		Set("attempts", 0).
+		Set("nextAttemptAt", at).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING " + strings.Join(webhookDeliveryColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := scanWebhookDelivery(r.db.QueryRow(query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, internalErrors.ErrDeliveryNotFound
+		}
+		return nil, translateError(err)
+	}
+	return delivery, nil
+}
+
+func (r *WebhookRepository) queryWebhookDeliveries(query string, args []interface{}) ([]*models.WebhookDelivery, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanWebhookSubscription(row rowScanner) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	err := row.Scan(
+		&subscription.ID, &subscription.URL, &subscription.Secret, pq.Array(&subscription.EventTypes),
+		pq.Array(&subscription.PvzIDs), &subscription.CreatedBy, &subscription.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var payload []byte
+	var lastAttemptAt, deliveredAt sql.NullTime
+	err := row.Scan(
+		&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.PvzID,
+		&payload, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &lastAttemptAt,
+		&delivery.ResponseStatus, &delivery.LastError, &delivery.CreatedAt, &deliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	if lastAttemptAt.Valid {
+		delivery.LastAttemptAt = &lastAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return &delivery, nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var webhookDeliveryRowColumns = []string{
+	"id", "subscriptionId", "eventId", "eventType", "pvzId", "payload", "status", "attempts",
+	"nextAttemptAt", "lastAttemptAt", "responseStatus", "lastError", "createdAt", "deliveredAt",
+}
+
+func TestWebhookRepository_ListWebhookSubscriptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT id, url, secret, eventTypes, pvzIds, createdBy, createdAt FROM webhook_subscriptions ORDER BY createdAt DESC").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "eventTypes", "pvzIds", "createdBy", "createdAt"}).
+			AddRow("webhook-1", "https://partner.example", "whsec_secret", "{reception.closed}", "{}", "moderator-id", now))
+
+	subscriptions, err := repo.ListWebhookSubscriptions()
+
+	assert.NoError(t, err)
+	if assert.Len(t, subscriptions, 1) {
+		assert.Equal(t, []string{models.EventReceptionClosed}, subscriptions[0].EventTypes)
+		assert.Empty(t, subscriptions[0].PvzIDs)
+		assert.Equal(t, "whsec_secret", subscriptions[0].Secret)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_DeleteWebhookSubscription_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+
+	mock.ExpectExec("DELETE FROM webhook_subscriptions WHERE id = \\$1").
+		WithArgs("webhook-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.DeleteWebhookSubscription("webhook-1")
+
+	assert.Equal(t, internalErrors.ErrWebhookNotFound, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_CreateWebhookDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	now := time.Now()
+	deliveries := []*models.WebhookDelivery{
+		{ID: "delivery-1", SubscriptionID: "webhook-1", EventID: "event-1", Payload: []byte(`{}`), Status: models.WebhookDeliveryPending},
+		{ID: "delivery-2", SubscriptionID: "webhook-2", EventID: "event-1", Payload: []byte(`{}`), Status: models.WebhookDeliveryPending},
+	}
+	for _, delivery := range deliveries {
+		delivery.EventType = models.EventReceptionClosed
+		delivery.PvzID = "pvz-1"
+		delivery.NextAttemptAt = now
+		delivery.CreatedAt = now
+	}
+
+	mock.ExpectExec("INSERT INTO webhook_deliveries \\(.+\\) VALUES \\(.+\\),\\(.+\\) ON CONFLICT \\(subscriptionId, eventId\\) DO NOTHING").
+		WithArgs(
+			"delivery-1", "webhook-1", "event-1", models.EventReceptionClosed, "pvz-1", "{}", models.WebhookDeliveryPending, now, now,
+			"delivery-2", "webhook-2", "event-1", models.EventReceptionClosed, "pvz-1", "{}", models.WebhookDeliveryPending, now, now,
+		).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.CreateWebhookDeliveries(deliveries))
+	assert.NoError(t, repo.CreateWebhookDeliveries(nil), "nothing to queue is not a query")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_ClaimWebhookDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	now := time.Now()
+	leaseUntil := now.Add(time.Minute)
+
+	mock.ExpectQuery("UPDATE webhook_deliveries SET nextAttemptAt = \\$1 WHERE id IN \\(SELECT id FROM webhook_deliveries "+
+		"WHERE status = \\$2 AND nextAttemptAt <= \\$3 ORDER BY nextAttemptAt LIMIT 10 FOR UPDATE SKIP LOCKED\\) RETURNING id").
+		WithArgs(leaseUntil, models.WebhookDeliveryPending, now).
+		WillReturnRows(sqlmock.NewRows(webhookDeliveryRowColumns).
+			AddRow("delivery-1", "webhook-1", "event-1", models.EventProductAdded, "pvz-1", []byte(`{"id":"event-1"}`),
+				models.WebhookDeliveryPending, 2, leaseUntil, now, 503, "answered 503", now, nil))
+
+	deliveries, err := repo.ClaimWebhookDeliveries(10, now, leaseUntil)
+
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, 2, deliveries[0].Attempts)
+		assert.Equal(t, now, *deliveries[0].LastAttemptAt)
+		assert.Nil(t, deliveries[0].DeliveredAt)
+		assert.JSONEq(t, `{"id":"event-1"}`, string(deliveries[0].Payload))
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_RecordWebhookAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		ID:             "delivery-1",
+		Status:         models.WebhookDeliveryDelivered,
+		Attempts:       1,
+		NextAttemptAt:  now,
+		LastAttemptAt:  &now,
+		ResponseStatus: 204,
+		DeliveredAt:    &now,
+	}
+
+	mock.ExpectExec("UPDATE webhook_deliveries SET status = \\$1, attempts = \\$2, nextAttemptAt = \\$3, lastAttemptAt = \\$4, "+
+		"responseStatus = \\$5, lastError = \\$6, deliveredAt = \\$7 WHERE id = \\$8").
+		WithArgs(models.WebhookDeliveryDelivered, 1, now, &now, 204, "", &now, "delivery-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.RecordWebhookAttempt(delivery))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_ListWebhookDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+
+	mock.ExpectQuery("SELECT .+ FROM webhook_deliveries WHERE subscriptionId = \\$1 AND status = \\$2 ORDER BY createdAt DESC LIMIT 20 OFFSET 40").
+		WithArgs("webhook-1", models.WebhookDeliveryDead).
+		WillReturnRows(sqlmock.NewRows(webhookDeliveryRowColumns))
+	mock.ExpectQuery("SELECT .+ FROM webhook_deliveries ORDER BY createdAt DESC LIMIT 20 OFFSET 0").
+		WillReturnError(sql.ErrConnDone)
+
+	deliveries, err := repo.ListWebhookDeliveries("webhook-1", models.WebhookDeliveryDead, 20, 40)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	_, err = repo.ListWebhookDeliveries("", "", 20, 0)
+	assert.Equal(t, sql.ErrConnDone, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_ResendWebhookDelivery_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery("UPDATE webhook_deliveries SET status = \\$1, attempts = \\$2, nextAttemptAt = \\$3 WHERE id = \\$4 RETURNING id").
+		WithArgs(models.WebhookDeliveryPending, 0, now, "delivery-1").
+		WillReturnError(sql.ErrNoRows)
+
+	delivery, err := repo.ResendWebhookDelivery("delivery-1", now)
+
+	assert.Equal(t, internalErrors.ErrDeliveryNotFound, err)
+	assert.Nil(t, delivery)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
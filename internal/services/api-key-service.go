@@ -0,0 +1,210 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/apiKeyDto"
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/utils"
+	"crypto/hmac"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix         = "pvz_"
+	signingSecretPrefix  = "pvzsig_"
+	apiKeyDisplayLength  = 12
+	apiKeyTouchInterval  = time.Minute
+	defaultSignatureSkew = 5 * time.Minute
+)
+
+var apiKeyScopes = []string{
+	models.ScopePVZRead,
+	models.ScopePVZWrite,
+	models.ScopeReceptionsWrite,
+	models.ScopeProductsWrite,
+}
+
+// APIKeyPolicy controls how far the timestamp of a signed request may drift
+// from the server clock. Nonces are remembered for the same window.
+type APIKeyPolicy struct {
+	SignatureSkew time.Duration
+}
+
+type APIKeyService struct {
+	repo   repository.APIKeyRepositoryInterface
+	policy APIKeyPolicy
+	sources
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepositoryInterface, policy APIKeyPolicy) *APIKeyService {
+	if policy.SignatureSkew <= 0 {
+		policy.SignatureSkew = defaultSignatureSkew
+	}
+	return &APIKeyService{
+		repo:    repo,
+		policy:  policy,
+		sources: newSources(),
+	}
+}
+
+// CreateKey issues a machine credential. The raw key and the secret to sign
+// requests with are returned only here; the repository keeps the hash of the
+// key. Keys act with the permissions of an
+// employee or moderator, narrowed down to the listed scopes.
+func (s *APIKeyService) CreateKey(creator models.User, req apiKeyDto.CreateAPIKeyRequest) (*models.APIKey, string, error) {
+	role := req.Role
+	if role == "" {
+		role = "employee"
+	}
+	if role != "employee" && role != "moderator" {
+		return nil, "", internalErrors.ErrInvalidRole
+	}
+	if len(req.Scopes) == 0 {
+		return nil, "", internalErrors.ErrInvalidScope
+	}
+	for _, scope := range req.Scopes {
+		if !isValidScope(scope) {
+			return nil, "", internalErrors.ErrInvalidScope
+		}
+	}
+	now := s.now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, "", internalErrors.ErrInvalidExpiration
+	}
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return nil, "", err
+	}
+	rawKey := apiKeyPrefix + token
+	secret, err := utils.GenerateToken()
+	if err != nil {
+		return nil, "", err
+	}
+	key := &models.APIKey{
+		ID:               s.newID(),
+		Name:             req.Name,
+		KeyPrefix:        rawKey[:apiKeyDisplayLength],
+		KeyHash:          utils.HashToken(rawKey),
+		SigningSecret:    signingSecretPrefix + secret,
+		Role:             role,
+		Scopes:           req.Scopes,
+		RequireSignature: req.RequireSignature,
+		CreatedBy:        creator.ID,
+		CreatedAt:        now,
+		ExpiresAt:        req.ExpiresAt,
+	}
+	if err = s.repo.CreateAPIKey(key); err != nil {
+		return nil, "", err
+	}
+	return key, rawKey, nil
+}
+
+func (s *APIKeyService) ListKeys() ([]*models.APIKey, error) {
+	return s.repo.ListAPIKeys()
+}
+
+func (s *APIKeyService) RevokeKey(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return internalErrors.ErrAPIKeyNotFound
+	}
+	return s.repo.RevokeAPIKey(id, s.now())
+}
+
+// AuthenticateKey checks a key sent as is. Keys that require signing are
+// rejected here and accepted only through VerifyRequest.
+func (s *APIKeyService) AuthenticateKey(rawKey string) (*models.APIKey, error) {
+	key, err := s.repo.GetAPIKeyByHash(utils.HashToken(rawKey))
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrAPIKeyNotFound) {
+			return nil, internalErrors.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if err = s.checkUsable(key); err != nil {
+		return nil, err
+	}
+	if key.RequireSignature {
+		return nil, internalErrors.ErrInvalidSignature
+	}
+	return key, s.touch(key)
+}
+
+// VerifyRequest checks a request signed with the signing secret of the key.
+// The timestamp must be within the allowed skew and every nonce can be used
+// only once per key.
+func (s *APIKeyService) VerifyRequest(req models.SignedRequest) (*models.APIKey, error) {
+	if _, err := uuid.Parse(req.KeyID); err != nil || req.Nonce == "" {
+		return nil, internalErrors.ErrInvalidSignature
+	}
+	key, err := s.repo.GetAPIKeyByID(req.KeyID)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrAPIKeyNotFound) {
+			return nil, internalErrors.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if err = s.checkUsable(key); err != nil {
+		return nil, err
+	}
+	if key.SigningSecret == "" {
+		return nil, internalErrors.ErrInvalidSignature
+	}
+
+	now := s.now()
+	unix, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, internalErrors.ErrInvalidSignature
+	}
+	if drift := now.Sub(time.Unix(unix, 0)); drift > s.policy.SignatureSkew || drift < -s.policy.SignatureSkew {
+		return nil, internalErrors.ErrInvalidSignature
+	}
+
+	canonical := utils.CanonicalRequest(req.Method, req.URI, req.Timestamp, req.Nonce, req.Body)
+	expected := utils.SignRequest(key.SigningSecret, canonical)
+	if !hmac.Equal([]byte(expected), []byte(req.Signature)) {
+		return nil, internalErrors.ErrInvalidSignature
+	}
+	if err = s.repo.UseNonce(key.ID, req.Nonce, now, now.Add(-2*s.policy.SignatureSkew)); err != nil {
+		return nil, err
+	}
+	return key, s.touch(key)
+}
+
+func (s *APIKeyService) checkUsable(key *models.APIKey) error {
+	if key.RevokedAt != nil {
+		return internalErrors.ErrInvalidAPIKey
+	}
+	if key.ExpiresAt != nil && !s.now().Before(*key.ExpiresAt) {
+		return internalErrors.ErrInvalidAPIKey
+	}
+	return nil
+}
+
+// touch records the last use at most once per apiKeyTouchInterval to avoid
+// a write on every request.
+func (s *APIKeyService) touch(key *models.APIKey) error {
+	now := s.now()
+	if key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) < apiKeyTouchInterval {
+		return nil
+	}
+	if err := s.repo.TouchAPIKey(key.ID, now); err != nil {
+		return err
+	}
+	key.LastUsedAt = &now
+	return nil
+}
+
+func isValidScope(scope string) bool {
+	for _, known := range apiKeyScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/apiKeyDto"
+	"avito-intern/internal/models"
+	"avito-intern/internal/utils"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAPIKeyRepository struct {
+	keys    map[string]*models.APIKey
+	nonces  map[string]bool
+	touches int
+}
+
+func newMockAPIKeyRepository() *mockAPIKeyRepository {
+	return &mockAPIKeyRepository{keys: map[string]*models.APIKey{}, nonces: map[string]bool{}}
+}
+
+func (m *mockAPIKeyRepository) CreateAPIKey(key *models.APIKey) error {
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *mockAPIKeyRepository) GetAPIKeyByID(id string) (*models.APIKey, error) {
+	if key, exists := m.keys[id]; exists {
+		return key, nil
+	}
+	return nil, internalErrors.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeyRepository) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	for _, key := range m.keys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return nil, internalErrors.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeyRepository) ListAPIKeys() ([]*models.APIKey, error) {
+	keys := make([]*models.APIKey, 0, len(m.keys))
+	for _, key := range m.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyRepository) RevokeAPIKey(id string, revokedAt time.Time) error {
+	key, exists := m.keys[id]
+	if !exists || key.RevokedAt != nil {
+		return internalErrors.ErrAPIKeyNotFound
+	}
+	key.RevokedAt = &revokedAt
+	return nil
+}
+
+func (m *mockAPIKeyRepository) TouchAPIKey(id string, usedAt time.Time) error {
+	m.touches++
+	return nil
+}
+
+func (m *mockAPIKeyRepository) UseNonce(keyID, nonce string, usedAt, expireBefore time.Time) error {
+	if m.nonces[keyID+nonce] {
+		return internalErrors.ErrReplayedRequest
+	}
+	m.nonces[keyID+nonce] = true
+	return nil
+}
+
+func newAPIKeyFixture(t *testing.T, req apiKeyDto.CreateAPIKeyRequest) (*APIKeyService, *mockAPIKeyRepository, *models.APIKey, string) {
+	repo := newMockAPIKeyRepository()
+	service := NewAPIKeyService(repo, APIKeyPolicy{})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	key, rawKey, err := service.CreateKey(models.User{ID: "admin-id", Role: "admin"}, req)
+	require.NoError(t, err)
+	return service, repo, key, rawKey
+}
+
+func signedRequest(key *models.APIKey, secret string, at time.Time, nonce string) models.SignedRequest {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	body := []byte(`{"pvzId":"pvz-id"}`)
+	canonical := utils.CanonicalRequest("POST", "/receptions", timestamp, nonce, body)
+	return models.SignedRequest{
+		KeyID:     key.ID,
+		Timestamp: timestamp,
+		Nonce:     nonce,
+		Signature: utils.SignRequest(secret, canonical),
+		Method:    "POST",
+		URI:       "/receptions",
+		Body:      body,
+	}
+}
+
+func TestAPIKeyService_CreateKey(t *testing.T) {
+	_, repo, key, rawKey := newAPIKeyFixture(t, apiKeyDto.CreateAPIKeyRequest{
+		Name:   "warehouse",
+		Scopes: []string{models.ScopeReceptionsWrite},
+	})
+
+	assert.True(t, strings.HasPrefix(rawKey, "pvz_"))
+	assert.Equal(t, rawKey[:12], key.KeyPrefix)
+	assert.Equal(t, "employee", key.Role)
+	assert.Equal(t, utils.HashToken(rawKey), repo.keys[key.ID].KeyHash)
+	assert.NotContains(t, repo.keys[key.ID].KeyHash, rawKey)
+	assert.True(t, strings.HasPrefix(key.SigningSecret, "pvzsig_"))
+	assert.NotContains(t, key.SigningSecret, strings.TrimPrefix(rawKey, "pvz_"))
+}
+
+func TestAPIKeyService_CreateKey_Validation(t *testing.T) {
+	service := NewAPIKeyService(newMockAPIKeyRepository(), APIKeyPolicy{})
+	admin := models.User{ID: "admin-id", Role: "admin"}
+	past := time.Now().Add(-time.Hour)
+
+	_, _, err := service.CreateKey(admin, apiKeyDto.CreateAPIKeyRequest{Name: "k", Role: "admin", Scopes: []string{models.ScopePVZRead}})
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidRole)
+
+	_, _, err = service.CreateKey(admin, apiKeyDto.CreateAPIKeyRequest{Name: "k"})
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidScope)
+
+	_, _, err = service.CreateKey(admin, apiKeyDto.CreateAPIKeyRequest{Name: "k", Scopes: []string{"users:write"}})
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidScope)
+
+	_, _, err = service.CreateKey(admin, apiKeyDto.CreateAPIKeyRequest{Name: "k", Scopes: []string{models.ScopePVZRead}, ExpiresAt: &past})
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidExpiration)
+}
+
+func TestAPIKeyService_AuthenticateKey(t *testing.T) {
+	service, repo, key, rawKey := newAPIKeyFixture(t, apiKeyDto.CreateAPIKeyRequest{
+		Name:   "warehouse",
+		Scopes: []string{models.ScopeReceptionsWrite},
+	})
+
+	authenticated, err := service.AuthenticateKey(rawKey)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, authenticated.ID)
+	assert.NotNil(t, authenticated.LastUsedAt)
+
+	_, err = service.AuthenticateKey(rawKey)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.touches, "last use is recorded at most once per minute")
+
+	_, err = service.AuthenticateKey("pvz_unknown")
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidAPIKey)
+
+	require.NoError(t, service.RevokeKey(key.ID))
+	_, err = service.AuthenticateKey(rawKey)
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidAPIKey)
+}
+
+func TestAPIKeyService_AuthenticateKey_Expired(t *testing.T) {
+	expiresAt := time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)
+	service, _, _, rawKey := newAPIKeyFixture(t, apiKeyDto.CreateAPIKeyRequest{
+		Name:      "warehouse",
+		Scopes:    []string{models.ScopeReceptionsWrite},
+		ExpiresAt: &expiresAt,
+	})
+
+	service.now = func() time.Time { return expiresAt }
+	_, err := service.AuthenticateKey(rawKey)
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidAPIKey)
+}
+
+func TestAPIKeyService_AuthenticateKey_SignatureRequired(t *testing.T) {
+	service, _, _, rawKey := newAPIKeyFixture(t, apiKeyDto.CreateAPIKeyRequest{
+		Name:             "warehouse",
+		Scopes:           []string{models.ScopeReceptionsWrite},
+		RequireSignature: true,
+	})
+
+	_, err := service.AuthenticateKey(rawKey)
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidSignature)
+}
+
+func TestAPIKeyService_VerifyRequest(t *testing.T) {
+	service, _, key, _ := newAPIKeyFixture(t, apiKeyDto.CreateAPIKeyRequest{
+		Name:             "warehouse",
+		Scopes:           []string{models.ScopeReceptionsWrite},
+		RequireSignature: true,
+	})
+	now := service.now()
+
+	verified, err := service.VerifyRequest(signedRequest(key, key.SigningSecret, now.Add(-time.Minute), "nonce-1"))
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, verified.ID)
+
+	_, err = service.VerifyRequest(signedRequest(key, key.SigningSecret, now.Add(-time.Minute), "nonce-1"))
+	assert.ErrorIs(t, err, internalErrors.ErrReplayedRequest)
+
+	_, err = service.VerifyRequest(signedRequest(key, key.SigningSecret, now.Add(-10*time.Minute), "nonce-2"))
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidSignature, "stale timestamp")
+
+	tampered := signedRequest(key, key.SigningSecret, now, "nonce-3")
+	tampered.Body = []byte(`{"pvzId":"other"}`)
+	_, err = service.VerifyRequest(tampered)
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidSignature)
+
+	_, err = service.VerifyRequest(signedRequest(key, "pvzsig_wrong", now, "nonce-4"))
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidSignature)
+
+	_, err = service.VerifyRequest(signedRequest(key, key.KeyHash, now, "nonce-6"))
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidSignature, "the key hash is not the signing secret")
+
+	unknown := signedRequest(key, key.SigningSecret, now, "nonce-5")
+	unknown.KeyID = "8b3c2d1e-0f9a-4b8c-9d7e-6f5a4b3c2d1e"
+	_, err = service.VerifyRequest(unknown)
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidAPIKey)
+}
@@ -3,21 +3,85 @@ package services
 import (
 	"avito-intern/internal/api/dto/internalErrors"
 	"avito-intern/internal/api/dto/request/authDto"
+	"avito-intern/internal/api/dto/request/inviteDto"
+	"avito-intern/internal/mailer"
 	"avito-intern/internal/models"
 	"avito-intern/internal/repository"
 	"avito-intern/internal/utils"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
 
-	"github.com/google/uuid"
+const (
+	defaultInviteTTL        = 72 * time.Hour
+	defaultPasswordResetTTL = time.Hour
 )
 
+// RegistrationPolicy controls who may register without an invite and how
+// long invites and password reset links stay valid. Privileged roles always
+// require an invite. PasswordResetURL is the page the reset link points to;
+// the token is appended as the "token" query parameter.
+type RegistrationPolicy struct {
+	EmployeeSelfRegistration bool
+	EmployeeApproval         bool
+	InviteTTL                time.Duration
+	PasswordResetTTL         time.Duration
+	PasswordResetURL         string
+	// DummyLogin serves tokens for any role at /dummyLogin without an
+	// account. Only for development: it is off by default.
+	DummyLogin bool
+}
+
+// LoginResult is the outcome of a password login. When TwoFactorRequired is
+// set, Token is an intermediate token that must be completed via
+// TwoFactorService.Verify.
+type LoginResult struct {
+	Token                       string
+	TwoFactorRequired           bool
+	TwoFactorEnrollmentRequired bool
+}
+
 type AuthService struct {
-	userRepo repository.UserRepositoryInterface
+	userRepo   repository.UserRepositoryInterface
+	inviteRepo repository.InviteRepositoryInterface
+	resetRepo  repository.PasswordResetRepositoryInterface
+	attempts   *LoginAttemptService
+	twoFactor  *TwoFactorService
+	mailer     mailer.Mailer
+	policy     RegistrationPolicy
+	sources
 }
 
-func NewAuthService(userRepo repository.UserRepositoryInterface) *AuthService {
+// NewAuthService creates the service. attempts may be nil to disable login
+// throttling, twoFactor may be nil to disable two-factor authentication.
+// Self-service password reset needs both resetRepo and sender.
+func NewAuthService(
+	userRepo repository.UserRepositoryInterface,
+	inviteRepo repository.InviteRepositoryInterface,
+	resetRepo repository.PasswordResetRepositoryInterface,
+	attempts *LoginAttemptService,
+	twoFactor *TwoFactorService,
+	sender mailer.Mailer,
+	policy RegistrationPolicy,
+) *AuthService {
+	if policy.InviteTTL <= 0 {
+		policy.InviteTTL = defaultInviteTTL
+	}
+	if policy.PasswordResetTTL <= 0 {
+		policy.PasswordResetTTL = defaultPasswordResetTTL
+	}
 	return &AuthService{
-		userRepo: userRepo,
+		userRepo:   userRepo,
+		inviteRepo: inviteRepo,
+		resetRepo:  resetRepo,
+		attempts:   attempts,
+		twoFactor:  twoFactor,
+		mailer:     sender,
+		policy:     policy,
+		sources:    newSources(),
 	}
 }
 
@@ -31,23 +95,272 @@ func (s *AuthService) RegisterUser(req authDto.RegisterRequest) (*models.User, e
 		return nil, err
 	}
 
+	status := "active"
+	var invite *models.Invite
+	if req.InviteToken != "" {
+		if invite, err = s.findInvite(req.InviteToken, req.Email, req.Role); err != nil {
+			return nil, err
+		}
+	} else {
+		if req.Role != "employee" || !s.policy.EmployeeSelfRegistration {
+			return nil, internalErrors.ErrInviteRequired
+		}
+		if s.policy.EmployeeApproval {
+			status = "pending"
+		}
+	}
+
 	user := &models.User{
-		ID:       uuid.New().String(),
+		ID:       s.newID(),
 		Email:    req.Email,
 		Role:     req.Role,
+		Status:   status,
 		Password: utils.HashPassword(req.Password),
 	}
 
-	err = s.userRepo.CreateUser(user)
+	if invite != nil {
+		err = s.inviteRepo.AcceptInvite(invite.ID, s.now(), user)
+	} else {
+		err = s.userRepo.CreateUser(user)
+	}
 	return user, err
 }
 
-func (s *AuthService) AuthenticateUser(req authDto.LoginRequest) (string, error) {
+func (s *AuthService) AuthenticateUser(req authDto.LoginRequest) (*LoginResult, error) {
+	if s.attempts != nil {
+		if err := s.attempts.Check(req.Email, req.ClientIP); err != nil {
+			return nil, err
+		}
+	}
 	user, err := s.validateCredentials(req.Email, req.Password)
 	if err != nil {
-		return "", err
+		if s.attempts != nil {
+			if recordErr := s.attempts.RegisterFailure(req.Email, req.ClientIP); recordErr != nil {
+				return nil, recordErr
+			}
+		}
+		return nil, err
+	}
+	if s.attempts != nil {
+		if err = s.attempts.RegisterSuccess(req.Email); err != nil {
+			return nil, err
+		}
+	}
+	switch user.Status {
+	case "pending":
+		return nil, internalErrors.ErrAccountPending
+	case "deactivated", "erased":
+		return nil, internalErrors.ErrAccountDeactivated
 	}
-	return utils.GenerateJWT(user.ID, user.Role)
+
+	result := &LoginResult{}
+	if s.twoFactor != nil {
+		enabled, err := s.twoFactor.IsEnabled(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if enabled {
+			result.Token, err = utils.GenerateTwoFactorJWT(user.ID)
+			result.TwoFactorRequired = true
+			return result, err
+		}
+		// The token is limited to enrollment by middleware.RequireTwoFactor.
+		result.TwoFactorEnrollmentRequired = s.twoFactor.IsRequired(user.Role)
+	}
+	result.Token, err = utils.GenerateJWT(user.ID, user.Role, user.TokenVersion, false)
+	return result, err
+}
+
+// ValidateSession checks that a token still belongs to an active account.
+// Tokens for users that are not stored are denied, unless /dummyLogin is
+// enabled for development and may have issued them.
+func (s *AuthService) ValidateSession(userID string, tokenVersion int) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrUserNotFound) {
+			if s.policy.DummyLogin {
+				return nil
+			}
+			return internalErrors.ErrAccessDenied
+		}
+		return err
+	}
+	if user.Status == "deactivated" || user.Status == "erased" {
+		return internalErrors.ErrAccountDeactivated
+	}
+	if user.TokenVersion != tokenVersion {
+		return internalErrors.ErrTokenRevoked
+	}
+	return nil
+}
+
+// CreateInvite issues a single-use invite bound to an email and role. The raw
+// token is returned only here; the repository keeps its hash.
+func (s *AuthService) CreateInvite(creator models.User, req inviteDto.CreateInviteRequest) (*models.Invite, string, error) {
+	if !isValidRole(req.Role) {
+		return nil, "", internalErrors.ErrInvalidRole
+	}
+	if !canInvite(creator.Role, req.Role) {
+		return nil, "", internalErrors.ErrAccessDenied
+	}
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := s.now()
+	invite := &models.Invite{
+		ID:        s.newID(),
+		TokenHash: utils.HashToken(token),
+		Email:     req.Email,
+		Role:      req.Role,
+		CreatedBy: creator.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.policy.InviteTTL),
+	}
+	if err = s.inviteRepo.CreateInvite(invite); err != nil {
+		return nil, "", err
+	}
+	return invite, token, nil
+}
+
+func (s *AuthService) ApproveUser(userID string) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != "pending" {
+		return nil, internalErrors.ErrUserNotPending
+	}
+	if err = s.userRepo.UpdateUserStatus(user.ID, "active"); err != nil {
+		return nil, err
+	}
+	user.Status = "active"
+	return user, nil
+}
+
+// EnsureAdmin creates the bootstrap administrator if no user with this email
+// exists yet. It is a no-op otherwise. An empty password is an error.
+func (s *AuthService) EnsureAdmin(email, password string) error {
+	if password == "" {
+		return fmt.Errorf("%w: the admin password is empty", internalErrors.ErrInvalidPassword)
+	}
+	_, err := s.getUserByEmail(email)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, internalErrors.ErrUserNotFound) {
+		return err
+	}
+	return s.userRepo.CreateUser(&models.User{
+		ID:       s.newID(),
+		Email:    email,
+		Role:     "admin",
+		Status:   "active",
+		Password: utils.HashPassword(password),
+	})
+}
+
+// ForgotPassword mails a single-use reset link to the user. Unknown and
+// inactive accounts are silently ignored so the endpoint cannot be used to
+// probe which emails are registered.
+func (s *AuthService) ForgotPassword(email string) error {
+	if s.resetRepo == nil || s.mailer == nil {
+		return errors.New("password reset is not configured")
+	}
+	user, err := s.getUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Status != "" && user.Status != "active" {
+		return nil
+	}
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	err = s.resetRepo.CreateResetToken(&models.PasswordResetToken{
+		ID:        s.newID(),
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.policy.PasswordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"To reset your password, follow the link below. It expires in %s.\n\n%s\n\nIf you did not request a reset, ignore this message.",
+			s.policy.PasswordResetTTL, s.resetLink(token),
+		),
+	})
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword. All
+// other outstanding reset tokens and existing sessions of the user are
+// revoked.
+func (s *AuthService) ResetPassword(token, password string) error {
+	if s.resetRepo == nil {
+		return errors.New("password reset is not configured")
+	}
+	if password == "" {
+		return internalErrors.ErrInvalidPassword
+	}
+	reset, err := s.resetRepo.GetResetTokenByHash(utils.HashToken(token))
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	if reset.UsedAt != nil || now.After(reset.ExpiresAt) {
+		return internalErrors.ErrInvalidResetToken
+	}
+	user, err := s.userRepo.GetUserByID(reset.UserID)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrUserNotFound) {
+			return internalErrors.ErrInvalidResetToken
+		}
+		return err
+	}
+	if user.Status != "" && user.Status != "active" {
+		return internalErrors.ErrInvalidResetToken
+	}
+	if err = s.resetRepo.MarkResetTokenUsed(reset.ID, now); err != nil {
+		return err
+	}
+	if err = s.userRepo.UpdateUserPassword(user.ID, utils.HashPassword(password)); err != nil {
+		return err
+	}
+	if err = s.resetRepo.InvalidateResetTokens(user.ID, now); err != nil {
+		return err
+	}
+	if s.attempts != nil {
+		return s.attempts.RegisterSuccess(user.Email)
+	}
+	return nil
+}
+
+func (s *AuthService) resetLink(token string) string {
+	if s.policy.PasswordResetURL == "" {
+		return token
+	}
+	link, err := url.Parse(s.policy.PasswordResetURL)
+	if err != nil {
+		return token
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
 }
 
 func (s *AuthService) getUserByEmail(email string) (*models.User, error) {
@@ -65,3 +378,35 @@ func (s *AuthService) validateCredentials(email, password string) (*models.User,
 	}
 	return user, nil
 }
+
+func (s *AuthService) findInvite(token, email, role string) (*models.Invite, error) {
+	invite, err := s.inviteRepo.GetInviteByTokenHash(utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if invite.UsedAt != nil ||
+		s.now().After(invite.ExpiresAt) ||
+		!strings.EqualFold(invite.Email, email) ||
+		invite.Role != role {
+		return nil, internalErrors.ErrInvalidInvite
+	}
+	return invite, nil
+}
+
+func isValidRole(role string) bool {
+	return role == "employee" || role == "moderator" || role == "admin"
+}
+
+// canInvite reports whether a user with creatorRole may invite someone into
+// role. Moderators invite employees only; privileged accounts are minted by
+// administrators.
+func canInvite(creatorRole, role string) bool {
+	switch creatorRole {
+	case "admin":
+		return true
+	case "moderator":
+		return role == "employee"
+	default:
+		return false
+	}
+}
@@ -3,12 +3,17 @@ package services
 import (
 	"avito-intern/internal/api/dto/internalErrors"
 	"avito-intern/internal/api/dto/request/authDto"
+	"avito-intern/internal/api/dto/request/inviteDto"
+	"avito-intern/internal/mailer"
 	"avito-intern/internal/models"
 	"avito-intern/internal/utils"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockUserRepository struct {
@@ -38,16 +43,109 @@ func (m *mockUserRepository) GetUserByEmail(email string) (*models.User, error)
 	return nil, internalErrors.ErrUserNotFound
 }
 
+func (m *mockUserRepository) GetUserByID(id string) (*models.User, error) {
+	if m.getUserErr != nil {
+		return nil, m.getUserErr
+	}
+	for _, user := range m.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, internalErrors.ErrUserNotFound
+}
+
+func (m *mockUserRepository) UpdateUserStatus(id, status string) error {
+	user, err := m.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	user.Status = status
+	user.TokenVersion++
+	return nil
+}
+
+func (m *mockUserRepository) ListUsers(email, role, status string, limit, offset int) ([]*models.User, error) {
+	users := make([]*models.User, 0)
+	for _, user := range m.users {
+		if (role == "" || user.Role == role) && (status == "" || user.Status == status) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (m *mockUserRepository) CountUsers(email, role, status string) (int, error) {
+	users, err := m.ListUsers(email, role, status, 0, 0)
+	return len(users), err
+}
+
+func (m *mockUserRepository) UpdateUserRole(id, role string) error {
+	user, err := m.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	user.Role = role
+	user.TokenVersion++
+	return nil
+}
+
+func (m *mockUserRepository) UpdateUserPassword(id, password string) error {
+	user, err := m.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	user.Password = password
+	user.TokenVersion++
+	return nil
+}
+
+type mockInviteRepository struct {
+	invites   map[string]*models.Invite
+	acceptErr error
+}
+
+func newMockInviteRepository() *mockInviteRepository {
+	return &mockInviteRepository{invites: make(map[string]*models.Invite)}
+}
+
+func (m *mockInviteRepository) CreateInvite(invite *models.Invite) error {
+	m.invites[invite.TokenHash] = invite
+	return nil
+}
+
+func (m *mockInviteRepository) GetInviteByTokenHash(tokenHash string) (*models.Invite, error) {
+	if invite, exists := m.invites[tokenHash]; exists {
+		return invite, nil
+	}
+	return nil, internalErrors.ErrInvalidInvite
+}
+
+func (m *mockInviteRepository) AcceptInvite(id string, usedAt time.Time, user *models.User) error {
+	if m.acceptErr != nil {
+		return m.acceptErr
+	}
+	for _, invite := range m.invites {
+		if invite.ID == id && invite.UsedAt == nil {
+			invite.UsedAt = &usedAt
+			return nil
+		}
+	}
+	return internalErrors.ErrInvalidInvite
+}
+
+var testPolicy = RegistrationPolicy{EmployeeSelfRegistration: true}
+
 func TestAuthService_RegisterUser_Success(t *testing.T) {
 
 	mockRepo := &mockUserRepository{
 		users: make(map[string]*models.User),
 	}
-	service := NewAuthService(mockRepo)
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
 	req := authDto.RegisterRequest{
 		Email:    "test@example.com",
 		Password: "password123",
-		Role:     "moderator",
+		Role:     "employee",
 	}
 
 	user, err := service.RegisterUser(req)
@@ -55,7 +153,8 @@ func TestAuthService_RegisterUser_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
 	assert.Equal(t, "test@example.com", user.Email)
-	assert.Equal(t, "moderator", user.Role)
+	assert.Equal(t, "employee", user.Role)
+	assert.Equal(t, "active", user.Status)
 
 	assert.NotEqual(t, "password123", user.Password)
 }
@@ -74,7 +173,7 @@ func TestAuthService_RegisterUser_DuplicateEmail(t *testing.T) {
 			"test@example.com": existingUser,
 		},
 	}
-	service := NewAuthService(mockRepo)
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
 	req := authDto.RegisterRequest{
 		Email:    "test@example.com",
 		Password: "password123",
@@ -94,7 +193,7 @@ func TestAuthService_RegisterUser_RepositoryError(t *testing.T) {
 		users:      make(map[string]*models.User),
 		getUserErr: errors.New("database error"),
 	}
-	service := NewAuthService(mockRepo)
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
 	req := authDto.RegisterRequest{
 		Email:    "test@example.com",
 		Password: "password123",
@@ -122,7 +221,7 @@ func TestAuthService_AuthenticateUser_ValidCredentials(t *testing.T) {
 			testUser.Email: testUser,
 		},
 	}
-	service := NewAuthService(mockRepo)
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
 	req := authDto.LoginRequest{
 		Email:    "test@example.com",
 		Password: "password123",
@@ -148,7 +247,7 @@ func TestAuthService_AuthenticateUser_InvalidPassword(t *testing.T) {
 			testUser.Email: testUser,
 		},
 	}
-	service := NewAuthService(mockRepo)
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
 	req := authDto.LoginRequest{
 		Email:    "test@example.com",
 		Password: "wrongpassword",
@@ -166,7 +265,7 @@ func TestAuthService_AuthenticateUser_NonExistentUser(t *testing.T) {
 	mockRepo := &mockUserRepository{
 		users: make(map[string]*models.User),
 	}
-	service := NewAuthService(mockRepo)
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
 	req := authDto.LoginRequest{
 		Email:    "nonexistent@example.com",
 		Password: "password123",
@@ -185,7 +284,7 @@ func TestAuthService_AuthenticateUser_RepositoryError(t *testing.T) {
 		users:      make(map[string]*models.User),
 		getUserErr: errors.New("database error"),
 	}
-	service := NewAuthService(mockRepo)
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
 	req := authDto.LoginRequest{
 		Email:    "test@example.com",
 		Password: "password123",
@@ -197,3 +296,363 @@ func TestAuthService_AuthenticateUser_RepositoryError(t *testing.T) {
 	assert.Equal(t, internalErrors.ErrInvalidCredentials, err) // Changed from database error
 	assert.Empty(t, token)
 }
+
+func TestAuthService_RegisterUser_ModeratorRequiresInvite(t *testing.T) {
+
+	mockRepo := &mockUserRepository{
+		users: make(map[string]*models.User),
+	}
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
+	req := authDto.RegisterRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+		Role:     "moderator",
+	}
+
+	user, err := service.RegisterUser(req)
+
+	assert.Equal(t, internalErrors.ErrInviteRequired, err)
+	assert.Nil(t, user)
+	assert.Empty(t, mockRepo.users)
+}
+
+func TestAuthService_RegisterUser_EmployeeSelfRegistrationDisabled(t *testing.T) {
+
+	mockRepo := &mockUserRepository{
+		users: make(map[string]*models.User),
+	}
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, RegistrationPolicy{})
+	req := authDto.RegisterRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+		Role:     "employee",
+	}
+
+	user, err := service.RegisterUser(req)
+
+	assert.Equal(t, internalErrors.ErrInviteRequired, err)
+	assert.Nil(t, user)
+}
+
+func TestAuthService_RegisterUser_EmployeeApprovalRequired(t *testing.T) {
+
+	mockRepo := &mockUserRepository{
+		users: make(map[string]*models.User),
+	}
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, RegistrationPolicy{
+		EmployeeSelfRegistration: true,
+		EmployeeApproval:         true,
+	})
+	req := authDto.RegisterRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+		Role:     "employee",
+	}
+
+	user, err := service.RegisterUser(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", user.Status)
+
+	token, err := service.AuthenticateUser(authDto.LoginRequest{Email: req.Email, Password: req.Password})
+	assert.Equal(t, internalErrors.ErrAccountPending, err)
+	assert.Empty(t, token)
+
+	approved, err := service.ApproveUser(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "active", approved.Status)
+
+	token, err = service.AuthenticateUser(authDto.LoginRequest{Email: req.Email, Password: req.Password})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	_, err = service.ApproveUser(user.ID)
+	assert.Equal(t, internalErrors.ErrUserNotPending, err)
+}
+
+func TestAuthService_RegisterUser_WithInvite(t *testing.T) {
+
+	mockRepo := &mockUserRepository{
+		users: make(map[string]*models.User),
+	}
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
+	admin := models.User{ID: "admin-id", Role: "admin"}
+
+	invite, token, err := service.CreateInvite(admin, inviteDto.CreateInviteRequest{
+		Email: "Moderator@example.com",
+		Role:  "moderator",
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.NotEqual(t, token, invite.TokenHash)
+
+	req := authDto.RegisterRequest{
+		Email:       "moderator@example.com",
+		Password:    "password123",
+		Role:        "moderator",
+		InviteToken: token,
+	}
+
+	user, err := service.RegisterUser(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "moderator", user.Role)
+	assert.Equal(t, "active", user.Status)
+	assert.NotNil(t, invite.UsedAt)
+
+	req.Email = "other@example.com"
+	user, err = service.RegisterUser(req)
+	assert.Equal(t, internalErrors.ErrInvalidInvite, err)
+	assert.Nil(t, user)
+}
+
+func TestAuthService_RegisterUser_InviteKeptWhenCreateFails(t *testing.T) {
+
+	mockRepo := &mockUserRepository{
+		users: make(map[string]*models.User),
+	}
+	inviteRepo := newMockInviteRepository()
+	inviteRepo.acceptErr = internalErrors.ErrEmailExists
+	service := NewAuthService(mockRepo, inviteRepo, nil, nil, nil, nil, testPolicy)
+
+	invite, token, err := service.CreateInvite(models.User{ID: "admin-id", Role: "admin"}, inviteDto.CreateInviteRequest{
+		Email: "moderator@example.com",
+		Role:  "moderator",
+	})
+	assert.NoError(t, err)
+
+	_, err = service.RegisterUser(authDto.RegisterRequest{
+		Email:       "moderator@example.com",
+		Password:    "password123",
+		Role:        "moderator",
+		InviteToken: token,
+	})
+	assert.Equal(t, internalErrors.ErrEmailExists, err)
+	assert.Nil(t, invite.UsedAt, "the invite is consumed together with the new account only")
+}
+
+func TestAuthService_RegisterUser_InviteMismatch(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		req    authDto.RegisterRequest
+		expire bool
+	}{
+		{
+			name: "Wrong email",
+			req:  authDto.RegisterRequest{Email: "other@example.com", Role: "moderator"},
+		},
+		{
+			name: "Wrong role",
+			req:  authDto.RegisterRequest{Email: "moderator@example.com", Role: "admin"},
+		},
+		{
+			name:   "Expired invite",
+			req:    authDto.RegisterRequest{Email: "moderator@example.com", Role: "moderator"},
+			expire: true,
+		},
+		{
+			name: "Unknown token",
+			req:  authDto.RegisterRequest{Email: "moderator@example.com", Role: "moderator", InviteToken: "unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockUserRepository{
+				users: make(map[string]*models.User),
+			}
+			service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
+			invite, token, err := service.CreateInvite(models.User{ID: "admin-id", Role: "admin"}, inviteDto.CreateInviteRequest{
+				Email: "moderator@example.com",
+				Role:  "moderator",
+			})
+			assert.NoError(t, err)
+			if tt.expire {
+				invite.ExpiresAt = time.Now().Add(-time.Minute)
+			}
+			if tt.req.InviteToken == "" {
+				tt.req.InviteToken = token
+			}
+
+			user, err := service.RegisterUser(tt.req)
+
+			assert.Equal(t, internalErrors.ErrInvalidInvite, err)
+			assert.Nil(t, user)
+			assert.Nil(t, invite.UsedAt)
+		})
+	}
+}
+
+func TestAuthService_CreateInvite_Permissions(t *testing.T) {
+
+	service := NewAuthService(&mockUserRepository{users: make(map[string]*models.User)}, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
+
+	_, _, err := service.CreateInvite(models.User{ID: "moderator-id", Role: "moderator"}, inviteDto.CreateInviteRequest{
+		Email: "admin@example.com",
+		Role:  "admin",
+	})
+	assert.Equal(t, internalErrors.ErrAccessDenied, err)
+
+	_, _, err = service.CreateInvite(models.User{ID: "employee-id", Role: "employee"}, inviteDto.CreateInviteRequest{
+		Email: "employee@example.com",
+		Role:  "employee",
+	})
+	assert.Equal(t, internalErrors.ErrAccessDenied, err)
+
+	_, _, err = service.CreateInvite(models.User{ID: "admin-id", Role: "admin"}, inviteDto.CreateInviteRequest{
+		Email: "someone@example.com",
+		Role:  "superuser",
+	})
+	assert.Equal(t, internalErrors.ErrInvalidRole, err)
+
+	_, _, err = service.CreateInvite(models.User{ID: "moderator-id", Role: "moderator"}, inviteDto.CreateInviteRequest{
+		Email: "moderator@example.com",
+		Role:  "moderator",
+	})
+	assert.Equal(t, internalErrors.ErrAccessDenied, err, "only administrators invite moderators")
+
+	invite, _, err := service.CreateInvite(models.User{ID: "moderator-id", Role: "moderator"}, inviteDto.CreateInviteRequest{
+		Email: "employee@example.com",
+		Role:  "employee",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "moderator-id", invite.CreatedBy)
+	assert.WithinDuration(t, time.Now().Add(defaultInviteTTL), invite.ExpiresAt, time.Minute)
+}
+
+func TestAuthService_EnsureAdmin(t *testing.T) {
+
+	mockRepo := &mockUserRepository{
+		users: make(map[string]*models.User),
+	}
+	service := NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy)
+
+	assert.ErrorIs(t, service.EnsureAdmin("admin@example.com", ""), internalErrors.ErrInvalidPassword)
+	assert.Empty(t, mockRepo.users, "no admin without a password")
+
+	assert.NoError(t, service.EnsureAdmin("admin@example.com", "secret"))
+	assert.NoError(t, service.EnsureAdmin("admin@example.com", "other"))
+
+	admin := mockRepo.users["admin@example.com"]
+	assert.Equal(t, "admin", admin.Role)
+	assert.Equal(t, utils.HashPassword("secret"), admin.Password)
+}
+
+type mockPasswordResetRepository struct {
+	tokens map[string]*models.PasswordResetToken
+}
+
+func newMockPasswordResetRepository() *mockPasswordResetRepository {
+	return &mockPasswordResetRepository{tokens: make(map[string]*models.PasswordResetToken)}
+}
+
+func (m *mockPasswordResetRepository) CreateResetToken(token *models.PasswordResetToken) error {
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (m *mockPasswordResetRepository) GetResetTokenByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	if token, exists := m.tokens[tokenHash]; exists {
+		copied := *token
+		return &copied, nil
+	}
+	return nil, internalErrors.ErrInvalidResetToken
+}
+
+func (m *mockPasswordResetRepository) MarkResetTokenUsed(id string, usedAt time.Time) error {
+	for _, token := range m.tokens {
+		if token.ID == id && token.UsedAt == nil {
+			token.UsedAt = &usedAt
+			return nil
+		}
+	}
+	return internalErrors.ErrInvalidResetToken
+}
+
+func (m *mockPasswordResetRepository) InvalidateResetTokens(userID string, usedAt time.Time) error {
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+type mockMailer struct {
+	sent []mailer.Message
+}
+
+func (m *mockMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestAuthService_PasswordReset(t *testing.T) {
+	mockRepo := &mockUserRepository{
+		users: map[string]*models.User{
+			"test@example.com": {
+				ID: "test-id", Email: "test@example.com", Role: "employee", Status: "active",
+				Password: utils.HashPassword("password123"),
+			},
+		},
+	}
+	resetRepo := newMockPasswordResetRepository()
+	sent := &mockMailer{}
+	service := NewAuthService(mockRepo, newMockInviteRepository(), resetRepo, nil, nil, sent, RegistrationPolicy{
+		EmployeeSelfRegistration: true,
+		PasswordResetURL:         "https://pvz.example.com/reset",
+	})
+
+	require.NoError(t, service.ForgotPassword("unknown@example.com"))
+	assert.Empty(t, sent.sent, "unknown emails must not reveal themselves")
+
+	require.NoError(t, service.ForgotPassword("test@example.com"))
+	require.NoError(t, service.ForgotPassword("test@example.com"))
+	require.Len(t, sent.sent, 2)
+	assert.Equal(t, "test@example.com", sent.sent[0].To)
+
+	token := resetTokenFromMail(t, sent.sent[0].Body)
+	otherToken := resetTokenFromMail(t, sent.sent[1].Body)
+	for hash := range resetRepo.tokens {
+		assert.NotEqual(t, token, hash, "only token hashes are stored")
+	}
+
+	assert.ErrorIs(t, service.ResetPassword("bogus", "newpassword"), internalErrors.ErrInvalidResetToken)
+	assert.ErrorIs(t, service.ResetPassword(token, ""), internalErrors.ErrInvalidPassword)
+
+	require.NoError(t, service.ResetPassword(token, "newpassword"))
+	assert.ErrorIs(t, service.ResetPassword(token, "another"), internalErrors.ErrInvalidResetToken)
+	assert.ErrorIs(t, service.ResetPassword(otherToken, "another"), internalErrors.ErrInvalidResetToken,
+		"a successful reset revokes the other outstanding links")
+
+	_, err := service.AuthenticateUser(authDto.LoginRequest{Email: "test@example.com", Password: "password123"})
+	assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+	_, err = service.AuthenticateUser(authDto.LoginRequest{Email: "test@example.com", Password: "newpassword"})
+	assert.NoError(t, err)
+	assert.Equal(t, internalErrors.ErrTokenRevoked, service.ValidateSession("test-id", 0))
+}
+
+func TestAuthService_ResetPassword_Expired(t *testing.T) {
+	mockRepo := &mockUserRepository{
+		users: map[string]*models.User{
+			"test@example.com": {ID: "test-id", Email: "test@example.com", Role: "employee", Status: "active"},
+		},
+	}
+	resetRepo := newMockPasswordResetRepository()
+	resetRepo.tokens[utils.HashToken("expired")] = &models.PasswordResetToken{
+		ID:        "reset-id",
+		UserID:    "test-id",
+		TokenHash: utils.HashToken("expired"),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	service := NewAuthService(mockRepo, newMockInviteRepository(), resetRepo, nil, nil, &mockMailer{}, testPolicy)
+
+	assert.ErrorIs(t, service.ResetPassword("expired", "newpassword"), internalErrors.ErrInvalidResetToken)
+}
+
+func resetTokenFromMail(t *testing.T, body string) string {
+	const prefix = "https://pvz.example.com/reset?token="
+	start := strings.Index(body, prefix)
+	require.NotEqual(t, -1, start, "reset link not found in %q", body)
+	token := body[start+len(prefix):]
+	return token[:strings.IndexAny(token, "\n")]
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"time"
+)
+
+const defaultIdempotencyTTL = 24 * time.Hour
+
+type IdempotencyService struct {
+	repo repository.IdempotencyRepositoryInterface
+	ttl  time.Duration
+	sources
+}
+
+// NewIdempotencyService keeps the responses of requests with an
+// Idempotency-Key for ttl, so that retries within it get the same response.
+func NewIdempotencyService(repo repository.IdempotencyRepositoryInterface, ttl time.Duration) *IdempotencyService {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	return &IdempotencyService{
+		repo:    repo,
+		ttl:     ttl,
+		sources: newSources(),
+	}
+}
+
+// Begin reserves key within scope for the request with requestHash. It
+// returns nil when the request should be served, and the stored response
+// when it is a retry of a completed request. A key used for another request
+// gives ErrIdempotencyKeyReused, a key of a request still being served gives
+// ErrIdempotencyInProgress.
+func (s *IdempotencyService) Begin(scope, key, requestHash string) (*models.IdempotencyKey, error) {
+	now := s.now()
+	created, err := s.repo.CreateIdempotencyKey(&models.IdempotencyKey{
+		Scope:       scope,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	})
+	if err != nil || created {
+		return nil, err
+	}
+
+	stored, err := s.repo.GetIdempotencyKey(scope, key)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case stored == nil:
+		// Expired and removed by a concurrent request, which now holds it.
+		return nil, internalErrors.ErrIdempotencyInProgress
+	case stored.RequestHash != requestHash:
+		return nil, internalErrors.ErrIdempotencyKeyReused
+	case !stored.Completed():
+		return nil, internalErrors.ErrIdempotencyInProgress
+	default:
+		return stored, nil
+	}
+}
+
+// Complete stores the response to replay for retries of the request.
+func (s *IdempotencyService) Complete(response *models.IdempotencyKey) error {
+	return s.repo.CompleteIdempotencyKey(response)
+}
+
+// Release frees key after a request that may succeed when retried, e.g. one
+// that failed with a server error.
+func (s *IdempotencyService) Release(scope, key string) error {
+	return s.repo.DeleteIdempotencyKey(scope, key)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockIdempotencyRepository struct {
+	keys map[string]*models.IdempotencyKey
+}
+
+func newMockIdempotencyRepository() *mockIdempotencyRepository {
+	return &mockIdempotencyRepository{keys: make(map[string]*models.IdempotencyKey)}
+}
+
+func (m *mockIdempotencyRepository) CreateIdempotencyKey(key *models.IdempotencyKey) (bool, error) {
+	for id, stored := range m.keys {
+		if stored.ExpiresAt.Before(key.CreatedAt) {
+			delete(m.keys, id)
+		}
+	}
+	if _, exists := m.keys[key.Scope+"/"+key.Key]; exists {
+		return false, nil
+	}
+	copied := *key
+	m.keys[key.Scope+"/"+key.Key] = &copied
+	return true, nil
+}
+
+func (m *mockIdempotencyRepository) GetIdempotencyKey(scope, key string) (*models.IdempotencyKey, error) {
+	if stored, exists := m.keys[scope+"/"+key]; exists {
+		copied := *stored
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockIdempotencyRepository) CompleteIdempotencyKey(key *models.IdempotencyKey) error {
+	stored := m.keys[key.Scope+"/"+key.Key]
+	stored.StatusCode = key.StatusCode
+	stored.ResponseHeaders = key.ResponseHeaders
+	stored.ResponseBody = key.ResponseBody
+	return nil
+}
+
+func (m *mockIdempotencyRepository) DeleteIdempotencyKey(scope, key string) error {
+	delete(m.keys, scope+"/"+key)
+	return nil
+}
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	service := NewIdempotencyService(newMockIdempotencyRepository(), time.Hour)
+	service.now = func() time.Time { return now }
+
+	stored, err := service.Begin("caller", "key-1", "hash-1")
+	require.NoError(t, err)
+	assert.Nil(t, stored, "first request is served")
+
+	_, err = service.Begin("caller", "key-1", "hash-1")
+	assert.ErrorIs(t, err, internalErrors.ErrIdempotencyInProgress)
+
+	_, err = service.Begin("caller", "key-1", "hash-2")
+	assert.ErrorIs(t, err, internalErrors.ErrIdempotencyKeyReused)
+
+	require.NoError(t, service.Complete(&models.IdempotencyKey{
+		Scope:        "caller",
+		Key:          "key-1",
+		StatusCode:   201,
+		ResponseBody: []byte(`{"id":"1"}`),
+	}))
+
+	stored, err = service.Begin("caller", "key-1", "hash-1")
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, 201, stored.StatusCode)
+	assert.Equal(t, `{"id":"1"}`, string(stored.ResponseBody))
+
+	_, err = service.Begin("caller", "key-1", "hash-2")
+	assert.ErrorIs(t, err, internalErrors.ErrIdempotencyKeyReused)
+
+	stored, err = service.Begin("another-caller", "key-1", "hash-2")
+	require.NoError(t, err)
+	assert.Nil(t, stored, "keys are scoped by caller")
+}
+
+func TestIdempotencyService_ExpiredAndReleasedKeys(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	service := NewIdempotencyService(newMockIdempotencyRepository(), time.Hour)
+	service.now = func() time.Time { return now }
+
+	_, err := service.Begin("caller", "key-1", "hash-1")
+	require.NoError(t, err)
+	require.NoError(t, service.Release("caller", "key-1"))
+
+	stored, err := service.Begin("caller", "key-1", "hash-2")
+	require.NoError(t, err)
+	assert.Nil(t, stored, "released key can be used again")
+
+	now = now.Add(2 * time.Hour)
+	stored, err = service.Begin("caller", "key-1", "hash-3")
+	require.NoError(t, err)
+	assert.Nil(t, stored, "expired key can be used again")
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/utils"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const defaultImpersonationTTL = 15 * time.Minute
+
+// ImpersonationService lets administrators act as another user to reproduce
+// what they see. Impersonated sessions are read-only and every request made
+// with them ends up in the audit log.
+type ImpersonationService struct {
+	userRepo  repository.UserRepositoryInterface
+	auditRepo repository.AuditRepositoryInterface
+	ttl       time.Duration
+	sources
+}
+
+func NewImpersonationService(
+	userRepo repository.UserRepositoryInterface,
+	auditRepo repository.AuditRepositoryInterface,
+	ttl time.Duration,
+) *ImpersonationService {
+	if ttl <= 0 {
+		ttl = defaultImpersonationTTL
+	}
+	return &ImpersonationService{
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+		ttl:       ttl,
+		sources:   newSources(),
+	}
+}
+
+// Start issues a token of the target user that also names the administrator.
+// Other administrators cannot be impersonated.
+func (s *ImpersonationService) Start(actor models.User, userID string) (string, time.Time, error) {
+	if actor.ImpersonatedBy != "" {
+		return "", time.Time{}, internalErrors.ErrAccessDenied
+	}
+	if actor.ID == userID {
+		return "", time.Time{}, internalErrors.ErrCannotModifySelf
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if user.Role == "admin" {
+		return "", time.Time{}, internalErrors.ErrAccessDenied
+	}
+	if user.Status != "" && user.Status != "active" {
+		return "", time.Time{}, internalErrors.ErrInvalidUserStatus
+	}
+
+	if err = s.record(actor.ID, user.ID, models.AuditImpersonationStart, ""); err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := s.now().Add(s.ttl)
+	token, err := utils.GenerateImpersonationJWT(user.ID, user.Role, user.TokenVersion, actor.ID, actor.TokenVersion, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ValidateActor checks that the administrator of an impersonation session
+// may still use it: the account is active, still an administrator and has
+// not revoked the token it started the session with.
+func (s *ImpersonationService) ValidateActor(actorID string, tokenVersion int) error {
+	actor, err := s.userRepo.GetUserByID(actorID)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrUserNotFound) {
+			return internalErrors.ErrAccessDenied
+		}
+		return err
+	}
+	if actor.Status != "" && actor.Status != "active" {
+		return internalErrors.ErrAccountDeactivated
+	}
+	if actor.Role != "admin" {
+		return internalErrors.ErrAccessDenied
+	}
+	if actor.TokenVersion != tokenVersion {
+		return internalErrors.ErrTokenRevoked
+	}
+	return nil
+}
+
+// RecordRequest audits a request made with an impersonation token. blocked
+// marks mutations that were refused.
+func (s *ImpersonationService) RecordRequest(actorID, userID, method, path string, blocked bool) error {
+	action := models.AuditImpersonationRequest
+	if blocked {
+		action = models.AuditImpersonationBlocked
+	}
+	return s.record(actorID, userID, action, method+" "+path)
+}
+
+func (s *ImpersonationService) ListAuditEvents(actorID, userID, limitStr, pageStr string) ([]*models.AuditEvent, error) {
+	page, _ := strconv.Atoi(pageStr)
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(limitStr)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return s.auditRepo.ListAuditEvents(actorID, userID, limit, (page-1)*limit)
+}
+
+func (s *ImpersonationService) record(actorID, userID, action, details string) error {
+	return s.auditRepo.CreateAuditEvent(&models.AuditEvent{
+		ID:        s.newID(),
+		ActorID:   actorID,
+		UserID:    userID,
+		Action:    action,
+		Details:   details,
+		CreatedAt: s.now(),
+	})
+}
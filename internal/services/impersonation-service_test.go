@@ -0,0 +1,125 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"avito-intern/internal/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAuditRepository struct {
+	events    []*models.AuditEvent
+	createErr error
+}
+
+func (m *mockAuditRepository) CreateAuditEvent(event *models.AuditEvent) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockAuditRepository) ListAuditEvents(actorID, userID string, limit, offset int) ([]*models.AuditEvent, error) {
+	events := make([]*models.AuditEvent, 0)
+	for _, event := range m.events {
+		if (actorID == "" || event.ActorID == actorID) && (userID == "" || event.UserID == userID) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func newImpersonationFixture() (*ImpersonationService, *mockAuditRepository) {
+	userRepo := &mockUserRepository{users: map[string]*models.User{
+		"employee@example.com": {ID: "employee-id", Email: "employee@example.com", Role: "employee", Status: "active", TokenVersion: 3},
+		"pending@example.com":  {ID: "pending-id", Email: "pending@example.com", Role: "employee", Status: "pending"},
+		"other@example.com":    {ID: "other-admin-id", Email: "other@example.com", Role: "admin", Status: "active"},
+		"admin@example.com":    {ID: "admin-id", Email: "admin@example.com", Role: "admin", Status: "active", TokenVersion: 2},
+		"former@example.com":   {ID: "former-admin-id", Email: "former@example.com", Role: "moderator", Status: "active"},
+		"gone@example.com":     {ID: "gone-admin-id", Email: "gone@example.com", Role: "admin", Status: "deactivated"},
+	}}
+	auditRepo := &mockAuditRepository{}
+	return NewImpersonationService(userRepo, auditRepo, time.Minute), auditRepo
+}
+
+func TestImpersonationService_Start(t *testing.T) {
+	service, auditRepo := newImpersonationFixture()
+	admin := models.User{ID: "admin-id", Role: "admin", TokenVersion: 2}
+
+	token, expiresAt, err := service.Start(admin, "employee-id")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, 5*time.Second)
+
+	claims, err := utils.ParseJWT(token)
+	require.NoError(t, err)
+	assert.Equal(t, "employee-id", claims["user_id"])
+	assert.Equal(t, "employee", claims["role"])
+	assert.Equal(t, float64(3), claims["token_version"])
+	assert.Equal(t, map[string]interface{}{"sub": "admin-id", "token_version": float64(2)}, claims["act"])
+
+	require.Len(t, auditRepo.events, 1)
+	assert.Equal(t, models.AuditImpersonationStart, auditRepo.events[0].Action)
+	assert.Equal(t, "admin-id", auditRepo.events[0].ActorID)
+	assert.Equal(t, "employee-id", auditRepo.events[0].UserID)
+}
+
+func TestImpersonationService_Start_Rejected(t *testing.T) {
+	service, auditRepo := newImpersonationFixture()
+	admin := models.User{ID: "admin-id", Role: "admin"}
+
+	_, _, err := service.Start(admin, "admin-id")
+	assert.ErrorIs(t, err, internalErrors.ErrCannotModifySelf)
+
+	_, _, err = service.Start(admin, "other-admin-id")
+	assert.ErrorIs(t, err, internalErrors.ErrAccessDenied)
+
+	_, _, err = service.Start(admin, "pending-id")
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidUserStatus)
+
+	_, _, err = service.Start(admin, "missing-id")
+	assert.ErrorIs(t, err, internalErrors.ErrUserNotFound)
+
+	_, _, err = service.Start(models.User{ID: "employee-id", Role: "admin", ImpersonatedBy: "admin-id"}, "pending-id")
+	assert.ErrorIs(t, err, internalErrors.ErrAccessDenied)
+
+	assert.Empty(t, auditRepo.events)
+}
+
+func TestImpersonationService_Start_AuditFailure(t *testing.T) {
+	service, auditRepo := newImpersonationFixture()
+	auditRepo.createErr = errors.New("database error")
+
+	token, _, err := service.Start(models.User{ID: "admin-id", Role: "admin"}, "employee-id")
+	assert.Error(t, err)
+	assert.Empty(t, token, "no token is issued without an audit record")
+}
+
+func TestImpersonationService_ValidateActor(t *testing.T) {
+	service, _ := newImpersonationFixture()
+
+	assert.NoError(t, service.ValidateActor("admin-id", 2))
+	assert.ErrorIs(t, service.ValidateActor("admin-id", 1), internalErrors.ErrTokenRevoked)
+	assert.ErrorIs(t, service.ValidateActor("former-admin-id", 0), internalErrors.ErrAccessDenied)
+	assert.ErrorIs(t, service.ValidateActor("gone-admin-id", 0), internalErrors.ErrAccountDeactivated)
+	assert.ErrorIs(t, service.ValidateActor("missing-id", 0), internalErrors.ErrAccessDenied)
+}
+
+func TestImpersonationService_RecordRequest(t *testing.T) {
+	service, auditRepo := newImpersonationFixture()
+
+	require.NoError(t, service.RecordRequest("admin-id", "employee-id", "GET", "/pvz", false))
+	require.NoError(t, service.RecordRequest("admin-id", "employee-id", "POST", "/receptions", true))
+
+	events, err := service.ListAuditEvents("admin-id", "", "", "")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.AuditImpersonationRequest, auditRepo.events[0].Action)
+	assert.Equal(t, "GET /pvz", auditRepo.events[0].Details)
+	assert.Equal(t, models.AuditImpersonationBlocked, auditRepo.events[1].Action)
+}
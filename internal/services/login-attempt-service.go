@@ -0,0 +1,153 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/metrics"
+	"avito-intern/internal/repository"
+	"strings"
+	"time"
+)
+
+const defaultFailureWindow = time.Hour
+
+// LockoutPolicy configures login throttling. After every failure the next
+// attempt is delayed by BaseDelay doubled per failure, up to MaxDelay. Reaching
+// the threshold locks the account (or client IP) for LockoutDuration.
+// Failures count only while they follow each other within FailureWindow
+// (an hour when zero); after a longer pause the counter starts over.
+type LockoutPolicy struct {
+	AccountThreshold int
+	IPThreshold      int
+	LockoutDuration  time.Duration
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	FailureWindow    time.Duration
+}
+
+type LoginAttemptService struct {
+	userRepo    repository.UserRepositoryInterface
+	attemptRepo repository.LoginAttemptRepositoryInterface
+	policy      LockoutPolicy
+	sources
+}
+
+func NewLoginAttemptService(
+	userRepo repository.UserRepositoryInterface,
+	attemptRepo repository.LoginAttemptRepositoryInterface,
+	policy LockoutPolicy,
+) *LoginAttemptService {
+	if policy.FailureWindow <= 0 {
+		policy.FailureWindow = defaultFailureWindow
+	}
+	return &LoginAttemptService{
+		userRepo:    userRepo,
+		attemptRepo: attemptRepo,
+		policy:      policy,
+		sources:     newSources(),
+	}
+}
+
+// Check returns a RetryAfterError wrapping ErrTooManyLoginAttempts when the
+// account or the client IP is locked or still inside its progressive delay.
+func (s *LoginAttemptService) Check(email, ip string) error {
+	for _, key := range s.keys(email, ip) {
+		attempt, err := s.attemptRepo.GetLoginAttempt(key)
+		if err != nil {
+			return err
+		}
+		now := s.now()
+		if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+			metrics.LoginFailedCount.WithLabelValues("locked").Inc()
+			return &internalErrors.RetryAfterError{
+				Err:        internalErrors.ErrTooManyLoginAttempts,
+				RetryAfter: attempt.LockedUntil.Sub(now),
+			}
+		}
+		if attempt.Failures > 0 && !attempt.LastFailureAt.Before(now.Add(-s.policy.FailureWindow)) {
+			if next := attempt.LastFailureAt.Add(s.delay(attempt.Failures)); now.Before(next) {
+				metrics.LoginFailedCount.WithLabelValues("throttled").Inc()
+				return &internalErrors.RetryAfterError{
+					Err:        internalErrors.ErrTooManyLoginAttempts,
+					RetryAfter: next.Sub(now),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *LoginAttemptService) RegisterFailure(email, ip string) error {
+	metrics.LoginFailedCount.WithLabelValues("invalid_credentials").Inc()
+	now := s.now()
+	for _, key := range s.keys(email, ip) {
+		failures, err := s.attemptRepo.RecordFailure(key, now, now.Add(-s.policy.FailureWindow))
+		if err != nil {
+			return err
+		}
+		threshold := s.policy.AccountThreshold
+		scope := "account"
+		if strings.HasPrefix(key, "ip:") {
+			threshold = s.policy.IPThreshold
+			scope = "ip"
+		}
+		if threshold > 0 && failures >= threshold {
+			if err = s.attemptRepo.Lock(key, now.Add(s.policy.LockoutDuration)); err != nil {
+				return err
+			}
+			metrics.AccountLockoutCount.WithLabelValues(scope).Inc()
+		}
+	}
+	return nil
+}
+
+// RegisterSuccess clears the counter of the account. The counter of the
+// client IP is left to expire or to UnlockUser: a password sprayer could
+// otherwise wipe it by logging into an account of their own now and then.
+func (s *LoginAttemptService) RegisterSuccess(email string) error {
+	return s.reset(s.keys(email, ""))
+}
+
+// UnlockUser clears the lockout of the account and, when ip is not empty,
+// the lockout of that client IP.
+func (s *LoginAttemptService) UnlockUser(userID, ip string) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	return s.reset(s.keys(user.Email, ip))
+}
+
+func (s *LoginAttemptService) reset(keys []string) error {
+	for _, key := range keys {
+		if err := s.attemptRepo.Reset(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LoginAttemptService) delay(failures int) time.Duration {
+	if s.policy.BaseDelay <= 0 {
+		return 0
+	}
+	delay := s.policy.BaseDelay
+	for i := 1; i < failures && i < 32; i++ {
+		delay *= 2
+		if s.policy.MaxDelay > 0 && delay >= s.policy.MaxDelay {
+			return s.policy.MaxDelay
+		}
+	}
+	return delay
+}
+
+func (s *LoginAttemptService) keys(email, ip string) []string {
+	keys := []string{accountKey(email)}
+	if ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+	return keys
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
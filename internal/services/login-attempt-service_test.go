@@ -0,0 +1,224 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/authDto"
+	"avito-intern/internal/models"
+	"avito-intern/internal/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLoginAttemptRepository struct {
+	attempts map[string]*models.LoginAttempt
+}
+
+func newMockLoginAttemptRepository() *mockLoginAttemptRepository {
+	return &mockLoginAttemptRepository{attempts: make(map[string]*models.LoginAttempt)}
+}
+
+func (m *mockLoginAttemptRepository) GetLoginAttempt(key string) (*models.LoginAttempt, error) {
+	if attempt, exists := m.attempts[key]; exists {
+		copied := *attempt
+		return &copied, nil
+	}
+	return &models.LoginAttempt{Key: key}, nil
+}
+
+func (m *mockLoginAttemptRepository) RecordFailure(key string, at, windowStart time.Time) (int, error) {
+	attempt, exists := m.attempts[key]
+	if !exists {
+		attempt = &models.LoginAttempt{Key: key}
+		m.attempts[key] = attempt
+	}
+	if attempt.LastFailureAt.Before(windowStart) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = at
+	return attempt.Failures, nil
+}
+
+func (m *mockLoginAttemptRepository) Lock(key string, until time.Time) error {
+	m.attempts[key].LockedUntil = &until
+	m.attempts[key].Failures = 0
+	return nil
+}
+
+func (m *mockLoginAttemptRepository) Reset(key string) error {
+	delete(m.attempts, key)
+	return nil
+}
+
+func newLoginAttemptFixture(policy LockoutPolicy) (*AuthService, *LoginAttemptService, *time.Time) {
+	userRepo := &mockUserRepository{
+		users: map[string]*models.User{
+			"test@example.com": {
+				ID: "test-id", Email: "test@example.com", Role: "employee", Status: "active",
+				Password: utils.HashPassword("password123"),
+			},
+		},
+	}
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	attempts := NewLoginAttemptService(userRepo, newMockLoginAttemptRepository(), policy)
+	attempts.now = func() time.Time { return clock }
+	return NewAuthService(userRepo, newMockInviteRepository(), nil, attempts, nil, nil, testPolicy), attempts, &clock
+}
+
+func retryAfter(t *testing.T, err error) time.Duration {
+	var retryErr *internalErrors.RetryAfterError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected RetryAfterError, got %v", err)
+	}
+	assert.ErrorIs(t, err, internalErrors.ErrTooManyLoginAttempts)
+	return retryErr.RetryAfter
+}
+
+func TestLoginAttemptService_ProgressiveDelay(t *testing.T) {
+
+	service, _, clock := newLoginAttemptFixture(LockoutPolicy{
+		AccountThreshold: 10,
+		BaseDelay:        time.Second,
+		MaxDelay:         3 * time.Second,
+	})
+	wrong := authDto.LoginRequest{Email: "test@example.com", Password: "wrong"}
+
+	_, err := service.AuthenticateUser(wrong)
+	assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+
+	_, err = service.AuthenticateUser(wrong)
+	assert.Equal(t, time.Second, retryAfter(t, err))
+
+	*clock = clock.Add(time.Second)
+	_, err = service.AuthenticateUser(wrong)
+	assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+
+	_, err = service.AuthenticateUser(wrong)
+	assert.Equal(t, 2*time.Second, retryAfter(t, err))
+
+	*clock = clock.Add(2 * time.Second)
+	_, err = service.AuthenticateUser(wrong)
+	assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+
+	*clock = clock.Add(time.Second)
+	_, err = service.AuthenticateUser(wrong)
+	assert.Equal(t, 2*time.Second, retryAfter(t, err), "delay is capped at MaxDelay")
+}
+
+func TestLoginAttemptService_AccountLockoutAndUnlock(t *testing.T) {
+
+	service, attempts, clock := newLoginAttemptFixture(LockoutPolicy{
+		AccountThreshold: 3,
+		LockoutDuration:  15 * time.Minute,
+	})
+	wrong := authDto.LoginRequest{Email: "test@example.com", Password: "wrong", ClientIP: "10.0.0.1"}
+	right := authDto.LoginRequest{Email: "test@example.com", Password: "password123", ClientIP: "10.0.0.2"}
+
+	for i := 0; i < 3; i++ {
+		_, err := service.AuthenticateUser(wrong)
+		assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+	}
+
+	_, err := service.AuthenticateUser(right)
+	assert.Equal(t, 15*time.Minute, retryAfter(t, err), "correct password is rejected while locked")
+
+	*clock = clock.Add(5 * time.Minute)
+	_, err = service.AuthenticateUser(right)
+	assert.Equal(t, 10*time.Minute, retryAfter(t, err))
+
+	assert.NoError(t, attempts.UnlockUser("test-id", ""))
+	token, err := service.AuthenticateUser(right)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	assert.Equal(t, internalErrors.ErrUserNotFound, attempts.UnlockUser("missing-id", ""))
+}
+
+func TestLoginAttemptService_IPLockout(t *testing.T) {
+
+	service, attempts, _ := newLoginAttemptFixture(LockoutPolicy{
+		AccountThreshold: 100,
+		IPThreshold:      2,
+		LockoutDuration:  time.Minute,
+	})
+
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		_, err := service.AuthenticateUser(authDto.LoginRequest{Email: email, Password: "x", ClientIP: "10.0.0.1"})
+		assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+	}
+
+	_, err := service.AuthenticateUser(authDto.LoginRequest{Email: "test@example.com", Password: "password123", ClientIP: "10.0.0.1"})
+	assert.Equal(t, time.Minute, retryAfter(t, err))
+
+	token, err := service.AuthenticateUser(authDto.LoginRequest{Email: "test@example.com", Password: "password123", ClientIP: "10.0.0.2"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	assert.NoError(t, attempts.UnlockUser("test-id", "10.0.0.1"))
+	_, err = service.AuthenticateUser(authDto.LoginRequest{Email: "test@example.com", Password: "password123", ClientIP: "10.0.0.1"})
+	assert.NoError(t, err, "unlocking with the IP clears its lockout")
+}
+
+func TestLoginAttemptService_SuccessResetsAccountCounter(t *testing.T) {
+
+	service, attempts, _ := newLoginAttemptFixture(LockoutPolicy{
+		AccountThreshold: 2,
+		LockoutDuration:  time.Minute,
+	})
+
+	_, err := service.AuthenticateUser(authDto.LoginRequest{Email: "test@example.com", Password: "wrong"})
+	assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+
+	_, err = service.AuthenticateUser(authDto.LoginRequest{Email: "test@example.com", Password: "password123"})
+	assert.NoError(t, err)
+
+	attempt, err := attempts.attemptRepo.GetLoginAttempt(accountKey("test@example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, attempt.Failures)
+}
+
+func TestLoginAttemptService_SuccessKeepsIPCounter(t *testing.T) {
+
+	service, _, _ := newLoginAttemptFixture(LockoutPolicy{
+		AccountThreshold: 100,
+		IPThreshold:      3,
+		LockoutDuration:  time.Minute,
+	})
+	right := authDto.LoginRequest{Email: "test@example.com", Password: "password123", ClientIP: "10.0.0.1"}
+
+	// A sprayer logs into their own account between guesses at others.
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		_, err := service.AuthenticateUser(authDto.LoginRequest{Email: email, Password: "x", ClientIP: "10.0.0.1"})
+		assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+	}
+	_, err := service.AuthenticateUser(right)
+	assert.NoError(t, err)
+	_, err = service.AuthenticateUser(authDto.LoginRequest{Email: "c@example.com", Password: "x", ClientIP: "10.0.0.1"})
+	assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+
+	_, err = service.AuthenticateUser(right)
+	assert.Equal(t, time.Minute, retryAfter(t, err), "the success did not clear the IP counter")
+}
+
+func TestLoginAttemptService_FailuresExpire(t *testing.T) {
+
+	service, _, clock := newLoginAttemptFixture(LockoutPolicy{
+		AccountThreshold: 3,
+		LockoutDuration:  time.Minute,
+		FailureWindow:    time.Hour,
+	})
+	wrong := authDto.LoginRequest{Email: "test@example.com", Password: "wrong"}
+
+	// An occasional typo a day does not add up to a lockout.
+	for i := 0; i < 5; i++ {
+		_, err := service.AuthenticateUser(wrong)
+		assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+		*clock = clock.Add(24 * time.Hour)
+	}
+
+	_, err := service.AuthenticateUser(authDto.LoginRequest{Email: "test@example.com", Password: "password123"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/utils"
+	"errors"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type TokenVerifier interface {
+	Verify(rawToken string) (jwt.MapClaims, error)
+}
+
+// OIDCPolicy maps identity provider groups to roles. When a user is in
+// several mapped groups the most privileged role wins.
+type OIDCPolicy struct {
+	GroupsClaim string
+	GroupRoles  map[string]string
+}
+
+type OIDCService struct {
+	userRepo repository.UserRepositoryInterface
+	verifier TokenVerifier
+	policy   OIDCPolicy
+	sources
+}
+
+func NewOIDCService(userRepo repository.UserRepositoryInterface, verifier TokenVerifier, policy OIDCPolicy) *OIDCService {
+	if policy.GroupsClaim == "" {
+		policy.GroupsClaim = "groups"
+	}
+	return &OIDCService{
+		userRepo: userRepo,
+		verifier: verifier,
+		policy:   policy,
+		sources:  newSources(),
+	}
+}
+
+// Authenticate validates a token issued by the identity provider and returns
+// the matching local user. Users are created on first login and their role
+// follows the provider's group membership. Accounts are matched by email, so
+// the provider must have verified it.
+func (s *OIDCService) Authenticate(rawToken string) (*models.User, error) {
+	claims, err := s.verifier.Verify(rawToken)
+	if err != nil {
+		return nil, internalErrors.ErrInvalidCredentials
+	}
+	email, _ := claims["email"].(string)
+	if email == "" || !emailVerified(claims) {
+		return nil, internalErrors.ErrInvalidCredentials
+	}
+	role := s.roleFor(claims)
+	if role == "" {
+		return nil, internalErrors.ErrAccessDenied
+	}
+
+	user, err := s.userRepo.GetUserByEmail(email)
+	if errors.Is(err, internalErrors.ErrUserNotFound) {
+		return s.createUser(email, role)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch user.Status {
+	case "pending":
+		return nil, internalErrors.ErrAccountPending
+	case "deactivated":
+		return nil, internalErrors.ErrAccountDeactivated
+	}
+	if user.Role != role {
+		if err = s.userRepo.UpdateUserRole(user.ID, role); err != nil {
+			return nil, err
+		}
+		user.Role = role
+		user.TokenVersion++
+	}
+	return user, nil
+}
+
+func (s *OIDCService) createUser(email, role string) (*models.User, error) {
+	// The account can only be used through the identity provider, so the
+	// password is random and never shown to anyone.
+	password, err := utils.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{
+		ID:       s.newID(),
+		Email:    email,
+		Role:     role,
+		Status:   "active",
+		Password: utils.HashPassword(password),
+	}
+	if err = s.userRepo.CreateUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// emailVerified reads the email_verified claim, which some providers send
+// as a string.
+func emailVerified(claims jwt.MapClaims) bool {
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		return verified
+	case string:
+		return verified == "true"
+	default:
+		return false
+	}
+}
+
+func (s *OIDCService) roleFor(claims jwt.MapClaims) string {
+	var groups []string
+	switch value := claims[s.policy.GroupsClaim].(type) {
+	case string:
+		groups = strings.Fields(value)
+	case []interface{}:
+		for _, group := range value {
+			if name, ok := group.(string); ok {
+				groups = append(groups, name)
+			}
+		}
+	}
+
+	best := ""
+	for _, group := range groups {
+		if role, ok := s.policy.GroupRoles[group]; ok && rolePriority(role) > rolePriority(best) {
+			best = role
+		}
+	}
+	return best
+}
+
+func rolePriority(role string) int {
+	switch role {
+	case "admin":
+		return 3
+	case "moderator":
+		return 2
+	case "employee":
+		return 1
+	default:
+		return 0
+	}
+}
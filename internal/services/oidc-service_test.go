@@ -0,0 +1,99 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"avito-intern/internal/oidc"
+	"avito-intern/internal/oidc/oidctest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOIDCFixture(t *testing.T) (*OIDCService, *oidctest.Issuer, *mockUserRepository) {
+	issuer, err := oidctest.NewIssuer()
+	require.NoError(t, err)
+	t.Cleanup(issuer.Close)
+
+	provider, err := oidc.NewProvider(oidc.Config{Issuer: issuer.URL, Audience: "pvz"})
+	require.NoError(t, err)
+	userRepo := &mockUserRepository{users: make(map[string]*models.User)}
+	service := NewOIDCService(userRepo, provider, OIDCPolicy{
+		GroupRoles: map[string]string{
+			"pvz-staff":      "employee",
+			"pvz-moderators": "moderator",
+		},
+	})
+	return service, issuer, userRepo
+}
+
+func issueToken(t *testing.T, issuer *oidctest.Issuer, email string, groups ...string) string {
+	token, err := issuer.Token(jwt.MapClaims{"aud": "pvz", "email": email, "email_verified": true, "groups": groups})
+	require.NoError(t, err)
+	return token
+}
+
+func TestOIDCService_JustInTimeProvisioning(t *testing.T) {
+	service, issuer, userRepo := newOIDCFixture(t)
+
+	user, err := service.Authenticate(issueToken(t, issuer, "sso@example.com", "pvz-staff", "pvz-moderators"))
+	require.NoError(t, err)
+	assert.Equal(t, "moderator", user.Role)
+	assert.Equal(t, "active", user.Status)
+	require.Contains(t, userRepo.users, "sso@example.com")
+
+	again, err := service.Authenticate(issueToken(t, issuer, "sso@example.com", "pvz-staff", "pvz-moderators"))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, again.ID)
+	assert.Len(t, userRepo.users, 1)
+}
+
+func TestOIDCService_RoleFollowsGroups(t *testing.T) {
+	service, issuer, userRepo := newOIDCFixture(t)
+
+	_, err := service.Authenticate(issueToken(t, issuer, "sso@example.com", "pvz-moderators"))
+	require.NoError(t, err)
+
+	user, err := service.Authenticate(issueToken(t, issuer, "sso@example.com", "pvz-staff"))
+	require.NoError(t, err)
+	assert.Equal(t, "employee", user.Role)
+	assert.Equal(t, "employee", userRepo.users["sso@example.com"].Role)
+	assert.Equal(t, userRepo.users["sso@example.com"].TokenVersion, user.TokenVersion)
+}
+
+func TestOIDCService_Rejects(t *testing.T) {
+	service, issuer, userRepo := newOIDCFixture(t)
+	userRepo.users["gone@example.com"] = &models.User{
+		ID: "gone-id", Email: "gone@example.com", Role: "employee", Status: "deactivated",
+	}
+
+	_, err := service.Authenticate("garbage")
+	assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+
+	_, err = service.Authenticate(issueToken(t, issuer, "", "pvz-staff"))
+	assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+
+	userRepo.users["local@example.com"] = &models.User{
+		ID: "local-id", Email: "local@example.com", Role: "employee", Status: "active",
+	}
+	for _, verified := range []interface{}{nil, false, "false"} {
+		claims := jwt.MapClaims{"aud": "pvz", "email": "local@example.com", "groups": []string{"pvz-moderators"}}
+		if verified != nil {
+			claims["email_verified"] = verified
+		}
+		token, err := issuer.Token(claims)
+		require.NoError(t, err)
+		_, err = service.Authenticate(token)
+		assert.Equal(t, internalErrors.ErrInvalidCredentials, err, "unverified emails must not take over accounts")
+	}
+	assert.Equal(t, "employee", userRepo.users["local@example.com"].Role)
+
+	_, err = service.Authenticate(issueToken(t, issuer, "nogroup@example.com", "marketing"))
+	assert.Equal(t, internalErrors.ErrAccessDenied, err)
+	assert.NotContains(t, userRepo.users, "nogroup@example.com")
+
+	_, err = service.Authenticate(issueToken(t, issuer, "gone@example.com", "pvz-staff"))
+	assert.Equal(t, internalErrors.ErrAccountDeactivated, err)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/utils"
+)
+
+const erasedEmailDomain = "@erased.invalid"
+
+// PrivacyService handles data access and erasure requests of users. Both
+// operations are recorded in the audit log with the administrator as actor.
+type PrivacyService struct {
+	userRepo  repository.UserRepositoryInterface
+	dataRepo  repository.PersonalDataRepositoryInterface
+	auditRepo repository.AuditRepositoryInterface
+	sources
+}
+
+func NewPrivacyService(
+	userRepo repository.UserRepositoryInterface,
+	dataRepo repository.PersonalDataRepositoryInterface,
+	auditRepo repository.AuditRepositoryInterface,
+) *PrivacyService {
+	return &PrivacyService{
+		userRepo:  userRepo,
+		dataRepo:  dataRepo,
+		auditRepo: auditRepo,
+		sources:   newSources(),
+	}
+}
+
+func (s *PrivacyService) ExportUser(actor models.User, userID string) (*models.UserDataExport, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	export, err := s.dataRepo.ExportUserData(user, accountKey(user.Email))
+	if err != nil {
+		return nil, err
+	}
+	export.ExportedAt = s.now()
+	if err = s.record(actor.ID, user.ID, models.AuditUserExported); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// EraseUser anonymizes the account: the email becomes a pseudonym derived
+// from the user ID, the password is replaced with an unknown one and all
+// issued tokens are revoked. The ID itself is kept for existing references.
+func (s *PrivacyService) EraseUser(actor models.User, userID string) (*models.User, error) {
+	if actor.ID == userID {
+		return nil, internalErrors.ErrCannotModifySelf
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == "erased" {
+		return nil, internalErrors.ErrInvalidUserStatus
+	}
+	password, err := utils.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	erasure := models.UserErasure{
+		UserID:         user.ID,
+		Email:          user.Email,
+		AttemptKey:     accountKey(user.Email),
+		PseudonymEmail: "erased-" + user.ID + erasedEmailDomain,
+		PasswordHash:   utils.HashPassword(password),
+		ErasedAt:       s.now(),
+	}
+	erasure.Audit = s.auditEvent(actor.ID, user.ID, models.AuditUserErased)
+	if err = s.dataRepo.EraseUser(erasure); err != nil {
+		return nil, err
+	}
+	return &models.User{
+		ID:     user.ID,
+		Email:  erasure.PseudonymEmail,
+		Role:   user.Role,
+		Status: "erased",
+	}, nil
+}
+
+func (s *PrivacyService) record(actorID, userID, action string) error {
+	return s.auditRepo.CreateAuditEvent(s.auditEvent(actorID, userID, action))
+}
+
+func (s *PrivacyService) auditEvent(actorID, userID, action string) *models.AuditEvent {
+	return &models.AuditEvent{
+		ID:        s.newID(),
+		ActorID:   actorID,
+		UserID:    userID,
+		Action:    action,
+		CreatedAt: s.now(),
+	}
+}
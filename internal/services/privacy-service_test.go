@@ -0,0 +1,92 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPersonalDataRepository struct {
+	erasures   []models.UserErasure
+	attemptKey string
+}
+
+func (m *mockPersonalDataRepository) ExportUserData(user *models.User, attemptKey string) (*models.UserDataExport, error) {
+	m.attemptKey = attemptKey
+	return &models.UserDataExport{Account: user, PVZIDs: []string{}}, nil
+}
+
+func (m *mockPersonalDataRepository) EraseUser(erasure models.UserErasure) error {
+	m.erasures = append(m.erasures, erasure)
+	return nil
+}
+
+func newPrivacyFixture() (*PrivacyService, *mockPersonalDataRepository, *mockAuditRepository) {
+	userRepo := &mockUserRepository{users: map[string]*models.User{
+		"Employee@example.com": {ID: "employee-id", Email: "Employee@example.com", Role: "employee", Status: "active", Password: "hash"},
+		"erased@example.com":   {ID: "erased-id", Email: "erased@example.com", Role: "employee", Status: "erased"},
+	}}
+	dataRepo := &mockPersonalDataRepository{}
+	auditRepo := &mockAuditRepository{}
+	return NewPrivacyService(userRepo, dataRepo, auditRepo), dataRepo, auditRepo
+}
+
+func TestPrivacyService_ExportUser(t *testing.T) {
+	service, dataRepo, auditRepo := newPrivacyFixture()
+	admin := models.User{ID: "admin-id", Role: "admin"}
+
+	export, err := service.ExportUser(admin, "employee-id")
+	require.NoError(t, err)
+	assert.Equal(t, "employee-id", export.Account.ID)
+	assert.False(t, export.ExportedAt.IsZero())
+	assert.Equal(t, "account:employee@example.com", dataRepo.attemptKey)
+
+	require.Len(t, auditRepo.events, 1)
+	assert.Equal(t, models.AuditUserExported, auditRepo.events[0].Action)
+	assert.Equal(t, "admin-id", auditRepo.events[0].ActorID)
+
+	_, err = service.ExportUser(admin, "missing-id")
+	assert.ErrorIs(t, err, internalErrors.ErrUserNotFound)
+}
+
+func TestPrivacyService_EraseUser(t *testing.T) {
+	service, dataRepo, auditRepo := newPrivacyFixture()
+	admin := models.User{ID: "admin-id", Role: "admin"}
+
+	user, err := service.EraseUser(admin, "employee-id")
+	require.NoError(t, err)
+	assert.Equal(t, "employee-id", user.ID)
+	assert.Equal(t, "erased-employee-id@erased.invalid", user.Email)
+	assert.Equal(t, "erased", user.Status)
+
+	require.Len(t, dataRepo.erasures, 1)
+	erasure := dataRepo.erasures[0]
+	assert.Equal(t, "Employee@example.com", erasure.Email)
+	assert.Equal(t, "account:employee@example.com", erasure.AttemptKey)
+	assert.NotEqual(t, "hash", erasure.PasswordHash)
+	assert.NotEmpty(t, erasure.PasswordHash)
+
+	require.NotNil(t, erasure.Audit, "the audit record is written with the erasure")
+	assert.Equal(t, models.AuditUserErased, erasure.Audit.Action)
+	assert.Equal(t, "admin-id", erasure.Audit.ActorID)
+	assert.Equal(t, "employee-id", erasure.Audit.UserID)
+	assert.Empty(t, auditRepo.events)
+}
+
+func TestPrivacyService_EraseUser_Rejected(t *testing.T) {
+	service, dataRepo, _ := newPrivacyFixture()
+
+	_, err := service.EraseUser(models.User{ID: "employee-id", Role: "admin"}, "employee-id")
+	assert.ErrorIs(t, err, internalErrors.ErrCannotModifySelf)
+
+	_, err = service.EraseUser(models.User{ID: "admin-id", Role: "admin"}, "erased-id")
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidUserStatus)
+
+	_, err = service.EraseUser(models.User{ID: "admin-id", Role: "admin"}, "missing-id")
+	assert.ErrorIs(t, err, internalErrors.ErrUserNotFound)
+
+	assert.Empty(t, dataRepo.erasures)
+}
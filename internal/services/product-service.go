@@ -5,24 +5,34 @@ import (
 	"avito-intern/internal/api/dto/request/productDto"
 	"avito-intern/internal/models"
 	"avito-intern/internal/repository"
-	"time"
-
-	"github.com/google/uuid"
+	"fmt"
 )
 
 type ProductService struct {
 	productRepo   repository.ProductRepositoryInterface
 	receptionRepo repository.ReceptionRepositoryInterface
+	validateType  func(productType string) error
+	sources
 }
 
 func NewProductService(productRepo repository.ProductRepositoryInterface, receptionRepo repository.ReceptionRepositoryInterface) *ProductService {
 	return &ProductService{
 		productRepo:   productRepo,
 		receptionRepo: receptionRepo,
+		sources:       newSources(),
 	}
 }
 
-func (s *ProductService) AddProduct(req *productDto.CreateProductRequest) (*models.Product, error) {
+// SetTypeValidator adds a check of the type of added products. It runs after
+// the built-in list of types, and its errors are reported as
+// ErrInvalidProductType.
+func (s *ProductService) SetTypeValidator(validate func(productType string) error) {
+	s.validateType = validate
+}
+
+// AddProduct adds a product to the active reception of the pickup point.
+// receptionsVersion is checked as in ReceptionService.CreateReception.
+func (s *ProductService) AddProduct(req *productDto.CreateProductRequest, receptionsVersion int) (*models.Product, error) {
 	reception, err := s.receptionRepo.GetActiveReception(req.PvzID)
 	if err != nil {
 		return nil, err
@@ -31,21 +41,32 @@ func (s *ProductService) AddProduct(req *productDto.CreateProductRequest) (*mode
 	if err = checkProductType(req.Type); err != nil {
 		return nil, err
 	}
+	if s.validateType != nil {
+		if err = s.validateType(req.Type); err != nil {
+			return nil, fmt.Errorf("%w: %w", internalErrors.ErrInvalidProductType, err)
+		}
+	}
 
 	product := &models.Product{
-		ID:          uuid.New().String(),
-		DateTime:    time.Now(),
+		ID:          req.ID,
+		DateTime:    s.now(),
 		Type:        req.Type,
 		ReceptionID: reception.ID,
 	}
-	err = s.productRepo.AddProduct(product)
+	if product.ID == "" {
+		product.ID = s.newID()
+	}
+	err = s.productRepo.AddProduct(product, receptionsVersion)
 	if err != nil {
 		return nil, err
 	}
 	return product, nil
 }
 
-func (s *ProductService) DeleteLastProduct(pvzId string) error {
+// DeleteLastProduct removes the last product of the active reception of the
+// pickup point. receptionsVersion is checked as in
+// ReceptionService.CreateReception.
+func (s *ProductService) DeleteLastProduct(pvzId string, receptionsVersion int) error {
 	reception, err := s.receptionRepo.GetActiveReception(pvzId)
 	if err != nil {
 		return err
@@ -54,7 +75,41 @@ func (s *ProductService) DeleteLastProduct(pvzId string) error {
 	if err != nil {
 		return err
 	}
-	return s.productRepo.DeleteProduct(product.ID)
+	return s.productRepo.DeleteProduct(product.ID, receptionsVersion)
+}
+
+// ListProducts returns the products of the given receptions.
+func (s *ProductService) ListProducts(receptionIDs []string) ([]*models.Product, error) {
+	return s.productRepo.ListProducts(receptionIDs)
+}
+
+// ListPVZReceptions returns the receptions of the pickup point, oldest first,
+// each with its products.
+func (s *ProductService) ListPVZReceptions(pvzID string) ([]*models.ReceptionWithProducts, error) {
+	receptions, err := s.receptionRepo.ListReceptions([]string{pvzID})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*models.ReceptionWithProducts, 0, len(receptions))
+	byID := make(map[string]*models.ReceptionWithProducts, len(receptions))
+	ids := make([]string, 0, len(receptions))
+	for _, reception := range receptions {
+		item := &models.ReceptionWithProducts{Reception: reception, Products: make([]*models.Product, 0)}
+		result = append(result, item)
+		byID[reception.ID] = item
+		ids = append(ids, reception.ID)
+	}
+
+	products, err := s.productRepo.ListProducts(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		if item, ok := byID[product.ReceptionID]; ok {
+			item.Products = append(item.Products, product)
+		}
+	}
+	return result, nil
 }
 
 func checkProductType(productType string) error {
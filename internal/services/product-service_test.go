@@ -5,6 +5,8 @@ import (
 	"avito-intern/internal/api/dto/request/productDto"
 	"avito-intern/internal/models"
 	"errors"
+	"slices"
+	"sort"
 	"testing"
 	"time"
 
@@ -18,7 +20,7 @@ type mockProductRepository struct {
 	deleteErr error
 }
 
-func (m *mockProductRepository) AddProduct(product *models.Product) error {
+func (m *mockProductRepository) AddProduct(product *models.Product, receptionsVersion int) error {
 	if m.addErr != nil {
 		return m.addErr
 	}
@@ -38,7 +40,7 @@ func (m *mockProductRepository) GetLastProduct(receptionID string) (*models.Prod
 	return nil, internalErrors.ErrProductNotFound
 }
 
-func (m *mockProductRepository) DeleteProduct(id string) error {
+func (m *mockProductRepository) DeleteProduct(id string, receptionsVersion int) error {
 	if m.deleteErr != nil {
 		return m.deleteErr
 	}
@@ -48,6 +50,19 @@ func (m *mockProductRepository) DeleteProduct(id string) error {
 	delete(m.products, id)
 	return nil
 }
+func (m *mockProductRepository) ListProducts(receptionIDs []string) ([]*models.Product, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	products := make([]*models.Product, 0)
+	for _, product := range m.products {
+		if slices.Contains(receptionIDs, product.ReceptionID) {
+			products = append(products, product)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products, nil
+}
 
 type mockReceptionRepository struct {
 	receptions map[string]*models.Reception
@@ -56,7 +71,7 @@ type mockReceptionRepository struct {
 	closeErr   error
 }
 
-func (m *mockReceptionRepository) CreateReception(reception *models.Reception) error {
+func (m *mockReceptionRepository) CreateReception(reception *models.Reception, receptionsVersion int) error {
 	if m.createErr != nil {
 		return m.createErr
 	}
@@ -79,7 +94,7 @@ func (m *mockReceptionRepository) GetActiveReception(pvzID string) (*models.Rece
 	return nil, internalErrors.ErrNoActiveReception
 }
 
-func (m *mockReceptionRepository) CloseReception(receptionID string) error {
+func (m *mockReceptionRepository) CloseReception(receptionID string, expectedVersion, receptionsVersion int) error {
 	if m.closeErr != nil {
 		return m.closeErr
 	}
@@ -87,9 +102,25 @@ func (m *mockReceptionRepository) CloseReception(receptionID string) error {
 	if !exists {
 		return internalErrors.ErrNoActiveReception
 	}
+	if reception.Version != expectedVersion {
+		return &internalErrors.ConflictError{Err: internalErrors.ErrVersionConflict, Current: reception}
+	}
 	reception.Status = "close"
 	return nil
 }
+func (m *mockReceptionRepository) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	receptions := make([]*models.Reception, 0)
+	for _, reception := range m.receptions {
+		if slices.Contains(pvzIDs, reception.PvzID) {
+			receptions = append(receptions, reception)
+		}
+	}
+	sort.Slice(receptions, func(i, j int) bool { return receptions[i].ID < receptions[j].ID })
+	return receptions, nil
+}
 
 func TestProductService_AddProduct_ValidProduct(t *testing.T) {
 	mockProductRepo := &mockProductRepository{
@@ -110,7 +141,7 @@ func TestProductService_AddProduct_ValidProduct(t *testing.T) {
 		Type:  "электроника",
 	}
 
-	product, err := service.AddProduct(req)
+	product, err := service.AddProduct(req, 0)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, product)
@@ -137,13 +168,43 @@ func TestProductService_AddProduct_InvalidProductType(t *testing.T) {
 		Type:  "invalid-type",
 	}
 
-	product, err := service.AddProduct(req)
+	product, err := service.AddProduct(req, 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, internalErrors.ErrInvalidProductType, err)
 	assert.Nil(t, product)
 }
 
+func TestProductService_AddProduct_TypeValidator(t *testing.T) {
+	mockProductRepo := &mockProductRepository{
+		products: make(map[string]*models.Product),
+	}
+	mockReceptionRepo := &mockReceptionRepository{
+		receptions: map[string]*models.Reception{
+			"test-reception": {
+				ID:    "test-reception",
+				PvzID: "test-pvz",
+			},
+		},
+	}
+
+	service := NewProductService(mockProductRepo, mockReceptionRepo)
+	service.SetTypeValidator(func(productType string) error {
+		if productType == "обувь" {
+			return errors.New("not accepted")
+		}
+		return nil
+	})
+
+	product, err := service.AddProduct(&productDto.CreateProductRequest{PvzID: "test-pvz", Type: "обувь"}, 0)
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidProductType)
+	assert.Nil(t, product)
+
+	product, err = service.AddProduct(&productDto.CreateProductRequest{PvzID: "test-pvz", Type: "одежда"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "одежда", product.Type)
+}
+
 func TestProductService_AddProduct_NonExistentPVZ(t *testing.T) {
 	mockProductRepo := &mockProductRepository{
 		products: make(map[string]*models.Product),
@@ -163,7 +224,7 @@ func TestProductService_AddProduct_NonExistentPVZ(t *testing.T) {
 		Type:  "электроника",
 	}
 
-	product, err := service.AddProduct(req)
+	product, err := service.AddProduct(req, 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, internalErrors.ErrNoActiveReception, err)
@@ -191,7 +252,7 @@ func TestProductService_AddProduct_RepositoryError(t *testing.T) {
 		Type:  "электроника",
 	}
 
-	product, err := service.AddProduct(req)
+	product, err := service.AddProduct(req, 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, "database error", err.Error())
@@ -221,7 +282,7 @@ func TestProductService_DeleteLastProduct_Success(t *testing.T) {
 
 	service := NewProductService(mockProductRepo, mockReceptionRepo)
 
-	err := service.DeleteLastProduct("test-pvz")
+	err := service.DeleteLastProduct("test-pvz", 0)
 
 	assert.NoError(t, err)
 	assert.Empty(t, mockProductRepo.products)
@@ -250,7 +311,7 @@ func TestProductService_DeleteLastProduct_NonExistentPVZ(t *testing.T) {
 
 	service := NewProductService(mockProductRepo, mockReceptionRepo)
 
-	err := service.DeleteLastProduct("non-existent-pvz")
+	err := service.DeleteLastProduct("non-existent-pvz", 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, internalErrors.ErrNoActiveReception, err)
@@ -273,7 +334,7 @@ func TestProductService_DeleteLastProduct_NoProductsInReception(t *testing.T) {
 
 	service := NewProductService(mockProductRepo, mockReceptionRepo)
 
-	err := service.DeleteLastProduct("test-pvz")
+	err := service.DeleteLastProduct("test-pvz", 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, internalErrors.ErrProductNotFound, err)
@@ -303,9 +364,55 @@ func TestProductService_DeleteLastProduct_DeleteError(t *testing.T) {
 
 	service := NewProductService(mockProductRepo, mockReceptionRepo)
 
-	err := service.DeleteLastProduct("test-pvz")
+	err := service.DeleteLastProduct("test-pvz", 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, "delete error", err.Error())
 	assert.Len(t, mockProductRepo.products, 1)
 }
+
+func TestProductService_ListProducts(t *testing.T) {
+	mockProductRepo := &mockProductRepository{
+		products: map[string]*models.Product{
+			"product-1": {ID: "product-1", Type: "обувь", ReceptionID: "reception-1"},
+			"product-2": {ID: "product-2", Type: "одежда", ReceptionID: "reception-2"},
+			"product-3": {ID: "product-3", Type: "электроника", ReceptionID: "reception-3"},
+		},
+	}
+	service := NewProductService(mockProductRepo, &mockReceptionRepository{})
+
+	products, err := service.ListProducts([]string{"reception-1", "reception-2"})
+
+	assert.NoError(t, err)
+	assert.Len(t, products, 2)
+	assert.Equal(t, "product-1", products[0].ID)
+	assert.Equal(t, "product-2", products[1].ID)
+}
+
+func TestProductService_ListPVZReceptions(t *testing.T) {
+	mockProductRepo := &mockProductRepository{
+		products: map[string]*models.Product{
+			"product-1": {ID: "product-1", Type: "обувь", ReceptionID: "reception-1"},
+			"product-2": {ID: "product-2", Type: "одежда", ReceptionID: "reception-1"},
+			"product-3": {ID: "product-3", Type: "электроника", ReceptionID: "reception-3"},
+		},
+	}
+	mockReceptionRepo := &mockReceptionRepository{
+		receptions: map[string]*models.Reception{
+			"reception-1": {ID: "reception-1", PvzID: "pvz-1", Status: "close"},
+			"reception-2": {ID: "reception-2", PvzID: "pvz-1", Status: "in_progress"},
+			"reception-3": {ID: "reception-3", PvzID: "pvz-2", Status: "in_progress"},
+		},
+	}
+	service := NewProductService(mockProductRepo, mockReceptionRepo)
+
+	receptions, err := service.ListPVZReceptions("pvz-1")
+
+	assert.NoError(t, err)
+	assert.Len(t, receptions, 2)
+	assert.Equal(t, "reception-1", receptions[0].Reception.ID)
+	assert.Len(t, receptions[0].Products, 2)
+	assert.Equal(t, "reception-2", receptions[1].Reception.ID)
+	assert.Empty(t, receptions[1].Products)
+	assert.NotNil(t, receptions[1].Products, "receptions without products are encoded with an empty list")
+}
@@ -4,33 +4,71 @@ import (
 	"avito-intern/internal/api/dto/internalErrors"
 	"avito-intern/internal/models"
 	"avito-intern/internal/repository"
+	"fmt"
 	"strconv"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 type PVZService struct {
-	pvzRepo repository.PVZRepositoryInterface
+	pvzRepo        repository.PVZRepositoryInterface
+	assignmentRepo repository.PVZAssignmentRepositoryInterface
+	validateCity   func(city string) error
+	sources
 }
 
 func NewPVZService(pvzRepo repository.PVZRepositoryInterface) *PVZService {
 	return &PVZService{
 		pvzRepo: pvzRepo,
+		sources: newSources(),
 	}
 }
 
+// SetCityValidator adds a check of the city of created and updated pickup
+// points. It runs after the built-in list of cities, and its errors are
+// reported as ErrInvalidCity.
+func (s *PVZService) SetCityValidator(validate func(city string) error) {
+	s.validateCity = validate
+}
+
+// SetAssignments limits employees to the pickup points they are assigned to,
+// as provisioned through SCIM groups, in CheckAssigned. Without assignments
+// every employee may reach every pickup point.
+func (s *PVZService) SetAssignments(assignmentRepo repository.PVZAssignmentRepositoryInterface) {
+	s.assignmentRepo = assignmentRepo
+}
+
+// CheckAssigned returns ErrAccessDenied when user is an employee who is not
+// assigned to the pickup point. Other roles are not limited.
+func (s *PVZService) CheckAssigned(user models.User, pvzID string) error {
+	if s.assignmentRepo == nil || user.Role != "employee" {
+		return nil
+	}
+	pvzIDs, err := s.assignmentRepo.ListUserPVZs(user.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range pvzIDs {
+		if id == pvzID {
+			return nil
+		}
+	}
+	return internalErrors.ErrAccessDenied
+}
+
 func (s *PVZService) CreatePVZ(pvz *models.PVZ) error {
-	if err := checkCity(pvz.City); err != nil {
+	if err := s.checkCity(pvz.City); err != nil {
 		return err
 	}
 
 	if pvz.ID == "" {
-		pvz.ID = uuid.New().String()
+		pvz.ID = s.newID()
 	}
 	if pvz.RegistrationDate.IsZero() {
-		pvz.RegistrationDate = time.Now()
+		pvz.RegistrationDate = s.now()
 	}
+	// New rows start at the column default.
+	pvz.Version = 1
+	pvz.ReceptionsVersion = 1
 	return s.pvzRepo.CreatePVZ(pvz)
 }
 
@@ -61,6 +99,41 @@ func (s *PVZService) ListPVZ(limitStr, pageStr, startDateStr, endDateStr string)
 	return s.pvzRepo.ListPVZ(limit, offset, startDate, endDate)
 }
 
+// GetPVZ returns the pickup point with the given ID.
+func (s *PVZService) GetPVZ(id string) (*models.PVZ, error) {
+	return s.pvzRepo.GetPVZByID(id)
+}
+
+// UpdatePVZ changes the city of the pickup point, which must still be at
+// expectedVersion. Otherwise it fails with a ConflictError holding the
+// current pickup point.
+func (s *PVZService) UpdatePVZ(id, city string, expectedVersion int) (*models.PVZ, error) {
+	if err := s.checkCity(city); err != nil {
+		return nil, err
+	}
+	pvz, err := s.pvzRepo.GetPVZByID(id)
+	if err != nil {
+		return nil, err
+	}
+	pvz.City = city
+	if err := s.pvzRepo.UpdatePVZ(pvz, expectedVersion); err != nil {
+		return nil, err
+	}
+	return pvz, nil
+}
+
+func (s *PVZService) checkCity(city string) error {
+	if err := checkCity(city); err != nil {
+		return err
+	}
+	if s.validateCity != nil {
+		if err := s.validateCity(city); err != nil {
+			return fmt.Errorf("%w: %w", internalErrors.ErrInvalidCity, err)
+		}
+	}
+	return nil
+}
+
 func checkCity(city string) error {
 	allowedCities := map[string]bool{
 		"Москва":          true,
@@ -45,6 +45,34 @@ func (m *mockPVZRepository) ListPVZ(limit, offset int, startDate, endDate *time.
 	return result, nil
 }
 
+func (m *mockPVZRepository) GetPVZByID(id string) (*models.PVZ, error) {
+	if pvz, exists := m.pvzs[id]; exists {
+		copied := *pvz
+		return &copied, nil
+	}
+	return nil, internalErrors.ErrPVZNotFound
+}
+
+func (m *mockPVZRepository) CountPVZ() (int, error) {
+	return len(m.pvzs), nil
+}
+
+func (m *mockPVZRepository) UpdatePVZ(pvz *models.PVZ, expectedVersion int) error {
+	stored, exists := m.pvzs[pvz.ID]
+	if !exists {
+		return internalErrors.ErrPVZNotFound
+	}
+	if stored.Version != expectedVersion {
+		current := *stored
+		return &internalErrors.ConflictError{Err: internalErrors.ErrVersionConflict, Current: &current}
+	}
+	updated := *pvz
+	updated.Version = expectedVersion + 1
+	m.pvzs[pvz.ID] = &updated
+	pvz.Version = updated.Version
+	return nil
+}
+
 func TestPVZService_CreatePVZ_ValidMoscow(t *testing.T) {
 
 	mockRepo := &mockPVZRepository{
@@ -97,6 +125,44 @@ func TestPVZService_CreatePVZ_InvalidCity(t *testing.T) {
 	assert.Equal(t, 0, len(mockRepo.pvzs))
 }
 
+func TestPVZService_CreatePVZ_CityValidator(t *testing.T) {
+	mockRepo := &mockPVZRepository{
+		pvzs: make(map[string]*models.PVZ),
+	}
+	service := NewPVZService(mockRepo)
+	service.SetCityValidator(func(city string) error {
+		if city != "Казань" {
+			return errors.New("not served")
+		}
+		return nil
+	})
+
+	err := service.CreatePVZ(&models.PVZ{City: "Москва"})
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidCity)
+	assert.Contains(t, err.Error(), "not served")
+
+	err = service.CreatePVZ(&models.PVZ{City: "Invalid City"})
+	assert.Equal(t, internalErrors.ErrInvalidCity, err)
+
+	assert.NoError(t, service.CreatePVZ(&models.PVZ{City: "Казань"}))
+	assert.Equal(t, 1, len(mockRepo.pvzs))
+}
+
+func TestPVZService_CreatePVZ_Sources(t *testing.T) {
+	mockRepo := &mockPVZRepository{
+		pvzs: make(map[string]*models.PVZ),
+	}
+	service := NewPVZService(mockRepo)
+	clock := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	service.SetClock(func() time.Time { return clock })
+	service.SetIDGenerator(func() string { return "pvz-1" })
+	pvz := &models.PVZ{City: "Москва"}
+
+	assert.NoError(t, service.CreatePVZ(pvz))
+	assert.Equal(t, "pvz-1", pvz.ID)
+	assert.Equal(t, clock, pvz.RegistrationDate)
+}
+
 func TestPVZService_CreatePVZ_RepositoryError(t *testing.T) {
 	mockRepo := &mockPVZRepository{
 		pvzs:      make(map[string]*models.PVZ),
@@ -225,3 +291,42 @@ func TestPVZService_ListPVZ_RepositoryError(t *testing.T) {
 	assert.Equal(t, "database error", err.Error())
 	assert.Nil(t, pvzs)
 }
+
+func TestPVZService_GetPVZ(t *testing.T) {
+	mockRepo := &mockPVZRepository{
+		pvzs: map[string]*models.PVZ{"pvz-1": {ID: "pvz-1", City: "Казань"}},
+	}
+	service := NewPVZService(mockRepo)
+
+	pvz, err := service.GetPVZ("pvz-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Казань", pvz.City)
+
+	_, err = service.GetPVZ("pvz-2")
+	assert.ErrorIs(t, err, internalErrors.ErrPVZNotFound)
+}
+
+func TestPVZService_UpdatePVZ(t *testing.T) {
+	mockRepo := &mockPVZRepository{
+		pvzs: map[string]*models.PVZ{"pvz-1": {ID: "pvz-1", City: "Москва", Version: 1}},
+	}
+	service := NewPVZService(mockRepo)
+
+	pvz, err := service.UpdatePVZ("pvz-1", "Казань", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Казань", pvz.City)
+	assert.Equal(t, 2, pvz.Version)
+
+	_, err = service.UpdatePVZ("pvz-1", "Москва", 1)
+	assert.ErrorIs(t, err, internalErrors.ErrVersionConflict)
+	var conflictErr *internalErrors.ConflictError
+	if assert.ErrorAs(t, err, &conflictErr) {
+		assert.Equal(t, "Казань", conflictErr.Current.(*models.PVZ).City)
+	}
+
+	_, err = service.UpdatePVZ("pvz-1", "Новосибирск", 2)
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidCity)
+
+	_, err = service.UpdatePVZ("pvz-2", "Казань", 1)
+	assert.ErrorIs(t, err, internalErrors.ErrPVZNotFound)
+}
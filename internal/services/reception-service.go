@@ -4,48 +4,59 @@ import (
 	"avito-intern/internal/api/dto/internalErrors"
 	"avito-intern/internal/models"
 	"avito-intern/internal/repository"
-	"time"
-
-	"github.com/google/uuid"
 )
 
 type ReceptionService struct {
 	receptionRepo repository.ReceptionRepositoryInterface
+	sources
 }
 
 func NewReceptionService(receptionRepo repository.ReceptionRepositoryInterface) *ReceptionService {
 	return &ReceptionService{
 		receptionRepo: receptionRepo,
+		sources:       newSources(),
 	}
 }
 
-func (s *ReceptionService) CreateReception(reception *models.Reception) error {
+// CreateReception opens reception. A positive receptionsVersion is the
+// receptions version of the pickup point the change is based on; if the
+// receptions have changed since, it fails with ErrVersionConflict. The same
+// goes for the other changes of receptions and products.
+func (s *ReceptionService) CreateReception(reception *models.Reception, receptionsVersion int) error {
 	activeReception, _ := s.getActiveReception(reception.PvzID)
 	if activeReception != nil {
 		return internalErrors.ErrActiveReceptionExists
 	}
 	if reception.ID == "" {
-		reception.ID = uuid.New().String()
+		reception.ID = s.newID()
 	}
 	if reception.DateTime.IsZero() {
-		reception.DateTime = time.Now()
+		reception.DateTime = s.now()
 	}
-	return s.receptionRepo.CreateReception(reception)
+	// New rows start at the column default.
+	reception.Version = 1
+	return s.receptionRepo.CreateReception(reception, receptionsVersion)
 }
 
 func (s *ReceptionService) getActiveReception(pvzId string) (*models.Reception, error) {
 	return s.receptionRepo.GetActiveReception(pvzId)
 }
 
-func (s *ReceptionService) CloseLastReception(pvzID string) (*models.Reception, error) {
+func (s *ReceptionService) CloseLastReception(pvzID string, receptionsVersion int) (*models.Reception, error) {
 	reception, err := s.getActiveReception(pvzID)
 	if err != nil {
 		return nil, internalErrors.ErrNoActiveReception
 	}
-	err = s.receptionRepo.CloseReception(reception.ID)
+	err = s.receptionRepo.CloseReception(reception.ID, reception.Version, receptionsVersion)
 	if err != nil {
 		return nil, err
 	}
 	reception.Status = "close"
+	reception.Version++
 	return reception, nil
 }
+
+// ListReceptions returns the receptions of the given pickup points.
+func (s *ReceptionService) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	return s.receptionRepo.ListReceptions(pvzIDs)
+}
@@ -4,6 +4,8 @@ import (
 	"avito-intern/internal/api/dto/internalErrors"
 	"avito-intern/internal/models"
 	"errors"
+	"slices"
+	"sort"
 	"testing"
 	"time"
 
@@ -17,7 +19,7 @@ type mockReceptionServiceRepository struct {
 	closeErr   error
 }
 
-func (m *mockReceptionServiceRepository) CreateReception(reception *models.Reception) error {
+func (m *mockReceptionServiceRepository) CreateReception(reception *models.Reception, receptionsVersion int) error {
 	if m.createErr != nil {
 		return m.createErr
 	}
@@ -40,7 +42,7 @@ func (m *mockReceptionServiceRepository) GetActiveReception(pvzID string) (*mode
 	return nil, internalErrors.ErrNoActiveReception
 }
 
-func (m *mockReceptionServiceRepository) CloseReception(receptionID string) error {
+func (m *mockReceptionServiceRepository) CloseReception(receptionID string, expectedVersion, receptionsVersion int) error {
 	if m.closeErr != nil {
 		return m.closeErr
 	}
@@ -48,9 +50,25 @@ func (m *mockReceptionServiceRepository) CloseReception(receptionID string) erro
 	if !exists {
 		return internalErrors.ErrNoActiveReception
 	}
+	if reception.Version != expectedVersion {
+		return &internalErrors.ConflictError{Err: internalErrors.ErrVersionConflict, Current: reception}
+	}
 	reception.Status = "close"
 	return nil
 }
+func (m *mockReceptionServiceRepository) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	receptions := make([]*models.Reception, 0)
+	for _, reception := range m.receptions {
+		if slices.Contains(pvzIDs, reception.PvzID) {
+			receptions = append(receptions, reception)
+		}
+	}
+	sort.Slice(receptions, func(i, j int) bool { return receptions[i].ID < receptions[j].ID })
+	return receptions, nil
+}
 
 func TestReceptionService_CreateReception_Success(t *testing.T) {
 	mockRepo := &mockReceptionServiceRepository{
@@ -63,7 +81,7 @@ func TestReceptionService_CreateReception_Success(t *testing.T) {
 		Status:   "in_progress",
 	}
 
-	err := service.CreateReception(reception)
+	err := service.CreateReception(reception, 0)
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, reception.ID)
@@ -92,7 +110,7 @@ func TestReceptionService_CreateReception_ActiveReceptionExists(t *testing.T) {
 		Status:   "in_progress",
 	}
 
-	err := service.CreateReception(newReception)
+	err := service.CreateReception(newReception, 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, internalErrors.ErrActiveReceptionExists, err)
@@ -110,7 +128,7 @@ func TestReceptionService_CreateReception_RepositoryError(t *testing.T) {
 		Status:   "in_progress",
 	}
 
-	err := service.CreateReception(reception)
+	err := service.CreateReception(reception, 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, "database error", err.Error())
@@ -132,7 +150,7 @@ func TestReceptionService_CloseLastReception_Success(t *testing.T) {
 	}
 	service := NewReceptionService(mockRepo)
 
-	reception, err := service.CloseLastReception("test-pvz")
+	reception, err := service.CloseLastReception("test-pvz", 0)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, reception)
@@ -146,7 +164,7 @@ func TestReceptionService_CloseLastReception_NoActiveReception(t *testing.T) {
 	}
 	service := NewReceptionService(mockRepo)
 
-	reception, err := service.CloseLastReception("test-pvz")
+	reception, err := service.CloseLastReception("test-pvz", 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, internalErrors.ErrNoActiveReception, err)
@@ -160,7 +178,7 @@ func TestReceptionService_CloseLastReception_GetActiveReceptionError(t *testing.
 	}
 	service := NewReceptionService(mockRepo)
 
-	reception, err := service.CloseLastReception("test-pvz")
+	reception, err := service.CloseLastReception("test-pvz", 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, internalErrors.ErrNoActiveReception, err)
@@ -184,9 +202,54 @@ func TestReceptionService_CloseLastReception_CloseReceptionError(t *testing.T) {
 	}
 	service := NewReceptionService(mockRepo)
 
-	reception, err := service.CloseLastReception("test-pvz")
+	reception, err := service.CloseLastReception("test-pvz", 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, "database error", err.Error())
 	assert.Nil(t, reception)
 }
+
+func TestReceptionService_CloseLastReception_IncrementsVersion(t *testing.T) {
+	mockRepo := &mockReceptionServiceRepository{
+		receptions: map[string]*models.Reception{
+			"active-reception": {ID: "active-reception", PvzID: "test-pvz", Status: "in_progress", Version: 3},
+		},
+	}
+	service := NewReceptionService(mockRepo)
+
+	reception, err := service.CloseLastReception("test-pvz", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, reception.Version)
+}
+
+func TestReceptionService_ListReceptions(t *testing.T) {
+	mockRepo := &mockReceptionServiceRepository{
+		receptions: map[string]*models.Reception{
+			"reception-1": {ID: "reception-1", PvzID: "pvz-1", Status: "close"},
+			"reception-2": {ID: "reception-2", PvzID: "pvz-1", Status: "in_progress"},
+			"reception-3": {ID: "reception-3", PvzID: "pvz-2", Status: "in_progress"},
+		},
+	}
+	service := NewReceptionService(mockRepo)
+
+	receptions, err := service.ListReceptions([]string{"pvz-1"})
+
+	assert.NoError(t, err)
+	assert.Len(t, receptions, 2)
+	assert.Equal(t, "reception-1", receptions[0].ID)
+	assert.Equal(t, "reception-2", receptions[1].ID)
+}
+
+func TestReceptionService_ListReceptions_RepositoryError(t *testing.T) {
+	mockRepo := &mockReceptionServiceRepository{
+		receptions: make(map[string]*models.Reception),
+		getErr:     errors.New("database error"),
+	}
+	service := NewReceptionService(mockRepo)
+
+	receptions, err := service.ListReceptions([]string{"pvz-1"})
+
+	assert.Error(t, err)
+	assert.Nil(t, receptions)
+}
@@ -0,0 +1,477 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/utils"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSCIMCount = 100
+	maxSCIMCount     = 200
+	maxGroupMembers  = 1000
+	pvzGroupPrefix   = "pvz-"
+)
+
+// roleGroups are exposed as SCIM groups next to one group per PVZ.
+var roleGroups = []string{"employee", "moderator", "admin"}
+
+var (
+	scimFilterPattern = regexp.MustCompile(`^\s*([A-Za-z.]+)\s+(eq|co|sw)\s+(.+?)\s*$`)
+	memberPathPattern = regexp.MustCompile(`^members\[value eq "([^"]+)"\]$`)
+)
+
+// SCIMService implements SCIM 2.0 provisioning on top of the user
+// repository. Roles and PVZs are exposed as groups: membership in a role
+// group sets the user's role, membership in a PVZ group assigns the user to
+// that PVZ.
+type SCIMService struct {
+	userRepo       repository.UserRepositoryInterface
+	pvzRepo        repository.PVZRepositoryInterface
+	assignmentRepo repository.PVZAssignmentRepositoryInterface
+	sources
+}
+
+func NewSCIMService(
+	userRepo repository.UserRepositoryInterface,
+	pvzRepo repository.PVZRepositoryInterface,
+	assignmentRepo repository.PVZAssignmentRepositoryInterface,
+) *SCIMService {
+	return &SCIMService{
+		userRepo:       userRepo,
+		pvzRepo:        pvzRepo,
+		assignmentRepo: assignmentRepo,
+		sources:        newSources(),
+	}
+}
+
+// ListUsers supports the filters `userName eq|co|sw "..."` and
+// `active eq true|false`. startIndex is 1-based as in SCIM.
+func (s *SCIMService) ListUsers(filter string, startIndex, count int) ([]*models.SCIMUser, int, error) {
+	startIndex, count = normalizeSCIMPage(startIndex, count)
+	email, status := "", ""
+	if filter != "" {
+		attr, op, value, err := parseSCIMFilter(filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		switch {
+		case (attr == "username" || attr == "emails.value") && op == "eq":
+			return s.findUserByEmail(value, startIndex)
+		case (attr == "username" || attr == "emails.value") && (op == "co" || op == "sw"):
+			email = value
+		case attr == "active" && op == "eq":
+			active, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, 0, internalErrors.ErrInvalidFilter
+			}
+			status = "deactivated"
+			if active {
+				status = "active"
+			}
+		default:
+			return nil, 0, internalErrors.ErrInvalidFilter
+		}
+	}
+
+	total, err := s.userRepo.CountUsers(email, "", status)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]*models.SCIMUser, 0)
+	if count == 0 {
+		return result, total, nil
+	}
+	users, err := s.userRepo.ListUsers(email, "", status, count, startIndex-1)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, user := range users {
+		scimUser, err := s.withAssignments(user)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, scimUser)
+	}
+	return result, total, nil
+}
+
+func (s *SCIMService) GetUser(id string) (*models.SCIMUser, error) {
+	user, err := s.userRepo.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withAssignments(user)
+}
+
+func (s *SCIMService) CreateUser(req scimDto.UserRequest) (*models.SCIMUser, error) {
+	email := req.UserName
+	for _, candidate := range req.Emails {
+		if email == "" || candidate.Primary {
+			email = candidate.Value
+		}
+	}
+	if email == "" {
+		return nil, internalErrors.ErrInvalidPatch
+	}
+
+	role := "employee"
+	if len(req.Roles) > 0 {
+		role = req.Roles[0].Value
+	}
+	if !isValidRole(role) {
+		return nil, internalErrors.ErrInvalidRole
+	}
+
+	_, err := s.userRepo.GetUserByEmail(email)
+	if err == nil {
+		return nil, internalErrors.ErrEmailExists
+	}
+	if !errors.Is(err, internalErrors.ErrUserNotFound) {
+		return nil, err
+	}
+
+	password := req.Password
+	if password == "" {
+		// Provisioned users sign in through SSO or set a password with the
+		// reset flow.
+		if password, err = utils.GenerateToken(); err != nil {
+			return nil, err
+		}
+	}
+	status := "active"
+	if req.Active != nil && !*req.Active {
+		status = "deactivated"
+	}
+	user := &models.User{
+		ID:       s.newID(),
+		Email:    email,
+		Role:     role,
+		Status:   status,
+		Password: utils.HashPassword(password),
+	}
+	if err = s.userRepo.CreateUser(user); err != nil {
+		return nil, err
+	}
+	return &models.SCIMUser{User: user, PVZIDs: []string{}}, nil
+}
+
+// PatchUser applies replace/add operations on `active` and `roles`, either
+// with an explicit path or as a value object without one.
+func (s *SCIMService) PatchUser(id string, req scimDto.PatchRequest) (*models.SCIMUser, error) {
+	user, err := s.userRepo.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range req.Operations {
+		kind := strings.ToLower(op.Op)
+		if kind != "replace" && kind != "add" {
+			return nil, internalErrors.ErrInvalidPatch
+		}
+		values := map[string]json.RawMessage{}
+		if op.Path == "" {
+			if err = json.Unmarshal(op.Value, &values); err != nil {
+				return nil, internalErrors.ErrInvalidPatch
+			}
+		} else {
+			values[op.Path] = op.Value
+		}
+		for path, value := range values {
+			if err = s.patchUserAttribute(user, strings.ToLower(path), value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s.withAssignments(user)
+}
+
+// DeactivateUser deprovisions the user. Erased users cannot sign in
+// anyway and stay erased.
+func (s *SCIMService) DeactivateUser(id string) error {
+	user, err := s.userRepo.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	if user.Status == "erased" {
+		return nil
+	}
+	return s.setActive(user, false)
+}
+
+// ListGroups returns the role groups followed by one group per PVZ. The only
+// supported filter is `displayName eq "..."`.
+func (s *SCIMService) ListGroups(filter string, startIndex, count int) ([]*models.SCIMGroup, int, error) {
+	startIndex, count = normalizeSCIMPage(startIndex, count)
+	if filter != "" {
+		attr, op, value, err := parseSCIMFilter(filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		if attr != "displayname" || op != "eq" {
+			return nil, 0, internalErrors.ErrInvalidFilter
+		}
+		group, err := s.GetGroup(strings.TrimPrefix(value, pvzGroupPrefix))
+		if errors.Is(err, internalErrors.ErrGroupNotFound) || (err == nil && group.DisplayName != value) {
+			return []*models.SCIMGroup{}, 0, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if startIndex > 1 || count == 0 {
+			return []*models.SCIMGroup{}, 1, nil
+		}
+		return []*models.SCIMGroup{group}, 1, nil
+	}
+
+	pvzCount, err := s.pvzRepo.CountPVZ()
+	if err != nil {
+		return nil, 0, err
+	}
+	total := len(roleGroups) + pvzCount
+
+	groups := make([]*models.SCIMGroup, 0)
+	offset := startIndex - 1
+	for ; offset < len(roleGroups) && len(groups) < count; offset++ {
+		group, err := s.roleGroup(roleGroups[offset])
+		if err != nil {
+			return nil, 0, err
+		}
+		groups = append(groups, group)
+	}
+	if len(groups) < count {
+		pvzs, err := s.pvzRepo.ListPVZ(count-len(groups), offset-len(roleGroups), nil, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, pvz := range pvzs {
+			group, err := s.pvzGroup(pvz)
+			if err != nil {
+				return nil, 0, err
+			}
+			groups = append(groups, group)
+		}
+	}
+	return groups, total, nil
+}
+
+func (s *SCIMService) GetGroup(id string) (*models.SCIMGroup, error) {
+	if isValidRole(id) {
+		return s.roleGroup(id)
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, internalErrors.ErrGroupNotFound
+	}
+	pvz, err := s.pvzRepo.GetPVZByID(id)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrPVZNotFound) {
+			return nil, internalErrors.ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return s.pvzGroup(pvz)
+}
+
+// PatchGroup supports adding and removing members. Removing a user from a
+// privileged role group demotes them to employee; the employee group cannot
+// be left because every user needs a role.
+func (s *SCIMService) PatchGroup(id string, req scimDto.PatchRequest) (*models.SCIMGroup, error) {
+	if _, err := s.GetGroup(id); err != nil {
+		return nil, err
+	}
+	for _, op := range req.Operations {
+		userIDs, err := patchMembers(op)
+		if err != nil {
+			return nil, err
+		}
+		add := strings.EqualFold(op.Op, "add")
+		if !add && !strings.EqualFold(op.Op, "remove") {
+			return nil, internalErrors.ErrInvalidPatch
+		}
+		for _, userID := range userIDs {
+			if err = s.patchMember(id, userID, add); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s.GetGroup(id)
+}
+
+func (s *SCIMService) patchMember(groupID, userID string, add bool) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !isValidRole(groupID) {
+		if add {
+			return s.assignmentRepo.AssignPVZ(user.ID, groupID)
+		}
+		return s.assignmentRepo.UnassignPVZ(user.ID, groupID)
+	}
+
+	role := user.Role
+	switch {
+	case add:
+		role = groupID
+	case user.Role == groupID && groupID != "employee":
+		role = "employee"
+	}
+	if role == user.Role {
+		return nil
+	}
+	return s.userRepo.UpdateUserRole(user.ID, role)
+}
+
+func (s *SCIMService) patchUserAttribute(user *models.User, path string, value json.RawMessage) error {
+	switch path {
+	case "active":
+		var active bool
+		if err := json.Unmarshal(value, &active); err != nil {
+			return internalErrors.ErrInvalidPatch
+		}
+		return s.setActive(user, active)
+	case "roles":
+		var roles []scimDto.Role
+		if err := json.Unmarshal(value, &roles); err != nil || len(roles) == 0 {
+			return internalErrors.ErrInvalidPatch
+		}
+		if !isValidRole(roles[0].Value) {
+			return internalErrors.ErrInvalidRole
+		}
+		if roles[0].Value == user.Role {
+			return nil
+		}
+		if err := s.userRepo.UpdateUserRole(user.ID, roles[0].Value); err != nil {
+			return err
+		}
+		user.Role = roles[0].Value
+		return nil
+	default:
+		return internalErrors.ErrInvalidPatch
+	}
+}
+
+// setActive activates or deactivates the user. Erasure cannot be undone, so
+// the status of erased users is not changed.
+func (s *SCIMService) setActive(user *models.User, active bool) error {
+	if user.Status == "erased" {
+		return internalErrors.ErrInvalidUserStatus
+	}
+	status := "deactivated"
+	if active {
+		status = "active"
+	}
+	if user.Status == status {
+		return nil
+	}
+	if err := s.userRepo.UpdateUserStatus(user.ID, status); err != nil {
+		return err
+	}
+	user.Status = status
+	return nil
+}
+
+func (s *SCIMService) findUserByEmail(email string, startIndex int) ([]*models.SCIMUser, int, error) {
+	user, err := s.userRepo.GetUserByEmail(email)
+	if errors.Is(err, internalErrors.ErrUserNotFound) {
+		return []*models.SCIMUser{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if startIndex > 1 {
+		return []*models.SCIMUser{}, 1, nil
+	}
+	scimUser, err := s.withAssignments(user)
+	if err != nil {
+		return nil, 0, err
+	}
+	return []*models.SCIMUser{scimUser}, 1, nil
+}
+
+func (s *SCIMService) withAssignments(user *models.User) (*models.SCIMUser, error) {
+	pvzIDs, err := s.assignmentRepo.ListUserPVZs(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SCIMUser{User: user, PVZIDs: pvzIDs}, nil
+}
+
+func (s *SCIMService) roleGroup(role string) (*models.SCIMGroup, error) {
+	users, err := s.userRepo.ListUsers("", role, "", maxGroupMembers, 0)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]string, 0, len(users))
+	for _, user := range users {
+		members = append(members, user.ID)
+	}
+	return &models.SCIMGroup{ID: role, DisplayName: role, Members: members}, nil
+}
+
+func (s *SCIMService) pvzGroup(pvz *models.PVZ) (*models.SCIMGroup, error) {
+	members, err := s.assignmentRepo.ListPVZUsers(pvz.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SCIMGroup{ID: pvz.ID, DisplayName: pvzGroupPrefix + pvz.ID, Members: members}, nil
+}
+
+func normalizeSCIMPage(startIndex, count int) (int, int) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = defaultSCIMCount
+	}
+	if count > maxSCIMCount {
+		count = maxSCIMCount
+	}
+	return startIndex, count
+}
+
+// parseSCIMFilter parses a single `attribute op value` expression. Attribute
+// names are returned lower-cased since SCIM treats them case-insensitively.
+func parseSCIMFilter(filter string) (string, string, string, error) {
+	match := scimFilterPattern.FindStringSubmatch(filter)
+	if match == nil {
+		return "", "", "", internalErrors.ErrInvalidFilter
+	}
+	value := match[3]
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", "", "", internalErrors.ErrInvalidFilter
+		}
+		value = unquoted
+	}
+	return strings.ToLower(match[1]), match[2], value, nil
+}
+
+// patchMembers extracts user IDs from either `members` with a value list or
+// a `members[value eq "id"]` path.
+func patchMembers(op scimDto.PatchOperation) ([]string, error) {
+	if match := memberPathPattern.FindStringSubmatch(op.Path); match != nil {
+		return []string{match[1]}, nil
+	}
+	if !strings.EqualFold(op.Path, "members") {
+		return nil, internalErrors.ErrInvalidPatch
+	}
+	var members []scimDto.Reference
+	if err := json.Unmarshal(op.Value, &members); err != nil {
+		return nil, internalErrors.ErrInvalidPatch
+	}
+	userIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.Value)
+	}
+	return userIDs, nil
+}
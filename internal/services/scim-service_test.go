@@ -0,0 +1,236 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/scimDto"
+	"avito-intern/internal/models"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scimPVZID = "0b7c8d4e-2f3a-4b5c-9d6e-7f8a9b0c1d2e"
+
+type mockPVZAssignmentRepository struct {
+	assignments map[string]map[string]bool
+}
+
+func (m *mockPVZAssignmentRepository) AssignPVZ(userID, pvzID string) error {
+	if m.assignments[userID] == nil {
+		m.assignments[userID] = map[string]bool{}
+	}
+	m.assignments[userID][pvzID] = true
+	return nil
+}
+
+func (m *mockPVZAssignmentRepository) UnassignPVZ(userID, pvzID string) error {
+	delete(m.assignments[userID], pvzID)
+	return nil
+}
+
+func (m *mockPVZAssignmentRepository) ListUserPVZs(userID string) ([]string, error) {
+	pvzIDs := make([]string, 0)
+	for pvzID := range m.assignments[userID] {
+		pvzIDs = append(pvzIDs, pvzID)
+	}
+	sort.Strings(pvzIDs)
+	return pvzIDs, nil
+}
+
+func (m *mockPVZAssignmentRepository) ListPVZUsers(pvzID string) ([]string, error) {
+	userIDs := make([]string, 0)
+	for userID, pvzIDs := range m.assignments {
+		if pvzIDs[pvzID] {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+func newSCIMFixture() (*SCIMService, *mockUserRepository, *mockPVZAssignmentRepository) {
+	userRepo := &mockUserRepository{users: map[string]*models.User{
+		"employee@example.com": {ID: "employee-id", Email: "employee@example.com", Role: "employee", Status: "active"},
+		"admin@example.com":    {ID: "admin-id", Email: "admin@example.com", Role: "admin", Status: "active"},
+	}}
+	pvzRepo := &mockPVZRepository{pvzs: map[string]*models.PVZ{
+		scimPVZID: {ID: scimPVZID, City: "Москва"},
+	}}
+	assignmentRepo := &mockPVZAssignmentRepository{assignments: map[string]map[string]bool{}}
+	return NewSCIMService(userRepo, pvzRepo, assignmentRepo), userRepo, assignmentRepo
+}
+
+func patchRequest(op, path, value string) scimDto.PatchRequest {
+	return scimDto.PatchRequest{Operations: []scimDto.PatchOperation{{Op: op, Path: path, Value: json.RawMessage(value)}}}
+}
+
+func TestSCIMService_ListUsers_Filters(t *testing.T) {
+	service, _, _ := newSCIMFixture()
+
+	users, total, err := service.ListUsers(`userName eq "admin@example.com"`, 1, -1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, users, 1)
+	assert.Equal(t, "admin-id", users[0].User.ID)
+
+	users, total, err = service.ListUsers(`USERNAME eq "missing@example.com"`, 1, -1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, users)
+
+	_, total, err = service.ListUsers("active eq true", 1, -1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	_, _, err = service.ListUsers(`title eq "boss"`, 1, -1)
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidFilter)
+
+	_, _, err = service.ListUsers("userName pr", 1, -1)
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidFilter)
+}
+
+func TestSCIMService_CreateUser(t *testing.T) {
+	service, userRepo, _ := newSCIMFixture()
+	inactive := false
+
+	user, err := service.CreateUser(scimDto.UserRequest{
+		UserName: "new@example.com",
+		Active:   &inactive,
+		Roles:    []scimDto.Role{{Value: "moderator"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "moderator", user.User.Role)
+	assert.Equal(t, "deactivated", user.User.Status)
+	assert.NotEmpty(t, userRepo.users["new@example.com"].Password)
+
+	_, err = service.CreateUser(scimDto.UserRequest{UserName: "new@example.com"})
+	assert.ErrorIs(t, err, internalErrors.ErrEmailExists)
+
+	_, err = service.CreateUser(scimDto.UserRequest{UserName: "other@example.com", Roles: []scimDto.Role{{Value: "root"}}})
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidRole)
+
+	_, err = service.CreateUser(scimDto.UserRequest{})
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidPatch)
+}
+
+func TestSCIMService_PatchUser(t *testing.T) {
+	service, userRepo, _ := newSCIMFixture()
+
+	user, err := service.PatchUser("employee-id", patchRequest("replace", "active", "false"))
+	require.NoError(t, err)
+	assert.Equal(t, "deactivated", user.User.Status)
+
+	user, err = service.PatchUser("employee-id", patchRequest("replace", "", `{"active":true,"roles":[{"value":"moderator"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "active", user.User.Status)
+	assert.Equal(t, "moderator", userRepo.users["employee@example.com"].Role)
+
+	_, err = service.PatchUser("employee-id", patchRequest("replace", "name.givenName", `"Ivan"`))
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidPatch)
+
+	_, err = service.PatchUser("employee-id", patchRequest("remove", "active", ""))
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidPatch)
+
+	_, err = service.PatchUser("missing-id", patchRequest("replace", "active", "true"))
+	assert.ErrorIs(t, err, internalErrors.ErrUserNotFound)
+}
+
+func TestSCIMService_DeactivateUser(t *testing.T) {
+	service, userRepo, _ := newSCIMFixture()
+
+	require.NoError(t, service.DeactivateUser("employee-id"))
+	assert.Equal(t, "deactivated", userRepo.users["employee@example.com"].Status)
+	assert.ErrorIs(t, service.DeactivateUser("missing-id"), internalErrors.ErrUserNotFound)
+}
+
+func TestSCIMService_ErasedUser(t *testing.T) {
+	service, userRepo, _ := newSCIMFixture()
+	userRepo.users["employee@example.com"].Status = "erased"
+
+	_, err := service.PatchUser("employee-id", patchRequest("replace", "active", "true"))
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidUserStatus)
+	_, err = service.PatchUser("employee-id", patchRequest("replace", "active", "false"))
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidUserStatus)
+
+	require.NoError(t, service.DeactivateUser("employee-id"))
+	assert.Equal(t, "erased", userRepo.users["employee@example.com"].Status)
+}
+
+func TestSCIMService_ListGroups_Paging(t *testing.T) {
+	service, _, _ := newSCIMFixture()
+
+	groups, total, err := service.ListGroups("", 1, -1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	require.Len(t, groups, 4)
+	assert.Equal(t, "employee", groups[0].ID)
+	assert.Equal(t, []string{"admin-id"}, groups[2].Members)
+	assert.Equal(t, "pvz-"+scimPVZID, groups[3].DisplayName)
+
+	groups, _, err = service.ListGroups("", 3, 2)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "admin", groups[0].ID)
+	assert.Equal(t, scimPVZID, groups[1].ID)
+
+	groups, total, err = service.ListGroups(`displayName eq "moderator"`, 1, -1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "moderator", groups[0].ID)
+
+	_, total, err = service.ListGroups(`displayName eq "pvz-unknown"`, 1, -1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+}
+
+func TestSCIMService_GetGroup_NotFound(t *testing.T) {
+	service, _, _ := newSCIMFixture()
+
+	_, err := service.GetGroup("unknown")
+	assert.ErrorIs(t, err, internalErrors.ErrGroupNotFound)
+
+	_, err = service.GetGroup("1e4b5c6d-7e8f-4a0b-8c1d-2e3f4a5b6c7d")
+	assert.ErrorIs(t, err, internalErrors.ErrGroupNotFound)
+}
+
+func TestSCIMService_PatchGroup_RoleMembership(t *testing.T) {
+	service, userRepo, _ := newSCIMFixture()
+
+	group, err := service.PatchGroup("moderator", patchRequest("add", "members", `[{"value":"employee-id"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"employee-id"}, group.Members)
+	assert.Equal(t, 1, userRepo.users["employee@example.com"].TokenVersion)
+
+	_, err = service.PatchGroup("moderator", patchRequest("remove", `members[value eq "employee-id"]`, ""))
+	require.NoError(t, err)
+	assert.Equal(t, "employee", userRepo.users["employee@example.com"].Role)
+
+	_, err = service.PatchGroup("employee", patchRequest("remove", `members[value eq "employee-id"]`, ""))
+	require.NoError(t, err)
+	assert.Equal(t, "employee", userRepo.users["employee@example.com"].Role)
+
+	_, err = service.PatchGroup("admin", patchRequest("add", "members", `[{"value":"missing-id"}]`))
+	assert.ErrorIs(t, err, internalErrors.ErrUserNotFound)
+
+	_, err = service.PatchGroup("admin", patchRequest("replace", "displayName", `"root"`))
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidPatch)
+}
+
+func TestSCIMService_PatchGroup_PVZMembership(t *testing.T) {
+	service, _, assignmentRepo := newSCIMFixture()
+
+	group, err := service.PatchGroup(scimPVZID, patchRequest("add", "members", `[{"value":"employee-id"},{"value":"admin-id"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin-id", "employee-id"}, group.Members)
+
+	user, err := service.GetUser("employee-id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{scimPVZID}, user.PVZIDs)
+
+	_, err = service.PatchGroup(scimPVZID, patchRequest("remove", `members[value eq "admin-id"]`, ""))
+	require.NoError(t, err)
+	assert.False(t, assignmentRepo.assignments["admin-id"][scimPVZID])
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sources are the clock and the ID generator of a service. Services embed
+// them, so an embedding application can replace both, see pkg/pvz.
+type sources struct {
+	now   func() time.Time
+	newID func() string
+}
+
+func newSources() sources {
+	return sources{
+		now:   time.Now,
+		newID: func() string { return uuid.New().String() },
+	}
+}
+
+// SetClock replaces the source of the current time.
+func (s *sources) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetIDGenerator replaces the source of the IDs of created entities.
+func (s *sources) SetIDGenerator(newID func() string) {
+	s.newID = newID
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/utils"
+	"errors"
+	"strings"
+)
+
+const (
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	totpSkew           = 1
+)
+
+// TwoFactorPolicy lists the roles that must complete a TOTP login before
+// they can use the API.
+type TwoFactorPolicy struct {
+	Issuer        string
+	RequiredRoles []string
+}
+
+type TwoFactorService struct {
+	userRepo      repository.UserRepositoryInterface
+	twoFactorRepo repository.TwoFactorRepositoryInterface
+	attempts      *LoginAttemptService
+	policy        TwoFactorPolicy
+	sources
+}
+
+// NewTwoFactorService creates the service. attempts may be nil to disable
+// throttling of the second step.
+func NewTwoFactorService(
+	userRepo repository.UserRepositoryInterface,
+	twoFactorRepo repository.TwoFactorRepositoryInterface,
+	attempts *LoginAttemptService,
+	policy TwoFactorPolicy,
+) *TwoFactorService {
+	if policy.Issuer == "" {
+		policy.Issuer = "PVZ Service"
+	}
+	return &TwoFactorService{
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		attempts:      attempts,
+		policy:        policy,
+		sources:       newSources(),
+	}
+}
+
+func (s *TwoFactorService) IsRequired(role string) bool {
+	for _, required := range s.policy.RequiredRoles {
+		if required == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *TwoFactorService) IsEnabled(userID string) (bool, error) {
+	secret, err := s.twoFactorRepo.GetTOTPSecret(userID)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrTwoFactorNotEnrolled) {
+			return false, nil
+		}
+		return false, err
+	}
+	return secret.Confirmed, nil
+}
+
+// Enroll generates a new secret for the user and returns it together with an
+// otpauth:// URI. The secret becomes active only after Confirm.
+func (s *TwoFactorService) Enroll(userID string) (string, string, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	err = s.twoFactorRepo.SaveTOTPSecret(&models.TOTPSecret{
+		UserID:    user.ID,
+		Secret:    secret,
+		CreatedAt: s.now(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return secret, utils.TOTPURI(s.policy.Issuer, user.Email, secret), nil
+}
+
+// Confirm activates a pending secret with a valid code. It returns freshly
+// generated recovery codes and an access token for the completed 2FA login.
+func (s *TwoFactorService) Confirm(userID, code string) ([]string, string, error) {
+	secret, err := s.twoFactorRepo.GetTOTPSecret(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if secret.Confirmed {
+		return nil, "", internalErrors.ErrTwoFactorEnabled
+	}
+	if err = s.useTOTPCode(secret, code); err != nil {
+		return nil, "", err
+	}
+	if err = s.twoFactorRepo.ConfirmTOTPSecret(userID); err != nil {
+		return nil, "", err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		token, err := utils.GenerateToken()
+		if err != nil {
+			return nil, "", err
+		}
+		code := token[:recoveryCodeLength]
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(code))
+	}
+	if err = s.twoFactorRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, "", err
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	token, err := utils.GenerateJWT(user.ID, user.Role, user.TokenVersion, true)
+	if err != nil {
+		return nil, "", err
+	}
+	return codes, token, nil
+}
+
+// Verify completes a two-step login. code may be a TOTP code or an unused
+// recovery code.
+func (s *TwoFactorService) Verify(twoFactorToken, code, clientIP string) (string, error) {
+	userID, err := utils.ParseTwoFactorJWT(twoFactorToken)
+	if err != nil {
+		return "", internalErrors.ErrInvalidTwoFactorToken
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrUserNotFound) {
+			return "", internalErrors.ErrInvalidTwoFactorToken
+		}
+		return "", err
+	}
+	if user.Status != "" && user.Status != "active" {
+		return "", internalErrors.ErrInvalidTwoFactorToken
+	}
+	if s.attempts != nil {
+		if err = s.attempts.Check(user.Email, clientIP); err != nil {
+			return "", err
+		}
+	}
+
+	secret, err := s.twoFactorRepo.GetTOTPSecret(user.ID)
+	if err != nil {
+		return "", err
+	}
+	if !secret.Confirmed {
+		return "", internalErrors.ErrTwoFactorNotEnrolled
+	}
+
+	err = s.useTOTPCode(secret, code)
+	if errors.Is(err, internalErrors.ErrInvalidTwoFactorCode) {
+		err = s.twoFactorRepo.UseRecoveryCode(user.ID, utils.HashToken(strings.TrimSpace(code)), s.now())
+	}
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrInvalidTwoFactorCode) && s.attempts != nil {
+			if recordErr := s.attempts.RegisterFailure(user.Email, clientIP); recordErr != nil {
+				return "", recordErr
+			}
+		}
+		return "", err
+	}
+	if s.attempts != nil {
+		if err = s.attempts.RegisterSuccess(user.Email); err != nil {
+			return "", err
+		}
+	}
+	return utils.GenerateJWT(user.ID, user.Role, user.TokenVersion, true)
+}
+
+func (s *TwoFactorService) useTOTPCode(secret *models.TOTPSecret, code string) error {
+	step, ok := utils.ValidateTOTP(secret.Secret, strings.TrimSpace(code), s.now(), totpSkew)
+	if !ok {
+		return internalErrors.ErrInvalidTwoFactorCode
+	}
+	return s.twoFactorRepo.UseTOTPStep(secret.UserID, step)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/authDto"
+	"avito-intern/internal/models"
+	"avito-intern/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTwoFactorRepository struct {
+	secrets       map[string]*models.TOTPSecret
+	recoveryCodes map[string]map[string]bool
+}
+
+func newMockTwoFactorRepository() *mockTwoFactorRepository {
+	return &mockTwoFactorRepository{
+		secrets:       make(map[string]*models.TOTPSecret),
+		recoveryCodes: make(map[string]map[string]bool),
+	}
+}
+
+func (m *mockTwoFactorRepository) SaveTOTPSecret(secret *models.TOTPSecret) error {
+	if existing, exists := m.secrets[secret.UserID]; exists && existing.Confirmed {
+		return internalErrors.ErrTwoFactorEnabled
+	}
+	copied := *secret
+	m.secrets[secret.UserID] = &copied
+	return nil
+}
+
+func (m *mockTwoFactorRepository) GetTOTPSecret(userID string) (*models.TOTPSecret, error) {
+	if secret, exists := m.secrets[userID]; exists {
+		copied := *secret
+		return &copied, nil
+	}
+	return nil, internalErrors.ErrTwoFactorNotEnrolled
+}
+
+func (m *mockTwoFactorRepository) ConfirmTOTPSecret(userID string) error {
+	m.secrets[userID].Confirmed = true
+	return nil
+}
+
+func (m *mockTwoFactorRepository) UseTOTPStep(userID string, step int64) error {
+	secret := m.secrets[userID]
+	if secret.LastUsedStep >= step {
+		return internalErrors.ErrInvalidTwoFactorCode
+	}
+	secret.LastUsedStep = step
+	return nil
+}
+
+func (m *mockTwoFactorRepository) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	m.recoveryCodes[userID] = make(map[string]bool)
+	for _, hash := range codeHashes {
+		m.recoveryCodes[userID][hash] = false
+	}
+	return nil
+}
+
+func (m *mockTwoFactorRepository) UseRecoveryCode(userID, codeHash string, usedAt time.Time) error {
+	used, exists := m.recoveryCodes[userID][codeHash]
+	if !exists || used {
+		return internalErrors.ErrInvalidTwoFactorCode
+	}
+	m.recoveryCodes[userID][codeHash] = true
+	return nil
+}
+
+func newTwoFactorFixture(requiredRoles ...string) (*AuthService, *TwoFactorService, *time.Time) {
+	userRepo := &mockUserRepository{
+		users: map[string]*models.User{
+			"moderator@example.com": {
+				ID: "moderator-id", Email: "moderator@example.com", Role: "moderator", Status: "active",
+				Password: utils.HashPassword("password123"),
+			},
+		},
+	}
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	twoFactor := NewTwoFactorService(userRepo, newMockTwoFactorRepository(), nil, TwoFactorPolicy{
+		RequiredRoles: requiredRoles,
+	})
+	twoFactor.now = func() time.Time { return clock }
+	return NewAuthService(userRepo, newMockInviteRepository(), nil, nil, twoFactor, nil, testPolicy), twoFactor, &clock
+}
+
+func currentCode(t *testing.T, secret string, at time.Time) string {
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(at))
+	require.NoError(t, err)
+	return code
+}
+
+func TestTwoFactorService_EnrollAndConfirm(t *testing.T) {
+	authService, service, clock := newTwoFactorFixture("moderator")
+	login := authDto.LoginRequest{Email: "moderator@example.com", Password: "password123"}
+
+	result, err := authService.AuthenticateUser(login)
+	require.NoError(t, err)
+	assert.False(t, result.TwoFactorRequired)
+	assert.True(t, result.TwoFactorEnrollmentRequired)
+
+	secret, uri, err := service.Enroll("moderator-id")
+	require.NoError(t, err)
+	assert.Contains(t, uri, "otpauth://totp/")
+	assert.Contains(t, uri, "secret="+secret)
+
+	_, _, err = service.Confirm("moderator-id", "000000")
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidTwoFactorCode)
+
+	codes, token, err := service.Confirm("moderator-id", currentCode(t, secret, *clock))
+	require.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+	claims, err := utils.ParseJWT(token)
+	require.NoError(t, err)
+	assert.Equal(t, true, claims["two_factor"])
+
+	_, _, err = service.Enroll("moderator-id")
+	assert.ErrorIs(t, err, internalErrors.ErrTwoFactorEnabled)
+
+	result, err = authService.AuthenticateUser(login)
+	require.NoError(t, err)
+	assert.True(t, result.TwoFactorRequired)
+	_, err = utils.ParseJWT(result.Token)
+	assert.Error(t, err, "intermediate token must not be usable as an access token")
+}
+
+func TestTwoFactorService_Verify(t *testing.T) {
+	authService, service, clock := newTwoFactorFixture()
+	secret, _, err := service.Enroll("moderator-id")
+	require.NoError(t, err)
+	codes, _, err := service.Confirm("moderator-id", currentCode(t, secret, *clock))
+	require.NoError(t, err)
+
+	result, err := authService.AuthenticateUser(authDto.LoginRequest{Email: "moderator@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.True(t, result.TwoFactorRequired)
+
+	_, err = service.Verify("garbage", "123456", "")
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidTwoFactorToken)
+
+	_, err = service.Verify(result.Token, currentCode(t, secret, *clock), "")
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidTwoFactorCode, "a code cannot be reused within its step")
+
+	*clock = clock.Add(30 * time.Second)
+	token, err := service.Verify(result.Token, currentCode(t, secret, *clock), "")
+	require.NoError(t, err)
+	claims, err := utils.ParseJWT(token)
+	require.NoError(t, err)
+	assert.Equal(t, "moderator-id", claims["user_id"])
+	assert.Equal(t, true, claims["two_factor"])
+
+	_, err = service.Verify(result.Token, codes[0], "")
+	assert.NoError(t, err)
+	_, err = service.Verify(result.Token, codes[0], "")
+	assert.ErrorIs(t, err, internalErrors.ErrInvalidTwoFactorCode, "recovery codes are single-use")
+}
+
+func TestTwoFactorService_IsRequired(t *testing.T) {
+	_, service, _ := newTwoFactorFixture("moderator", "admin")
+
+	assert.True(t, service.IsRequired("moderator"))
+	assert.True(t, service.IsRequired("admin"))
+	assert.False(t, service.IsRequired("employee"))
+}
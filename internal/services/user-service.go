@@ -0,0 +1,104 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/utils"
+	"strconv"
+)
+
+const temporaryPasswordLength = 16
+
+type UserService struct {
+	userRepo repository.UserRepositoryInterface
+}
+
+func NewUserService(userRepo repository.UserRepositoryInterface) *UserService {
+	return &UserService{
+		userRepo: userRepo,
+	}
+}
+
+func (s *UserService) ListUsers(email, role, status, limitStr, pageStr string) ([]*models.User, error) {
+	page, _ := strconv.Atoi(pageStr)
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(limitStr)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	return s.userRepo.ListUsers(email, role, status, limit, offset)
+}
+
+func (s *UserService) ChangeRole(actor models.User, userID, role string) (*models.User, error) {
+	if !isValidRole(role) {
+		return nil, internalErrors.ErrInvalidRole
+	}
+	if actor.ID == userID {
+		return nil, internalErrors.ErrCannotModifySelf
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.userRepo.UpdateUserRole(user.ID, role); err != nil {
+		return nil, err
+	}
+	user.Role = role
+	return user, nil
+}
+
+func (s *UserService) DeactivateUser(actor models.User, userID string) (*models.User, error) {
+	if actor.ID == userID {
+		return nil, internalErrors.ErrCannotModifySelf
+	}
+	return s.setStatus(userID, "deactivated", "active", "pending")
+}
+
+func (s *UserService) ReactivateUser(userID string) (*models.User, error) {
+	return s.setStatus(userID, "active", "deactivated")
+}
+
+// ResetPassword replaces the user's password with a generated one and returns
+// it to the administrator. Existing tokens of the user are revoked.
+func (s *UserService) ResetPassword(userID string) (string, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	password := token[:temporaryPasswordLength]
+	if err = s.userRepo.UpdateUserPassword(user.ID, utils.HashPassword(password)); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+func (s *UserService) setStatus(userID, status string, allowedFrom ...string) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	allowed := false
+	for _, from := range allowedFrom {
+		if user.Status == from {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, internalErrors.ErrInvalidUserStatus
+	}
+	if err = s.userRepo.UpdateUserStatus(user.ID, status); err != nil {
+		return nil, err
+	}
+	user.Status = status
+	return user, nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/authDto"
+	"avito-intern/internal/models"
+	"avito-intern/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newUserServiceFixture() (*UserService, *AuthService, *mockUserRepository) {
+	mockRepo := &mockUserRepository{
+		users: map[string]*models.User{
+			"admin@example.com": {
+				ID: "admin-id", Email: "admin@example.com", Role: "admin", Status: "active",
+				Password: utils.HashPassword("password123"),
+			},
+			"employee@example.com": {
+				ID: "employee-id", Email: "employee@example.com", Role: "employee", Status: "active",
+				Password: utils.HashPassword("password123"),
+			},
+		},
+	}
+	return NewUserService(mockRepo), NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, testPolicy), mockRepo
+}
+
+func TestUserService_ListUsers(t *testing.T) {
+
+	service, _, _ := newUserServiceFixture()
+
+	users, err := service.ListUsers("", "employee", "", "", "")
+
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, "employee-id", users[0].ID)
+}
+
+func TestUserService_ChangeRole(t *testing.T) {
+
+	service, authService, _ := newUserServiceFixture()
+	admin := models.User{ID: "admin-id", Role: "admin"}
+
+	_, err := service.ChangeRole(admin, "employee-id", "superuser")
+	assert.Equal(t, internalErrors.ErrInvalidRole, err)
+
+	_, err = service.ChangeRole(admin, "admin-id", "employee")
+	assert.Equal(t, internalErrors.ErrCannotModifySelf, err)
+
+	_, err = service.ChangeRole(admin, "missing-id", "employee")
+	assert.Equal(t, internalErrors.ErrUserNotFound, err)
+
+	user, err := service.ChangeRole(admin, "employee-id", "moderator")
+	assert.NoError(t, err)
+	assert.Equal(t, "moderator", user.Role)
+
+	assert.Equal(t, internalErrors.ErrTokenRevoked, authService.ValidateSession("employee-id", 0))
+}
+
+func TestUserService_DeactivateAndReactivate(t *testing.T) {
+
+	service, authService, _ := newUserServiceFixture()
+	admin := models.User{ID: "admin-id", Role: "admin"}
+	login := authDto.LoginRequest{Email: "employee@example.com", Password: "password123"}
+
+	assert.NoError(t, authService.ValidateSession("employee-id", 0))
+
+	_, err := service.DeactivateUser(admin, "admin-id")
+	assert.Equal(t, internalErrors.ErrCannotModifySelf, err)
+
+	_, err = service.ReactivateUser("employee-id")
+	assert.Equal(t, internalErrors.ErrInvalidUserStatus, err)
+
+	user, err := service.DeactivateUser(admin, "employee-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "deactivated", user.Status)
+
+	_, err = service.DeactivateUser(admin, "employee-id")
+	assert.Equal(t, internalErrors.ErrInvalidUserStatus, err)
+
+	_, err = authService.AuthenticateUser(login)
+	assert.Equal(t, internalErrors.ErrAccountDeactivated, err)
+	assert.Equal(t, internalErrors.ErrAccountDeactivated, authService.ValidateSession("employee-id", 0))
+
+	user, err = service.ReactivateUser("employee-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "active", user.Status)
+
+	token, err := authService.AuthenticateUser(login)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, internalErrors.ErrTokenRevoked, authService.ValidateSession("employee-id", 0))
+	assert.NoError(t, authService.ValidateSession("employee-id", user.TokenVersion))
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+
+	service, authService, _ := newUserServiceFixture()
+
+	_, err := service.ResetPassword("missing-id")
+	assert.Equal(t, internalErrors.ErrUserNotFound, err)
+
+	password, err := service.ResetPassword("employee-id")
+	assert.NoError(t, err)
+	assert.Len(t, password, temporaryPasswordLength)
+
+	_, err = authService.AuthenticateUser(authDto.LoginRequest{Email: "employee@example.com", Password: "password123"})
+	assert.Equal(t, internalErrors.ErrInvalidCredentials, err)
+
+	token, err := authService.AuthenticateUser(authDto.LoginRequest{Email: "employee@example.com", Password: password})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+}
+
+func TestAuthService_ValidateSession_UnknownUser(t *testing.T) {
+
+	_, authService, mockRepo := newUserServiceFixture()
+
+	assert.Equal(t, internalErrors.ErrAccessDenied, authService.ValidateSession("dummy-user-id", 0))
+
+	policy := testPolicy
+	policy.DummyLogin = true
+	authService = NewAuthService(mockRepo, newMockInviteRepository(), nil, nil, nil, nil, policy)
+	assert.NoError(t, authService.ValidateSession("dummy-user-id", 0), "dummy tokens pass only in development")
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/webhookDto"
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/utils"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const webhookSecretPrefix = "whsec_"
+
+// WebhookService manages the webhook subscriptions of partners and their
+// delivery log. Deliveries are queued and sent by the webhook package.
+type WebhookService struct {
+	repo repository.WebhookRepositoryInterface
+	sources
+}
+
+func NewWebhookService(repo repository.WebhookRepositoryInterface) *WebhookService {
+	return &WebhookService{
+		repo:    repo,
+		sources: newSources(),
+	}
+}
+
+// CreateWebhook subscribes an http(s) URL to the events passing the filters.
+// The signing secret is returned only here. URLs of internal hosts are
+// refused here already; the dispatcher checks the resolved address again.
+func (s *WebhookService) CreateWebhook(creator models.User, req webhookDto.CreateWebhookRequest) (*models.WebhookSubscription, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" || internalHost(target.Hostname()) {
+		return nil, internalErrors.ErrInvalidWebhookURL
+	}
+	for _, eventType := range req.EventTypes {
+		if !slices.Contains(models.WebhookEventTypes, eventType) {
+			return nil, internalErrors.ErrInvalidEventType
+		}
+	}
+	for _, pvzID := range req.PvzIDs {
+		if _, err := uuid.Parse(pvzID); err != nil {
+			return nil, internalErrors.ErrInvalidID
+		}
+	}
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+	subscription := &models.WebhookSubscription{
+		ID:         s.newID(),
+		URL:        req.URL,
+		Secret:     webhookSecretPrefix + token,
+		EventTypes: req.EventTypes,
+		PvzIDs:     req.PvzIDs,
+		CreatedBy:  creator.ID,
+		CreatedAt:  s.now(),
+	}
+	if err = s.repo.CreateWebhookSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// internalHost reports whether host is an address or a name that cannot be
+// reached from the internet.
+func internalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && !utils.IsPublicAddress(addr)
+}
+
+func (s *WebhookService) ListWebhooks() ([]*models.WebhookSubscription, error) {
+	return s.repo.ListWebhookSubscriptions()
+}
+
+func (s *WebhookService) DeleteWebhook(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return internalErrors.ErrWebhookNotFound
+	}
+	return s.repo.DeleteWebhookSubscription(id)
+}
+
+// ListDeliveries returns a page of the delivery log, newest first. The dead
+// letters are the deliveries with status dead.
+func (s *WebhookService) ListDeliveries(webhookID, status, limitStr, pageStr string) ([]*models.WebhookDelivery, error) {
+	if webhookID != "" {
+		if _, err := uuid.Parse(webhookID); err != nil {
+			return nil, internalErrors.ErrWebhookNotFound
+		}
+	}
+	page, _ := strconv.Atoi(pageStr)
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(limitStr)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.ListWebhookDeliveries(webhookID, status, limit, (page-1)*limit)
+}
+
+// ResendDelivery queues a delivery to be sent again right away, also when it
+// was delivered or is a dead letter. It gets the full number of attempts.
+func (s *WebhookService) ResendDelivery(id string) (*models.WebhookDelivery, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, internalErrors.ErrDeliveryNotFound
+	}
+	return s.repo.ResendWebhookDelivery(id, s.now())
+}
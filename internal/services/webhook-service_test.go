@@ -0,0 +1,156 @@
+package services
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/webhookDto"
+	"avito-intern/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWebhookRepository struct {
+	subscriptions map[string]*models.WebhookSubscription
+	listed        []interface{}
+	resentAt      time.Time
+}
+
+func newMockWebhookRepository() *mockWebhookRepository {
+	return &mockWebhookRepository{subscriptions: map[string]*models.WebhookSubscription{}}
+}
+
+func (m *mockWebhookRepository) CreateWebhookSubscription(subscription *models.WebhookSubscription) error {
+	m.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (m *mockWebhookRepository) GetWebhookSubscription(id string) (*models.WebhookSubscription, error) {
+	if subscription, exists := m.subscriptions[id]; exists {
+		return subscription, nil
+	}
+	return nil, internalErrors.ErrWebhookNotFound
+}
+
+func (m *mockWebhookRepository) ListWebhookSubscriptions() ([]*models.WebhookSubscription, error) {
+	subscriptions := make([]*models.WebhookSubscription, 0, len(m.subscriptions))
+	for _, subscription := range m.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, nil
+}
+
+func (m *mockWebhookRepository) DeleteWebhookSubscription(id string) error {
+	if _, exists := m.subscriptions[id]; !exists {
+		return internalErrors.ErrWebhookNotFound
+	}
+	delete(m.subscriptions, id)
+	return nil
+}
+
+func (m *mockWebhookRepository) CreateWebhookDeliveries([]*models.WebhookDelivery) error {
+	return nil
+}
+
+func (m *mockWebhookRepository) ClaimWebhookDeliveries(int, time.Time, time.Time) ([]*models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (m *mockWebhookRepository) RecordWebhookAttempt(*models.WebhookDelivery) error {
+	return nil
+}
+
+func (m *mockWebhookRepository) ListWebhookDeliveries(subscriptionID, status string, limit, offset int) ([]*models.WebhookDelivery, error) {
+	m.listed = []interface{}{subscriptionID, status, limit, offset}
+	return []*models.WebhookDelivery{}, nil
+}
+
+func (m *mockWebhookRepository) ResendWebhookDelivery(id string, at time.Time) (*models.WebhookDelivery, error) {
+	m.resentAt = at
+	return &models.WebhookDelivery{ID: id, Status: models.WebhookDeliveryPending, NextAttemptAt: at}, nil
+}
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	repo := newMockWebhookRepository()
+	service := NewWebhookService(repo)
+	moderator := models.User{ID: "moderator-id", Role: "moderator"}
+
+	subscription, err := service.CreateWebhook(moderator, webhookDto.CreateWebhookRequest{
+		URL:        "https://partner.example/hooks",
+		EventTypes: []string{models.EventReceptionClosed},
+		PvzIDs:     []string{"0b9f4d2a-8f3e-4c1a-9a57-3f1f2f6e0b11"},
+	})
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(subscription.Secret, "whsec_"))
+	assert.Equal(t, "moderator-id", subscription.CreatedBy)
+	assert.Same(t, subscription, repo.subscriptions[subscription.ID])
+}
+
+func TestWebhookService_CreateWebhook_Validation(t *testing.T) {
+	service := NewWebhookService(newMockWebhookRepository())
+
+	tests := []struct {
+		name        string
+		req         webhookDto.CreateWebhookRequest
+		expectedErr error
+	}{
+		{"Relative URL", webhookDto.CreateWebhookRequest{URL: "/hooks"}, internalErrors.ErrInvalidWebhookURL},
+		{"Not HTTP", webhookDto.CreateWebhookRequest{URL: "ftp://partner.example/hooks"}, internalErrors.ErrInvalidWebhookURL},
+		{"Loopback", webhookDto.CreateWebhookRequest{URL: "http://127.0.0.1:8080/hooks"}, internalErrors.ErrInvalidWebhookURL},
+		{"Localhost", webhookDto.CreateWebhookRequest{URL: "http://localhost/hooks"}, internalErrors.ErrInvalidWebhookURL},
+		{"Metadata service", webhookDto.CreateWebhookRequest{URL: "http://169.254.169.254/latest"}, internalErrors.ErrInvalidWebhookURL},
+		{"Private IPv6", webhookDto.CreateWebhookRequest{URL: "http://[fd00::1]/hooks"}, internalErrors.ErrInvalidWebhookURL},
+		{
+			"Unknown event type",
+			webhookDto.CreateWebhookRequest{URL: "https://partner.example", EventTypes: []string{"user.created"}},
+			internalErrors.ErrInvalidEventType,
+		},
+		{
+			"Invalid PVZ ID",
+			webhookDto.CreateWebhookRequest{URL: "https://partner.example", PvzIDs: []string{"pvz-1"}},
+			internalErrors.ErrInvalidID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreateWebhook(models.User{ID: "moderator-id"}, tt.req)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestWebhookService_ListDeliveries(t *testing.T) {
+	repo := newMockWebhookRepository()
+	service := NewWebhookService(repo)
+
+	_, err := service.ListDeliveries("", models.WebhookDeliveryDead, "50", "3")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"", "dead", 50, 100}, repo.listed)
+
+	_, err = service.ListDeliveries("", "", "1000", "")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"", "", 20, 0}, repo.listed)
+
+	_, err = service.ListDeliveries("webhook", "", "", "")
+	assert.ErrorIs(t, err, internalErrors.ErrWebhookNotFound)
+}
+
+func TestWebhookService_ResendDelivery(t *testing.T) {
+	repo := newMockWebhookRepository()
+	service := NewWebhookService(repo)
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	service.SetClock(func() time.Time { return now })
+
+	delivery, err := service.ResendDelivery("2c5e8a9e-3d5f-4b77-8f1b-0f5a3c9d1e22")
+
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, now, repo.resentAt)
+
+	_, err = service.ResendDelivery("delivery")
+	assert.ErrorIs(t, err, internalErrors.ErrDeliveryNotFound)
+}
@@ -0,0 +1,171 @@
+// Package stream pushes the domain events of pickup points to live
+// subscribers. The Hub keeps the last events of every pickup point so that a
+// subscriber can resume after the last event it received; the Listener
+// feeds it with the events Postgres notifies, so that every replica sees
+// the events written through any of them.
+package stream
+
+import (
+	"avito-intern/internal/metrics"
+	"avito-intern/internal/models"
+	"context"
+	"sync"
+)
+
+const (
+	defaultReplay = 100
+
+	// subscriberBuffer is how many events a subscriber may fall behind
+	// before it is dropped. It resumes from the replay buffer.
+	subscriberBuffer = 64
+)
+
+// Hub fans the events of a pickup point out to its subscribers. It is the
+// outbox.Publisher of the streams, for services without Postgres
+// notifications.
+type Hub struct {
+	mu     sync.Mutex
+	replay int
+	feeds  map[string]*feed
+}
+
+// feed holds the last events of a pickup point in sequence order. The
+// events of a pickup point are written one transaction at a time, so the
+// buffer has every event from its first one on.
+type feed struct {
+	events      []*models.OutboxEvent
+	subscribers map[*Subscription]struct{}
+}
+
+// NewHub creates a hub keeping the last replay events of every pickup
+// point, 100 if replay is not positive.
+func NewHub(replay int) *Hub {
+	if replay <= 0 {
+		replay = defaultReplay
+	}
+	return &Hub{
+		replay: replay,
+		feeds:  make(map[string]*feed),
+	}
+}
+
+// Subscription receives the events of a pickup point until it is closed.
+type Subscription struct {
+	hub    *Hub
+	pvzID  string
+	events chan *models.OutboxEvent
+}
+
+// Events yields the events as they are published. It is closed when the
+// subscriber falls behind or the hub may have missed events; the
+// subscriber should then resume.
+func (s *Subscription) Events() <-chan *models.OutboxEvent {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.drop(s)
+}
+
+// Publish passes the event to the subscribers of its pickup point and keeps
+// it for resuming ones. Events the hub already has are ignored, so that an
+// event published again is sent once.
+func (h *Hub) Publish(_ context.Context, event *models.OutboxEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f := h.feed(event.PvzID)
+	if n := len(f.events); n > 0 && event.Sequence <= f.events[n-1].Sequence {
+		return nil
+	}
+	if len(f.events) == h.replay {
+		f.events = append(f.events[:0], f.events[1:]...)
+	}
+	f.events = append(f.events, event)
+
+	for s := range f.subscribers {
+		select {
+		case s.events <- event:
+		default:
+			h.drop(s)
+			metrics.StreamSubscribersDroppedCount.Inc()
+		}
+	}
+	return nil
+}
+
+// Subscribe starts a subscription to the events published from now on.
+func (h *Hub) Subscribe(pvzID string) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.subscribe(pvzID)
+}
+
+// Resume starts a subscription after the event with sequence lastEventID.
+// It returns the kept events that followed it and whether they are all of
+// them: the hub cannot tell when lastEventID is older than its buffer.
+func (h *Hub) Resume(pvzID string, lastEventID int64) (*Subscription, []*models.OutboxEvent, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f := h.feed(pvzID)
+	var missed []*models.OutboxEvent
+	for _, event := range f.events {
+		if event.Sequence > lastEventID {
+			missed = append(missed, event)
+		}
+	}
+	complete := len(f.events) > 0 && lastEventID >= f.events[0].Sequence
+	return h.subscribe(pvzID), missed, complete
+}
+
+// Reset forgets the kept events and closes every subscription. The Listener
+// calls it when it may have missed notifications.
+func (h *Hub) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for pvzID, f := range h.feeds {
+		for s := range f.subscribers {
+			h.drop(s)
+			metrics.StreamSubscribersDroppedCount.Inc()
+		}
+		delete(h.feeds, pvzID)
+	}
+}
+
+func (h *Hub) feed(pvzID string) *feed {
+	f, ok := h.feeds[pvzID]
+	if !ok {
+		f = &feed{subscribers: make(map[*Subscription]struct{})}
+		h.feeds[pvzID] = f
+	}
+	return f
+}
+
+func (h *Hub) subscribe(pvzID string) *Subscription {
+	s := &Subscription{
+		hub:    h,
+		pvzID:  pvzID,
+		events: make(chan *models.OutboxEvent, subscriberBuffer),
+	}
+	h.feed(pvzID).subscribers[s] = struct{}{}
+	metrics.StreamSubscribers.Inc()
+	return s
+}
+
+// drop removes the subscription and closes its channel, once. The caller
+// holds the lock.
+func (h *Hub) drop(s *Subscription) {
+	f, ok := h.feeds[s.pvzID]
+	if !ok {
+		return
+	}
+	if _, ok := f.subscribers[s]; !ok {
+		return
+	}
+	delete(f.subscribers, s)
+	close(s.events)
+	metrics.StreamSubscribers.Dec()
+}
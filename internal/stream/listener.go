@@ -0,0 +1,74 @@
+package stream
+
+import (
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	minReconnect = time.Second
+	maxReconnect = time.Minute
+
+	// pingInterval checks the connection when no notification came for a
+	// while, so that a lost connection is noticed.
+	pingInterval = 90 * time.Second
+)
+
+// Listener feeds a Hub with the events notified on
+// repository.OutboxChannel by the transactions of every replica.
+type Listener struct {
+	hub      *Hub
+	listener *pq.Listener
+}
+
+// NewListener creates a listener on its own connection to the database at
+// connectionString. The connection is reopened when it is lost.
+func NewListener(connectionString string, hub *Hub) *Listener {
+	return &Listener{
+		hub: hub,
+		listener: pq.NewListener(connectionString, minReconnect, maxReconnect, func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Printf("Event stream listener: %v", err)
+			}
+		}),
+	}
+}
+
+// Run passes notifications to the hub until ctx is done.
+func (l *Listener) Run(ctx context.Context) error {
+	defer l.listener.Close()
+	if err := l.listener.Listen(repository.OutboxChannel); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-l.listener.Notify:
+			l.notify(n)
+		case <-time.After(pingInterval):
+			go l.listener.Ping()
+		}
+	}
+}
+
+// notify publishes a notified event. A nil notification follows a
+// reconnect: events may have been missed meanwhile, so the hub starts over.
+func (l *Listener) notify(n *pq.Notification) {
+	if n == nil {
+		l.hub.Reset()
+		return
+	}
+	var event models.OutboxEvent
+	if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+		log.Printf("Event stream listener: invalid notification: %v", err)
+		return
+	}
+	_ = l.hub.Publish(context.Background(), &event)
+}
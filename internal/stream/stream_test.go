@@ -0,0 +1,114 @@
+package stream
+
+import (
+	"avito-intern/internal/models"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent(sequence int64, pvzID string) *models.OutboxEvent {
+	return &models.OutboxEvent{
+		Sequence:   sequence,
+		ID:         "event",
+		Type:       models.EventProductAdded,
+		PvzID:      pvzID,
+		Payload:    json.RawMessage(`{"id":"product-1"}`),
+		OccurredAt: time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func publish(t *testing.T, hub *Hub, sequences ...int64) {
+	for _, sequence := range sequences {
+		require.NoError(t, hub.Publish(context.Background(), testEvent(sequence, "pvz-1")))
+	}
+}
+
+func sequences(events []*models.OutboxEvent) []int64 {
+	var result []int64
+	for _, event := range events {
+		result = append(result, event.Sequence)
+	}
+	return result
+}
+
+func TestHub_Subscribe(t *testing.T) {
+	hub := NewHub(10)
+	subscription := hub.Subscribe("pvz-1")
+	defer subscription.Close()
+
+	publish(t, hub, 1, 2, 2)
+	require.NoError(t, hub.Publish(context.Background(), testEvent(3, "pvz-2")))
+
+	assert.Equal(t, int64(1), (<-subscription.Events()).Sequence)
+	assert.Equal(t, int64(2), (<-subscription.Events()).Sequence)
+	assert.Empty(t, subscription.Events(), "events are sent once and only for the pickup point")
+}
+
+func TestHub_Resume(t *testing.T) {
+	hub := NewHub(3)
+	publish(t, hub, 2, 5, 9)
+
+	subscription, missed, complete := hub.Resume("pvz-1", 5)
+	subscription.Close()
+	assert.Equal(t, []int64{9}, sequences(missed))
+	assert.True(t, complete)
+
+	subscription, missed, complete = hub.Resume("pvz-1", 9)
+	subscription.Close()
+	assert.Empty(t, missed)
+	assert.True(t, complete)
+
+	publish(t, hub, 12)
+	subscription, missed, complete = hub.Resume("pvz-1", 2)
+	subscription.Close()
+	assert.Equal(t, []int64{5, 9, 12}, sequences(missed), "the oldest event is dropped from the buffer")
+	assert.False(t, complete, "events after 2 may have been dropped")
+
+	subscription, missed, complete = hub.Resume("pvz-2", 2)
+	subscription.Close()
+	assert.Empty(t, missed)
+	assert.False(t, complete, "the hub knows no events of the pickup point")
+}
+
+func TestHub_DropsSlowSubscribers(t *testing.T) {
+	hub := NewHub(0)
+	subscription := hub.Subscribe("pvz-1")
+
+	for sequence := int64(1); sequence <= subscriberBuffer+1; sequence++ {
+		publish(t, hub, sequence)
+	}
+
+	received := 0
+	for range subscription.Events() {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received, "the channel is closed after the buffered events")
+	subscription.Close()
+}
+
+func TestListener_Notify(t *testing.T) {
+	hub := NewHub(10)
+	listener := &Listener{hub: hub}
+	subscription := hub.Subscribe("pvz-1")
+
+	body, err := json.Marshal(testEvent(4, "pvz-1"))
+	require.NoError(t, err)
+	listener.notify(&pq.Notification{Extra: string(body)})
+	listener.notify(&pq.Notification{Extra: "not json"})
+
+	event := <-subscription.Events()
+	assert.Equal(t, testEvent(4, "pvz-1"), event)
+
+	listener.notify(nil)
+	_, open := <-subscription.Events()
+	assert.False(t, open, "a reconnect closes the subscriptions")
+	_, missed, complete := hub.Resume("pvz-1", 4)
+	assert.Empty(t, missed)
+	assert.False(t, complete)
+}
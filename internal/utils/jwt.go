@@ -8,19 +8,116 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+const (
+	twoFactorPurpose = "2fa"
+	streamPurpose    = "stream"
+)
+
 var JWTSecret = []byte(os.Getenv("JWT_SECRET"))
 
-func GenerateJWT(userID, role string) (string, error) {
+// GenerateJWT issues an access token. twoFactor records whether the login was
+// completed with a second factor.
+func GenerateJWT(userID, role string, tokenVersion int, twoFactor bool) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":       userID,
+		"role":          role,
+		"token_version": tokenVersion,
+		"two_factor":    twoFactor,
+		"exp":           time.Now().Add(24 * time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(JWTSecret)
+}
+
+// GenerateImpersonationJWT issues a short-lived access token of userID for
+// an administrator. The real actor is kept in the "act" claim (RFC 8693)
+// with the token version of their own session.
+func GenerateImpersonationJWT(userID, role string, tokenVersion int, actorID string, actorTokenVersion int, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":       userID,
+		"role":          role,
+		"token_version": tokenVersion,
+		"two_factor":    true,
+		"act":           actClaim(actorID, actorTokenVersion),
+		"exp":           expiresAt.Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(JWTSecret)
+}
+
+// GenerateTwoFactorJWT issues a short-lived token that only proves the
+// password step of a two-step login. It is not accepted as an access token.
+func GenerateTwoFactorJWT(userID string) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID,
-		"role":    role,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+		"purpose": twoFactorPurpose,
+		"exp":     time.Now().Add(5 * time.Minute).Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(JWTSecret)
 }
 
+// GenerateStreamJWT issues a short-lived token that opens the event streams
+// of one pickup point as the user. Browsers send it in the query, since
+// EventSource and WebSocket cannot set Authorization. It is not accepted as
+// an access token.
+func GenerateStreamJWT(userID, role string, tokenVersion int, twoFactor bool, actorID string, actorTokenVersion int, pvzID string, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":       userID,
+		"role":          role,
+		"token_version": tokenVersion,
+		"two_factor":    twoFactor,
+		"pvz_id":        pvzID,
+		"purpose":       streamPurpose,
+		"exp":           expiresAt.Unix(),
+	}
+	if actorID != "" {
+		claims["act"] = actClaim(actorID, actorTokenVersion)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(JWTSecret)
+}
+
+func actClaim(actorID string, actorTokenVersion int) map[string]interface{} {
+	return map[string]interface{}{"sub": actorID, "token_version": actorTokenVersion}
+}
+
 func ParseJWT(tokenStr string) (jwt.MapClaims, error) {
+	claims, err := parseJWT(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := claims["purpose"]; ok {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+func ParseTwoFactorJWT(tokenStr string) (string, error) {
+	claims, err := parseJWT(tokenStr)
+	if err != nil {
+		return "", err
+	}
+	userID, ok := claims["user_id"].(string)
+	if !ok || claims["purpose"] != twoFactorPurpose {
+		return "", errors.New("invalid token")
+	}
+	return userID, nil
+}
+
+// ParseStreamJWT returns the claims of a stream token of the pickup point.
+func ParseStreamJWT(tokenStr, pvzID string) (jwt.MapClaims, error) {
+	claims, err := parseJWT(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if claims["purpose"] != streamPurpose || claims["pvz_id"] != pvzID {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+func parseJWT(tokenStr string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("invalid signing method")
@@ -0,0 +1,24 @@
+package utils
+
+import "net/netip"
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which is not
+// reachable from the internet either.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicAddress reports whether addr is reachable on the internet, that is
+// not loopback, private, link-local (like the cloud metadata service at
+// 169.254.169.254), unspecified or multicast.
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr) &&
+		!(addr.Is4() && addr.As4()[0] == 0)
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicAddress(t *testing.T) {
+	for _, addr := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"} {
+		assert.True(t, IsPublicAddress(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{
+		"127.0.0.1", "10.0.0.1", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"0.0.0.0", "0.1.2.3", "100.64.0.1", "224.0.0.1",
+		"::1", "::", "fc00::1", "fe80::1", "::ffff:127.0.0.1",
+	} {
+		assert.False(t, IsPublicAddress(netip.MustParseAddr(addr)), addr)
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// CanonicalRequest builds the string an API client signs:
+//
+//	METHOD \n REQUEST-URI \n TIMESTAMP \n NONCE \n hex(sha256(body))
+func CanonicalRequest(method, uri, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		uri,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// SignRequest returns the hex HMAC-SHA256 of a canonical request with the
+// signing secret of the API key. The secret is not derived from the key, so
+// the stored key hash cannot forge signatures.
+func SignRequest(signingKey, canonical string) string {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of a webhook delivery, computed
+// over "TIMESTAMP.BODY" with the secret of the subscription.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
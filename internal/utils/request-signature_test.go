@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalRequest(t *testing.T) {
+	canonical := CanonicalRequest("post", "/receptions?x=1", "1700000000", "nonce", []byte(`{}`))
+	assert.Equal(t,
+		"POST\n/receptions?x=1\n1700000000\nnonce\n44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a",
+		canonical)
+}
+
+func TestSignRequest(t *testing.T) {
+	signingKey := HashToken("pvz_key")
+	signature := SignRequest(signingKey, "POST\n/receptions")
+
+	assert.Len(t, signature, 64)
+	assert.Equal(t, signature, SignRequest(signingKey, "POST\n/receptions"))
+	assert.NotEqual(t, signature, SignRequest(signingKey, "POST\n/products"))
+	assert.NotEqual(t, signature, SignRequest(HashToken("other_key"), "POST\n/receptions"))
+}
+
+func TestSignWebhook(t *testing.T) {
+	signature := SignWebhook("whsec_secret", "1700000000", []byte(`{"id":"event"}`))
+
+	assert.Equal(t, "b201c2f5532945c030db6360c905b316efee9ab61373e1a5b1472c9a992dea15", signature)
+	assert.NotEqual(t, signature, SignWebhook("whsec_secret", "1700000001", []byte(`{"id":"event"}`)))
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vectors from RFC 6238, appendix B (SHA1), truncated to 6 digits.
+func TestTOTPCode_RFC6238(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := TOTPCode(secret, TOTPStep(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	assert.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	previous, _ := TOTPCode(secret, TOTPStep(now)-1)
+	step, ok := ValidateTOTP(secret, previous, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, TOTPStep(now)-1, step)
+
+	stale, _ := TOTPCode(secret, TOTPStep(now)-2)
+	_, ok = ValidateTOTP(secret, stale, now, 1)
+	assert.False(t, ok)
+
+	_, ok = ValidateTOTP("not base32!", "123456", now, 1)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("PVZ Service", "moderator@example.com", "SECRET")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/PVZ%20Service:moderator@example.com?"))
+	assert.Contains(t, uri, "secret=SECRET")
+	assert.Contains(t, uri, "issuer=PVZ+Service")
+}
@@ -0,0 +1,257 @@
+package webhook
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/metrics"
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultBatchSize    = 50
+	defaultPollInterval = time.Second
+	defaultLease        = time.Minute
+	defaultTimeout      = 10 * time.Second
+	defaultBaseBackoff  = 10 * time.Second
+	defaultMaxBackoff   = time.Hour
+	defaultMaxAttempts  = 8
+
+	// maxErrorBody is how much of a rejecting response is kept in the
+	// delivery log.
+	maxErrorBody = 256
+)
+
+// Headers of a delivery. The signature is "v1=" and SignWebhook of the
+// timestamp and the body with the secret of the subscription. The ID is the
+// event ID and stays the same on retries and resends.
+const (
+	HeaderID        = "Webhook-Id"
+	HeaderEvent     = "Webhook-Event"
+	HeaderTimestamp = "Webhook-Timestamp"
+	HeaderSignature = "Webhook-Signature"
+)
+
+// DispatcherPolicy tunes the dispatcher. Zero fields get the defaults.
+type DispatcherPolicy struct {
+	BatchSize    int
+	PollInterval time.Duration
+	// Lease is how long claimed deliveries are reserved for this
+	// dispatcher. It has to be longer than Timeout.
+	Lease       time.Duration
+	Timeout     time.Duration
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// MaxAttempts failed attempts move a delivery to the dead letters.
+	MaxAttempts int
+}
+
+// Dispatcher sends queued deliveries to the subscriptions. A batch is sent
+// concurrently; failed deliveries are retried with exponential backoff.
+type Dispatcher struct {
+	repo   repository.WebhookRepositoryInterface
+	client *http.Client
+	policy DispatcherPolicy
+	now    func() time.Time
+}
+
+// NewDispatcher creates a dispatcher sending with client. A nil client gets
+// NewClient with the policy Timeout.
+func NewDispatcher(repo repository.WebhookRepositoryInterface, client *http.Client, policy DispatcherPolicy) *Dispatcher {
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = defaultBatchSize
+	}
+	if policy.PollInterval <= 0 {
+		policy.PollInterval = defaultPollInterval
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = defaultTimeout
+	}
+	if policy.Lease <= policy.Timeout {
+		policy.Lease = max(defaultLease, 2*policy.Timeout)
+	}
+	if policy.BaseBackoff <= 0 {
+		policy.BaseBackoff = defaultBaseBackoff
+	}
+	if policy.MaxBackoff < policy.BaseBackoff {
+		policy.MaxBackoff = max(defaultMaxBackoff, policy.BaseBackoff)
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultMaxAttempts
+	}
+	if client == nil {
+		client = NewClient(policy.Timeout)
+	}
+	return &Dispatcher{
+		repo:   repo,
+		client: client,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// NewClient returns a client that connects only to public addresses and
+// does not follow redirects, so that subscriptions cannot reach internal
+// services. The address is checked when dialing, after DNS resolution,
+// which a hostname pointed at an internal address later cannot evade.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialPublicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !utils.IsPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%s is not a public address", addrPort.Addr())
+	}
+	return nil
+}
+
+// Run sends deliveries until ctx is done. Full batches are followed by the
+// next one right away, otherwise the dispatcher waits for PollInterval.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		claimed, err := d.dispatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Webhook dispatcher: %v", err)
+		}
+		if err == nil && claimed == d.policy.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.policy.PollInterval):
+		}
+	}
+}
+
+// dispatch sends one batch of due deliveries and returns how many it
+// claimed.
+func (d *Dispatcher) dispatch(ctx context.Context) (int, error) {
+	now := d.now()
+	deliveries, err := d.repo.ClaimWebhookDeliveries(d.policy.BatchSize, now, now.Add(d.policy.Lease))
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := make(map[string]*models.WebhookSubscription)
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			subscription, err = d.repo.GetWebhookSubscription(delivery.SubscriptionID)
+			// The deliveries of a deleted subscription are deleted with it.
+			if errors.Is(err, internalErrors.ErrWebhookNotFound) {
+				continue
+			}
+			if err != nil {
+				wg.Wait()
+				return len(deliveries), err
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.deliver(ctx, subscription, delivery); err != nil {
+				log.Printf("Webhook dispatcher: could not record delivery %s: %v", delivery.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// deliver sends the delivery once and records the outcome. Deliveries of a
+// stopped dispatcher are claimed again once their lease expires.
+func (d *Dispatcher) deliver(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) error {
+	status, err := d.send(ctx, subscription, delivery)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	now := d.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = status
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		metrics.WebhookDeliveryCount.WithLabelValues(models.WebhookDeliveryDelivered).Inc()
+	case delivery.Attempts >= d.policy.MaxAttempts:
+		delivery.Status = models.WebhookDeliveryDead
+		delivery.LastError = err.Error()
+		metrics.WebhookDeliveryCount.WithLabelValues(models.WebhookDeliveryDead).Inc()
+	default:
+		delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+		delivery.LastError = err.Error()
+		metrics.WebhookDeliveryCount.WithLabelValues("failed").Inc()
+	}
+	return d.repo.RecordWebhookAttempt(delivery)
+}
+
+// send posts the signed event and returns the response status, zero if
+// there was no response. Statuses other than 2xx are errors.
+func (d *Dispatcher) send(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pvz-webhooks")
+	req.Header.Set(HeaderID, delivery.EventID)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "v1="+utils.SignWebhook(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("answered %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	return resp.StatusCode, nil
+}
+
+// backoff doubles the delay with each failed attempt, up to MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.policy.BaseBackoff
+	for i := 1; i < attempts && delay < d.policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.policy.MaxBackoff)
+}
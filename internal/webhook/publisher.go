@@ -0,0 +1,60 @@
+// Package webhook delivers domain events to the webhook subscriptions of
+// partners. The outbox relay hands events to the Publisher, which queues a
+// delivery for every matching subscription; the Dispatcher sends them.
+package webhook
+
+import (
+	"avito-intern/internal/models"
+	"avito-intern/internal/repository"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Publisher is the outbox.Publisher of webhooks. Queuing an event again
+// does not duplicate its deliveries.
+type Publisher struct {
+	repo  repository.WebhookRepositoryInterface
+	now   func() time.Time
+	newID func() string
+}
+
+func NewPublisher(repo repository.WebhookRepositoryInterface) *Publisher {
+	return &Publisher{
+		repo:  repo,
+		now:   time.Now,
+		newID: func() string { return uuid.New().String() },
+	}
+}
+
+func (p *Publisher) Publish(_ context.Context, event *models.OutboxEvent) error {
+	subscriptions, err := p.repo.ListWebhookSubscriptions()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	now := p.now()
+	var deliveries []*models.WebhookDelivery
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(event) {
+			continue
+		}
+		deliveries = append(deliveries, &models.WebhookDelivery{
+			ID:             p.newID(),
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			PvzID:          event.PvzID,
+			Payload:        body,
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+	return p.repo.CreateWebhookDeliveries(deliveries)
+}
@@ -0,0 +1,303 @@
+package webhook
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"avito-intern/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWebhookRepository struct {
+	mu            sync.Mutex
+	subscriptions []*models.WebhookSubscription
+	deliveries    []*models.WebhookDelivery
+}
+
+func (m *mockWebhookRepository) CreateWebhookSubscription(subscription *models.WebhookSubscription) error {
+	m.subscriptions = append(m.subscriptions, subscription)
+	return nil
+}
+
+func (m *mockWebhookRepository) GetWebhookSubscription(id string) (*models.WebhookSubscription, error) {
+	for _, subscription := range m.subscriptions {
+		if subscription.ID == id {
+			return subscription, nil
+		}
+	}
+	return nil, internalErrors.ErrWebhookNotFound
+}
+
+func (m *mockWebhookRepository) ListWebhookSubscriptions() ([]*models.WebhookSubscription, error) {
+	return m.subscriptions, nil
+}
+
+func (m *mockWebhookRepository) DeleteWebhookSubscription(string) error {
+	return nil
+}
+
+// CreateWebhookDeliveries skips events already queued for a subscription,
+// like the unique constraint.
+func (m *mockWebhookRepository) CreateWebhookDeliveries(deliveries []*models.WebhookDelivery) error {
+	for _, delivery := range deliveries {
+		if m.find(func(d *models.WebhookDelivery) bool {
+			return d.SubscriptionID == delivery.SubscriptionID && d.EventID == delivery.EventID
+		}) == nil {
+			m.deliveries = append(m.deliveries, delivery)
+		}
+	}
+	return nil
+}
+
+func (m *mockWebhookRepository) ClaimWebhookDeliveries(limit int, now, leaseUntil time.Time) ([]*models.WebhookDelivery, error) {
+	var claimed []*models.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if len(claimed) == limit || delivery.Status != models.WebhookDeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.NextAttemptAt = leaseUntil
+		copied := *delivery
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (m *mockWebhookRepository) RecordWebhookAttempt(delivery *models.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := m.find(func(d *models.WebhookDelivery) bool { return d.ID == delivery.ID })
+	*stored = *delivery
+	return nil
+}
+
+func (m *mockWebhookRepository) ListWebhookDeliveries(string, string, int, int) ([]*models.WebhookDelivery, error) {
+	return m.deliveries, nil
+}
+
+func (m *mockWebhookRepository) ResendWebhookDelivery(string, time.Time) (*models.WebhookDelivery, error) {
+	return nil, internalErrors.ErrDeliveryNotFound
+}
+
+func (m *mockWebhookRepository) find(match func(*models.WebhookDelivery) bool) *models.WebhookDelivery {
+	for _, delivery := range m.deliveries {
+		if match(delivery) {
+			return delivery
+		}
+	}
+	return nil
+}
+
+func testEvent(id, eventType, pvzID string) *models.OutboxEvent {
+	return &models.OutboxEvent{
+		Sequence:   1,
+		ID:         id,
+		Type:       eventType,
+		PvzID:      pvzID,
+		Payload:    json.RawMessage(`{"id":"reception-1"}`),
+		OccurredAt: time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	repo := &mockWebhookRepository{subscriptions: []*models.WebhookSubscription{
+		{ID: "all"},
+		{ID: "receptions", EventTypes: []string{models.EventReceptionOpened, models.EventReceptionClosed}},
+		{ID: "other-pvz", PvzIDs: []string{"pvz-2"}},
+	}}
+	publisher := NewPublisher(repo)
+	ids := 0
+	publisher.newID = func() string {
+		ids++
+		return fmt.Sprintf("delivery-%d", ids)
+	}
+
+	event := testEvent("event-1", models.EventReceptionOpened, "pvz-1")
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	require.NoError(t, publisher.Publish(context.Background(), event))
+
+	require.Len(t, repo.deliveries, 2, "a republished event is queued once")
+	assert.Equal(t, "all", repo.deliveries[0].SubscriptionID)
+	assert.Equal(t, "receptions", repo.deliveries[1].SubscriptionID)
+	assert.Equal(t, models.WebhookDeliveryPending, repo.deliveries[0].Status)
+	assert.JSONEq(t, `{
+		"sequence": 1,
+		"id": "event-1",
+		"type": "reception.opened",
+		"pvzId": "pvz-1",
+		"payload": {"id": "reception-1"},
+		"occurredAt": "2026-10-18T12:00:00Z"
+	}`, string(repo.deliveries[0].Payload))
+}
+
+// newTestDispatcher sends with NewClient, except that the test servers on
+// the loopback interface may be dialed.
+func newTestDispatcher(repo *mockWebhookRepository, clock *time.Time) *Dispatcher {
+	client := NewClient(time.Second)
+	client.Transport.(*http.Transport).DialContext = (&net.Dialer{}).DialContext
+	dispatcher := NewDispatcher(repo, client, DispatcherPolicy{
+		BaseBackoff: time.Minute,
+		MaxBackoff:  time.Hour,
+		MaxAttempts: 3,
+	})
+	dispatcher.now = func() time.Time { return *clock }
+	return dispatcher
+}
+
+func queue(t *testing.T, repo *mockWebhookRepository, url string) {
+	repo.subscriptions = append(repo.subscriptions, &models.WebhookSubscription{ID: "subscription-1", URL: url, Secret: "whsec_secret"})
+	publisher := NewPublisher(repo)
+	publisher.now = func() time.Time { return time.Date(2026, time.October, 18, 11, 0, 0, 0, time.UTC) }
+	publisher.newID = func() string { return "delivery-1" }
+	require.NoError(t, publisher.Publish(context.Background(), testEvent("event-1", models.EventProductAdded, "pvz-1")))
+}
+
+func TestDispatcher_SignsDeliveries(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	repo := &mockWebhookRepository{}
+	queue(t, repo, server.URL+"/hooks")
+	clock := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	claimed, err := newTestDispatcher(repo, &clock).dispatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	assert.Equal(t, "/hooks", received.URL.Path)
+	assert.Equal(t, "event-1", received.Header.Get(HeaderID))
+	assert.Equal(t, models.EventProductAdded, received.Header.Get(HeaderEvent))
+	assert.Equal(t, "1792324800", received.Header.Get(HeaderTimestamp))
+	assert.Equal(t, "v1="+utils.SignWebhook("whsec_secret", "1792324800", body), received.Header.Get(HeaderSignature))
+
+	delivery := repo.deliveries[0]
+	assert.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+	assert.Equal(t, clock, *delivery.DeliveredAt)
+}
+
+func TestDispatcher_RetriesAndDeadLetters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	repo := &mockWebhookRepository{}
+	queue(t, repo, server.URL)
+	clock := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	dispatcher := newTestDispatcher(repo, &clock)
+	delivery := repo.deliveries[0]
+
+	_, err := dispatcher.dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatus)
+	assert.Equal(t, "answered 503: maintenance", delivery.LastError)
+	assert.Equal(t, clock.Add(time.Minute), delivery.NextAttemptAt)
+
+	claimed, err := dispatcher.dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, claimed, "not due before the backoff")
+
+	clock = clock.Add(time.Minute)
+	_, err = dispatcher.dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, clock.Add(2*time.Minute), delivery.NextAttemptAt, "the backoff doubles")
+
+	clock = clock.Add(2 * time.Minute)
+	_, err = dispatcher.dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryDead, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Nil(t, delivery.DeliveredAt)
+}
+
+func TestDispatcher_UnreachableURL(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	repo := &mockWebhookRepository{}
+	queue(t, repo, server.URL)
+	clock := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	_, err := newTestDispatcher(repo, &clock).dispatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Zero(t, repo.deliveries[0].ResponseStatus)
+	assert.Contains(t, repo.deliveries[0].LastError, "connection refused")
+}
+
+func TestDispatcher_RefusesInternalAddresses(t *testing.T) {
+	served := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = true
+	}))
+	defer server.Close()
+	repo := &mockWebhookRepository{}
+	queue(t, repo, server.URL)
+	dispatcher := NewDispatcher(repo, nil, DispatcherPolicy{})
+
+	_, err := dispatcher.dispatch(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, served)
+	assert.Contains(t, repo.deliveries[0].LastError, "127.0.0.1 is not a public address")
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			followed = true
+			return
+		}
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+	repo := &mockWebhookRepository{}
+	queue(t, repo, server.URL)
+	clock := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	_, err := newTestDispatcher(repo, &clock).dispatch(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, followed)
+	assert.Equal(t, http.StatusTemporaryRedirect, repo.deliveries[0].ResponseStatus)
+	assert.Equal(t, models.WebhookDeliveryPending, repo.deliveries[0].Status)
+}
+
+func TestDispatcher_StoppedDispatcherLeavesDeliveriesClaimed(t *testing.T) {
+	repo := &mockWebhookRepository{}
+	queue(t, repo, "http://127.0.0.1:0")
+	clock := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := newTestDispatcher(repo, &clock).dispatch(ctx)
+
+	require.NoError(t, err)
+	assert.Zero(t, repo.deliveries[0].Attempts, "the lease expires instead of counting a failure")
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	dispatcher := NewDispatcher(nil, nil, DispatcherPolicy{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	assert.Equal(t, time.Second, dispatcher.backoff(1))
+	assert.Equal(t, 4*time.Second, dispatcher.backoff(3))
+	assert.Equal(t, 10*time.Second, dispatcher.backoff(5))
+	assert.Equal(t, 10*time.Second, dispatcher.backoff(64))
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Login logs in with email and password. The token is kept for the next
+// requests unless the account has a second factor, in which case the result
+// holds the TwoFactorToken for LoginTwoFactor.
+func (c *Client) Login(ctx context.Context, email, password string) (*LoginResult, error) {
+	var result LoginResult
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/login",
+		body:   map[string]string{"email": email, "password": password},
+		public: true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Token != "" {
+		c.SetToken(result.Token)
+	}
+	return &result, nil
+}
+
+// LoginTwoFactor completes a login with a TOTP or recovery code and keeps
+// the token.
+func (c *Client) LoginTwoFactor(ctx context.Context, twoFactorToken, code string) (*LoginResult, error) {
+	var result LoginResult
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/login/2fa",
+		body:   map[string]string{"twoFactorToken": twoFactorToken, "code": code},
+		public: true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	c.SetToken(result.Token)
+	return &result, nil
+}
+
+// DummyLogin gets and keeps a token of a throwaway user with role, for
+// development servers that enable it.
+func (c *Client) DummyLogin(ctx context.Context, role string) (string, error) {
+	var result LoginResult
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/dummyLogin",
+		body:   map[string]string{"role": role},
+		public: true,
+	}, &result)
+	if err != nil {
+		return "", err
+	}
+	c.SetToken(result.Token)
+	return result.Token, nil
+}
+
+func (c *Client) Register(ctx context.Context, req RegisterRequest) (*User, error) {
+	var user User
+	err := c.do(ctx, call{method: http.MethodPost, path: "/register", body: req, public: true}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ForgotPassword asks for a password reset link. It succeeds for unknown
+// emails too.
+func (c *Client) ForgotPassword(ctx context.Context, email string) error {
+	return c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/password/forgot",
+		body:   map[string]string{"email": email},
+		public: true,
+	}, nil)
+}
+
+// ResetPassword sets a new password with the token of a reset link.
+func (c *Client) ResetPassword(ctx context.Context, token, password string) error {
+	return c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/password/reset",
+		body:   map[string]string{"token": token, "password": password},
+		public: true,
+	}, nil)
+}
+
+// EnrollTwoFactor starts the enrollment of a TOTP second factor for the
+// current user.
+func (c *Client) EnrollTwoFactor(ctx context.Context) (*TwoFactorEnrollment, error) {
+	var enrollment TwoFactorEnrollment
+	if err := c.do(ctx, call{method: http.MethodPost, path: "/2fa/enroll", credentials: true}, &enrollment); err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// ConfirmTwoFactor completes the enrollment with a TOTP code and keeps the
+// returned token, which carries the second factor.
+func (c *Client) ConfirmTwoFactor(ctx context.Context, code string) (*TwoFactorConfirmation, error) {
+	var confirmation TwoFactorConfirmation
+	err := c.do(ctx, call{
+		method:      http.MethodPost,
+		path:        "/2fa/confirm",
+		body:        map[string]string{"code": code},
+		credentials: true,
+	}, &confirmation)
+	if err != nil {
+		return nil, err
+	}
+	c.SetToken(confirmation.Token)
+	return &confirmation, nil
+}
+
+// loginWithCredentials logs in with Options.Credentials. Concurrent requests
+// that find the token stale log in once.
+func (c *Client) loginWithCredentials(ctx context.Context) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+	if c.hasFreshToken() {
+		return nil
+	}
+
+	credentials := c.options.Credentials
+	result, err := c.Login(ctx, credentials.Email, credentials.Password)
+	if err != nil {
+		return err
+	}
+	if !result.TwoFactorRequired {
+		return nil
+	}
+	if credentials.TwoFactorCode == nil {
+		return errors.New("client: the account has a second factor but Credentials.TwoFactorCode is not set")
+	}
+	code, err := credentials.TwoFactorCode(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.LoginTwoFactor(ctx, result.TwoFactorToken, code)
+	return err
+}
@@ -0,0 +1,355 @@
+// Package client is a typed Go client for the PVZ service API.
+//
+// A Client authenticates with a bearer token, an API key or the email and
+// password of a user, in which case it logs in by itself and logs in again
+// when the token is about to expire or is rejected. Requests that fail with a
+// transient error are retried when that is safe: reads, and POST requests
+// with an Idempotency-Key, which stays the same across the retries so that
+// the operation is applied once. Authentication and operations that return
+// credentials carry no key and, like other changes, are not retried, since
+// the server may have applied them before the failure.
+// Errors of the API are returned as *Error.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// APIVersion is the version of the API the client calls.
+	APIVersion = "v1"
+
+	DefaultRetries      = 3
+	DefaultRetryBackoff = 200 * time.Millisecond
+
+	maxRetryDelay = 10 * time.Second
+	// tokenRefreshMargin is how long before its expiry a token obtained with
+	// Credentials is replaced.
+	tokenRefreshMargin = time.Minute
+)
+
+// Credentials let the client log in by itself. TwoFactorCode is needed for
+// accounts with a second factor and returns the current TOTP code.
+type Credentials struct {
+	Email         string
+	Password      string
+	TwoFactorCode func(ctx context.Context) (string, error)
+}
+
+// Options configure a Client. At most one of Token, APIKey and Credentials
+// is expected; without any of them only the login endpoints can be used
+// until Login or SetToken is called.
+type Options struct {
+	// HTTPClient sends the requests; http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// Token is a bearer token, e.g. from a previous login.
+	Token string
+	// APIKey is a service API key sent as "Authorization: ApiKey <key>".
+	APIKey      string
+	Credentials *Credentials
+	// Retries is how many times a read or a request with an Idempotency-Key
+	// is repeated after a transient failure: a network error, 429, 502, 503,
+	// 504 or a request with the same Idempotency-Key still in progress. Zero
+	// means DefaultRetries, a negative value disables retries.
+	Retries int
+	// RetryBackoff is the delay before the first retry, doubled for every
+	// next one unless the response has Retry-After. Zero means
+	// DefaultRetryBackoff.
+	RetryBackoff time.Duration
+	// AcceptLanguage selects the language of error titles, "en" or "ru".
+	AcceptLanguage string
+}
+
+type Client struct {
+	baseURL *url.URL
+	options Options
+	http    *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+	loginMu     sync.Mutex
+}
+
+// New returns a client of the API served at baseURL, e.g.
+// https://pvz.example.com. The version prefix is added by the client.
+func New(baseURL string, options Options) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", baseURL)
+	}
+	if options.Retries == 0 {
+		options.Retries = DefaultRetries
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = DefaultRetryBackoff
+	}
+	c := &Client{baseURL: u, options: options, http: options.HTTPClient}
+	if c.http == nil {
+		c.http = http.DefaultClient
+	}
+	if options.Token != "" {
+		c.SetToken(options.Token)
+	}
+	return c, nil
+}
+
+// Token returns the bearer token the client currently uses.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// SetToken makes the client use token for the next requests.
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+	c.tokenExpiry = tokenExpiry(token)
+}
+
+type idempotencyKeyContext struct{}
+
+// WithIdempotencyKey sets the Idempotency-Key of the POST requests made with
+// ctx instead of a generated one, e.g. to repeat an operation after a crash.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContext{}, key)
+}
+
+// call is one API operation.
+type call struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	// public operations are sent without credentials.
+	public bool
+	// credentials marks operations whose responses carry credentials, like
+	// tokens and secrets. The server keeps no responses of them for retries,
+	// so they are sent without an Idempotency-Key, as are public ones.
+	credentials bool
+}
+
+// do performs the call and decodes the response body into out unless out
+// is nil.
+func (c *Client) do(ctx context.Context, op call, out interface{}) error {
+	var body []byte
+	if op.body != nil {
+		var err error
+		if body, err = json.Marshal(op.body); err != nil {
+			return err
+		}
+	}
+	var idempotencyKey string
+	if op.method == http.MethodPost && !op.public && !op.credentials {
+		idempotencyKey, _ = ctx.Value(idempotencyKeyContext{}).(string)
+		if idempotencyKey == "" {
+			idempotencyKey = uuid.NewString()
+		}
+	}
+
+	retries := c.options.Retries
+	if idempotencyKey == "" && !safeMethod(op.method) {
+		retries = 0
+	}
+
+	relogged := false
+	for attempt := 0; ; attempt++ {
+		var authorization string
+		if !op.public {
+			var err error
+			if authorization, err = c.authorization(ctx); err != nil {
+				return err
+			}
+		}
+
+		resp, err := c.send(ctx, op, body, authorization, idempotencyKey)
+		if err != nil {
+			if ctx.Err() != nil || attempt >= retries {
+				return err
+			}
+			if err := c.wait(ctx, attempt, 0); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out == nil || resp.StatusCode == http.StatusNoContent {
+				return nil
+			}
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return fmt.Errorf("decode response: %w", err)
+			}
+			return nil
+		}
+
+		apiErr := readError(resp)
+		switch {
+		case apiErr.Status == http.StatusUnauthorized && !op.public && !relogged && c.options.Credentials != nil:
+			// The token was revoked or expired early; log in again once.
+			relogged = true
+			c.SetToken("")
+			attempt--
+			continue
+		case retryable(apiErr) && attempt < retries:
+			if err := c.wait(ctx, attempt, apiErr.RetryAfter); err != nil {
+				return err
+			}
+			continue
+		}
+		return apiErr
+	}
+}
+
+func (c *Client) send(ctx context.Context, op call, body []byte, authorization, idempotencyKey string) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += "/" + APIVersion + op.path
+	u.RawQuery = op.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, op.method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	if c.options.AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", c.options.AcceptLanguage)
+	}
+	return c.http.Do(req)
+}
+
+// authorization returns the Authorization header, logging in with the
+// credentials first if there is no token or it is about to expire.
+func (c *Client) authorization(ctx context.Context) (string, error) {
+	if c.options.APIKey != "" {
+		return "ApiKey " + c.options.APIKey, nil
+	}
+	if !c.hasFreshToken() && c.options.Credentials != nil {
+		if err := c.loginWithCredentials(ctx); err != nil {
+			return "", err
+		}
+	}
+	token := c.Token()
+	if token == "" {
+		return "", nil
+	}
+	return "Bearer " + token, nil
+}
+
+// hasFreshToken reports whether the client has a token that does not expire
+// within tokenRefreshMargin.
+func (c *Client) hasFreshToken() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == "" {
+		return false
+	}
+	return c.tokenExpiry.IsZero() || time.Until(c.tokenExpiry) >= tokenRefreshMargin
+}
+
+// wait sleeps before the retry after attempt, for retryAfter if the server
+// gave it and with exponential backoff otherwise.
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	delay := retryAfter
+	if delay <= 0 {
+		delay = c.options.RetryBackoff << attempt
+		delay += rand.N(delay/2 + 1)
+	}
+	delay = min(delay, maxRetryDelay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// safeMethod reports whether requests of method only read, so that repeating
+// them cannot apply a change twice.
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func retryable(err *Error) bool {
+	switch err.Status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusConflict:
+		return err.Code == IdempotencyInProgress
+	}
+	return false
+}
+
+// readError turns an unsuccessful response into an *Error.
+func readError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	apiErr := &Error{}
+	if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Code == "" {
+		apiErr = &Error{Message: strings.TrimSpace(string(body))}
+	}
+	apiErr.Status = resp.StatusCode
+	if apiErr.Title == "" {
+		apiErr.Title = http.StatusText(resp.StatusCode)
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it; the server
+// does that. A token without one gives the zero time.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
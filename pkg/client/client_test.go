@@ -0,0 +1,492 @@
+package client
+
+import (
+	"avito-intern/pkg/pvz"
+	"avito-intern/pkg/pvz/pvztest"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	adminEmail    = "admin@test.com"
+	adminPassword = "password"
+)
+
+// newTestServer serves the real router with services on in-memory
+// repositories. wrap, if set, sits in front of the router.
+func newTestServer(t *testing.T, wrap func(http.Handler) http.Handler) (*httptest.Server, pvz.Repositories) {
+	repos := pvztest.NewRepositories()
+	options := pvz.DefaultOptions()
+	options.Registration.DummyLogin = true
+	options.ValidateResponses = true
+	options.Lockout = pvz.LockoutPolicy{
+		AccountThreshold: 5,
+		IPThreshold:      20,
+		LockoutDuration:  time.Minute,
+	}
+	app, err := pvz.New(repos, options)
+	require.NoError(t, err)
+	require.NoError(t, app.Services.Auth.EnsureAdmin(adminEmail, adminPassword))
+
+	handler := app.Handler
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server, repos
+}
+
+func newTestClient(t *testing.T, server *httptest.Server, options Options) *Client {
+	if options.RetryBackoff == 0 {
+		options.RetryBackoff = time.Millisecond
+	}
+	c, err := New(server.URL, options)
+	require.NoError(t, err)
+	return c
+}
+
+// newUserClient registers a user with role and returns a client that logs in
+// with its credentials.
+func newUserClient(t *testing.T, server *httptest.Server, admin *Client, email, role string) *Client {
+	ctx := context.Background()
+	req := RegisterRequest{Email: email, Password: "password", Role: role}
+	if role != RoleEmployee {
+		invite, err := admin.CreateInvite(ctx, email, role)
+		require.NoError(t, err)
+		req.InviteToken = invite.Token
+	}
+	user, err := newTestClient(t, server, Options{}).Register(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, email, user.Email)
+
+	return newTestClient(t, server, Options{Credentials: &Credentials{Email: email, Password: "password"}})
+}
+
+func adminClient(t *testing.T, server *httptest.Server) *Client {
+	return newTestClient(t, server, Options{Credentials: &Credentials{Email: adminEmail, Password: adminPassword}})
+}
+
+func TestClient_ReceptionWorkflow(t *testing.T) {
+	ctx := context.Background()
+	server, _ := newTestServer(t, nil)
+	admin := adminClient(t, server)
+	moderator := newUserClient(t, server, admin, "moderator@test.com", RoleModerator)
+	employee := newUserClient(t, server, admin, "employee@test.com", RoleEmployee)
+
+	pvz, err := moderator.CreatePVZ(ctx, "Москва")
+	require.NoError(t, err)
+	assert.NotEmpty(t, pvz.ID)
+	assert.Equal(t, 1, pvz.Version)
+
+	pvz, err = moderator.UpdatePVZ(ctx, pvz.ID, "Казань", pvz.Version)
+	require.NoError(t, err)
+	assert.Equal(t, "Казань", pvz.City)
+	assert.Equal(t, 2, pvz.Version)
+
+	_, err = moderator.UpdatePVZ(ctx, pvz.ID, "Москва", 1)
+	require.ErrorIs(t, err, VersionConflict)
+	var conflict *Error
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, http.StatusConflict, conflict.Status)
+	var current PVZ
+	require.NoError(t, conflict.DecodeCurrent(&current))
+	assert.Equal(t, *pvz, current)
+
+	got, err := employee.GetPVZ(ctx, pvz.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pvz.City, got.City)
+
+	reception, err := employee.CreateReception(ctx, pvz.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ReceptionInProgress, reception.Status)
+
+	for _, productType := range []string{"электроника", "одежда", "обувь"} {
+		product, err := employee.AddProduct(ctx, pvz.ID, productType)
+		require.NoError(t, err)
+		assert.Equal(t, reception.ID, product.ReceptionID)
+	}
+	require.NoError(t, employee.DeleteLastProduct(ctx, pvz.ID))
+
+	closed, err := employee.CloseLastReception(ctx, pvz.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ReceptionClosed, closed.Status)
+
+	_, err = employee.CloseLastReception(ctx, pvz.ID)
+	assert.ErrorIs(t, err, NoActiveReception)
+
+	receptions, err := moderator.ListPVZReceptions(ctx, pvz.ID)
+	require.NoError(t, err)
+	require.Len(t, receptions, 1)
+	assert.Equal(t, reception.ID, receptions[0].Reception.ID)
+	assert.Equal(t, ReceptionClosed, receptions[0].Reception.Status)
+	require.Len(t, receptions[0].Products, 2)
+	assert.Equal(t, "одежда", receptions[0].Products[1].Type)
+
+	_, err = employee.CreatePVZ(ctx, "Москва")
+	assert.ErrorIs(t, err, AccessDenied)
+}
+
+func TestClient_IteratePVZ(t *testing.T) {
+	ctx := context.Background()
+	server, _ := newTestServer(t, nil)
+	moderator := newUserClient(t, server, adminClient(t, server), "moderator@test.com", RoleModerator)
+
+	created := make(map[string]bool)
+	for i := 0; i < maxPVZPage+5; i++ {
+		pvz, err := moderator.CreatePVZ(ctx, "Казань")
+		require.NoError(t, err)
+		created[pvz.ID] = true
+	}
+
+	page, err := moderator.ListPVZ(ctx, PVZFilter{}, Page{Number: 2, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page, 10)
+
+	seen := make(map[string]bool)
+	for pvz, err := range moderator.IteratePVZ(ctx, PVZFilter{}) {
+		require.NoError(t, err)
+		seen[pvz.ID] = true
+	}
+	assert.Equal(t, created, seen)
+
+	count := 0
+	for _, err := range moderator.IteratePVZ(ctx, PVZFilter{EndDate: time.Now().Add(-time.Hour)}) {
+		require.NoError(t, err)
+		count++
+	}
+	assert.Zero(t, count, "the filter is applied")
+}
+
+func TestClient_IterateUsersAndAudit(t *testing.T) {
+	ctx := context.Background()
+	server, _ := newTestServer(t, nil)
+	admin := adminClient(t, server)
+	for i := 0; i < 3; i++ {
+		newUserClient(t, server, admin, fmt.Sprintf("employee%d@test.com", i), RoleEmployee)
+	}
+
+	var emails []string
+	for user, err := range admin.IterateUsers(ctx, UserFilter{Role: RoleEmployee}) {
+		require.NoError(t, err)
+		emails = append(emails, user.Email)
+	}
+	assert.Equal(t, []string{"employee0@test.com", "employee1@test.com", "employee2@test.com"}, emails)
+
+	employees, err := admin.ListUsers(ctx, UserFilter{Role: RoleEmployee}, Page{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, employees, 2)
+
+	impersonation, err := admin.Impersonate(ctx, employees[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, employees[0].ID, impersonation.UserID)
+
+	var actions []string
+	for event, err := range admin.IterateAuditEvents(ctx, AuditFilter{UserID: employees[0].ID}) {
+		require.NoError(t, err)
+		actions = append(actions, event.Action)
+	}
+	assert.Equal(t, []string{"impersonation.start"}, actions)
+
+	user, err := admin.DeactivateUser(ctx, employees[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "deactivated", user.Status)
+}
+
+func TestClient_RetriesWithIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	var keys []string
+	dropped := false
+	server, repos := newTestServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != "/v1/pvz" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			mu.Lock()
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+			drop := !dropped
+			dropped = true
+			mu.Unlock()
+
+			if drop {
+				// The PVZ is created but the response is lost.
+				next.ServeHTTP(httptest.NewRecorder(), r)
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	moderator := newUserClient(t, server, adminClient(t, server), "moderator@test.com", RoleModerator)
+
+	pvz, err := moderator.CreatePVZ(ctx, "Москва")
+	require.NoError(t, err)
+	assert.Equal(t, "Москва", pvz.City)
+
+	require.Len(t, keys, 2)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1], "the retry reuses the key")
+	count, err := repos.PVZ.CountPVZ()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "the PVZ is created once")
+
+	_, err = moderator.CreatePVZ(WithIdempotencyKey(ctx, "order-1"), "Казань")
+	require.NoError(t, err)
+	assert.Equal(t, "order-1", keys[2])
+}
+
+func TestClient_NoIdempotencyKeyForCredentials(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	keys := make(map[string]string)
+	server, _ := newTestServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				mu.Lock()
+				keys[r.URL.Path] = r.Header.Get("Idempotency-Key")
+				mu.Unlock()
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	moderator := newUserClient(t, server, adminClient(t, server), "moderator@test.com", RoleModerator)
+
+	_, err := moderator.CreatePVZ(ctx, "Москва")
+	require.NoError(t, err)
+
+	assert.Empty(t, keys["/v1/login"])
+	assert.Empty(t, keys["/v1/invites"])
+	assert.NotEmpty(t, keys["/v1/pvz"])
+}
+
+func TestClient_NoRetriesWithoutIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	attempts := 0
+	server, _ := newTestServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != "/v1/admin/api_keys" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			attempts++
+			// The key is created but a proxy loses the response.
+			next.ServeHTTP(httptest.NewRecorder(), r)
+			w.WriteHeader(http.StatusBadGateway)
+		})
+	})
+	admin := adminClient(t, server)
+
+	_, err := admin.CreateAPIKey(ctx, CreateAPIKeyRequest{Name: "partner", Role: RoleEmployee, Scopes: []string{"pvz:read"}})
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.Status)
+	assert.Equal(t, 1, attempts, "a request without a key is not repeated")
+
+	keys, err := admin.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1, "the key is created once")
+}
+
+func TestClient_NoRetries(t *testing.T) {
+	attempts := 0
+	server, _ := newTestServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		})
+	})
+	c := newTestClient(t, server, Options{Retries: -1})
+
+	_, err := c.DummyLogin(context.Background(), RoleEmployee)
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.Status)
+	assert.Empty(t, apiErr.Code, "not a problem document")
+	assert.Equal(t, "overloaded", apiErr.Message)
+	assert.Equal(t, time.Second, apiErr.RetryAfter)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestClient_LogsInAgainWhenTokenIsRevoked(t *testing.T) {
+	ctx := context.Background()
+	server, _ := newTestServer(t, nil)
+	admin := adminClient(t, server)
+	moderator := newUserClient(t, server, admin, "moderator@test.com", RoleModerator)
+
+	_, err := moderator.CreatePVZ(ctx, "Москва")
+	require.NoError(t, err)
+	oldToken := moderator.Token()
+
+	users, err := admin.ListUsers(ctx, UserFilter{Email: "moderator@"}, Page{})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	// A role change revokes the tokens issued before it.
+	_, err = admin.ChangeUserRole(ctx, users[0].ID, RoleEmployee)
+	require.NoError(t, err)
+
+	stale := newTestClient(t, server, Options{Token: oldToken})
+	_, err = stale.ListPVZ(ctx, PVZFilter{}, Page{})
+	assert.ErrorIs(t, err, InvalidToken, "without credentials the error is returned")
+
+	_, err = moderator.ListPVZ(ctx, PVZFilter{}, Page{})
+	require.NoError(t, err)
+	assert.NotEqual(t, oldToken, moderator.Token())
+}
+
+func TestClient_RefreshesExpiringToken(t *testing.T) {
+	server, _ := newTestServer(t, nil)
+	c := adminClient(t, server)
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(10*time.Second).Unix())))
+	expiring := "e30." + payload + ".sig"
+	c.SetToken(expiring)
+	assert.False(t, c.hasFreshToken())
+
+	_, err := c.ListAPIKeys(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, expiring, c.Token())
+	assert.True(t, c.hasFreshToken())
+}
+
+func TestClient_APIKey(t *testing.T) {
+	ctx := context.Background()
+	server, _ := newTestServer(t, nil)
+	admin := adminClient(t, server)
+
+	key, err := admin.CreateAPIKey(ctx, CreateAPIKeyRequest{
+		Name:   "reports",
+		Role:   RoleEmployee,
+		Scopes: []string{ScopePVZRead},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, key.Key)
+
+	service := newTestClient(t, server, Options{APIKey: key.Key})
+	_, err = service.ListPVZ(ctx, PVZFilter{}, Page{})
+	require.NoError(t, err)
+	_, err = service.CreatePVZ(ctx, "Москва")
+	assert.ErrorIs(t, err, InsufficientScope)
+
+	require.NoError(t, admin.RevokeAPIKey(ctx, key.ID))
+	keys, err := admin.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.NotNil(t, keys[0].RevokedAt)
+	assert.Empty(t, keys[0].Key)
+}
+
+func TestClient_Errors(t *testing.T) {
+	ctx := context.Background()
+	server, _ := newTestServer(t, nil)
+
+	c := newTestClient(t, server, Options{AcceptLanguage: "ru"})
+	_, err := c.Login(ctx, adminEmail, "wrong")
+	require.ErrorIs(t, err, InvalidCredentials)
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnauthorized, apiErr.Status)
+	assert.Equal(t, "urn:pvz:problem:invalid_credentials", apiErr.Type)
+	assert.Equal(t, "Неверный email или пароль", apiErr.Title)
+	assert.Empty(t, c.Token())
+
+	_, err = c.ListPVZ(ctx, PVZFilter{}, Page{})
+	assert.ErrorIs(t, err, MissingAuthorization)
+
+	_, err = c.DummyLogin(ctx, RoleModerator)
+	require.NoError(t, err)
+	_, err = c.CreatePVZ(ctx, "Новосибирск")
+	assert.ErrorIs(t, err, InvalidCity)
+	_, err = c.GetPVZ(ctx, "00000000-0000-0000-0000-000000000000")
+	assert.ErrorIs(t, err, PVZNotFound)
+	assert.False(t, errors.Is(err, InvalidCity))
+}
+
+func TestClient_Webhooks(t *testing.T) {
+	ctx := context.Background()
+	server, repos := newTestServer(t, nil)
+	admin := adminClient(t, server)
+	moderator := newUserClient(t, server, admin, "moderator@test.com", RoleModerator)
+
+	_, err := moderator.CreateWebhook(ctx, CreateWebhookRequest{URL: "/hooks"})
+	assert.ErrorIs(t, err, InvalidWebhookURL)
+
+	webhook, err := moderator.CreateWebhook(ctx, CreateWebhookRequest{
+		URL:        "https://partner.example/hooks",
+		EventTypes: []string{"reception.closed"},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, webhook.Secret)
+
+	webhooks, err := moderator.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Empty(t, webhooks[0].Secret, "the secret is shown once")
+	assert.Equal(t, []string{}, webhooks[0].PvzIDs)
+
+	createdAt := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, repos.Webhooks.CreateWebhookDeliveries([]*pvz.WebhookDelivery{{
+		ID:             "2c5e8a9e-3d5f-4b77-8f1b-0f5a3c9d1e22",
+		SubscriptionID: webhook.ID,
+		EventID:        "7d0c6b1e-5a4f-4c3b-9e2d-1f0a9b8c7d6e",
+		EventType:      "reception.closed",
+		Payload:        []byte(`{"id":"7d0c6b1e-5a4f-4c3b-9e2d-1f0a9b8c7d6e"}`),
+		Status:         WebhookDeliveryDead,
+		Attempts:       8,
+		CreatedAt:      createdAt,
+	}}))
+
+	var dead []WebhookDelivery
+	for delivery, err := range moderator.IterateWebhookDeliveries(ctx, DeliveryFilter{Status: WebhookDeliveryDead}) {
+		require.NoError(t, err)
+		dead = append(dead, delivery)
+	}
+	require.Len(t, dead, 1)
+	assert.Equal(t, webhook.ID, dead[0].WebhookID)
+
+	delivery, err := moderator.ResendWebhookDelivery(ctx, dead[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, WebhookDeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.Attempts)
+
+	require.NoError(t, moderator.DeleteWebhook(ctx, webhook.ID))
+	_, err = moderator.ResendWebhookDelivery(ctx, dead[0].ID)
+	assert.ErrorIs(t, err, DeliveryNotFound)
+	assert.ErrorIs(t, moderator.DeleteWebhook(ctx, webhook.ID), WebhookNotFound)
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	body := []byte(`{"id":"event"}`)
+	timestamp := fmt.Sprint(time.Now().Unix())
+	mac := hmac.New(sha256.New, []byte("whsec_secret"))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	header := http.Header{}
+	header.Set(WebhookHeaderTimestamp, timestamp)
+	header.Set(WebhookHeaderSignature, "v1="+hex.EncodeToString(mac.Sum(nil)))
+
+	assert.NoError(t, VerifyWebhook("whsec_secret", header, body, time.Minute))
+	assert.ErrorIs(t, VerifyWebhook("whsec_other", header, body, time.Minute), ErrWebhookSignature)
+	assert.ErrorIs(t, VerifyWebhook("whsec_secret", header, []byte(`{}`), time.Minute), ErrWebhookSignature)
+
+	old := http.Header{}
+	old.Set(WebhookHeaderTimestamp, "1700000000")
+	old.Set(WebhookHeaderSignature, "v1=b201c2f5532945c030db6360c905b316efee9ab61373e1a5b1472c9a992dea15")
+	assert.ErrorIs(t, VerifyWebhook("whsec_secret", old, body, time.Minute), ErrWebhookTimestamp)
+	assert.NoError(t, VerifyWebhook("whsec_secret", old, body, 0), "no tolerance accepts any timestamp")
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ErrorCode is the stable code of an API error. Codes are errors
+// themselves, so that errors.Is(err, client.PVZNotFound) matches an *Error
+// with that code.
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+const (
+	InvalidRequest        ErrorCode = "invalid_request"
+	AccessDenied          ErrorCode = "access_denied"
+	InternalError         ErrorCode = "internal_error"
+	MissingAuthorization  ErrorCode = "missing_authorization"
+	InvalidAuthorization  ErrorCode = "invalid_authorization"
+	InvalidToken          ErrorCode = "invalid_token"
+	InsufficientScope     ErrorCode = "insufficient_scope"
+	TwoFactorRequired     ErrorCode = "two_factor_required"
+	ImpersonationReadOnly ErrorCode = "impersonation_read_only"
+
+	InvalidCredentials   ErrorCode = "invalid_credentials"
+	TooManyLoginAttempts ErrorCode = "too_many_login_attempts"
+	AccountPending       ErrorCode = "account_pending"
+	AccountDeactivated   ErrorCode = "account_deactivated"
+	InviteRequired       ErrorCode = "invite_required"
+	InvalidInvite        ErrorCode = "invalid_invite"
+	InvalidRole          ErrorCode = "invalid_role"
+	EmailExists          ErrorCode = "email_exists"
+
+	InvalidCity           ErrorCode = "invalid_city"
+	InvalidProductType    ErrorCode = "invalid_product_type"
+	ActiveReceptionExists ErrorCode = "active_reception_exists"
+	NoActiveReception     ErrorCode = "no_active_reception"
+	NoProducts            ErrorCode = "no_products"
+	PVZNotFound           ErrorCode = "pvz_not_found"
+
+	UserNotFound      ErrorCode = "user_not_found"
+	UserNotPending    ErrorCode = "user_not_pending"
+	CannotModifySelf  ErrorCode = "cannot_modify_self"
+	InvalidUserStatus ErrorCode = "invalid_user_status"
+
+	TwoFactorNotEnrolled  ErrorCode = "two_factor_not_enrolled"
+	TwoFactorEnabled      ErrorCode = "two_factor_enabled"
+	InvalidTwoFactorCode  ErrorCode = "invalid_two_factor_code"
+	InvalidTwoFactorToken ErrorCode = "invalid_two_factor_token"
+	InvalidResetToken     ErrorCode = "invalid_reset_token"
+	InvalidPassword       ErrorCode = "invalid_password"
+
+	APIKeyNotFound    ErrorCode = "api_key_not_found"
+	InvalidAPIKey     ErrorCode = "invalid_api_key"
+	InvalidScope      ErrorCode = "invalid_scope"
+	InvalidExpiration ErrorCode = "invalid_expiration"
+	InvalidSignature  ErrorCode = "invalid_signature"
+	ReplayedRequest   ErrorCode = "replayed_request"
+
+	IdempotencyKeyReused  ErrorCode = "idempotency_key_reused"
+	IdempotencyInProgress ErrorCode = "idempotency_in_progress"
+	PreconditionFailed    ErrorCode = "precondition_failed"
+	VersionConflict       ErrorCode = "version_conflict"
+	NotAcceptable         ErrorCode = "not_acceptable"
+	UnsupportedMediaType  ErrorCode = "unsupported_media_type"
+
+	WebhookNotFound   ErrorCode = "webhook_not_found"
+	DeliveryNotFound  ErrorCode = "delivery_not_found"
+	InvalidWebhookURL ErrorCode = "invalid_webhook_url"
+	InvalidEventType  ErrorCode = "invalid_event_type"
+
+	AlreadyExists       ErrorCode = "already_exists"
+	ReferenceNotFound   ErrorCode = "reference_not_found"
+	ConstraintViolation ErrorCode = "constraint_violation"
+	InvalidID           ErrorCode = "invalid_id"
+)
+
+// Error is an error response of the API, decoded from its problem document.
+type Error struct {
+	// Status is the HTTP status of the response.
+	Status int `json:"status"`
+	// Code is empty for responses without a problem document, e.g. from a
+	// proxy in front of the API.
+	Code     ErrorCode `json:"code"`
+	Type     string    `json:"type"`
+	Title    string    `json:"title"`
+	Message  string    `json:"message"`
+	Instance string    `json:"instance"`
+	// Errors lists the mismatches of a request rejected by validation.
+	Errors []ValidationError `json:"errors"`
+	// Current is the current state of the resource for VersionConflict.
+	Current json.RawMessage `json:"current"`
+	// RetryAfter is the delay the server asked for, if any.
+	RetryAfter time.Duration `json:"-"`
+}
+
+type ValidationError struct {
+	Location string `json:"location"`
+	Name     string `json:"name"`
+	Reason   string `json:"reason"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("pvz api: %d %s", e.Status, e.Title)
+	}
+	return fmt.Sprintf("pvz api: %d %s: %s", e.Status, e.Code, e.Title)
+}
+
+// Is reports whether target is the ErrorCode of e.
+func (e *Error) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && e.Code != "" && e.Code == code
+}
+
+// DecodeCurrent decodes the current state sent with VersionConflict into v,
+// e.g. a *PVZ or *Reception.
+func (e *Error) DecodeCurrent(v interface{}) error {
+	if len(e.Current) == 0 {
+		return fmt.Errorf("pvz api: %s has no current state", e.Code)
+	}
+	return json.Unmarshal(e.Current, v)
+}
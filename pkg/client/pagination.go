@@ -0,0 +1,51 @@
+package client
+
+import (
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// Page selects a page of a list. Zero values leave the choice to the server:
+// the first page of its default size.
+type Page struct {
+	Number int
+	Limit  int
+}
+
+func (p Page) query(query url.Values) url.Values {
+	if query == nil {
+		query = url.Values{}
+	}
+	if p.Number > 0 {
+		query.Set("page", strconv.Itoa(p.Number))
+	}
+	if p.Limit > 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return query
+}
+
+// paginate yields the items of the pages list returns, from the first one
+// until a page is shorter than limit. The first error is yielded and ends the
+// iteration.
+func paginate[T any](limit int, list func(page Page) ([]T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for number := 1; ; number++ {
+			items, err := list(Page{Number: number, Limit: limit})
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(items) < limit {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// dateLayout is the format of the registration date filters of ListPVZ.
+const dateLayout = "2006-01-02T15:04:05"
+
+// maxPVZPage is the largest page of pickup points the API returns.
+const maxPVZPage = 30
+
+// PVZFilter limits pickup points by registration date. Zero bounds are not
+// applied.
+type PVZFilter struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+func (f PVZFilter) query() url.Values {
+	query := url.Values{}
+	if !f.StartDate.IsZero() {
+		query.Set("startDate", f.StartDate.Format(dateLayout))
+	}
+	if !f.EndDate.IsZero() {
+		query.Set("endDate", f.EndDate.Format(dateLayout))
+	}
+	return query
+}
+
+// CreatePVZ registers a pickup point in city. Moderators only.
+func (c *Client) CreatePVZ(ctx context.Context, city string) (*PVZ, error) {
+	var pvz PVZ
+	err := c.do(ctx, call{method: http.MethodPost, path: "/pvz", body: map[string]string{"city": city}}, &pvz)
+	if err != nil {
+		return nil, err
+	}
+	return &pvz, nil
+}
+
+// ListPVZ returns a page of pickup points, at most 30 per page.
+func (c *Client) ListPVZ(ctx context.Context, filter PVZFilter, page Page) ([]PVZ, error) {
+	var pvzs []PVZ
+	err := c.do(ctx, call{method: http.MethodGet, path: "/pvz", query: page.query(filter.query())}, &pvzs)
+	return pvzs, err
+}
+
+// IteratePVZ yields all pickup points matching filter, fetching them page by
+// page.
+func (c *Client) IteratePVZ(ctx context.Context, filter PVZFilter) iter.Seq2[PVZ, error] {
+	return paginate(maxPVZPage, func(page Page) ([]PVZ, error) {
+		return c.ListPVZ(ctx, filter, page)
+	})
+}
+
+func (c *Client) GetPVZ(ctx context.Context, pvzID string) (*PVZ, error) {
+	var pvz PVZ
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/pvz/" + url.PathEscape(pvzID)}, &pvz); err != nil {
+		return nil, err
+	}
+	return &pvz, nil
+}
+
+// UpdatePVZ changes the city of a pickup point. version is the version the
+// change is based on; if the pickup point has changed since, the error is
+// VersionConflict with the current state, see Error.DecodeCurrent.
+func (c *Client) UpdatePVZ(ctx context.Context, pvzID, city string, version int) (*PVZ, error) {
+	var pvz PVZ
+	err := c.do(ctx, call{
+		method: http.MethodPatch,
+		path:   "/pvz/" + url.PathEscape(pvzID),
+		body:   map[string]interface{}{"city": city, "version": version},
+	}, &pvz)
+	if err != nil {
+		return nil, err
+	}
+	return &pvz, nil
+}
+
+// ListPVZReceptions returns the receptions of a pickup point with their
+// products.
+func (c *Client) ListPVZReceptions(ctx context.Context, pvzID string) ([]ReceptionWithProducts, error) {
+	var receptions []ReceptionWithProducts
+	err := c.do(ctx, call{method: http.MethodGet, path: "/pvz/" + url.PathEscape(pvzID) + "/receptions"}, &receptions)
+	return receptions, err
+}
+
+// CreateReception opens a reception at a pickup point. Employees only.
+func (c *Client) CreateReception(ctx context.Context, pvzID string) (*Reception, error) {
+	var reception Reception
+	err := c.do(ctx, call{method: http.MethodPost, path: "/receptions", body: map[string]string{"pvzId": pvzID}}, &reception)
+	if err != nil {
+		return nil, err
+	}
+	return &reception, nil
+}
+
+// CloseLastReception closes the reception in progress at a pickup point.
+func (c *Client) CloseLastReception(ctx context.Context, pvzID string) (*Reception, error) {
+	var reception Reception
+	err := c.do(ctx, call{method: http.MethodPost, path: "/pvz/" + url.PathEscape(pvzID) + "/close_last_reception"}, &reception)
+	if err != nil {
+		return nil, err
+	}
+	return &reception, nil
+}
+
+// AddProduct adds a product of productType to the reception in progress at
+// a pickup point.
+func (c *Client) AddProduct(ctx context.Context, pvzID, productType string) (*Product, error) {
+	var product Product
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/products",
+		body:   map[string]string{"type": productType, "pvzId": pvzID},
+	}, &product)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// DeleteLastProduct removes the last product added to the reception in
+// progress at a pickup point.
+func (c *Client) DeleteLastProduct(ctx context.Context, pvzID string) error {
+	return c.do(ctx, call{method: http.MethodPost, path: "/pvz/" + url.PathEscape(pvzID) + "/delete_last_product"}, nil)
+}
+
+// GraphQLError is an error of a GraphQL field, returned next to the data of
+// the other fields.
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	if len(e) == 1 {
+		return "graphql: " + e[0].Message
+	}
+	return "graphql: " + e[0].Message + " (and other errors)"
+}
+
+// GraphQL runs a query against /graphql and decodes its data into out. If
+// fields failed, the data of the others is still decoded and the error is
+// GraphQLErrors.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors GraphQLErrors   `json:"errors"`
+	}
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/graphql",
+		body:   map[string]interface{}{"query": query, "variables": variables},
+	}, &result)
+	if err != nil {
+		return err
+	}
+	if out != nil && len(result.Data) > 0 && string(result.Data) != "null" {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			return err
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result.Errors
+	}
+	return nil
+}
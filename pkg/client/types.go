@@ -0,0 +1,191 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	RoleEmployee  = "employee"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+
+	ReceptionInProgress = "in_progress"
+	ReceptionClosed     = "close"
+
+	ScopePVZRead         = "pvz:read"
+	ScopePVZWrite        = "pvz:write"
+	ScopeReceptionsWrite = "receptions:write"
+	ScopeProductsWrite   = "products:write"
+
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+type User struct {
+	ID     string `json:"id,omitempty"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	Status string `json:"status,omitempty"`
+}
+
+type PVZ struct {
+	ID               string    `json:"id"`
+	RegistrationDate time.Time `json:"registrationDate"`
+	City             string    `json:"city"`
+	Version          int       `json:"version"`
+}
+
+type Reception struct {
+	ID       string    `json:"id"`
+	DateTime time.Time `json:"dateTime"`
+	PVZID    string    `json:"pvzId"`
+	Status   string    `json:"status"`
+	Version  int       `json:"version"`
+}
+
+type Product struct {
+	ID          string    `json:"id"`
+	DateTime    time.Time `json:"dateTime"`
+	Type        string    `json:"type"`
+	ReceptionID string    `json:"receptionId"`
+}
+
+type ReceptionWithProducts struct {
+	Reception Reception `json:"reception"`
+	Products  []Product `json:"products"`
+}
+
+// LoginResult is the outcome of a login. Token is empty when the account
+// has a second factor; the login is then completed with LoginTwoFactor and
+// TwoFactorToken.
+type LoginResult struct {
+	Token                       string `json:"token"`
+	TwoFactorEnrollmentRequired bool   `json:"twoFactorEnrollmentRequired"`
+	TwoFactorRequired           bool   `json:"twoFactorRequired"`
+	TwoFactorToken              string `json:"twoFactorToken"`
+}
+
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+	// InviteToken is required for privileged roles and, depending on the
+	// server configuration, for employees.
+	InviteToken string `json:"inviteToken,omitempty"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+// TwoFactorConfirmation holds a token with the second factor and recovery
+// codes, which are shown only once.
+type TwoFactorConfirmation struct {
+	Token         string   `json:"token"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// Invite holds the invite token, which is shown only once.
+type Invite struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Token     string    `json:"token"`
+}
+
+type Impersonation struct {
+	Token     string    `json:"token"`
+	UserID    string    `json:"userId"`
+	ActorID   string    `json:"actorId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type AuditEvent struct {
+	ID        string    `json:"id"`
+	ActorID   string    `json:"actorId"`
+	UserID    string    `json:"userId,omitempty"`
+	Action    string    `json:"action"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// UserDataExport is everything stored about a user. The records the API
+// documents only as objects are kept as JSON.
+type UserDataExport struct {
+	ExportedAt      time.Time         `json:"exportedAt"`
+	Account         User              `json:"account"`
+	TwoFactor       json.RawMessage   `json:"twoFactor,omitempty"`
+	LoginAttempts   []json.RawMessage `json:"loginAttempts"`
+	PasswordResets  []json.RawMessage `json:"passwordResets"`
+	InvitesCreated  []json.RawMessage `json:"invitesCreated"`
+	InvitesReceived []json.RawMessage `json:"invitesReceived"`
+	APIKeys         []json.RawMessage `json:"apiKeys"`
+	PVZIDs          []string          `json:"pvzIds"`
+	AuditEvents     []AuditEvent      `json:"auditEvents"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name             string     `json:"name"`
+	Role             string     `json:"role"`
+	Scopes           []string   `json:"scopes"`
+	RequireSignature bool       `json:"requireSignature,omitempty"`
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+}
+
+// APIKey describes a key. Key and SigningSecret are set only in the response
+// to its creation.
+type APIKey struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	KeyPrefix        string     `json:"keyPrefix"`
+	Role             string     `json:"role"`
+	Scopes           []string   `json:"scopes"`
+	RequireSignature bool       `json:"requireSignature"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt       *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	Key              string     `json:"key,omitempty"`
+	SigningSecret    string     `json:"signingSecret,omitempty"`
+}
+
+type CreateWebhookRequest struct {
+	URL string `json:"url"`
+	// EventTypes and PvzIDs filter the events sent; empty ones send all.
+	EventTypes []string `json:"eventTypes,omitempty"`
+	PvzIDs     []string `json:"pvzIds,omitempty"`
+}
+
+// Webhook describes a subscription. Secret is set only in the response to
+// its creation.
+type Webhook struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	PvzIDs     []string  `json:"pvzIds"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Secret     string    `json:"secret,omitempty"`
+}
+
+// WebhookDelivery is an entry of the delivery log. Payload is the body
+// sent, the event as JSON.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhookId"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	PvzID          string          `json:"pvzId"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time      `json:"lastAttemptAt,omitempty"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
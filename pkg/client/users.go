@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// maxAdminPage is the largest page of users and audit events the API
+// returns.
+const maxAdminPage = 100
+
+// UserFilter limits users by a substring of the email, role and status.
+// Empty fields are not applied.
+type UserFilter struct {
+	Email  string
+	Role   string
+	Status string
+}
+
+func (f UserFilter) query() url.Values {
+	query := url.Values{}
+	for name, value := range map[string]string{"email": f.Email, "role": f.Role, "status": f.Status} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	return query
+}
+
+// AuditFilter limits audit events by the administrator who acted and the
+// user acted on. Empty fields are not applied.
+type AuditFilter struct {
+	ActorID string
+	UserID  string
+}
+
+func (f AuditFilter) query() url.Values {
+	query := url.Values{}
+	if f.ActorID != "" {
+		query.Set("actorId", f.ActorID)
+	}
+	if f.UserID != "" {
+		query.Set("userId", f.UserID)
+	}
+	return query
+}
+
+// CreateInvite invites email to register with role.
+func (c *Client) CreateInvite(ctx context.Context, email, role string) (*Invite, error) {
+	var invite Invite
+	err := c.do(ctx, call{
+		method:      http.MethodPost,
+		path:        "/invites",
+		body:        map[string]string{"email": email, "role": role},
+		credentials: true,
+	}, &invite)
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// ApproveUser activates a pending user.
+func (c *Client) ApproveUser(ctx context.Context, userID string) (*User, error) {
+	return c.userAction(ctx, "/users/"+url.PathEscape(userID)+"/approve", nil)
+}
+
+// ListUsers returns a page of users ordered by email. Administrators only.
+func (c *Client) ListUsers(ctx context.Context, filter UserFilter, page Page) ([]User, error) {
+	var users []User
+	err := c.do(ctx, call{method: http.MethodGet, path: "/admin/users", query: page.query(filter.query())}, &users)
+	return users, err
+}
+
+// IterateUsers yields all users matching filter, fetching them page by page.
+func (c *Client) IterateUsers(ctx context.Context, filter UserFilter) iter.Seq2[User, error] {
+	return paginate(maxAdminPage, func(page Page) ([]User, error) {
+		return c.ListUsers(ctx, filter, page)
+	})
+}
+
+func (c *Client) ChangeUserRole(ctx context.Context, userID, role string) (*User, error) {
+	return c.userAction(ctx, adminUserPath(userID, "role"), map[string]string{"role": role})
+}
+
+func (c *Client) DeactivateUser(ctx context.Context, userID string) (*User, error) {
+	return c.userAction(ctx, adminUserPath(userID, "deactivate"), nil)
+}
+
+func (c *Client) ReactivateUser(ctx context.Context, userID string) (*User, error) {
+	return c.userAction(ctx, adminUserPath(userID, "reactivate"), nil)
+}
+
+// ResetUserPassword replaces the password of a user with a temporary one
+// and returns it.
+func (c *Client) ResetUserPassword(ctx context.Context, userID string) (string, error) {
+	var result struct {
+		TemporaryPassword string `json:"temporaryPassword"`
+	}
+	if err := c.do(ctx, call{method: http.MethodPost, path: adminUserPath(userID, "reset_password"), credentials: true}, &result); err != nil {
+		return "", err
+	}
+	return result.TemporaryPassword, nil
+}
+
+// UnlockUser clears the login lockout of a user.
+func (c *Client) UnlockUser(ctx context.Context, userID string) error {
+	return c.do(ctx, call{method: http.MethodPost, path: adminUserPath(userID, "unlock")}, nil)
+}
+
+// UnlockUserAndIP clears the login lockout of a user and of a client IP.
+func (c *Client) UnlockUserAndIP(ctx context.Context, userID, ip string) error {
+	return c.do(ctx, call{method: http.MethodPost, path: adminUserPath(userID, "unlock"), query: url.Values{"ip": {ip}}}, nil)
+}
+
+func (c *Client) ExportUserData(ctx context.Context, userID string) (*UserDataExport, error) {
+	var export UserDataExport
+	if err := c.do(ctx, call{method: http.MethodGet, path: adminUserPath(userID, "export")}, &export); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// EraseUser anonymizes the personal data of a user.
+func (c *Client) EraseUser(ctx context.Context, userID string) (*User, error) {
+	return c.userAction(ctx, adminUserPath(userID, "erase"), nil)
+}
+
+// Impersonate returns a read-only token of userID for support sessions. The
+// client keeps its own token; use a second client with the returned one.
+func (c *Client) Impersonate(ctx context.Context, userID string) (*Impersonation, error) {
+	var impersonation Impersonation
+	err := c.do(ctx, call{method: http.MethodPost, path: "/admin/impersonate/" + url.PathEscape(userID), credentials: true}, &impersonation)
+	if err != nil {
+		return nil, err
+	}
+	return &impersonation, nil
+}
+
+// ListAuditEvents returns a page of audit events, newest first.
+func (c *Client) ListAuditEvents(ctx context.Context, filter AuditFilter, page Page) ([]AuditEvent, error) {
+	var events []AuditEvent
+	err := c.do(ctx, call{method: http.MethodGet, path: "/admin/audit", query: page.query(filter.query())}, &events)
+	return events, err
+}
+
+// IterateAuditEvents yields all audit events matching filter, newest first.
+func (c *Client) IterateAuditEvents(ctx context.Context, filter AuditFilter) iter.Seq2[AuditEvent, error] {
+	return paginate(maxAdminPage, func(page Page) ([]AuditEvent, error) {
+		return c.ListAuditEvents(ctx, filter, page)
+	})
+}
+
+// CreateAPIKey creates a service API key. The key itself is only in the
+// returned APIKey.Key.
+func (c *Client) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (*APIKey, error) {
+	var key APIKey
+	if err := c.do(ctx, call{method: http.MethodPost, path: "/admin/api_keys", body: req, credentials: true}, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (c *Client) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	var keys []APIKey
+	err := c.do(ctx, call{method: http.MethodGet, path: "/admin/api_keys"}, &keys)
+	return keys, err
+}
+
+func (c *Client) RevokeAPIKey(ctx context.Context, keyID string) error {
+	return c.do(ctx, call{method: http.MethodDelete, path: "/admin/api_keys/" + url.PathEscape(keyID)}, nil)
+}
+
+func (c *Client) userAction(ctx context.Context, path string, body interface{}) (*User, error) {
+	var user User
+	if err := c.do(ctx, call{method: http.MethodPost, path: path, body: body}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func adminUserPath(userID, action string) string {
+	return "/admin/users/" + url.PathEscape(userID) + "/" + action
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of the webhook deliveries.
+const (
+	WebhookHeaderID        = "Webhook-Id"
+	WebhookHeaderEvent     = "Webhook-Event"
+	WebhookHeaderTimestamp = "Webhook-Timestamp"
+	WebhookHeaderSignature = "Webhook-Signature"
+)
+
+var (
+	ErrWebhookSignature = errors.New("webhook signature does not match")
+	ErrWebhookTimestamp = errors.New("webhook timestamp is outside the tolerance")
+)
+
+// DeliveryFilter limits the delivery log by webhook and status. Empty fields
+// are not applied.
+type DeliveryFilter struct {
+	WebhookID string
+	Status    string
+}
+
+func (f DeliveryFilter) query() url.Values {
+	query := url.Values{}
+	if f.WebhookID != "" {
+		query.Set("webhookId", f.WebhookID)
+	}
+	if f.Status != "" {
+		query.Set("status", f.Status)
+	}
+	return query
+}
+
+// CreateWebhook subscribes a URL to events. The signing secret is only in
+// the returned Webhook.Secret. Moderators only.
+func (c *Client) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.do(ctx, call{method: http.MethodPost, path: "/webhooks", body: req, credentials: true}, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := c.do(ctx, call{method: http.MethodGet, path: "/webhooks"}, &webhooks)
+	return webhooks, err
+}
+
+// DeleteWebhook removes a subscription together with its delivery log.
+func (c *Client) DeleteWebhook(ctx context.Context, webhookID string) error {
+	return c.do(ctx, call{method: http.MethodDelete, path: "/webhooks/" + url.PathEscape(webhookID)}, nil)
+}
+
+// ListWebhookDeliveries returns a page of the delivery log, newest first.
+// The dead letters are the deliveries with status WebhookDeliveryDead.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, filter DeliveryFilter, page Page) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := c.do(ctx, call{method: http.MethodGet, path: "/webhooks/deliveries", query: page.query(filter.query())}, &deliveries)
+	return deliveries, err
+}
+
+// IterateWebhookDeliveries yields all deliveries matching filter, newest
+// first.
+func (c *Client) IterateWebhookDeliveries(ctx context.Context, filter DeliveryFilter) iter.Seq2[WebhookDelivery, error] {
+	return paginate(maxAdminPage, func(page Page) ([]WebhookDelivery, error) {
+		return c.ListWebhookDeliveries(ctx, filter, page)
+	})
+}
+
+// ResendWebhookDelivery queues a delivery to be sent again right away.
+func (c *Client) ResendWebhookDelivery(ctx context.Context, deliveryID string) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/webhooks/deliveries/" + url.PathEscape(deliveryID) + "/resend",
+	}, &delivery)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// VerifyWebhook checks the signature of a received delivery against the
+// secret of its webhook. A positive tolerance also rejects deliveries whose
+// timestamp is further than that from now, against replays.
+func VerifyWebhook(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp := header.Get(WebhookHeaderTimestamp)
+	signature, ok := strings.CutPrefix(header.Get(WebhookHeaderSignature), "v1=")
+	if !ok {
+		return ErrWebhookSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(signature)) {
+		return ErrWebhookSignature
+	}
+	if tolerance > 0 {
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return ErrWebhookTimestamp
+		}
+		if age := time.Since(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+			return ErrWebhookTimestamp
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package pvz
+
+import (
+	"avito-intern/internal/ingest"
+	"avito-intern/internal/mailer"
+	"avito-intern/internal/oidc"
+	"avito-intern/internal/outbox"
+	"net/http"
+)
+
+// NewLogMailer returns a Mailer that logs the messages.
+func NewLogMailer() Mailer {
+	return mailer.NewLogMailer()
+}
+
+// NewFileMailer returns a Mailer that appends the messages to the file at
+// path.
+func NewFileMailer(path string) Mailer {
+	return mailer.NewFileMailer(path)
+}
+
+// NewSMTPMailer returns a Mailer that sends the messages from the address
+// from over the SMTP server at host:port. An empty username skips
+// authentication.
+func NewSMTPMailer(host, port, username, password, from string) Mailer {
+	return mailer.NewSMTPMailer(host, port, username, password, from)
+}
+
+// NewOIDCProvider returns the verifier of Options.OIDCVerifier for the
+// tokens of one OIDC issuer. It fails without an audience.
+func NewOIDCProvider(config OIDCConfig) (TokenVerifier, error) {
+	provider, err := oidc.NewProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// NewLogPublisher returns a publisher that logs the domain events.
+func NewLogPublisher() EventPublisher {
+	return outbox.NewLogPublisher()
+}
+
+// NewFilePublisher returns a publisher that appends the domain events to
+// the file at path as NDJSON.
+func NewFilePublisher(path string) EventPublisher {
+	return outbox.NewFilePublisher(path)
+}
+
+// NewHTTPPublisher returns a publisher that posts the domain events to url
+// with headers. A nil client gets a default one.
+func NewHTTPPublisher(url string, headers http.Header, client *http.Client) EventPublisher {
+	return outbox.NewHTTPPublisher(url, headers, client)
+}
+
+// NewKafkaPublisher returns a publisher that produces the domain events to
+// topic through the Kafka REST proxy at proxyURL.
+func NewKafkaPublisher(proxyURL, topic string, client *http.Client) EventPublisher {
+	return outbox.NewKafkaPublisher(proxyURL, topic, client)
+}
+
+// NewNATSConsumer returns a consumer of the commands published to subject,
+// shared by the replicas in queue.
+func NewNATSConsumer(serverURL, subject, queue string) CommandConsumer {
+	return ingest.NewNATSConsumer(serverURL, subject, queue)
+}
+
+// NewKafkaConsumer returns a consumer of the commands of topic in the
+// consumer group through the Kafka REST proxy at proxyURL.
+func NewKafkaConsumer(proxyURL, group, topic string, client *http.Client) CommandConsumer {
+	return ingest.NewKafkaConsumer(proxyURL, group, topic, client)
+}
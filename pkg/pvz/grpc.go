@@ -0,0 +1,19 @@
+package pvz
+
+import (
+	grpcserver "avito-intern/internal/grpc/server"
+
+	"google.golang.org/grpc"
+)
+
+// NewGRPCServer returns the gRPC API over the pickup point, reception and
+// product services of the app, with the health service and reflection.
+// Calls are authenticated with the same tokens as the HTTP API.
+func (a *App) NewGRPCServer() *grpc.Server {
+	return grpcserver.New(
+		a.Services.PVZ,
+		a.Services.Receptions,
+		a.Services.Products,
+		grpcserver.NewAuthInterceptor(a.Services.Auth, a.Services.TwoFactor),
+	)
+}
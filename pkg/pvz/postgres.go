@@ -0,0 +1,42 @@
+package pvz
+
+import (
+	"avito-intern/internal/database"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/stream"
+	"database/sql"
+)
+
+// OpenPostgres connects to the database at connectionString, a lib/pq
+// connection string, and checks that it answers.
+func OpenPostgres(connectionString string) (*sql.DB, error) {
+	return database.NewPostgres(connectionString)
+}
+
+// NewPostgresRepositories returns the repositories of the standalone
+// service on db. The schema comes from internal/database/migrations.
+func NewPostgresRepositories(db *sql.DB) Repositories {
+	return Repositories{
+		Users:          repository.NewUserRepository(db),
+		PVZ:            repository.NewPVZRepository(db),
+		Receptions:     repository.NewReceptionRepository(db),
+		Products:       repository.NewProductRepository(db),
+		Invites:        repository.NewInviteRepository(db),
+		PasswordResets: repository.NewPasswordResetRepository(db),
+		LoginAttempts:  repository.NewLoginAttemptRepository(db),
+		TwoFactor:      repository.NewTwoFactorRepository(db),
+		APIKeys:        repository.NewAPIKeyRepository(db),
+		Audit:          repository.NewAuditRepository(db),
+		PersonalData:   repository.NewPersonalDataRepository(db),
+		Idempotency:    repository.NewIdempotencyRepository(db),
+		PVZAssignments: repository.NewPVZAssignmentRepository(db),
+		Webhooks:       repository.NewWebhookRepository(db),
+	}
+}
+
+// NewPostgresEventListener passes the events the Postgres repositories
+// write, on any replica, to hub once Run is called. It opens its own
+// connection with connectionString.
+func NewPostgresEventListener(connectionString string, hub *EventHub) *EventListener {
+	return stream.NewListener(connectionString, hub)
+}
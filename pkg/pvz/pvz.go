@@ -0,0 +1,291 @@
+// Package pvz embeds the pickup point service into other Go programs. New
+// builds the services on the given repositories and returns the HTTP API
+// together with the services, so that the embedding program can serve both
+// its own routes and other transports, like gRPC, from one set of services.
+//
+// Types of the service are re-exported as aliases, so that repositories can
+// be implemented outside of this module.
+package pvz
+
+import (
+	"avito-intern/internal/api"
+	"avito-intern/internal/mailer"
+	"avito-intern/internal/services"
+	"avito-intern/internal/stream"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Repositories are the storage of the services. PVZAssignments is required
+// only with Options.SCIMToken, Idempotency may be nil to ignore the
+// Idempotency-Key header and Webhooks to leave the webhook API out.
+type Repositories struct {
+	Users          UserRepository
+	PVZ            PVZRepository
+	Receptions     ReceptionRepository
+	Products       ProductRepository
+	Invites        InviteRepository
+	PasswordResets PasswordResetRepository
+	LoginAttempts  LoginAttemptRepository
+	TwoFactor      TwoFactorRepository
+	APIKeys        APIKeyRepository
+	Audit          AuditRepository
+	PersonalData   PersonalDataRepository
+	Idempotency    IdempotencyRepository
+	PVZAssignments PVZAssignmentRepository
+	Webhooks       WebhookRepository
+}
+
+// Options configure the services and the router. Start from DefaultOptions:
+// the zero value of a policy is taken as is.
+type Options struct {
+	Registration     RegistrationPolicy
+	Lockout          LockoutPolicy
+	TwoFactor        TwoFactorPolicy
+	APIKeys          APIKeyPolicy
+	ImpersonationTTL time.Duration
+	IdempotencyTTL   time.Duration
+	GraphQL          GraphQLLimits
+	Legacy           LegacyRoutes
+
+	// Mailer sends invites and password resets. Nil logs them.
+	Mailer Mailer
+
+	// OIDCVerifier enables tokens of an external identity provider.
+	OIDCVerifier TokenVerifier
+	OIDC         OIDCPolicy
+
+	// SCIMToken enables SCIM provisioning for the identity provider that
+	// presents it. Employees then follow the event streams of the pickup
+	// points of their groups only.
+	SCIMToken string
+
+	// Events streams the domain events of pickup points to live
+	// subscribers at /pvz/{pvzId}/events. Nil leaves the streams out. Feed
+	// it with NewPostgresEventListener, or publish events to it yourself.
+	Events *EventHub
+	// StreamTokenTTL is how long the tokens browsers open the event streams
+	// with are valid. StreamOrigins are the origins of pages besides the
+	// API's own that may open the WebSocket stream.
+	StreamTokenTTL time.Duration
+	StreamOrigins  []string
+
+	// Middleware wraps every request after logging, recovery and metrics,
+	// before the request is validated against the API specification.
+	Middleware []func(http.Handler) http.Handler
+
+	// ValidateResponses checks every response against the API specification
+	// and answers 500 when it does not match. Meant for tests.
+	ValidateResponses bool
+
+	// ValidateCity and ValidateProductType add checks to the built-in lists
+	// of cities and product types. Their errors are wrapped in
+	// ErrInvalidCity and ErrInvalidProductType.
+	ValidateCity        func(city string) error
+	ValidateProductType func(productType string) error
+
+	// Clock and NewID replace time.Now and random UUIDs in the services.
+	Clock func() time.Time
+	NewID func() string
+}
+
+// DefaultOptions returns the options of the standalone service.
+func DefaultOptions() Options {
+	return Options{
+		Registration: RegistrationPolicy{
+			EmployeeSelfRegistration: true,
+			InviteTTL:                72 * time.Hour,
+			PasswordResetTTL:         time.Hour,
+		},
+		Lockout: LockoutPolicy{
+			AccountThreshold: 5,
+			IPThreshold:      20,
+			LockoutDuration:  15 * time.Minute,
+			BaseDelay:        time.Second,
+			MaxDelay:         30 * time.Second,
+			FailureWindow:    time.Hour,
+		},
+		TwoFactor:        TwoFactorPolicy{Issuer: "PVZ Service"},
+		APIKeys:          APIKeyPolicy{SignatureSkew: 5 * time.Minute},
+		ImpersonationTTL: 15 * time.Minute,
+		StreamTokenTTL:   time.Minute,
+		IdempotencyTTL:   24 * time.Hour,
+		GraphQL:          GraphQLLimits{MaxDepth: 6, MaxComplexity: 10000},
+	}
+}
+
+// App is the service built by New.
+type App struct {
+	// Handler serves the HTTP API, both under /v1 and the legacy root.
+	Handler  http.Handler
+	Services Services
+}
+
+// Services share the repositories of the App. OIDC and SCIM are nil unless
+// enabled in the options, Idempotency and Webhooks without their
+// repositories.
+type Services struct {
+	Auth          *AuthService
+	LoginAttempts *LoginAttemptService
+	TwoFactor     *TwoFactorService
+	PVZ           *PVZService
+	Receptions    *ReceptionService
+	Products      *ProductService
+	Users         *UserService
+	OIDC          *OIDCService
+	APIKeys       *APIKeyService
+	Impersonation *ImpersonationService
+	Privacy       *PrivacyService
+	Idempotency   *IdempotencyService
+	Webhooks      *WebhookService
+	SCIM          *SCIMService
+}
+
+// NewEventHub creates the hub of Options.Events, keeping the last replay
+// events of every pickup point for subscribers that resume.
+func NewEventHub(replay int) *EventHub {
+	return stream.NewHub(replay)
+}
+
+// sourced are the services with a clock and an ID generator.
+type sourced interface {
+	SetClock(now func() time.Time)
+	SetIDGenerator(newID func() string)
+}
+
+// New builds the services on repos and the router over them.
+func New(repos Repositories, options Options) (*App, error) {
+	if err := repos.check(options); err != nil {
+		return nil, err
+	}
+	sender := options.Mailer
+	if sender == nil {
+		sender = mailer.NewLogMailer()
+	}
+
+	var s Services
+	s.LoginAttempts = services.NewLoginAttemptService(repos.Users, repos.LoginAttempts, options.Lockout)
+	s.TwoFactor = services.NewTwoFactorService(repos.Users, repos.TwoFactor, s.LoginAttempts, options.TwoFactor)
+	s.Auth = services.NewAuthService(
+		repos.Users,
+		repos.Invites,
+		repos.PasswordResets,
+		s.LoginAttempts,
+		s.TwoFactor,
+		sender,
+		options.Registration,
+	)
+	s.PVZ = services.NewPVZService(repos.PVZ)
+	s.Receptions = services.NewReceptionService(repos.Receptions)
+	s.Products = services.NewProductService(repos.Products, repos.Receptions)
+	s.Users = services.NewUserService(repos.Users)
+	if options.OIDCVerifier != nil {
+		s.OIDC = services.NewOIDCService(repos.Users, options.OIDCVerifier, options.OIDC)
+	}
+	s.APIKeys = services.NewAPIKeyService(repos.APIKeys, options.APIKeys)
+	s.Impersonation = services.NewImpersonationService(repos.Users, repos.Audit, options.ImpersonationTTL)
+	s.Privacy = services.NewPrivacyService(repos.Users, repos.PersonalData, repos.Audit)
+	if repos.Idempotency != nil {
+		s.Idempotency = services.NewIdempotencyService(repos.Idempotency, options.IdempotencyTTL)
+	}
+	if repos.Webhooks != nil {
+		s.Webhooks = services.NewWebhookService(repos.Webhooks)
+	}
+	if options.SCIMToken != "" {
+		s.SCIM = services.NewSCIMService(repos.Users, repos.PVZ, repos.PVZAssignments)
+		// Employees follow the pickup points of their SCIM groups only.
+		s.PVZ.SetAssignments(repos.PVZAssignments)
+	}
+
+	if options.ValidateCity != nil {
+		s.PVZ.SetCityValidator(options.ValidateCity)
+	}
+	if options.ValidateProductType != nil {
+		s.Products.SetTypeValidator(options.ValidateProductType)
+	}
+	for _, service := range s.sourced() {
+		if options.Clock != nil {
+			service.SetClock(options.Clock)
+		}
+		if options.NewID != nil {
+			service.SetIDGenerator(options.NewID)
+		}
+	}
+
+	router := api.SetupRouter(api.Config{
+		Auth:              s.Auth,
+		PVZ:               s.PVZ,
+		Receptions:        s.Receptions,
+		Products:          s.Products,
+		Users:             s.Users,
+		LoginAttempts:     s.LoginAttempts,
+		TwoFactor:         s.TwoFactor,
+		OIDC:              s.OIDC,
+		APIKeys:           s.APIKeys,
+		Impersonation:     s.Impersonation,
+		Privacy:           s.Privacy,
+		Idempotency:       s.Idempotency,
+		Webhooks:          s.Webhooks,
+		SCIM:              s.SCIM,
+		Events:            options.Events,
+		StreamTokenTTL:    options.StreamTokenTTL,
+		StreamOrigins:     options.StreamOrigins,
+		SCIMToken:         options.SCIMToken,
+		DummyLogin:        options.Registration.DummyLogin,
+		GraphQL:           options.GraphQL,
+		Legacy:            options.Legacy,
+		Middleware:        options.Middleware,
+		ValidateResponses: options.ValidateResponses,
+	})
+	return &App{Handler: router, Services: s}, nil
+}
+
+// sourced returns the services that are not nil and have sources. The
+// optional ones are checked one by one: a nil pointer in an interface is
+// not nil.
+func (s Services) sourced() []sourced {
+	result := []sourced{
+		s.Auth, s.LoginAttempts, s.TwoFactor, s.PVZ, s.Receptions,
+		s.Products, s.APIKeys, s.Impersonation, s.Privacy,
+	}
+	if s.OIDC != nil {
+		result = append(result, s.OIDC)
+	}
+	if s.Idempotency != nil {
+		result = append(result, s.Idempotency)
+	}
+	if s.Webhooks != nil {
+		result = append(result, s.Webhooks)
+	}
+	if s.SCIM != nil {
+		result = append(result, s.SCIM)
+	}
+	return result
+}
+
+func (r Repositories) check(options Options) error {
+	required := []struct {
+		name    string
+		missing bool
+	}{
+		{"Users", r.Users == nil},
+		{"PVZ", r.PVZ == nil},
+		{"Receptions", r.Receptions == nil},
+		{"Products", r.Products == nil},
+		{"Invites", r.Invites == nil},
+		{"PasswordResets", r.PasswordResets == nil},
+		{"LoginAttempts", r.LoginAttempts == nil},
+		{"TwoFactor", r.TwoFactor == nil},
+		{"APIKeys", r.APIKeys == nil},
+		{"Audit", r.Audit == nil},
+		{"PersonalData", r.PersonalData == nil},
+		{"PVZAssignments", options.SCIMToken != "" && r.PVZAssignments == nil},
+	}
+	for _, repo := range required {
+		if repo.missing {
+			return fmt.Errorf("pvz: %s repository is required", repo.name)
+		}
+	}
+	return nil
+}
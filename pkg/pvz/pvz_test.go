@@ -0,0 +1,359 @@
+package pvz_test
+
+import (
+	"avito-intern/internal/grpc/pvzv1"
+	"avito-intern/pkg/pvz"
+	"avito-intern/pkg/pvz/pvztest"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// testOptions are the default options with responses checked against the
+// API specification.
+func testOptions() pvz.Options {
+	options := pvz.DefaultOptions()
+	options.ValidateResponses = true
+	return options
+}
+
+func TestNew_RequiresRepositories(t *testing.T) {
+	repos := pvztest.NewRepositories()
+	repos.Receptions = nil
+	_, err := pvz.New(repos, testOptions())
+	assert.EqualError(t, err, "pvz: Receptions repository is required")
+
+	options := testOptions()
+	options.SCIMToken = "scim-token"
+	_, err = pvz.New(pvztest.NewRepositories(), options)
+	assert.EqualError(t, err, "pvz: PVZAssignments repository is required")
+
+	repos = pvztest.NewRepositories()
+	repos.Idempotency = nil
+	app, err := pvz.New(repos, testOptions())
+	require.NoError(t, err)
+	assert.Nil(t, app.Services.Idempotency)
+	assert.Nil(t, app.Services.SCIM)
+	assert.Nil(t, app.Services.OIDC)
+}
+
+func TestNew_DummyLoginIsOffByDefault(t *testing.T) {
+	app, err := pvz.New(pvztest.NewRepositories(), pvz.DefaultOptions())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/dummyLogin", strings.NewReader(`{"role": "moderator"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	app.Handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestNew_Hooks(t *testing.T) {
+	clock := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	ids := 0
+	options := testOptions()
+	options.Registration.DummyLogin = true
+	options.Clock = func() time.Time { return clock }
+	options.NewID = func() string {
+		ids++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", ids)
+	}
+	options.ValidateCity = func(city string) error {
+		if city == "Казань" {
+			return errors.New("not served yet")
+		}
+		return nil
+	}
+	options.Middleware = []func(http.Handler) http.Handler{
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Tenant", "north")
+				next.ServeHTTP(w, r)
+			})
+		},
+	}
+	app, err := pvz.New(pvztest.NewRepositories(), options)
+	require.NoError(t, err)
+	server := httptest.NewServer(app.Handler)
+	t.Cleanup(server.Close)
+
+	resp := post(t, server.URL+"/v1/dummyLogin", "", `{"role": "moderator"}`)
+	assert.Equal(t, "north", resp.Header.Get("X-Tenant"))
+	var login struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&login))
+
+	resp = post(t, server.URL+"/v1/pvz", login.Token, `{"city": "Москва"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created pvz.PVZ
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", created.ID)
+	assert.True(t, clock.Equal(created.RegistrationDate))
+
+	resp = post(t, server.URL+"/v1/pvz", login.Token, `{"city": "Казань"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	err = app.Services.PVZ.CreatePVZ(&pvz.PVZ{City: "Казань"})
+	assert.ErrorIs(t, err, pvz.ErrInvalidCity)
+}
+
+func post(t *testing.T, url, token, body string) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestNew_EventStream(t *testing.T) {
+	options := testOptions()
+	options.Registration.DummyLogin = true
+	options.Events = pvz.NewEventHub(0)
+	app, err := pvz.New(pvztest.NewRepositories(), options)
+	require.NoError(t, err)
+	server := httptest.NewServer(app.Handler)
+	t.Cleanup(server.Close)
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(post(t, server.URL+"/v1/dummyLogin", "", `{"role": "moderator"}`).Body).Decode(&login))
+	var created pvz.PVZ
+	require.NoError(t, json.NewDecoder(post(t, server.URL+"/v1/pvz", login.Token, `{"city": "Москва"}`).Body).Decode(&created))
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/pvz/"+created.ID+"/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+login.Token)
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, options.Events.Publish(req.Context(), &pvz.DomainEvent{
+		Sequence: 1,
+		ID:       "00000000-0000-0000-0000-000000000001",
+		Type:     "reception.opened",
+		PvzID:    created.ID,
+		Payload:  json.RawMessage(`{}`),
+	}))
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "id: 1\n", line, "the stream is not held back by the middleware")
+}
+
+func TestNew_EventStreamToken(t *testing.T) {
+	options := testOptions()
+	options.Registration.DummyLogin = true
+	options.Events = pvz.NewEventHub(0)
+	app, err := pvz.New(pvztest.NewRepositories(), options)
+	require.NoError(t, err)
+	server := httptest.NewServer(app.Handler)
+	t.Cleanup(server.Close)
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(post(t, server.URL+"/v1/dummyLogin", "", `{"role": "employee"}`).Body).Decode(&login))
+	var moderator struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(post(t, server.URL+"/v1/dummyLogin", "", `{"role": "moderator"}`).Body).Decode(&moderator))
+	var created pvz.PVZ
+	require.NoError(t, json.NewDecoder(post(t, server.URL+"/v1/pvz", moderator.Token, `{"city": "Москва"}`).Body).Decode(&created))
+
+	resp := post(t, server.URL+"/v1/pvz/"+created.ID+"/events/token", login.Token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var streamToken struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&streamToken))
+	assert.WithinDuration(t, time.Now().Add(time.Minute), streamToken.ExpiresAt, 5*time.Second)
+
+	open := func(path string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/event-stream")
+		client := &http.Client{Timeout: 5 * time.Second}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	// Like EventSource, the request has no Authorization header.
+	assert.Equal(t, http.StatusOK, open("/v1/pvz/"+created.ID+"/events?access_token="+streamToken.Token).StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, open("/v1/pvz/"+created.ID+"/events").StatusCode)
+
+	// The token is neither an access token nor valid for other pickup points.
+	resp = post(t, server.URL+"/v1/pvz", streamToken.Token, `{"city": "Казань"}`)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	var other pvz.PVZ
+	require.NoError(t, json.NewDecoder(post(t, server.URL+"/v1/pvz", moderator.Token, `{"city": "Казань"}`).Body).Decode(&other))
+	assert.Equal(t, http.StatusUnauthorized, open("/v1/pvz/"+other.ID+"/events?access_token="+streamToken.Token).StatusCode)
+}
+
+func TestNew_IdempotencyStoresNoCredentials(t *testing.T) {
+	options := testOptions()
+	options.Registration.DummyLogin = true
+	app, err := pvz.New(pvztest.NewRepositories(), options)
+	require.NoError(t, err)
+	server := httptest.NewServer(app.Handler)
+	t.Cleanup(server.Close)
+
+	resp := post(t, server.URL+"/v1/dummyLogin", "", `{"role": "moderator"}`)
+	var login struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&login))
+
+	invite := func() *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/invites",
+			strings.NewReader(`{"email": "employee@example.com", "role": "employee"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+login.Token)
+		req.Header.Set("Idempotency-Key", "key-1")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	first := invite()
+	require.Equal(t, http.StatusCreated, first.StatusCode)
+	second := invite()
+	require.Equal(t, http.StatusCreated, second.StatusCode)
+	assert.Empty(t, second.Header.Get("Idempotent-Replayed"), "responses with credentials are not replayed")
+}
+
+func TestNew_Protobuf(t *testing.T) {
+	options := testOptions()
+	options.Registration.DummyLogin = true
+	app, err := pvz.New(pvztest.NewRepositories(), options)
+	require.NoError(t, err)
+	server := httptest.NewServer(app.Handler)
+	t.Cleanup(server.Close)
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(post(t, server.URL+"/v1/dummyLogin", "", `{"role": "moderator"}`).Body).Decode(&login))
+
+	send := func(method, path string, message proto.Message) []byte {
+		var body []byte
+		if message != nil {
+			body, err = proto.Marshal(message)
+			require.NoError(t, err)
+		}
+		req, err := http.NewRequest(method, server.URL+path, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		req.Header.Set("Accept", "application/x-protobuf")
+		req.Header.Set("Authorization", "Bearer "+login.Token)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Less(t, resp.StatusCode, 300, string(data))
+		assert.Equal(t, "application/x-protobuf", resp.Header.Get("Content-Type"))
+		return data
+	}
+
+	var created pvzv1.PVZ
+	require.NoError(t, proto.Unmarshal(send(http.MethodPost, "/v1/pvz", &pvzv1.PVZ{City: "Казань"}), &created))
+	assert.NotEmpty(t, created.GetId())
+	assert.Equal(t, "Казань", created.GetCity())
+	assert.Equal(t, int32(1), created.GetVersion())
+
+	var list pvzv1.PVZList
+	require.NoError(t, proto.Unmarshal(send(http.MethodGet, "/v1/pvz", nil), &list))
+	require.Len(t, list.GetItems(), 1)
+	assert.Equal(t, created.GetId(), list.GetItems()[0].GetId())
+}
+
+func TestApp_NewGRPCServer(t *testing.T) {
+	app, err := pvz.New(pvztest.NewRepositories(), testOptions())
+	require.NoError(t, err)
+
+	info := app.NewGRPCServer().GetServiceInfo()
+	assert.Contains(t, info, pvzv1.PVZService_ServiceDesc.ServiceName)
+	assert.Contains(t, info, pvzv1.ReceptionService_ServiceDesc.ServiceName)
+	assert.Contains(t, info, pvzv1.ProductService_ServiceDesc.ServiceName)
+}
+
+func TestNew_IfMatch(t *testing.T) {
+	options := testOptions()
+	options.Registration.DummyLogin = true
+	app, err := pvz.New(pvztest.NewRepositories(), options)
+	require.NoError(t, err)
+	server := httptest.NewServer(app.Handler)
+	t.Cleanup(server.Close)
+
+	var moderator, employee struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(post(t, server.URL+"/v1/dummyLogin", "", `{"role": "moderator"}`).Body).Decode(&moderator))
+	require.NoError(t, json.NewDecoder(post(t, server.URL+"/v1/dummyLogin", "", `{"role": "employee"}`).Body).Decode(&employee))
+	var created pvz.PVZ
+	require.NoError(t, json.NewDecoder(post(t, server.URL+"/v1/pvz", moderator.Token, `{"city": "Москва"}`).Body).Decode(&created))
+
+	send := func(method, path, header, etag, body string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+employee.Token)
+		req.Header.Set("Content-Type", "application/json")
+		if etag != "" {
+			req.Header.Set(header, etag)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	receptions := "/v1/pvz/" + created.ID + "/receptions"
+	openReception := `{"pvzId": "` + created.ID + `"}`
+
+	resp := send(http.MethodGet, receptions, "", "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	seen := resp.Header.Get("ETag")
+	require.NotEmpty(t, seen)
+
+	resp = send(http.MethodPost, "/v1/receptions", "If-Match", seen, openReception)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp = send(http.MethodPost, "/v1/pvz/"+created.ID+"/close_last_reception", "If-Match", seen, "")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, "the receptions changed since the tag was seen")
+	resp = send(http.MethodPost, "/v1/products", "If-Match", seen, `{"pvzId": "`+created.ID+`", "type": "обувь"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp = send(http.MethodGet, receptions, "If-None-Match", seen, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	current := resp.Header.Get("ETag")
+	assert.NotEqual(t, seen, current)
+	assert.Equal(t, http.StatusNotModified, send(http.MethodGet, receptions, "If-None-Match", current, "").StatusCode)
+
+	resp = send(http.MethodPost, "/v1/pvz/"+created.ID+"/close_last_reception", "If-Match", current, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = send(http.MethodPost, "/v1/receptions", "If-Match", `"`+created.ID+`"`, openReception)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, "tags that are not versions never match")
+}
@@ -0,0 +1,802 @@
+// Package pvztest provides in-memory repositories for tests of programs
+// embedding the service, so that they run against the real router and
+// services without a database.
+package pvztest
+
+import (
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/models"
+	"avito-intern/pkg/pvz"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewRepositories returns empty repositories sharing one store. The
+// repositories hand out copies, like a database would. Two-factor
+// authentication is never enrolled, API key nonces are not tracked and
+// PVZAssignments is nil.
+func NewRepositories() pvz.Repositories {
+	store := newMemoryStore()
+	return pvz.Repositories{
+		Users:          memoryUsers{store},
+		PVZ:            memoryPVZ{store},
+		Receptions:     memoryReceptions{store},
+		Products:       memoryProducts{store},
+		Invites:        memoryInvites{store},
+		PasswordResets: memoryPasswordResets{store},
+		LoginAttempts:  memoryLoginAttempts{store},
+		TwoFactor:      memoryTwoFactor{},
+		APIKeys:        memoryAPIKeys{store},
+		Audit:          memoryAudit{store},
+		PersonalData:   memoryPersonalData{store},
+		Idempotency:    memoryIdempotency{store},
+		Webhooks:       memoryWebhooks{store},
+	}
+}
+
+type memoryStore struct {
+	mu             sync.Mutex
+	users          []*models.User
+	pvzs           []*models.PVZ
+	receptions     []*models.Reception
+	products       []*models.Product
+	invites        []*models.Invite
+	apiKeys        []*models.APIKey
+	auditEvents    []*models.AuditEvent
+	idempotency    map[string]*models.IdempotencyKey
+	loginAttempts  map[string]*models.LoginAttempt
+	passwordResets []*models.PasswordResetToken
+	webhooks       []*models.WebhookSubscription
+	deliveries     []*models.WebhookDelivery
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		idempotency:   make(map[string]*models.IdempotencyKey),
+		loginAttempts: make(map[string]*models.LoginAttempt),
+	}
+}
+
+type memoryUsers struct{ *memoryStore }
+
+func (s memoryUsers) CreateUser(user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.Email == user.Email {
+			return internalErrors.ErrEmailExists
+		}
+	}
+	stored := *user
+	s.users = append(s.users, &stored)
+	return nil
+}
+
+func (s memoryUsers) GetUserByEmail(email string) (*models.User, error) {
+	return s.find(func(u *models.User) bool { return u.Email == email })
+}
+
+func (s memoryUsers) GetUserByID(id string) (*models.User, error) {
+	return s.find(func(u *models.User) bool { return u.ID == id })
+}
+
+func (s memoryUsers) find(match func(*models.User) bool) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if match(u) {
+			user := *u
+			return &user, nil
+		}
+	}
+	return nil, internalErrors.ErrUserNotFound
+}
+
+func (s memoryUsers) filter(email, role, status string) []*models.User {
+	var users []*models.User
+	for _, u := range s.users {
+		if strings.Contains(u.Email, email) && (role == "" || u.Role == role) && (status == "" || u.Status == status) {
+			user := *u
+			users = append(users, &user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Email < users[j].Email })
+	return users
+}
+
+func (s memoryUsers) ListUsers(email, role, status string, limit, offset int) ([]*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return page(s.filter(email, role, status), limit, offset), nil
+}
+
+func (s memoryUsers) CountUsers(email, role, status string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.filter(email, role, status)), nil
+}
+
+func (s memoryUsers) UpdateUserStatus(id, status string) error {
+	return s.update(id, func(u *models.User) { u.Status = status })
+}
+
+func (s memoryUsers) UpdateUserRole(id, role string) error {
+	return s.update(id, func(u *models.User) { u.Role = role })
+}
+
+func (s memoryUsers) UpdateUserPassword(id, password string) error {
+	return s.update(id, func(u *models.User) { u.Password = password })
+}
+
+func (s memoryUsers) update(id string, change func(*models.User)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.ID == id {
+			change(u)
+			u.TokenVersion++
+			return nil
+		}
+	}
+	return internalErrors.ErrUserNotFound
+}
+
+type memoryPVZ struct{ *memoryStore }
+
+func (s memoryPVZ) CreatePVZ(pvz *models.PVZ) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *pvz
+	s.pvzs = append(s.pvzs, &stored)
+	return nil
+}
+
+func (s memoryPVZ) ListPVZ(limit, offset int, startDate, endDate *time.Time) ([]*models.PVZ, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pvzs []*models.PVZ
+	for _, p := range s.pvzs {
+		if (startDate == nil || !p.RegistrationDate.Before(*startDate)) && (endDate == nil || !p.RegistrationDate.After(*endDate)) {
+			pvz := *p
+			pvzs = append(pvzs, &pvz)
+		}
+	}
+	return page(pvzs, limit, offset), nil
+}
+
+func (s memoryPVZ) GetPVZByID(id string) (*models.PVZ, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.pvzs {
+		if p.ID == id {
+			pvz := *p
+			return &pvz, nil
+		}
+	}
+	return nil, internalErrors.ErrPVZNotFound
+}
+
+func (s memoryPVZ) CountPVZ() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pvzs), nil
+}
+
+func (s memoryPVZ) UpdatePVZ(pvz *models.PVZ, expectedVersion int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.pvzs {
+		if p.ID != pvz.ID {
+			continue
+		}
+		if p.Version != expectedVersion {
+			current := *p
+			return &internalErrors.ConflictError{Err: internalErrors.ErrVersionConflict, Current: &current}
+		}
+		p.City = pvz.City
+		p.Version++
+		pvz.Version = p.Version
+		return nil
+	}
+	return internalErrors.ErrPVZNotFound
+}
+
+type memoryReceptions struct{ *memoryStore }
+
+func (s memoryReceptions) CreateReception(reception *models.Reception, receptionsVersion int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.bumpReceptionsVersion(reception.PvzID, receptionsVersion); err != nil {
+		return err
+	}
+	stored := *reception
+	s.receptions = append(s.receptions, &stored)
+	return nil
+}
+
+func (s memoryReceptions) GetActiveReception(pvzID string) (*models.Reception, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.receptions) - 1; i >= 0; i-- {
+		if r := s.receptions[i]; r.PvzID == pvzID && r.Status == "in_progress" {
+			reception := *r
+			return &reception, nil
+		}
+	}
+	return nil, internalErrors.ErrNoActiveReception
+}
+
+func (s memoryReceptions) CloseReception(receptionID string, expectedVersion, receptionsVersion int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.receptions {
+		if r.ID != receptionID {
+			continue
+		}
+		if r.Version != expectedVersion {
+			current := *r
+			return &internalErrors.ConflictError{Err: internalErrors.ErrVersionConflict, Current: &current}
+		}
+		if err := s.bumpReceptionsVersion(r.PvzID, receptionsVersion); err != nil {
+			return err
+		}
+		r.Status = "close"
+		r.Version++
+		return nil
+	}
+	return errors.New("no reception updated")
+}
+
+func (s memoryReceptions) ListReceptions(pvzIDs []string) ([]*models.Reception, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var receptions []*models.Reception
+	for _, r := range s.receptions {
+		for _, id := range pvzIDs {
+			if r.PvzID == id {
+				reception := *r
+				receptions = append(receptions, &reception)
+			}
+		}
+	}
+	return receptions, nil
+}
+
+type memoryProducts struct{ *memoryStore }
+
+func (s memoryProducts) AddProduct(product *models.Product, receptionsVersion int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.bumpReceptionsVersion(s.receptionPVZ(product.ReceptionID), receptionsVersion); err != nil {
+		return err
+	}
+	stored := *product
+	s.products = append(s.products, &stored)
+	return nil
+}
+
+func (s memoryProducts) GetLastProduct(receptionID string) (*models.Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.products) - 1; i >= 0; i-- {
+		if p := s.products[i]; p.ReceptionID == receptionID {
+			product := *p
+			return &product, nil
+		}
+	}
+	return nil, internalErrors.ErrProductNotFound
+}
+
+func (s memoryProducts) DeleteProduct(id string, receptionsVersion int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, p := range s.products {
+		if p.ID == id {
+			if err := s.bumpReceptionsVersion(s.receptionPVZ(p.ReceptionID), receptionsVersion); err != nil {
+				return err
+			}
+			s.products = append(s.products[:i], s.products[i+1:]...)
+			return nil
+		}
+	}
+	return internalErrors.ErrProductNotFound
+}
+
+func (s memoryProducts) ListProducts(receptionIDs []string) ([]*models.Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var products []*models.Product
+	for _, p := range s.products {
+		for _, id := range receptionIDs {
+			if p.ReceptionID == id {
+				product := *p
+				products = append(products, &product)
+			}
+		}
+	}
+	return products, nil
+}
+
+// bumpReceptionsVersion increments the receptions version of the pickup
+// point, which a positive expectedVersion has to match. s.mu is held.
+func (s *memoryStore) bumpReceptionsVersion(pvzID string, expectedVersion int) error {
+	for _, p := range s.pvzs {
+		if p.ID != pvzID {
+			continue
+		}
+		if expectedVersion > 0 && p.ReceptionsVersion != expectedVersion {
+			break
+		}
+		p.ReceptionsVersion++
+		return nil
+	}
+	if expectedVersion > 0 {
+		return internalErrors.ErrVersionConflict
+	}
+	return nil
+}
+
+// receptionPVZ returns the pickup point of the reception. s.mu is held.
+func (s *memoryStore) receptionPVZ(receptionID string) string {
+	for _, r := range s.receptions {
+		if r.ID == receptionID {
+			return r.PvzID
+		}
+	}
+	return ""
+}
+
+type memoryInvites struct{ *memoryStore }
+
+func (s memoryInvites) CreateInvite(invite *models.Invite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *invite
+	s.invites = append(s.invites, &stored)
+	return nil
+}
+
+func (s memoryInvites) GetInviteByTokenHash(tokenHash string) (*models.Invite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, i := range s.invites {
+		if i.TokenHash == tokenHash {
+			invite := *i
+			return &invite, nil
+		}
+	}
+	return nil, internalErrors.ErrInvalidInvite
+}
+
+func (s memoryInvites) AcceptInvite(id string, usedAt time.Time, user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var invite *models.Invite
+	for _, i := range s.invites {
+		if i.ID == id && i.UsedAt == nil {
+			invite = i
+		}
+	}
+	if invite == nil {
+		return internalErrors.ErrInvalidInvite
+	}
+	for _, u := range s.users {
+		if u.Email == user.Email {
+			return internalErrors.ErrEmailExists
+		}
+	}
+	invite.UsedAt = &usedAt
+	stored := *user
+	s.users = append(s.users, &stored)
+	return nil
+}
+
+type memoryPasswordResets struct{ *memoryStore }
+
+func (s memoryPasswordResets) CreateResetToken(token *models.PasswordResetToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *token
+	s.passwordResets = append(s.passwordResets, &stored)
+	return nil
+}
+
+func (s memoryPasswordResets) GetResetTokenByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.passwordResets {
+		if t.TokenHash == tokenHash {
+			token := *t
+			return &token, nil
+		}
+	}
+	return nil, internalErrors.ErrInvalidResetToken
+}
+
+func (s memoryPasswordResets) MarkResetTokenUsed(id string, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.passwordResets {
+		if t.ID == id {
+			t.UsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func (s memoryPasswordResets) InvalidateResetTokens(userID string, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.passwordResets {
+		if t.UserID == userID && t.UsedAt == nil {
+			t.UsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+type memoryLoginAttempts struct{ *memoryStore }
+
+func (s memoryLoginAttempts) GetLoginAttempt(key string) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if attempt, ok := s.loginAttempts[key]; ok {
+		stored := *attempt
+		return &stored, nil
+	}
+	return &models.LoginAttempt{Key: key}, nil
+}
+
+func (s memoryLoginAttempts) RecordFailure(key string, at, windowStart time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.loginAttempts[key]
+	if !ok {
+		attempt = &models.LoginAttempt{Key: key}
+		s.loginAttempts[key] = attempt
+	}
+	if attempt.LastFailureAt.Before(windowStart) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = at
+	return attempt.Failures, nil
+}
+
+func (s memoryLoginAttempts) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if attempt, ok := s.loginAttempts[key]; ok {
+		attempt.LockedUntil = &until
+	}
+	return nil
+}
+
+func (s memoryLoginAttempts) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.loginAttempts, key)
+	return nil
+}
+
+// memoryTwoFactor has no enrolled users.
+type memoryTwoFactor struct{}
+
+func (memoryTwoFactor) SaveTOTPSecret(*models.TOTPSecret) error { return nil }
+func (memoryTwoFactor) GetTOTPSecret(string) (*models.TOTPSecret, error) {
+	return nil, internalErrors.ErrTwoFactorNotEnrolled
+}
+func (memoryTwoFactor) ConfirmTOTPSecret(string) error                  { return nil }
+func (memoryTwoFactor) UseTOTPStep(string, int64) error                 { return nil }
+func (memoryTwoFactor) ReplaceRecoveryCodes(string, []string) error     { return nil }
+func (memoryTwoFactor) UseRecoveryCode(string, string, time.Time) error { return nil }
+
+type memoryIdempotency struct{ *memoryStore }
+
+func (s memoryIdempotency) CreateIdempotencyKey(key *models.IdempotencyKey) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := key.Scope + " " + key.Key
+	if _, ok := s.idempotency[id]; ok {
+		return false, nil
+	}
+	stored := *key
+	s.idempotency[id] = &stored
+	return true, nil
+}
+
+func (s memoryIdempotency) GetIdempotencyKey(scope, key string) (*models.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stored, ok := s.idempotency[scope+" "+key]; ok {
+		copied := *stored
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (s memoryIdempotency) CompleteIdempotencyKey(key *models.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stored, ok := s.idempotency[key.Scope+" "+key.Key]; ok {
+		stored.StatusCode = key.StatusCode
+		stored.ResponseHeaders = key.ResponseHeaders
+		stored.ResponseBody = key.ResponseBody
+	}
+	return nil
+}
+
+func (s memoryIdempotency) DeleteIdempotencyKey(scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.idempotency, scope+" "+key)
+	return nil
+}
+
+type memoryAPIKeys struct{ *memoryStore }
+
+func (s memoryAPIKeys) CreateAPIKey(key *models.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *key
+	s.apiKeys = append(s.apiKeys, &stored)
+	return nil
+}
+
+func (s memoryAPIKeys) GetAPIKeyByID(id string) (*models.APIKey, error) {
+	return s.find(func(k *models.APIKey) bool { return k.ID == id })
+}
+
+func (s memoryAPIKeys) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	return s.find(func(k *models.APIKey) bool { return k.KeyHash == keyHash })
+}
+
+func (s memoryAPIKeys) find(match func(*models.APIKey) bool) (*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.apiKeys {
+		if match(k) {
+			key := *k
+			return &key, nil
+		}
+	}
+	return nil, internalErrors.ErrAPIKeyNotFound
+}
+
+func (s memoryAPIKeys) ListAPIKeys() ([]*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]*models.APIKey, 0, len(s.apiKeys))
+	for _, k := range s.apiKeys {
+		key := *k
+		keys = append(keys, &key)
+	}
+	return keys, nil
+}
+
+func (s memoryAPIKeys) RevokeAPIKey(id string, revokedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.apiKeys {
+		if k.ID == id {
+			k.RevokedAt = &revokedAt
+			return nil
+		}
+	}
+	return internalErrors.ErrAPIKeyNotFound
+}
+
+func (s memoryAPIKeys) TouchAPIKey(string, time.Time) error { return nil }
+
+func (s memoryAPIKeys) UseNonce(string, string, time.Time, time.Time) error { return nil }
+
+// memoryWebhooks keeps subscriptions and deliveries. Nothing queues
+// deliveries by itself: the service publishes events through the outbox,
+// which is not part of the repositories.
+type memoryWebhooks struct{ *memoryStore }
+
+func (s memoryWebhooks) CreateWebhookSubscription(subscription *models.WebhookSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *subscription
+	s.webhooks = append(s.webhooks, &stored)
+	return nil
+}
+
+func (s memoryWebhooks) GetWebhookSubscription(id string) (*models.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.webhooks {
+		if w.ID == id {
+			subscription := *w
+			return &subscription, nil
+		}
+	}
+	return nil, internalErrors.ErrWebhookNotFound
+}
+
+func (s memoryWebhooks) ListWebhookSubscriptions() ([]*models.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscriptions := make([]*models.WebhookSubscription, 0, len(s.webhooks))
+	for i := len(s.webhooks) - 1; i >= 0; i-- {
+		subscription := *s.webhooks[i]
+		subscriptions = append(subscriptions, &subscription)
+	}
+	return subscriptions, nil
+}
+
+func (s memoryWebhooks) DeleteWebhookSubscription(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.webhooks {
+		if w.ID == id {
+			s.webhooks = append(s.webhooks[:i], s.webhooks[i+1:]...)
+			kept := s.deliveries[:0]
+			for _, d := range s.deliveries {
+				if d.SubscriptionID != id {
+					kept = append(kept, d)
+				}
+			}
+			s.deliveries = kept
+			return nil
+		}
+	}
+	return internalErrors.ErrWebhookNotFound
+}
+
+func (s memoryWebhooks) CreateWebhookDeliveries(deliveries []*models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, delivery := range deliveries {
+		queued := false
+		for _, d := range s.deliveries {
+			queued = queued || (d.SubscriptionID == delivery.SubscriptionID && d.EventID == delivery.EventID)
+		}
+		if !queued {
+			stored := *delivery
+			s.deliveries = append(s.deliveries, &stored)
+		}
+	}
+	return nil
+}
+
+func (s memoryWebhooks) ClaimWebhookDeliveries(limit int, now, leaseUntil time.Time) ([]*models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var claimed []*models.WebhookDelivery
+	for _, d := range s.deliveries {
+		if len(claimed) < limit && d.Status == models.WebhookDeliveryPending && !d.NextAttemptAt.After(now) {
+			d.NextAttemptAt = leaseUntil
+			delivery := *d
+			claimed = append(claimed, &delivery)
+		}
+	}
+	return claimed, nil
+}
+
+func (s memoryWebhooks) RecordWebhookAttempt(delivery *models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deliveries {
+		if d.ID == delivery.ID {
+			*d = *delivery
+		}
+	}
+	return nil
+}
+
+func (s memoryWebhooks) ListWebhookDeliveries(subscriptionID, status string, limit, offset int) ([]*models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deliveries []*models.WebhookDelivery
+	for i := len(s.deliveries) - 1; i >= 0; i-- {
+		d := s.deliveries[i]
+		if (subscriptionID == "" || d.SubscriptionID == subscriptionID) && (status == "" || d.Status == status) {
+			delivery := *d
+			deliveries = append(deliveries, &delivery)
+		}
+	}
+	return page(deliveries, limit, offset), nil
+}
+
+func (s memoryWebhooks) ResendWebhookDelivery(id string, at time.Time) (*models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deliveries {
+		if d.ID == id {
+			d.Status = models.WebhookDeliveryPending
+			d.Attempts = 0
+			d.NextAttemptAt = at
+			delivery := *d
+			return &delivery, nil
+		}
+	}
+	return nil, internalErrors.ErrDeliveryNotFound
+}
+
+type memoryAudit struct{ *memoryStore }
+
+func (s memoryAudit) CreateAuditEvent(event *models.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *event
+	s.auditEvents = append(s.auditEvents, &stored)
+	return nil
+}
+
+func (s memoryAudit) ListAuditEvents(actorID, userID string, limit, offset int) ([]*models.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []*models.AuditEvent
+	for i := len(s.auditEvents) - 1; i >= 0; i-- {
+		e := s.auditEvents[i]
+		if (actorID == "" || e.ActorID == actorID) && (userID == "" || e.UserID == userID) {
+			event := *e
+			events = append(events, &event)
+		}
+	}
+	return page(events, limit, offset), nil
+}
+
+type memoryPersonalData struct{ *memoryStore }
+
+// ExportUserData exports the account and the audit events of the user.
+func (s memoryPersonalData) ExportUserData(user *models.User, attemptKey string) (*models.UserDataExport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account := *user
+	export := &models.UserDataExport{
+		Account:         &account,
+		LoginAttempts:   []*models.LoginAttempt{},
+		PasswordResets:  []*models.PasswordResetToken{},
+		InvitesCreated:  []*models.Invite{},
+		InvitesReceived: []*models.Invite{},
+		APIKeys:         []*models.APIKey{},
+		PVZIDs:          []string{},
+		AuditEvents:     []*models.AuditEvent{},
+	}
+	for _, e := range s.auditEvents {
+		if e.UserID == user.ID || e.ActorID == user.ID {
+			event := *e
+			export.AuditEvents = append(export.AuditEvents, &event)
+		}
+	}
+	return export, nil
+}
+
+func (s memoryPersonalData) EraseUser(erasure models.UserErasure) error {
+	err := memoryUsers(s).update(erasure.UserID, func(u *models.User) {
+		u.Email = erasure.PseudonymEmail
+		u.Password = erasure.PasswordHash
+		u.Status = "erased"
+	})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.loginAttempts, erasure.AttemptKey)
+	for _, k := range s.apiKeys {
+		if k.CreatedBy == erasure.UserID && k.RevokedAt == nil {
+			erasedAt := erasure.ErasedAt
+			k.RevokedAt = &erasedAt
+		}
+	}
+	if erasure.Audit != nil {
+		stored := *erasure.Audit
+		s.auditEvents = append(s.auditEvents, &stored)
+	}
+	return nil
+}
+
+func page[T any](items []T, limit, offset int) []T {
+	result := make([]T, 0, limit)
+	for i := offset; i < len(items) && len(result) < limit; i++ {
+		result = append(result, items[i])
+	}
+	return result
+}
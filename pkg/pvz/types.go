@@ -0,0 +1,152 @@
+package pvz
+
+import (
+	"avito-intern/internal/api"
+	"avito-intern/internal/api/dto/internalErrors"
+	"avito-intern/internal/api/dto/request/apiKeyDto"
+	"avito-intern/internal/api/dto/request/authDto"
+	"avito-intern/internal/api/dto/request/inviteDto"
+	"avito-intern/internal/api/dto/request/productDto"
+	"avito-intern/internal/api/dto/request/webhookDto"
+	graphqlserver "avito-intern/internal/graphql/server"
+	"avito-intern/internal/ingest"
+	"avito-intern/internal/mailer"
+	"avito-intern/internal/models"
+	"avito-intern/internal/oidc"
+	"avito-intern/internal/outbox"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/services"
+	"avito-intern/internal/stream"
+	"avito-intern/internal/webhook"
+)
+
+// Models stored by the repositories.
+type (
+	User                  = models.User
+	PVZ                   = models.PVZ
+	Reception             = models.Reception
+	Product               = models.Product
+	ReceptionWithProducts = models.ReceptionWithProducts
+	Invite                = models.Invite
+	PasswordResetToken    = models.PasswordResetToken
+	LoginAttempt          = models.LoginAttempt
+	TOTPSecret            = models.TOTPSecret
+	APIKey                = models.APIKey
+	AuditEvent            = models.AuditEvent
+	IdempotencyKey        = models.IdempotencyKey
+	UserDataExport        = models.UserDataExport
+	UserErasure           = models.UserErasure
+	WebhookSubscription   = models.WebhookSubscription
+	WebhookDelivery       = models.WebhookDelivery
+	DomainEvent           = models.OutboxEvent
+)
+
+// Repositories the services are built on. NewPostgresRepositories returns
+// the implementations used by the service itself.
+type (
+	UserRepository          = repository.UserRepositoryInterface
+	PVZRepository           = repository.PVZRepositoryInterface
+	ReceptionRepository     = repository.ReceptionRepositoryInterface
+	ProductRepository       = repository.ProductRepositoryInterface
+	InviteRepository        = repository.InviteRepositoryInterface
+	PasswordResetRepository = repository.PasswordResetRepositoryInterface
+	LoginAttemptRepository  = repository.LoginAttemptRepositoryInterface
+	TwoFactorRepository     = repository.TwoFactorRepositoryInterface
+	APIKeyRepository        = repository.APIKeyRepositoryInterface
+	AuditRepository         = repository.AuditRepositoryInterface
+	PersonalDataRepository  = repository.PersonalDataRepositoryInterface
+	IdempotencyRepository   = repository.IdempotencyRepositoryInterface
+	PVZAssignmentRepository = repository.PVZAssignmentRepositoryInterface
+	WebhookRepository       = repository.WebhookRepositoryInterface
+)
+
+// Services returned by New.
+type (
+	AuthService          = services.AuthService
+	LoginAttemptService  = services.LoginAttemptService
+	TwoFactorService     = services.TwoFactorService
+	PVZService           = services.PVZService
+	ReceptionService     = services.ReceptionService
+	ProductService       = services.ProductService
+	UserService          = services.UserService
+	OIDCService          = services.OIDCService
+	APIKeyService        = services.APIKeyService
+	ImpersonationService = services.ImpersonationService
+	PrivacyService       = services.PrivacyService
+	IdempotencyService   = services.IdempotencyService
+	SCIMService          = services.SCIMService
+	WebhookService       = services.WebhookService
+)
+
+// Arguments and results of service methods.
+type (
+	LoginResult          = services.LoginResult
+	RegisterRequest      = authDto.RegisterRequest
+	LoginRequest         = authDto.LoginRequest
+	CreateInviteRequest  = inviteDto.CreateInviteRequest
+	CreateProductRequest = productDto.CreateProductRequest
+	CreateAPIKeyRequest  = apiKeyDto.CreateAPIKeyRequest
+	CreateWebhookRequest = webhookDto.CreateWebhookRequest
+)
+
+// Policies and collaborators of the services and the router, see Options.
+type (
+	RegistrationPolicy = services.RegistrationPolicy
+	LockoutPolicy      = services.LockoutPolicy
+	TwoFactorPolicy    = services.TwoFactorPolicy
+	APIKeyPolicy       = services.APIKeyPolicy
+	OIDCPolicy         = services.OIDCPolicy
+	TokenVerifier      = services.TokenVerifier
+	GraphQLLimits      = graphqlserver.Limits
+	LegacyRoutes       = api.LegacyRoutes
+	Mailer             = mailer.Mailer
+	MailMessage        = mailer.Message
+	EventHub           = stream.Hub
+	EventListener      = stream.Listener
+	OIDCConfig         = oidc.Config
+)
+
+// Collaborators and policies of the background workers, see WorkerOptions.
+type (
+	EventPublisher   = outbox.Publisher
+	CommandConsumer  = ingest.Consumer
+	RelayPolicy      = outbox.RelayPolicy
+	DispatcherPolicy = webhook.DispatcherPolicy
+	IngestorPolicy   = ingest.IngestorPolicy
+)
+
+// ConflictError is returned by updates of a stale version, with the current
+// state of the entity.
+type ConflictError = internalErrors.ConflictError
+
+// Errors repositories return so that the API answers with the matching
+// problem instead of an internal error.
+var (
+	ErrUserNotFound          = internalErrors.ErrUserNotFound
+	ErrEmailExists           = internalErrors.ErrEmailExists
+	ErrPVZNotFound           = internalErrors.ErrPVZNotFound
+	ErrNoActiveReception     = internalErrors.ErrNoActiveReception
+	ErrActiveReceptionExists = internalErrors.ErrActiveReceptionExists
+	ErrProductNotFound       = internalErrors.ErrProductNotFound
+	ErrInvalidInvite         = internalErrors.ErrInvalidInvite
+	ErrInvalidResetToken     = internalErrors.ErrInvalidResetToken
+	ErrTwoFactorNotEnrolled  = internalErrors.ErrTwoFactorNotEnrolled
+	ErrTwoFactorEnabled      = internalErrors.ErrTwoFactorEnabled
+	ErrInvalidTwoFactorCode  = internalErrors.ErrInvalidTwoFactorCode
+	ErrAPIKeyNotFound        = internalErrors.ErrAPIKeyNotFound
+	ErrReplayedRequest       = internalErrors.ErrReplayedRequest
+	ErrWebhookNotFound       = internalErrors.ErrWebhookNotFound
+	ErrDeliveryNotFound      = internalErrors.ErrDeliveryNotFound
+	ErrVersionConflict       = internalErrors.ErrVersionConflict
+	ErrAlreadyExists         = internalErrors.ErrAlreadyExists
+	ErrReferenceNotFound     = internalErrors.ErrReferenceNotFound
+	ErrConstraintViolation   = internalErrors.ErrConstraintViolation
+	ErrInvalidID             = internalErrors.ErrInvalidID
+)
+
+// Errors of the built-in checks. Errors of Options.ValidateCity and
+// Options.ValidateProductType are wrapped in them.
+var (
+	ErrInvalidCity        = internalErrors.ErrInvalidCity
+	ErrInvalidProductType = internalErrors.ErrInvalidProductType
+)
@@ -0,0 +1,90 @@
+package pvz
+
+import (
+	"avito-intern/internal/ingest"
+	"avito-intern/internal/outbox"
+	"avito-intern/internal/repository"
+	"avito-intern/internal/webhook"
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// WorkerOptions configure the background work of RunPostgresWorkers. Zero
+// policy fields get the defaults of the workers.
+type WorkerOptions struct {
+	// Publisher gets the domain events of the outbox, next to the webhook
+	// subscriptions. Nil logs them.
+	Publisher EventPublisher
+	Relay     RelayPolicy
+	Webhooks  DispatcherPolicy
+
+	// Consumer takes the commands of the warehouse management system from
+	// a broker. Nil leaves the ingestion off.
+	Consumer CommandConsumer
+	Ingest   IngestorPolicy
+}
+
+// DefaultWorkerOptions returns the worker options of the standalone
+// service.
+func DefaultWorkerOptions() WorkerOptions {
+	return WorkerOptions{
+		Relay: RelayPolicy{
+			BatchSize:    100,
+			PollInterval: time.Second,
+			BaseBackoff:  time.Second,
+			MaxBackoff:   5 * time.Minute,
+			Retention:    7 * 24 * time.Hour,
+		},
+		Webhooks: DispatcherPolicy{
+			Timeout:     10 * time.Second,
+			BaseBackoff: 10 * time.Second,
+			MaxBackoff:  time.Hour,
+			MaxAttempts: 8,
+		},
+		Ingest: IngestorPolicy{
+			MaxAttempts: 5,
+			Retention:   7 * 24 * time.Hour,
+		},
+	}
+}
+
+// RunPostgresWorkers relays the outbox of db to the publisher and the
+// webhook subscriptions, sends the webhook deliveries and, with a
+// Consumer, carries out the commands of the broker until ctx is done. The
+// app has to be built on NewPostgresRepositories of the same db.
+func (a *App) RunPostgresWorkers(ctx context.Context, db *sql.DB, options WorkerOptions) {
+	publisher := options.Publisher
+	if publisher == nil {
+		publisher = outbox.NewLogPublisher()
+	}
+	webhooks := repository.NewWebhookRepository(db)
+	workers := []func(context.Context){
+		outbox.NewRelay(
+			repository.NewOutboxRepository(db),
+			outbox.Fanout{publisher, webhook.NewPublisher(webhooks)},
+			options.Relay,
+		).Run,
+		webhook.NewDispatcher(webhooks, nil, options.Webhooks).Run,
+	}
+	if options.Consumer != nil {
+		workers = append(workers, ingest.NewIngestor(
+			options.Consumer,
+			repository.NewInboundRepository(db),
+			a.Services.Receptions,
+			a.Services.Products,
+			options.Ingest,
+		).Run)
+	}
+
+	var wg sync.WaitGroup
+	for _, run := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run(ctx)
+		}()
+	}
+	wg.Wait()
+}
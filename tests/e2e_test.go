@@ -3,9 +3,11 @@ package tests
 import (
 	"avito-intern/internal/api"
 	"avito-intern/internal/api/dto/request/authDto"
+	"avito-intern/internal/api/dto/request/inviteDto"
 	"avito-intern/internal/api/dto/request/productDto"
 	"avito-intern/internal/api/dto/request/receptionDto"
 	"avito-intern/internal/api/dto/response"
+	"avito-intern/internal/mailer"
 	"avito-intern/internal/models"
 	"avito-intern/internal/repository"
 	"avito-intern/internal/services"
@@ -112,21 +114,50 @@ func applyMigrations(db *sql.DB) error {
 
 func setupTestServer(db *sql.DB) *chi.Mux {
 	userRepo := repository.NewUserRepository(db)
+	inviteRepo := repository.NewInviteRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
 	pvzRepo := repository.NewPVZRepository(db)
 	receptionRepo := repository.NewReceptionRepository(db)
 	productRepo := repository.NewProductRepository(db)
 
-	authService := services.NewAuthService(userRepo)
+	loginAttemptService := services.NewLoginAttemptService(userRepo, loginAttemptRepo, services.LockoutPolicy{
+		AccountThreshold: 5,
+		IPThreshold:      20,
+		LockoutDuration:  time.Minute,
+	})
+	twoFactorService := services.NewTwoFactorService(userRepo, twoFactorRepo, loginAttemptService, services.TwoFactorPolicy{})
+	authService := services.NewAuthService(userRepo, inviteRepo, passwordResetRepo, loginAttemptService, twoFactorService, mailer.NewLogMailer(), services.RegistrationPolicy{
+		EmployeeSelfRegistration: true,
+	})
+	if err := authService.EnsureAdmin("admin@test.com", "password"); err != nil {
+		panic(err)
+	}
 	pvzService := services.NewPVZService(pvzRepo)
 	receptionService := services.NewReceptionService(receptionRepo)
 	productService := services.NewProductService(productRepo, receptionRepo)
-
-	return api.SetupRouter(
-		authService,
-		pvzService,
-		receptionService,
-		productService,
-	)
+	userService := services.NewUserService(userRepo)
+	apiKeyService := services.NewAPIKeyService(repository.NewAPIKeyRepository(db), services.APIKeyPolicy{})
+	auditRepo := repository.NewAuditRepository(db)
+	impersonationService := services.NewImpersonationService(userRepo, auditRepo, 0)
+	privacyService := services.NewPrivacyService(userRepo, repository.NewPersonalDataRepository(db), auditRepo)
+
+	return api.SetupRouter(api.Config{
+		Auth:              authService,
+		PVZ:               pvzService,
+		Receptions:        receptionService,
+		Products:          productService,
+		Users:             userService,
+		LoginAttempts:     loginAttemptService,
+		TwoFactor:         twoFactorService,
+		APIKeys:           apiKeyService,
+		Impersonation:     impersonationService,
+		Privacy:           privacyService,
+		Idempotency:       services.NewIdempotencyService(repository.NewIdempotencyRepository(db), time.Hour),
+		Webhooks:          services.NewWebhookService(repository.NewWebhookRepository(db)),
+		ValidateResponses: true,
+	})
 }
 
 func TestE2EWorkflow(t *testing.T) {
@@ -144,9 +175,13 @@ func TestE2EWorkflow(t *testing.T) {
 
 	client := &http.Client{}
 
-	_ = registerTestUser(t, client, server.URL, "moderator@test.com", "password", "moderator")
+	adminToken := loginTestUser(t, client, server.URL, "admin@test.com", "password")
+
+	inviteToken := createTestInvite(t, client, server.URL, adminToken, "moderator@test.com", "moderator")
 
-	_ = registerTestUser(t, client, server.URL, "employee@test.com", "password", "employee")
+	_ = registerTestUser(t, client, server.URL, "moderator@test.com", "password", "moderator", inviteToken)
+
+	_ = registerTestUser(t, client, server.URL, "employee@test.com", "password", "employee", "")
 
 	moderatorToken := loginTestUser(t, client, server.URL, "moderator@test.com", "password")
 
@@ -179,11 +214,39 @@ func TestE2EWorkflow(t *testing.T) {
 	assert.Equal(t, "close", status)
 }
 
-func registerTestUser(t *testing.T, client *http.Client, baseURL, email, password, role string) response.UserResponse {
+func createTestInvite(t *testing.T, client *http.Client, baseURL, token, email, role string) string {
+	inviteReq := inviteDto.CreateInviteRequest{
+		Email: email,
+		Role:  role,
+	}
+
+	reqBody, err := json.Marshal(inviteReq)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("POST", baseURL+"/invites", bytes.NewBuffer(reqBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var inviteResp response.InviteResponse
+	err = json.NewDecoder(resp.Body).Decode(&inviteResp)
+	require.NoError(t, err)
+
+	return inviteResp.Token
+}
+
+func registerTestUser(t *testing.T, client *http.Client, baseURL, email, password, role, inviteToken string) response.UserResponse {
 	registerReq := authDto.RegisterRequest{
-		Email:    email,
-		Password: password,
-		Role:     role,
+		Email:       email,
+		Password:    password,
+		Role:        role,
+		InviteToken: inviteToken,
 	}
 
 	reqBody, err := json.Marshal(registerReq)